@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/migration"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/pkg/config"
+	_ "modernc.org/sqlite"
+)
+
+// command はサーバーバイナリのサブコマンド。どれも config の環境変数で同じデータベースにつなぐ。
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, args []string, out io.Writer) error
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"serve", "run the API server (default)", serve},
+		{"migrate", "migrate up|down|status: apply pending migrations, roll back the last one, or list them", migrate},
+		{"rebuild-latest", "verify (-verify) or rebuild v3_user_latest_* from the save history, for one user (-user) or all in resumable batches", rebuildLatest},
+		{"recompute-stats", "compare the v4 summaries with a full recomputation (-apply to fix drift)", recomputeStats},
+		{"prune-saves", "thin old saves by the retention policy (-apply to delete)", pruneSaves},
+		{"promote-save-field", "move a save key kept in extra_fields into a column of the stored saves (-apply to update)", promoteSaveField},
+		{"export-user", "export-user <user_id>: print everything stored about a user as JSON", exportUser},
+		{"verify-sig", "verify-sig save|user: check a client signature against the configured secrets", verifySig},
+		{"help", "show this list", help},
+	}
+}
+
+func findCommand(name string) (command, bool) {
+	for _, c := range commands {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return command{}, false
+}
+
+func printUsage(out io.Writer) {
+	_, _ = fmt.Fprintln(out, "usage: server [command] [flags]")
+	_, _ = fmt.Fprintln(out)
+	for _, c := range commands {
+		_, _ = fmt.Fprintf(out, "  %-18s %s\n", c.name, c.summary)
+	}
+	_, _ = fmt.Fprintln(out)
+	_, _ = fmt.Fprintln(out, `run "server <command> -h" for the flags of a command`)
+}
+
+func help(_ context.Context, _ []string, out io.Writer) error {
+	printUsage(out)
+	return nil
+}
+
+// connectDB は DB_DRIVER で選んだデータベースに接続する
+func connectDB() (*sqlx.DB, error) {
+	var (
+		db  *sqlx.DB
+		err error
+	)
+	switch driver := config.DBDriver(); driver {
+	case "mysql":
+		db, err = sqlx.Connect("mysql", config.MySQL().FormatDSN())
+	case "sqlite":
+		db, err = sqlx.Connect("sqlite", config.SQLiteDSN())
+	default:
+		return nil, fmt.Errorf("unknown DB_DRIVER %q (want mysql or sqlite)", driver)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("connect database: %w", err)
+	}
+	return db, nil
+}
+
+// openDB は運用コマンド用にデータベースへ接続する。
+// マイグレーションは適用せず、未適用のものがあれば migrate up を促して失敗する。
+func openDB(ctx context.Context) (*sqlx.DB, error) {
+	db, err := connectDB()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkMigrated(ctx, db); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+var errPendingMigrations = errors.New(`database has pending migrations; run "migrate up" first`)
+
+func checkMigrated(ctx context.Context, db *sqlx.DB) error {
+	pending, err := migration.HasPending(ctx, db)
+	if err != nil {
+		return fmt.Errorf("check migrations: %w", err)
+	}
+	if pending {
+		return errPendingMigrations
+	}
+	return nil
+}
+
+// migrate はマイグレーションを適用する・最後の 1 つを戻す・適用状況を表示する
+func migrate(ctx context.Context, args []string, out io.Writer) error {
+	if len(args) != 1 {
+		return errors.New("usage: migrate up|down|status")
+	}
+	db, err := connectDB()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+
+	switch args[0] {
+	case "up":
+		results, err := migration.Up(ctx, db)
+		for _, r := range results {
+			_, _ = fmt.Fprintln(out, r)
+		}
+		if err != nil {
+			return err
+		}
+		if len(results) == 0 {
+			_, _ = fmt.Fprintln(out, "no pending migrations")
+		}
+		return nil
+	case "down":
+		result, err := migration.Down(ctx, db)
+		if result != nil {
+			_, _ = fmt.Fprintln(out, result)
+		}
+		return err
+	case "status":
+		statuses, err := migration.Status(ctx, db)
+		if err != nil {
+			return err
+		}
+		for _, s := range statuses {
+			applied := "pending"
+			if !s.AppliedAt.IsZero() {
+				applied = s.AppliedAt.UTC().Format("2006-01-02 15:04:05")
+			}
+			_, _ = fmt.Fprintf(out, "%-8s %-20s %s\n", s.State, applied, s.Source.Path)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate action %q (want up, down or status)", args[0])
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/domain"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/repository"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/openapi/models"
+)
+
+type userExport struct {
+	UserID           string                          `json:"user_id"`
+	ExportedAt       time.Time                       `json:"exported_at"`
+	LatestSaveID     int64                           `json:"latest_save_id"`
+	Latest           *models.SaveDataV2              `json:"latest"`
+	Saves            []models.SaveHistoryEntry       `json:"saves"`
+	SavesTruncated   bool                            `json:"saves_truncated"`
+	Achievements     []models.AchievementUnlockEntry `json:"achievements"`
+	Moderation       *domain.Moderation              `json:"moderation"`
+	AnomalyFlags     []domain.AnomalyFlag            `json:"anomaly_flags"`
+	AnomalyTruncated bool                            `json:"anomaly_flags_truncated"`
+}
+
+// exportUser はユーザーについて保存しているもの（最新セーブ・保存履歴・実績の解除履歴・モデレーション・
+// 不審なセーブの記録）を 1 つの JSON にまとめて出力する。問い合わせ対応や削除前の控えに使う。
+func exportUser(ctx context.Context, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("export-user", flag.ContinueOnError)
+	saves := fs.Int("saves", 1000, "maximum number of saves in the history, newest first")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: export-user [-saves N] <user_id>")
+	}
+	if *saves < 1 {
+		return errors.New("-saves must be positive")
+	}
+	userID := fs.Arg(0)
+
+	db, err := openDB(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+	repo := repository.New(db)
+
+	sd, err := repo.GetLatestSave(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("user %s has no saves", userID)
+		}
+		return err
+	}
+	exp := userExport{
+		UserID:       userID,
+		ExportedAt:   time.Now().UTC(),
+		LatestSaveID: sd.ID,
+		Latest:       sd.ToModel(),
+	}
+	if exp.Saves, exp.SavesTruncated, err = repo.GetSaveHistory(ctx, userID, *saves, nil); err != nil {
+		return err
+	}
+
+	achievements, total, err := repo.GetAchievementUnlockHistory(ctx, userID, *saves)
+	if err != nil {
+		return err
+	}
+	if total > len(achievements) {
+		if achievements, _, err = repo.GetAchievementUnlockHistory(ctx, userID, total); err != nil {
+			return err
+		}
+	}
+	exp.Achievements = achievements
+
+	switch m, err := repo.GetModeration(ctx, userID); {
+	case err == nil:
+		exp.Moderation = m
+	case !errors.Is(err, sql.ErrNoRows):
+		return err
+	}
+
+	flags, err := repo.ListAnomalyFlags(ctx, "", userID, *saves+1, 0)
+	if err != nil {
+		return err
+	}
+	if len(flags) > *saves {
+		flags, exp.AnomalyTruncated = flags[:*saves], true
+	}
+	exp.AnomalyFlags = flags
+
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(exp)
+}
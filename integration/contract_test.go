@@ -0,0 +1,15 @@
+//go:build integration
+
+package integration
+
+import (
+	"testing"
+
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/handler"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/repository"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/repository/repotest"
+)
+
+func TestRepositoryContract(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) handler.Repository { return repository.New(setupDB(t)) })
+}
@@ -5,6 +5,8 @@ package integration
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"sort"
 	"sync"
 	"testing"
@@ -33,7 +35,7 @@ func setupDB(t *testing.T) *sqlx.DB {
 
 	var migrateErr error
 	migrateOnce.Do(func() {
-		migrateErr = migration.MigrateTables(db.DB)
+		migrateErr = migration.MigrateTables(db)
 	})
 	if migrateErr != nil {
 		t.Fatalf("migrate: %v", migrateErr)
@@ -79,6 +81,7 @@ func cleanupTables(t *testing.T, db *sqlx.DB) {
 		"v2_save_data_ball_get",
 		"v2_save_data_ball_chain",
 		"v2_save_data_achievements",
+		"v2_save_data_achievement_revocations",
 		"v2_save_data_palball_get",
 		"v2_save_data_palball_jp",
 		"v2_save_data_bbox_shop",
@@ -92,6 +95,13 @@ func cleanupTables(t *testing.T, db *sqlx.DB) {
 		"v3_user_latest_save_data_achievements",
 		"v3_user_latest_save_data",
 		"v2_save_data",
+		"v4_summary_totals",
+		"v4_summary_achievement_users",
+		"v4_summary_credit_digits",
+		"admin_audit_log",
+		"signature_bypass_log",
+		"user_moderation",
+		"anomaly_flags",
 	}
 
 	if _, err := db.Exec("SET FOREIGN_KEY_CHECKS=0"); err != nil {
@@ -105,6 +115,10 @@ func cleanupTables(t *testing.T, db *sqlx.DB) {
 	if _, err := db.Exec("SET FOREIGN_KEY_CHECKS=1"); err != nil {
 		t.Fatalf("enable foreign keys: %v", err)
 	}
+	// anomaly_scan_state keeps its single row
+	if _, err := db.Exec("UPDATE anomaly_scan_state SET last_save_id = NULL, lease_owner = '', lease_until = NULL"); err != nil {
+		t.Fatalf("reset anomaly scan state: %v", err)
+	}
 }
 
 func newSaveData(userID string, playtime int64, creditAll int64, achievements []string) *domain.SaveData {
@@ -398,6 +412,49 @@ func TestRepositoryV4_StatisticsAndAchievementRates(t *testing.T) {
 	}
 }
 
+func TestRepositoryV4_GetRankingV4(t *testing.T) {
+	db := setupDB(t)
+	repo := repository.New(db)
+
+	ctx := context.Background()
+
+	if err := repo.InsertSaveV4(ctx, newSaveData("user-1", 10, 100, []string{"ach-1"})); err != nil {
+		t.Fatalf("insert user1: %v", err)
+	}
+	if err := repo.InsertSaveV4(ctx, newSaveData("user-2", 15, 200, []string{"ach-1", "ach-2"})); err != nil {
+		t.Fatalf("insert user2: %v", err)
+	}
+
+	ranking, err := repo.GetRankingV4(ctx, "achievements_count", 10)
+	if err != nil {
+		t.Fatalf("ranking: %v", err)
+	}
+	if len(ranking) != 2 || *ranking[0].UserId != "user-2" || *ranking[0].Value != 2 {
+		t.Fatalf("achievements_count ranking: got %#v", ranking)
+	}
+
+	ranking, err = repo.GetRankingV4(ctx, "achievements_count", 1)
+	if err != nil {
+		t.Fatalf("ranking with limit: %v", err)
+	}
+	if len(ranking) != 1 {
+		t.Fatalf("limit: got %d entries", len(ranking))
+	}
+
+	// the statistics carry the same ranking
+	stats, err := repo.GetStatisticsV4(ctx)
+	if err != nil {
+		t.Fatalf("statistics v4: %v", err)
+	}
+	if stats.AchievementsCount == nil || *(*stats.AchievementsCount)[0].UserId != "user-2" {
+		t.Fatalf("statistics ranking: got %#v", stats.AchievementsCount)
+	}
+
+	if _, err := repo.GetRankingV4(ctx, "credit_all", 10); err == nil {
+		t.Fatal("unknown metric should fail")
+	}
+}
+
 func sameStringSet(got []string, want []string) bool {
 	if len(got) != len(want) {
 		return false
@@ -413,3 +470,684 @@ func sameStringSet(got []string, want []string) bool {
 	}
 	return true
 }
+
+func TestRepositoryV4_SummaryMaintainedIncrementally(t *testing.T) {
+	db := setupDB(t)
+	repo := repository.New(db)
+
+	ctx := context.Background()
+
+	if err := repo.InsertSaveV4(ctx, newSaveData("user-1", 10, 500, []string{"ach-1"})); err != nil {
+		t.Fatalf("insert user1: %v", err)
+	}
+	if err := repo.InsertSaveV4(ctx, newSaveData("user-2", 10, 5000, nil)); err != nil {
+		t.Fatalf("insert user2: %v", err)
+	}
+	// user-1 moves to the 5-digit bucket and unlocks another achievement
+	if err := repo.InsertSaveV4(ctx, newSaveData("user-1", 20, 12000, []string{"ach-1", "ach-2"})); err != nil {
+		t.Fatalf("insert user1 again: %v", err)
+	}
+	// user-2 hides the record: removed from total medals and the distribution
+	hidden := newSaveData("user-2", 20, 6000, []string{"ach-1"})
+	hidden.HideRecord = 1
+	if err := repo.InsertSaveV4(ctx, hidden); err != nil {
+		t.Fatalf("insert user2 hidden: %v", err)
+	}
+
+	stats, err := repo.GetStatisticsV4(ctx)
+	if err != nil {
+		t.Fatalf("statistics v4: %v", err)
+	}
+	if stats.TotalMedals == nil || *stats.TotalMedals != 12000 {
+		t.Fatalf("total medals: got %#v", stats.TotalMedals)
+	}
+
+	rates, err := repo.GetAchievementRates(ctx)
+	if err != nil {
+		t.Fatalf("achievement rates: %v", err)
+	}
+	if rates.TotalUsers == nil || *rates.TotalUsers != 2 {
+		t.Fatalf("total users: got %#v", rates.TotalUsers)
+	}
+	if c := (*rates.AchievementRates)["ach-1"].Count; c == nil || *c != 2 {
+		t.Fatalf("ach-1 count: got %#v", c)
+	}
+	if c := (*rates.AchievementRates)["ach-2"].Count; c == nil || *c != 1 {
+		t.Fatalf("ach-2 count: got %#v", c)
+	}
+
+	dist, err := repo.GetCreditAllDistribution(ctx)
+	if err != nil {
+		t.Fatalf("credit distribution: %v", err)
+	}
+	if dist.Users != 1 {
+		t.Fatalf("distribution users: got %d", dist.Users)
+	}
+	for _, b := range dist.Distribution {
+		want := int64(0)
+		if b.RangeMin == 10000 {
+			want = 1
+		}
+		if b.Users != want {
+			t.Fatalf("bucket %d-%d: got %d want %d", b.RangeMin, b.RangeMax, b.Users, want)
+		}
+	}
+
+	drifts, err := repo.ReconcileSummary(ctx, false)
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if len(drifts) != 0 {
+		t.Fatalf("unexpected drift: %v", drifts)
+	}
+}
+
+func TestRepositoryV4_ReconcileSummaryFixesDrift(t *testing.T) {
+	db := setupDB(t)
+	repo := repository.New(db)
+
+	ctx := context.Background()
+
+	if err := repo.InsertSaveV4(ctx, newSaveData("user-1", 10, 500, []string{"ach-1"})); err != nil {
+		t.Fatalf("insert user1: %v", err)
+	}
+	if _, err := db.Exec("UPDATE v4_summary_totals SET value = value + 7 WHERE name = 'total_medals'"); err != nil {
+		t.Fatalf("corrupt totals: %v", err)
+	}
+	if _, err := db.Exec("DELETE FROM v4_summary_achievement_users"); err != nil {
+		t.Fatalf("corrupt achievements: %v", err)
+	}
+
+	drifts, err := repo.ReconcileSummary(ctx, false)
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if len(drifts) != 2 {
+		t.Fatalf("expected 2 drifts, got %v", drifts)
+	}
+
+	if _, err := repo.ReconcileSummary(ctx, true); err != nil {
+		t.Fatalf("reconcile apply: %v", err)
+	}
+	drifts, err = repo.ReconcileSummary(ctx, false)
+	if err != nil {
+		t.Fatalf("reconcile after apply: %v", err)
+	}
+	if len(drifts) != 0 {
+		t.Fatalf("drift remains after apply: %v", drifts)
+	}
+}
+
+func TestRepositoryV4_RollbackSaveAndDeleteUser(t *testing.T) {
+	db := setupDB(t)
+	repo := repository.New(db)
+
+	ctx := context.Background()
+
+	if err := repo.InsertSaveV4(ctx, newSaveData("user-1", 10, 500, []string{"ach-1"})); err != nil {
+		t.Fatalf("insert user1: %v", err)
+	}
+	var firstID int64
+	if err := db.GetContext(ctx, &firstID, "SELECT MIN(id) FROM v2_save_data WHERE user_id = 'user-1'"); err != nil {
+		t.Fatalf("first save id: %v", err)
+	}
+	if err := repo.InsertSaveV4(ctx, newSaveData("user-1", 20, 12000, []string{"ach-1", "ach-2"})); err != nil {
+		t.Fatalf("insert user1 again: %v", err)
+	}
+	if err := repo.InsertSaveV4(ctx, newSaveData("user-2", 10, 5000, []string{"ach-2"})); err != nil {
+		t.Fatalf("insert user2: %v", err)
+	}
+
+	if _, err := repo.RollbackSave(ctx, "user-2", firstID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("rollback to another user's save: got %v", err)
+	}
+	restored, err := repo.RollbackSave(ctx, "user-1", firstID)
+	if err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+	if restored.ID <= firstID {
+		t.Fatalf("rollback should store a new save, got id %d", restored.ID)
+	}
+
+	latest, err := repo.GetLatestSave(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("latest after rollback: %v", err)
+	}
+	if latest.CreditAll != 500 || latest.Playtime != 10 || !sameStringSet(latest.LAchieve, []string{"ach-1"}) {
+		t.Fatalf("latest after rollback: credit_all=%d playtime=%d achievements=%v", latest.CreditAll, latest.Playtime, latest.LAchieve)
+	}
+	// The save stored by the rollback does not hold the achievements it revoked.
+	if sd, err := repo.GetSave(ctx, "user-1", restored.ID); err != nil || !sameStringSet(sd.LAchieve, []string{"ach-1"}) {
+		t.Fatalf("rolled-back save: %v, %v", sd, err)
+	}
+	rates, err := repo.GetAchievementRates(ctx)
+	if err != nil {
+		t.Fatalf("achievement rates: %v", err)
+	}
+	if c := (*rates.AchievementRates)["ach-2"].Count; c == nil || *c != 1 {
+		t.Fatalf("ach-2 should only count user-2 after the rollback, got %#v", c)
+	}
+	if drifts, err := repo.ReconcileSummary(ctx, false); err != nil || len(drifts) != 0 {
+		t.Fatalf("drift after rollback: %v, %v", drifts, err)
+	}
+
+	if err := repo.InsertSignatureBypass(ctx, &domain.SignatureBypassEntry{Route: "/v4/data", UserID: "user-1", RemoteIP: "192.0.2.1"}); err != nil {
+		t.Fatalf("record bypass: %v", err)
+	}
+	deleted, err := repo.DeleteUserData(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if deleted != 3 {
+		t.Fatalf("deleted saves: got %d", deleted)
+	}
+	if bypasses, err := repo.ListSignatureBypass(ctx, 10, 0, "user-1"); err != nil || len(bypasses) != 0 {
+		t.Fatalf("bypass records after delete: %v, %v", bypasses, err)
+	}
+	if _, err := repo.GetLatestSave(ctx, "user-1"); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("latest after delete: got %v", err)
+	}
+	if _, err := repo.DeleteUserData(ctx, "user-1"); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("deleting again: got %v", err)
+	}
+	stats, err := repo.GetStatisticsV4(ctx)
+	if err != nil {
+		t.Fatalf("statistics v4: %v", err)
+	}
+	if stats.TotalMedals == nil || *stats.TotalMedals != 5000 {
+		t.Fatalf("total medals after delete: got %#v", stats.TotalMedals)
+	}
+	if drifts, err := repo.ReconcileSummary(ctx, false); err != nil || len(drifts) != 0 {
+		t.Fatalf("drift after delete: %v, %v", drifts, err)
+	}
+}
+
+func TestRepositoryV4_RebuildLatestSave(t *testing.T) {
+	db := setupDB(t)
+	repo := repository.New(db)
+
+	ctx := context.Background()
+
+	if err := repo.InsertSaveV4(ctx, newSaveData("user-1", 10, 500, []string{"ach-1"})); err != nil {
+		t.Fatalf("insert user1: %v", err)
+	}
+	var firstID int64
+	if err := db.GetContext(ctx, &firstID, "SELECT MIN(id) FROM v2_save_data WHERE user_id = 'user-1'"); err != nil {
+		t.Fatalf("first save id: %v", err)
+	}
+	if err := repo.InsertSaveV4(ctx, newSaveData("user-1", 20, 12000, []string{"ach-1", "ach-2"})); err != nil {
+		t.Fatalf("insert user1 again: %v", err)
+	}
+	if err := repo.InsertSaveV4(ctx, newSaveData("user-2", 10, 5000, []string{"ach-2"})); err != nil {
+		t.Fatalf("insert user2: %v", err)
+	}
+	if _, err := repo.RollbackSave(ctx, "user-1", firstID); err != nil {
+		t.Fatalf("rollback: %v", err)
+	}
+
+	// Lose the latest-save rows of user-1 and corrupt those of user-2, then rebuild everybody.
+	if _, err := db.ExecContext(ctx, "DELETE FROM v3_user_latest_save_data_achievements WHERE user_id = 'user-1'"); err != nil {
+		t.Fatalf("delete achievements: %v", err)
+	}
+	if _, err := db.ExecContext(ctx, "UPDATE v3_user_latest_save_data SET credit_all = 1 WHERE user_id = 'user-2'"); err != nil {
+		t.Fatalf("corrupt latest: %v", err)
+	}
+
+	users, err := repo.ListUserIDs(ctx, "", 1)
+	if err != nil || len(users) != 1 || users[0] != "user-1" {
+		t.Fatalf("first user page: %v, %v", users, err)
+	}
+	if users, err = repo.ListUserIDs(ctx, "user-1", 10); err != nil || len(users) != 1 || users[0] != "user-2" {
+		t.Fatalf("second user page: %v, %v", users, err)
+	}
+	mismatches, err := repo.VerifyLatestSave(ctx, "user-2")
+	if err != nil || len(mismatches) != 1 || mismatches[0].Field != "credit_all" || mismatches[0].Expected != "5000" {
+		t.Fatalf("verify user2: %v, %v", mismatches, err)
+	}
+	var found []domain.LatestSaveMismatch
+	report, err := repo.RebuildLatestSaves(ctx, repository.RebuildOptions{
+		VerifyOnly: true,
+		BatchSize:  1,
+		OnMismatch: func(m domain.LatestSaveMismatch) { found = append(found, m) },
+	})
+	if err != nil || report.Users != 2 || report.Mismatched != 2 || report.Rebuilt != 0 || report.LastUserID != "user-2" {
+		t.Fatalf("verify all: %+v, %v", report, err)
+	}
+	if len(found) != 2 || found[0].Field != "achievement:ach-1" || found[1].UserID != "user-2" {
+		t.Fatalf("mismatches: %v", found)
+	}
+
+	// Resuming after user-1 only handles user-2.
+	if report, err = repo.RebuildLatestSaves(ctx, repository.RebuildOptions{AfterUserID: "user-1", BatchSize: 10}); err != nil || report.Users != 1 || report.Rebuilt != 1 {
+		t.Fatalf("rebuild after user-1: %+v, %v", report, err)
+	}
+	if report, err = repo.RebuildLatestSaves(ctx, repository.RebuildOptions{BatchSize: 10}); err != nil || report.Users != 2 || report.Rebuilt != 1 {
+		t.Fatalf("rebuild all: %+v, %v", report, err)
+	}
+	if report, err = repo.RebuildLatestSaves(ctx, repository.RebuildOptions{VerifyOnly: true, BatchSize: 10}); err != nil || report.Mismatched != 0 {
+		t.Fatalf("verify after rebuild: %+v, %v", report, err)
+	}
+	if err := repo.RebuildLatestSave(ctx, "nobody"); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("rebuild unknown user: got %v", err)
+	}
+
+	latest, err := repo.GetLatestSave(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("latest user1: %v", err)
+	}
+	// ach-2 was revoked by the rollback and must stay revoked.
+	if latest.CreditAll != 500 || !sameStringSet(latest.LAchieve, []string{"ach-1"}) {
+		t.Fatalf("user1 after rebuild: credit_all=%d achievements=%v", latest.CreditAll, latest.LAchieve)
+	}
+	var credit int64
+	if err := db.GetContext(ctx, &credit, "SELECT credit_all FROM v3_user_latest_save_data WHERE user_id = 'user-2'"); err != nil {
+		t.Fatalf("user2 latest: %v", err)
+	}
+	if credit != 5000 {
+		t.Fatalf("user2 credit_all after rebuild: got %d", credit)
+	}
+	if drifts, err := repo.ReconcileSummary(ctx, false); err != nil || len(drifts) != 0 {
+		t.Fatalf("drift after rebuild: %v, %v", drifts, err)
+	}
+
+	// Unlocking ach-2 again after the revocation counts.
+	if err := repo.InsertSaveV4(ctx, newSaveData("user-1", 30, 13000, []string{"ach-1", "ach-2"})); err != nil {
+		t.Fatalf("insert user1 after rollback: %v", err)
+	}
+	if err := repo.RebuildLatestSave(ctx, "user-1"); err != nil {
+		t.Fatalf("rebuild user1 again: %v", err)
+	}
+	if latest, err = repo.GetLatestSave(ctx, "user-1"); err != nil || !sameStringSet(latest.LAchieve, []string{"ach-1", "ach-2"}) {
+		t.Fatalf("user1 after unlocking again: %v, %v", latest, err)
+	}
+	if drifts, err := repo.ReconcileSummary(ctx, false); err != nil || len(drifts) != 0 {
+		t.Fatalf("drift after second rebuild: %v, %v", drifts, err)
+	}
+}
+
+func TestRepositoryV4_AdminAuditLog(t *testing.T) {
+	db := setupDB(t)
+	repo := repository.New(db)
+
+	ctx := context.Background()
+
+	for _, target := range []string{"user-1", "user-2", "user-3"} {
+		entry := &domain.AdminAuditEntry{
+			Actor:    "ops",
+			Action:   "DELETE /api/admin/users/:user_id",
+			Target:   target,
+			RemoteIP: "192.0.2.1",
+			Status:   200,
+		}
+		if err := repo.InsertAdminAudit(ctx, entry); err != nil {
+			t.Fatalf("insert audit: %v", err)
+		}
+		if entry.ID == 0 {
+			t.Fatalf("audit entry id was not set")
+		}
+	}
+
+	page, err := repo.ListAdminAudit(ctx, 2, 0)
+	if err != nil {
+		t.Fatalf("list audit: %v", err)
+	}
+	if len(page) != 2 || page[0].Target != "user-3" || page[1].Target != "user-2" {
+		t.Fatalf("first page: %+v", page)
+	}
+	rest, err := repo.ListAdminAudit(ctx, 2, page[1].ID)
+	if err != nil {
+		t.Fatalf("list audit: %v", err)
+	}
+	if len(rest) != 1 || rest[0].Target != "user-1" || rest[0].CreatedAt.IsZero() {
+		t.Fatalf("second page: %+v", rest)
+	}
+}
+
+func TestRepositoryV4_SignatureBypassLog(t *testing.T) {
+	db := setupDB(t)
+	repo := repository.New(db)
+
+	ctx := context.Background()
+
+	for _, userID := range []string{"user-1", "user-2", "user-1"} {
+		entry := &domain.SignatureBypassEntry{
+			Route:    "/v4/users/{user_id}/data",
+			UserID:   userID,
+			RemoteIP: "192.0.2.1",
+		}
+		if err := repo.InsertSignatureBypass(ctx, entry); err != nil {
+			t.Fatalf("insert bypass: %v", err)
+		}
+		if entry.ID == 0 {
+			t.Fatalf("bypass entry id was not set")
+		}
+	}
+
+	all, err := repo.ListSignatureBypass(ctx, 10, 0, "")
+	if err != nil {
+		t.Fatalf("list bypass: %v", err)
+	}
+	if len(all) != 3 || all[0].UserID != "user-1" || all[1].UserID != "user-2" || all[0].CreatedAt.IsZero() {
+		t.Fatalf("all entries: %+v", all)
+	}
+	forUser, err := repo.ListSignatureBypass(ctx, 10, all[0].ID, "user-1")
+	if err != nil {
+		t.Fatalf("list bypass: %v", err)
+	}
+	if len(forUser) != 1 || forUser[0].ID != all[2].ID {
+		t.Fatalf("user-1 entries before %d: %+v", all[0].ID, forUser)
+	}
+}
+
+func TestRepositoryV4_ModerationExcludesFromStatistics(t *testing.T) {
+	db := setupDB(t)
+	repo := repository.New(db)
+
+	ctx := context.Background()
+
+	for _, sd := range []*domain.SaveData{
+		newSaveData("user-1", 10, 500, []string{"ach-1"}),
+		newSaveData("user-2", 10, 12000, []string{"ach-1", "ach-2"}),
+		newSaveData("user-3", 10, 700, nil),
+	} {
+		if err := repo.InsertSaveV4(ctx, sd); err != nil {
+			t.Fatalf("insert %s: %v", sd.UserId, err)
+		}
+	}
+
+	if _, err := repo.UpsertModeration(ctx, domain.Moderation{UserID: "user-2", Banned: true, HiddenFromRankings: true, Reason: "cheat", Moderator: "ops"}); err != nil {
+		t.Fatalf("ban user-2: %v", err)
+	}
+	expired := time.Now().Add(-time.Hour)
+	if _, err := repo.UpsertModeration(ctx, domain.Moderation{UserID: "user-3", HiddenFromRankings: true, Reason: "spam", ExpiresAt: &expired}); err != nil {
+		t.Fatalf("hide user-3: %v", err)
+	}
+
+	stats, err := repo.GetStatisticsV4(ctx)
+	if err != nil {
+		t.Fatalf("statistics v4: %v", err)
+	}
+	if stats.TotalMedals == nil || *stats.TotalMedals != 1200 {
+		t.Fatalf("total medals: got %#v", stats.TotalMedals)
+	}
+	if stats.AchievementsCount == nil || len(*stats.AchievementsCount) != 2 {
+		t.Fatalf("achievements count ranking: got %#v", stats.AchievementsCount)
+	}
+	for _, e := range *stats.AchievementsCount {
+		if e.UserId != nil && *e.UserId == "user-2" {
+			t.Fatalf("banned user is ranked: %#v", *stats.AchievementsCount)
+		}
+	}
+
+	rates, err := repo.GetAchievementRates(ctx)
+	if err != nil {
+		t.Fatalf("achievement rates: %v", err)
+	}
+	if rates.TotalUsers == nil || *rates.TotalUsers != 1 {
+		t.Fatalf("total users: got %#v", rates.TotalUsers)
+	}
+	if c := (*rates.AchievementRates)["ach-1"].Count; c == nil || *c != 1 {
+		t.Fatalf("ach-1 count: got %#v", c)
+	}
+	if _, ok := (*rates.AchievementRates)["ach-2"]; ok {
+		t.Fatalf("ach-2 is only held by the banned user: %#v", *rates.AchievementRates)
+	}
+
+	dist, err := repo.GetCreditAllDistribution(ctx)
+	if err != nil {
+		t.Fatalf("credit distribution: %v", err)
+	}
+	if dist.Users != 2 {
+		t.Fatalf("distribution users: got %d", dist.Users)
+	}
+	for _, b := range dist.Distribution {
+		if b.RangeMin == 10000 && b.Users != 0 {
+			t.Fatalf("banned user is in the distribution: %+v", b)
+		}
+	}
+
+	banned, err := repo.IsUserBanned(ctx, "user-2")
+	if err != nil || !banned {
+		t.Fatalf("user-2 banned: got %v %v", banned, err)
+	}
+	if banned, err := repo.IsUserBanned(ctx, "user-3"); err != nil || banned {
+		t.Fatalf("user-3 banned: got %v %v", banned, err)
+	}
+
+	active, err := repo.ListModerations(ctx, false, 10)
+	if err != nil {
+		t.Fatalf("list moderations: %v", err)
+	}
+	if len(active) != 1 || active[0].UserID != "user-2" || active[0].Moderator != "ops" {
+		t.Fatalf("active moderations: %+v", active)
+	}
+
+	// lifting restores the stored totals: the summary tables themselves were never rewritten
+	if err := repo.DeleteModeration(ctx, "user-2"); err != nil {
+		t.Fatalf("lift user-2: %v", err)
+	}
+	if err := repo.DeleteModeration(ctx, "user-2"); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("lift twice: got %v", err)
+	}
+	stats, err = repo.GetStatisticsV4(ctx)
+	if err != nil {
+		t.Fatalf("statistics v4: %v", err)
+	}
+	if *stats.TotalMedals != 13200 {
+		t.Fatalf("total medals after lifting: got %d", *stats.TotalMedals)
+	}
+	drifts, err := repo.ReconcileSummary(ctx, false)
+	if err != nil || len(drifts) != 0 {
+		t.Fatalf("reconcile: %v %v", drifts, err)
+	}
+}
+
+func TestRepositoryV4_AnomalyFlags(t *testing.T) {
+	db := setupDB(t)
+	repo := repository.New(db)
+
+	ctx := context.Background()
+
+	before, err := repo.LastSaveIDBefore(ctx, time.Now().Add(time.Hour))
+	if err != nil || before != 0 {
+		t.Fatalf("last save id on empty table: %d %v", before, err)
+	}
+
+	first := newSaveData("user-1", 600, 1000, []string{"ach-1"})
+	first.CpMMax = 100
+	second := newSaveData("user-1", 660, 900000, []string{"ach-1", "ach-2"})
+	second.CpMMax = 100
+	for _, sd := range []*domain.SaveData{first, newSaveData("user-2", 10, 500, nil), second} {
+		if err := repo.InsertSaveV4(ctx, sd); err != nil {
+			t.Fatalf("insert %s: %v", sd.UserId, err)
+		}
+	}
+
+	users, lastID, err := repo.ListUsersSavedAfter(ctx, 0, 10)
+	if err != nil {
+		t.Fatalf("list users: %v", err)
+	}
+	if len(users) != 2 || users[0] != "user-2" || users[1] != "user-1" {
+		t.Fatalf("users: %v", users)
+	}
+	if more, _, err := repo.ListUsersSavedAfter(ctx, lastID, 10); err != nil || len(more) != 0 {
+		t.Fatalf("users after %d: %v %v", lastID, more, err)
+	}
+
+	samples, err := repo.GetSaveSamples(ctx, "user-1", 10)
+	if err != nil {
+		t.Fatalf("save samples: %v", err)
+	}
+	if len(samples) != 2 || samples[0].Playtime != 600 || samples[1].Unlocked != 2 || samples[1].ID != lastID {
+		t.Fatalf("samples: %+v", samples)
+	}
+
+	flags, err := domain.AnalyzeSaves("user-1", samples, domain.DefaultAnomalyThresholds)
+	if err != nil || len(flags) != 1 {
+		t.Fatalf("analyze: %+v %v", flags, err)
+	}
+	if n, err := repo.InsertAnomalyFlags(ctx, flags); err != nil || n != 1 {
+		t.Fatalf("insert flags: %d %v", n, err)
+	}
+	// analyzing the same saves again must not duplicate the flag
+	if n, err := repo.InsertAnomalyFlags(ctx, flags); err != nil || n != 0 {
+		t.Fatalf("insert flags again: %d %v", n, err)
+	}
+
+	open, err := repo.ListAnomalyFlags(ctx, domain.AnomalyStatusOpen, "", 10, 0)
+	if err != nil || len(open) != 1 {
+		t.Fatalf("open flags: %+v %v", open, err)
+	}
+	if open[0].SaveID != lastID || string(open[0].Evidence) != string(flags[0].Evidence) {
+		t.Fatalf("stored flag: %+v", open[0])
+	}
+
+	reviewed, err := repo.ReviewAnomalyFlag(ctx, open[0].ID, domain.AnomalyStatusConfirmed, "ops", "credit edited")
+	if err != nil {
+		t.Fatalf("review: %v", err)
+	}
+	if reviewed.Status != domain.AnomalyStatusConfirmed || reviewed.ReviewedBy != "ops" || reviewed.ReviewedAt == nil {
+		t.Fatalf("reviewed flag: %+v", reviewed)
+	}
+	if open, err := repo.ListAnomalyFlags(ctx, domain.AnomalyStatusOpen, "", 10, 0); err != nil || len(open) != 0 {
+		t.Fatalf("open flags after review: %+v %v", open, err)
+	}
+	if _, err := repo.ReviewAnomalyFlag(ctx, open[0].ID+1, domain.AnomalyStatusDismissed, "ops", ""); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("review unknown flag: %v", err)
+	}
+
+	// one replica scans at a time and the next scan continues where the last one stopped
+	if afterID, ok, err := repo.ClaimAnomalyScan(ctx, "replica-a", time.Minute, time.Now().Add(time.Hour)); err != nil || !ok || afterID != lastID {
+		t.Fatalf("first claim: %d %v %v", afterID, ok, err)
+	}
+	if afterID, ok, err := repo.ClaimAnomalyScan(ctx, "replica-a", time.Minute, time.Now()); err != nil || !ok || afterID != lastID {
+		t.Fatalf("renewed claim: %d %v %v", afterID, ok, err)
+	}
+	if _, ok, err := repo.ClaimAnomalyScan(ctx, "replica-b", time.Minute, time.Now()); err != nil || ok {
+		t.Fatalf("claim of another replica: %v %v", ok, err)
+	}
+	if err := repo.AdvanceAnomalyScan(ctx, "replica-b", lastID+10, time.Minute); err != nil {
+		t.Fatalf("advance without the lease: %v", err)
+	}
+	if err := repo.AdvanceAnomalyScan(ctx, "replica-a", lastID+1, -time.Minute); err != nil {
+		t.Fatalf("advance: %v", err)
+	}
+	if afterID, ok, err := repo.ClaimAnomalyScan(ctx, "replica-b", time.Minute, time.Now()); err != nil || !ok || afterID != lastID+1 {
+		t.Fatalf("claim after the lease expired: %d %v %v", afterID, ok, err)
+	}
+}
+
+func TestRepositoryV4_PruneSaves(t *testing.T) {
+	db := setupDB(t)
+	repo := repository.New(db)
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+
+	// user-1 saved four times on the same day 20 days ago and then once more today;
+	// the second save unlocked an achievement
+	ages := []int{20, 20, 20, 20, 0}
+	for i, age := range ages {
+		var achievements []string
+		if i >= 1 {
+			achievements = []string{"ach-1"}
+		}
+		sd := newSaveData("user-1", int64(10*(i+1)), int64(100*(i+1)), achievements)
+		if err := repo.InsertSaveV4(ctx, sd); err != nil {
+			t.Fatalf("insert save %d: %v", i, err)
+		}
+		if age > 0 {
+			at := now.AddDate(0, 0, -age).Truncate(24 * time.Hour).Add(time.Duration(i) * time.Hour)
+			if _, err := db.Exec(`UPDATE v2_save_data SET created_at = ?, updated_at = ? ORDER BY id DESC LIMIT 1`, at, at); err != nil {
+				t.Fatalf("backdate save %d: %v", i, err)
+			}
+		}
+	}
+	var ids []int64
+	if err := db.Select(&ids, `SELECT id FROM v2_save_data ORDER BY id`); err != nil {
+		t.Fatalf("list saves: %v", err)
+	}
+
+	policy := domain.RetentionPolicy{KeepAllDays: 7, DailyDays: 30}
+	report, err := repo.PruneSaves(ctx, policy, now, repository.PruneOptions{BatchSize: 1})
+	if err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	// ids[1] unlocked ach-1 and ids[3] is the newest of its day; ids[0] and ids[2] go
+	if !report.DryRun || report.Users != 1 || report.Examined != 4 || report.Protected != 1 || report.Removed != 2 {
+		t.Fatalf("dry run report: %+v", report)
+	}
+	var count int
+	if err := db.Get(&count, `SELECT COUNT(*) FROM v2_save_data`); err != nil || count != len(ages) {
+		t.Fatalf("dry run deleted saves: %d %v", count, err)
+	}
+
+	var removed []int64
+	report, err = repo.PruneSaves(ctx, policy, now, repository.PruneOptions{Apply: true, BatchSize: 1, OnUser: func(userID string, ids []int64) {
+		removed = append(removed, ids...)
+	}})
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if report.DryRun || report.Removed != 2 || len(removed) != 2 || removed[0] != ids[0] || removed[1] != ids[2] {
+		t.Fatalf("apply report: %+v removed %v", report, removed)
+	}
+	var left []int64
+	if err := db.Select(&left, `SELECT id FROM v2_save_data ORDER BY id`); err != nil {
+		t.Fatalf("list saves: %v", err)
+	}
+	if want := []int64{ids[1], ids[3], ids[4]}; len(left) != len(want) || left[0] != want[0] || left[1] != want[1] || left[2] != want[2] {
+		t.Fatalf("saves left: %v, want %v", left, want)
+	}
+	if err := db.Get(&count, `SELECT COUNT(*) FROM v2_save_data_medal_get WHERE save_id IN (?, ?)`, ids[0], ids[2]); err != nil || count != 0 {
+		t.Fatalf("child rows of removed saves: %d %v", count, err)
+	}
+
+	if report, err := repo.PruneSaves(ctx, policy, now, repository.PruneOptions{Apply: true, BatchSize: 1}); err != nil || report.Removed != 0 {
+		t.Fatalf("second run: %+v %v", report, err)
+	}
+}
+
+func TestRepositoryV4_PromoteSaveField(t *testing.T) {
+	db := setupDB(t)
+	repo := repository.New(db)
+	ctx := context.Background()
+
+	var ids []int64
+	for i, extra := range []domain.SaveExtraFields{
+		{"zz_tower": json.RawMessage(`"12"`), "zz_other": json.RawMessage(`{"a":1}`)},
+		{"zz_tower": json.RawMessage(`null`)},
+		nil,
+	} {
+		sd := newSaveData("user-1", int64(10+i), 500, nil)
+		sd.ExtraFields = extra
+		if err := repo.InsertSaveV4(ctx, sd); err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+		ids = append(ids, sd.ID)
+	}
+	// The oldest save is the latest by updated_at; promoting must keep it so.
+	if _, err := db.ExecContext(ctx, `UPDATE v2_save_data SET updated_at = '2037-01-01 00:00:00' WHERE id = ?`, ids[0]); err != nil {
+		t.Fatalf("age saves: %v", err)
+	}
+
+	if _, err := repo.PromoteSaveField(ctx, "zz_tower", "no_such_column", repository.PromoteOptions{Apply: true}); err == nil {
+		t.Fatal("an unknown column must be rejected")
+	}
+	report, err := repo.PromoteSaveField(ctx, "zz_tower", "get_medaltower", repository.PromoteOptions{Apply: true, BatchSize: 1})
+	if err != nil || report.Promoted != 1 || report.Dropped != 1 {
+		t.Fatalf("apply: %+v, %v", report, err)
+	}
+
+	latest, err := repo.GetLatestSave(ctx, "user-1")
+	if err != nil || latest.ID != ids[0] || latest.UpdatedAt.Year() != 2037 {
+		t.Fatalf("the latest save changed: %+v, %v", latest, err)
+	}
+	if latest.GetMedalTower != 12 || len(latest.ExtraFields) != 1 || string(latest.ExtraFields["zz_other"]) != `{"a":1}` {
+		t.Fatalf("promoted save: tower %d, extra %s", latest.GetMedalTower, latest.ExtraFields)
+	}
+	var left int
+	if err := db.GetContext(ctx, &left, `SELECT COUNT(*) FROM v2_save_data WHERE extra_fields IS NOT NULL`); err != nil || left != 1 {
+		t.Fatalf("saves with extra fields left: %d, %v", left, err)
+	}
+}
@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+// AdminAuditEntry records one request made through the admin API.
+type AdminAuditEntry struct {
+	ID     int64  `db:"id" json:"id"`
+	Actor  string `db:"actor" json:"actor"`
+	Action string `db:"action" json:"action"`
+	// Target is the user_id the action was applied to, if any.
+	Target string `db:"target" json:"target,omitempty"`
+	// Payload is the request body, or the query parameters as JSON for requests without one.
+	Payload   string    `db:"payload" json:"payload,omitempty"`
+	RemoteIP  string    `db:"remote_ip" json:"remote_ip"`
+	Status    int       `db:"status" json:"status"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// SignatureBypassEntry records one request whose signature check was skipped with the bypass token.
+type SignatureBypassEntry struct {
+	ID int64 `db:"id" json:"id"`
+	// Route is the API route relative to the base URL, e.g. /v4/users/{user_id}/data.
+	Route     string    `db:"route" json:"route"`
+	UserID    string    `db:"user_id" json:"user_id"`
+	RemoteIP  string    `db:"remote_ip" json:"remote_ip"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
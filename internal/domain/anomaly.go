@@ -0,0 +1,163 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Kinds of anomaly the analyzer looks for.
+const (
+	// AnomalyCreditRate: credit_all grew faster between two saves than cpm_max allows for the elapsed playtime.
+	AnomalyCreditRate = "credit_rate"
+	// AnomalyAchievementBurst: one save unlocked many achievements within little playtime.
+	AnomalyAchievementBurst = "achievement_burst"
+)
+
+// Review states of an anomaly flag.
+const (
+	AnomalyStatusOpen      = "open"
+	AnomalyStatusConfirmed = "confirmed"
+	AnomalyStatusDismissed = "dismissed"
+)
+
+// playtimeUnitsPerMinute converts playtime (seconds) to minutes, the unit of cpm_max.
+const playtimeUnitsPerMinute = 60
+
+// AnomalyThresholds tunes how far a save may go beyond what is plausible before it is flagged.
+type AnomalyThresholds struct {
+	// CreditRateTolerance is how many times the credit cpm_max allows for the elapsed playtime a save may gain.
+	CreditRateTolerance float64
+	// MinCreditJump ignores smaller credit_all increases, which are within the noise of a session.
+	MinCreditJump int64
+	// AchievementBurstMin is the smallest number of achievements unlocked by one save that can be a burst.
+	AchievementBurstMin int
+	// AchievementsPerHour is the unlock rate per hour of playtime above which such a save is flagged.
+	AchievementsPerHour float64
+}
+
+// DefaultAnomalyThresholds are the thresholds the background analyzer uses.
+var DefaultAnomalyThresholds = AnomalyThresholds{
+	CreditRateTolerance: 1.5,
+	MinCreditJump:       1000,
+	AchievementBurstMin: 10,
+	AchievementsPerHour: 30,
+}
+
+// SaveSample is the part of a stored save the anomaly analysis needs.
+type SaveSample struct {
+	ID        int64     `db:"id" json:"save_id"`
+	Playtime  int64     `db:"playtime" json:"playtime"`
+	CreditAll int64     `db:"credit_all" json:"credit_all"`
+	CpmMax    float64   `db:"cpm_max" json:"cpm_max"`
+	Unlocked  int       `db:"unlocked" json:"unlocked"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+// AnomalyFlag is a suspicious save found by the analyzer, kept for a moderator to review.
+type AnomalyFlag struct {
+	ID     int64  `db:"id" json:"id"`
+	UserID string `db:"user_id" json:"user_id"`
+	Kind   string `db:"kind" json:"kind"`
+	// SaveID is the save that made the jump; with Kind it identifies the flag, so re-analysis never duplicates it.
+	SaveID int64 `db:"save_id" json:"save_id"`
+	// Score is how many times the threshold was exceeded (> 1).
+	Score float64 `db:"score" json:"score"`
+	// Evidence is a JSON document with the saves and rates that led to the flag.
+	// It is not mapped directly: scanning into a RawMessage would alias the driver's buffer.
+	Evidence   json.RawMessage `db:"-" json:"evidence"`
+	Status     string          `db:"status" json:"status"`
+	ReviewedBy string          `db:"reviewed_by" json:"reviewed_by,omitempty"`
+	ReviewNote string          `db:"review_note" json:"review_note,omitempty"`
+	ReviewedAt *time.Time      `db:"reviewed_at" json:"reviewed_at,omitempty"`
+	CreatedAt  time.Time       `db:"created_at" json:"created_at"`
+}
+
+// ValidAnomalyReview reports whether status is a state a reviewer may set.
+func ValidAnomalyReview(status string) bool {
+	switch status {
+	case AnomalyStatusOpen, AnomalyStatusConfirmed, AnomalyStatusDismissed:
+		return true
+	}
+	return false
+}
+
+type creditRateEvidence struct {
+	Previous      SaveSample `json:"previous"`
+	Current       SaveSample `json:"current"`
+	CreditGain    int64      `json:"credit_gain"`
+	PlaytimeGain  int64      `json:"playtime_gain"`
+	AllowedCredit float64    `json:"allowed_credit"`
+}
+
+type achievementBurstEvidence struct {
+	Previous     SaveSample `json:"previous"`
+	Current      SaveSample `json:"current"`
+	PlaytimeGain int64      `json:"playtime_gain"`
+	PerHour      float64    `json:"per_hour"`
+}
+
+// AnalyzeSaves compares each save of one user with the one before it (saves ordered oldest first)
+// and returns a flag for every jump beyond th. The first save only serves as the baseline.
+func AnalyzeSaves(userID string, saves []SaveSample, th AnomalyThresholds) ([]AnomalyFlag, error) {
+	var flags []AnomalyFlag
+	add := func(kind string, cur SaveSample, score float64, evidence any) error {
+		raw, err := json.Marshal(evidence)
+		if err != nil {
+			return fmt.Errorf("encode %s evidence: %w", kind, err)
+		}
+		flags = append(flags, AnomalyFlag{
+			UserID:   userID,
+			Kind:     kind,
+			SaveID:   cur.ID,
+			Score:    score,
+			Evidence: raw,
+			Status:   AnomalyStatusOpen,
+		})
+		return nil
+	}
+
+	for i := 1; i < len(saves); i++ {
+		prev, cur := saves[i-1], saves[i]
+		playtimeGain := max(cur.Playtime-prev.Playtime, 0)
+
+		// cpm_max is the best rate the player ever reached, so no interval can average above it.
+		// Old clients report cpm_max = 0; there is nothing to compare with then.
+		if creditGain := cur.CreditAll - prev.CreditAll; creditGain >= th.MinCreditJump && cur.CpmMax > 0 {
+			allowed := cur.CpmMax * float64(playtimeGain) / playtimeUnitsPerMinute
+			// a gain without any playtime is always beyond the tolerance; score it against the noise floor instead
+			score := float64(creditGain) / float64(max(th.MinCreditJump, 1))
+			if allowed > 0 {
+				score = float64(creditGain) / (allowed * th.CreditRateTolerance)
+			}
+			if allowed == 0 || score > 1 {
+				if err := add(AnomalyCreditRate, cur, score, creditRateEvidence{
+					Previous:      prev,
+					Current:       cur,
+					CreditGain:    creditGain,
+					PlaytimeGain:  playtimeGain,
+					AllowedCredit: allowed,
+				}); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		if cur.Unlocked >= th.AchievementBurstMin {
+			// less than a minute of playtime counts as one minute
+			hours := float64(max(playtimeGain, playtimeUnitsPerMinute)) / (60 * playtimeUnitsPerMinute)
+			perHour := float64(cur.Unlocked) / hours
+			if score := perHour / th.AchievementsPerHour; score > 1 {
+				if err := add(AnomalyAchievementBurst, cur, score, achievementBurstEvidence{
+					Previous:     prev,
+					Current:      cur,
+					PlaytimeGain: playtimeGain,
+					PerHour:      perHour,
+				}); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return flags, nil
+}
@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestAnalyzeSaves(t *testing.T) {
+	th := DefaultAnomalyThresholds
+	saves := []SaveSample{
+		{ID: 1, Playtime: 0, CreditAll: 0, CpmMax: 100},
+		// 10 minutes at up to 100 credits per minute: 1000 is plausible
+		{ID: 2, Playtime: 600, CreditAll: 1000, CpmMax: 100},
+		// another 10 minutes but 50000 credits: far above 1500
+		{ID: 3, Playtime: 1200, CreditAll: 51000, CpmMax: 100},
+		// credits without any playtime
+		{ID: 4, Playtime: 1200, CreditAll: 53000, CpmMax: 100},
+		// 12 achievements in 2 hours is fine, 12 in 5 minutes is a burst
+		{ID: 5, Playtime: 8400, CreditAll: 53000, CpmMax: 100, Unlocked: 12},
+		{ID: 6, Playtime: 8700, CreditAll: 53000, CpmMax: 100, Unlocked: 12},
+		// an old client without cpm_max is not judged on credits
+		{ID: 7, Playtime: 8800, CreditAll: 900000, CpmMax: 0},
+	}
+
+	flags, err := AnalyzeSaves("user-1", saves, th)
+	if err != nil {
+		t.Fatalf("AnalyzeSaves: %v", err)
+	}
+	type key struct {
+		kind   string
+		saveID int64
+	}
+	got := make(map[key]AnomalyFlag, len(flags))
+	for _, f := range flags {
+		if f.UserID != "user-1" || f.Status != AnomalyStatusOpen || f.Score <= 1 {
+			t.Fatalf("unexpected flag: %+v", f)
+		}
+		got[key{f.Kind, f.SaveID}] = f
+	}
+	want := []key{{AnomalyCreditRate, 3}, {AnomalyCreditRate, 4}, {AnomalyAchievementBurst, 6}}
+	if len(got) != len(want) {
+		t.Fatalf("flags: got %+v", flags)
+	}
+	for _, k := range want {
+		if _, ok := got[k]; !ok {
+			t.Fatalf("missing flag %+v in %+v", k, flags)
+		}
+	}
+
+	var evidence struct {
+		CreditGain    int64   `json:"credit_gain"`
+		AllowedCredit float64 `json:"allowed_credit"`
+		Previous      struct {
+			SaveID int64 `json:"save_id"`
+		} `json:"previous"`
+	}
+	if err := json.Unmarshal(got[key{AnomalyCreditRate, 3}].Evidence, &evidence); err != nil {
+		t.Fatalf("decode evidence: %v", err)
+	}
+	if evidence.CreditGain != 50000 || evidence.AllowedCredit != 1000 || evidence.Previous.SaveID != 2 {
+		t.Fatalf("evidence: %+v", evidence)
+	}
+	if score := got[key{AnomalyCreditRate, 3}].Score; score < 33 || score > 34 {
+		t.Fatalf("score: got %v", score)
+	}
+}
+
+func TestAnalyzeSaves_SingleSaveIsBaseline(t *testing.T) {
+	flags, err := AnalyzeSaves("user-1", []SaveSample{{ID: 1, CreditAll: 1 << 40, CpmMax: 1, Unlocked: 500}}, DefaultAnomalyThresholds)
+	if err != nil || len(flags) != 0 {
+		t.Fatalf("got %+v %v", flags, err)
+	}
+}
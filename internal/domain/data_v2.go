@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -15,82 +18,83 @@ import (
 
 // SaveData holds parsed v2 save data *and* DB metadata.
 type SaveData struct {
-	ID                          int64     `db:"id"`
-	UserId                      string    `db:"user_id"`
-	Legacy                      int       `db:"legacy"`
-	Version                     int       `db:"version"`
-	Credit                      int64     `db:"credit"`
-	CreditAll                   int64     `db:"credit_all"`
-	MedalIn                     int       `db:"medal_in"`
-	MedalGet                    int64     `db:"medal_get"`
-	BallGet                     int64     `db:"ball_get"`
-	BallChain                   int       `db:"ball_chain"`
-	SlotStart                   int64     `db:"slot_start"`
-	SlotStartFev                int64     `db:"slot_startfev"`
-	SlotHit                     int64     `db:"slot_hit"`
-	SlotGetFev                  int64     `db:"slot_getfev"`
-	SqrGet                      int64     `db:"sqr_get"`
-	SqrStep                     int64     `db:"sqr_step"`
-	JackGet                     int64     `db:"jack_get"`
-	JackStartMax                int64     `db:"jack_startmax"`
-	JackTotalMax                int       `db:"jack_totalmax"`
-	UltGet                      int       `db:"ult_get"`
-	UltComboMax                 int       `db:"ult_combomax"`
-	UltTotalMax                 int       `db:"ult_totalmax"`
-	RmShbiGet                   int       `db:"rmshbi_get"`
-	BuyShbi                     int       `db:"buy_shbi"`
-	FirstBoot                   int64     `db:"firstboot"`
-	LastSave                    int64     `db:"lastsave"`
-	Playtime                    int64     `db:"playtime"`
-	BstpStep                    int64     `db:"bstp_step"`
-	BstpRwd                     int64     `db:"bstp_rwd"`
-	BuyTotal                    int       `db:"buy_total"`
-	SkillPoint                  int64     `db:"skill_point"`
-	BlackBox                    int       `db:"blackbox"`
-	BlackBoxTotal               int64     `db:"blackbox_total"`
-	SpUse                       int64     `db:"sp_use"`
-	HideRecord                  int       `db:"hide_record"`
-	CpMMax                      float64   `db:"cpm_max"`
-	JackTotalMaxV2              int64     `db:"jack_totalmax_v2"`
-	UltimateTotalMaxV2          int64     `db:"ult_totalmax_v2"`
-	PalettaBallGet              int       `db:"palball_get"`
-	PalettaLotteryAttemptTier0  int       `db:"pallot_lot_t0"`
-	PalettaLotteryAttemptTier1  int       `db:"pallot_lot_t1"`
-	PalettaLotteryAttemptTier2  int       `db:"pallot_lot_t2"`
-	PalettaLotteryAttemptTier3  int       `db:"pallot_lot_t3"`
-	PalettaLotteryAttemptTier4  int       `db:"pallot_lot_t4"`
-	JackpotSuperGetTotal        int       `db:"jacksp_get_all"`
-	JackpotSuperGetTier0        int       `db:"jacksp_get_t0"`
-	JackpotSuperGetTier1        int       `db:"jacksp_get_t1"`
-	JackpotSuperGetTier2        int       `db:"jacksp_get_t2"`
-	JackpotSuperGetTier3        int       `db:"jacksp_get_t3"`
-	JackpotSuperGetTier4        int       `db:"jacksp_get_t4"`
-	JackpotSuperStartMax        int64     `db:"jacksp_startmax"`
-	JackpotSuperTotalMax        int64     `db:"jacksp_totalmax"`
-	FerrettaBallGet             int       `db:"ferball_get"`
-	FerrettaLotteryAttempt      int       `db:"ferlot_lot"`
-	JackpotFerrettaGetTotal     int       `db:"jackfr_get_all"`
-	JackpotFerrettaGetTier0     int       `db:"jackfr_get_t0"`
-	JackpotFerrettaGetTier1     int       `db:"jackfr_get_t1"`
-	JackpotFerrettaGetTier2     int       `db:"jackfr_get_t2"`
-	JackpotFerrettaGetTier3     int       `db:"jackfr_get_t3"`
-	JackpotFerrettaGetTier4     int       `db:"jackfr_get_t4"`
-	JackpotFerrettaStartMax     int64     `db:"jackfr_startmax"`
-	JackpotFerrettaTotalMax     int64     `db:"jackfr_totalmax"`
-	FerrettaLotteryHit          int       `db:"ferlot_hit"`
-	FerrettaLotteryLose         int       `db:"ferlot_lose"`
-	FerrettaLotteryChance       int       `db:"ferlot_chance"`
-	FerrettaLotteryActives      int       `db:"ferlot_act"`
-	FerrettaLotteryLines        int       `db:"ferlot_lines"`
-	BlackBoxShopUsed            int       `db:"bbox_shop"`
-	FerrettaLotteryMaxLines     int       `db:"ferlot_maxln"`
-	BlackBoxUsedFerrettaItem    int       `db:"bbox_used_ferlot"`
-	GetMedalTower               int       `db:"get_medaltower"`
-	TaskCompleteCount           int       `db:"task_cnt"`
-	TotemAltarUnlockCount       int       `db:"totem_altars"`
-	TotemAltarUnlockUsedCredits int64     `db:"totem_altars_credit"`
-	CreatedAt                   time.Time `db:"created_at"`
-	UpdatedAt                   time.Time `db:"updated_at"`
+	ID                          int64           `db:"id"`
+	UserId                      string          `db:"user_id"`
+	Legacy                      int             `db:"legacy"`
+	Version                     int             `db:"version"`
+	Credit                      int64           `db:"credit"`
+	CreditAll                   int64           `db:"credit_all"`
+	MedalIn                     int             `db:"medal_in"`
+	MedalGet                    int64           `db:"medal_get"`
+	BallGet                     int64           `db:"ball_get"`
+	BallChain                   int             `db:"ball_chain"`
+	SlotStart                   int64           `db:"slot_start"`
+	SlotStartFev                int64           `db:"slot_startfev"`
+	SlotHit                     int64           `db:"slot_hit"`
+	SlotGetFev                  int64           `db:"slot_getfev"`
+	SqrGet                      int64           `db:"sqr_get"`
+	SqrStep                     int64           `db:"sqr_step"`
+	JackGet                     int64           `db:"jack_get"`
+	JackStartMax                int64           `db:"jack_startmax"`
+	JackTotalMax                int             `db:"jack_totalmax"`
+	UltGet                      int             `db:"ult_get"`
+	UltComboMax                 int             `db:"ult_combomax"`
+	UltTotalMax                 int             `db:"ult_totalmax"`
+	RmShbiGet                   int             `db:"rmshbi_get"`
+	BuyShbi                     int             `db:"buy_shbi"`
+	FirstBoot                   int64           `db:"firstboot"`
+	LastSave                    int64           `db:"lastsave"`
+	Playtime                    int64           `db:"playtime"`
+	BstpStep                    int64           `db:"bstp_step"`
+	BstpRwd                     int64           `db:"bstp_rwd"`
+	BuyTotal                    int             `db:"buy_total"`
+	SkillPoint                  int64           `db:"skill_point"`
+	BlackBox                    int             `db:"blackbox"`
+	BlackBoxTotal               int64           `db:"blackbox_total"`
+	SpUse                       int64           `db:"sp_use"`
+	HideRecord                  int             `db:"hide_record"`
+	CpMMax                      float64         `db:"cpm_max"`
+	JackTotalMaxV2              int64           `db:"jack_totalmax_v2"`
+	UltimateTotalMaxV2          int64           `db:"ult_totalmax_v2"`
+	PalettaBallGet              int             `db:"palball_get"`
+	PalettaLotteryAttemptTier0  int             `db:"pallot_lot_t0"`
+	PalettaLotteryAttemptTier1  int             `db:"pallot_lot_t1"`
+	PalettaLotteryAttemptTier2  int             `db:"pallot_lot_t2"`
+	PalettaLotteryAttemptTier3  int             `db:"pallot_lot_t3"`
+	PalettaLotteryAttemptTier4  int             `db:"pallot_lot_t4"`
+	JackpotSuperGetTotal        int             `db:"jacksp_get_all"`
+	JackpotSuperGetTier0        int             `db:"jacksp_get_t0"`
+	JackpotSuperGetTier1        int             `db:"jacksp_get_t1"`
+	JackpotSuperGetTier2        int             `db:"jacksp_get_t2"`
+	JackpotSuperGetTier3        int             `db:"jacksp_get_t3"`
+	JackpotSuperGetTier4        int             `db:"jacksp_get_t4"`
+	JackpotSuperStartMax        int64           `db:"jacksp_startmax"`
+	JackpotSuperTotalMax        int64           `db:"jacksp_totalmax"`
+	FerrettaBallGet             int             `db:"ferball_get"`
+	FerrettaLotteryAttempt      int             `db:"ferlot_lot"`
+	JackpotFerrettaGetTotal     int             `db:"jackfr_get_all"`
+	JackpotFerrettaGetTier0     int             `db:"jackfr_get_t0"`
+	JackpotFerrettaGetTier1     int             `db:"jackfr_get_t1"`
+	JackpotFerrettaGetTier2     int             `db:"jackfr_get_t2"`
+	JackpotFerrettaGetTier3     int             `db:"jackfr_get_t3"`
+	JackpotFerrettaGetTier4     int             `db:"jackfr_get_t4"`
+	JackpotFerrettaStartMax     int64           `db:"jackfr_startmax"`
+	JackpotFerrettaTotalMax     int64           `db:"jackfr_totalmax"`
+	FerrettaLotteryHit          int             `db:"ferlot_hit"`
+	FerrettaLotteryLose         int             `db:"ferlot_lose"`
+	FerrettaLotteryChance       int             `db:"ferlot_chance"`
+	FerrettaLotteryActives      int             `db:"ferlot_act"`
+	FerrettaLotteryLines        int             `db:"ferlot_lines"`
+	BlackBoxShopUsed            int             `db:"bbox_shop"`
+	FerrettaLotteryMaxLines     int             `db:"ferlot_maxln"`
+	BlackBoxUsedFerrettaItem    int             `db:"bbox_used_ferlot"`
+	GetMedalTower               int             `db:"get_medaltower"`
+	TaskCompleteCount           int             `db:"task_cnt"`
+	TotemAltarUnlockCount       int             `db:"totem_altars"`
+	TotemAltarUnlockUsedCredits int64           `db:"totem_altars_credit"`
+	CreatedAt                   time.Time       `db:"created_at"`
+	UpdatedAt                   time.Time       `db:"updated_at"`
+	ExtraFields                 SaveExtraFields `db:"extra_fields"`
 
 	// child tables (not loaded via SELECT *)
 	DCMedalGet                map[string]int   `db:"-"`
@@ -107,107 +111,127 @@ type SaveData struct {
 	LTotemLevels              []int            `db:"-"`
 	LTotemUsedCredits         []int64          `db:"-"`
 	LTotemPlacements          []int            `db:"-"`
+
+	// UnlockedAchievements is filled by InsertSaveV4 with the achievements this save recorded for the first time.
+	UnlockedAchievements []string `db:"-"`
+}
+
+// savePayload is the save JSON as the client sends it. Every stored key of SaveKeys needs a field here.
+type savePayload struct {
+	Legacy                      *int             `json:"legacy"`
+	Version                     *int             `json:"version"`
+	Credit                      json.RawMessage  `json:"credit"`
+	CreditAll                   json.RawMessage  `json:"credit_all"`
+	MedalIn                     *int             `json:"medal_in"`
+	MedalGet                    *int64           `json:"medal_get"`
+	BallGet                     *int64           `json:"ball_get"`
+	BallChain                   *int             `json:"ball_chain"`
+	SlotStart                   *int64           `json:"slot_start"`
+	SlotStartFev                *int64           `json:"slot_startfev"`
+	SlotHit                     *int64           `json:"slot_hit"`
+	SlotGetFev                  *int64           `json:"slot_getfev"`
+	SqrGet                      json.RawMessage  `json:"sqr_get"`
+	SqrStep                     *int64           `json:"sqr_step"`
+	JackGet                     json.RawMessage  `json:"jack_get"`
+	JackStartMax                *int64           `json:"jack_startmax"`
+	JackTotalMax                *int             `json:"jack_totalmax"`
+	UltGet                      *int             `json:"ult_get"`
+	UltComboMax                 *int             `json:"ult_combomax"`
+	UltTotalMax                 *int             `json:"ult_totalmax"`
+	RmShbiGet                   *int             `json:"rmshbi_get"`
+	BuyShbi                     *int             `json:"buy_shbi"`
+	FirstBoot                   *json.Number     `json:"firstboot"`
+	LastSave                    *json.Number     `json:"lastsave"`
+	Playtime                    *int64           `json:"playtime"`
+	BstpStep                    json.RawMessage  `json:"bstp_step"`
+	BstpRwd                     json.RawMessage  `json:"bstp_rwd"`
+	BuyTotal                    *int             `json:"buy_total"`
+	SkillPoint                  json.RawMessage  `json:"sp"`
+	BlackBox                    *float64         `json:"bbox"`
+	BlackBoxTotal               *float64         `json:"bbox_all"`
+	SpUse                       json.RawMessage  `json:"sp_use"`
+	HideRecord                  *int             `json:"hide_record"`
+	CpMMax                      *float64         `json:"cpm_max"`
+	JackTotalMaxV2              *int64           `json:"jack_totalmax_v2"`
+	UltimateTotalMaxV2          *int64           `json:"ult_totalmax_v2"`
+	PalettaBallGet              *int             `json:"palball_get"`
+	PalettaLotteryAttemptTier0  *float64         `json:"pallot_lot_t0"`
+	PalettaLotteryAttemptTier1  *float64         `json:"pallot_lot_t1"`
+	PalettaLotteryAttemptTier2  *float64         `json:"pallot_lot_t2"`
+	PalettaLotteryAttemptTier3  *float64         `json:"pallot_lot_t3"`
+	PalettaLotteryAttemptTier4  *float64         `json:"pallot_lot_t4"`
+	JackpotSuperGetTotal        *int             `json:"jacksp_get_all"`
+	JackpotSuperGetTier0        *int             `json:"jacksp_get_t0"`
+	JackpotSuperGetTier1        *int             `json:"jacksp_get_t1"`
+	JackpotSuperGetTier2        *int             `json:"jacksp_get_t2"`
+	JackpotSuperGetTier3        *int             `json:"jacksp_get_t3"`
+	JackpotSuperGetTier4        *int             `json:"jacksp_get_t4"`
+	JackpotSuperStartMax        *int64           `json:"jacksp_startmax"`
+	JackpotSuperTotalMax        *int64           `json:"jacksp_totalmax"`
+	FerrettaBallGet             *float64         `json:"ferball_get"`
+	FerrettaLotteryAttempt      *float64         `json:"ferlot_lot"`
+	JackpotFerrettaGetTotal     *float64         `json:"jackfr_get_all"`
+	JackpotFerrettaGetTier0     *float64         `json:"jackfr_get_t0"`
+	JackpotFerrettaGetTier1     *float64         `json:"jackfr_get_t1"`
+	JackpotFerrettaGetTier2     *float64         `json:"jackfr_get_t2"`
+	JackpotFerrettaGetTier3     *float64         `json:"jackfr_get_t3"`
+	JackpotFerrettaGetTier4     *float64         `json:"jackfr_get_t4"`
+	JackpotFerrettaStartMax     json.RawMessage  `json:"jackfr_startmax"`
+	JackpotFerrettaTotalMax     json.RawMessage  `json:"jackfr_totalmax"`
+	FerrettaLotteryHit          *float64         `json:"ferlot_hit"`
+	FerrettaLotteryLose         *float64         `json:"ferlot_lose"`
+	FerrettaLotteryChance       *float64         `json:"ferlot_chance"`
+	FerrettaLotteryActives      *float64         `json:"ferlot_act"`
+	FerrettaLotteryLines        *float64         `json:"ferlot_lines"`
+	BlackBoxShopUsed            *float64         `json:"bbox_shop"`
+	FerrettaLotteryMaxLines     *float64         `json:"ferlot_maxln"`
+	BlackBoxUsedFerrettaItem    *float64         `json:"bbox_used_ferlot"`
+	GetMedalTower               *float64         `json:"get_medaltower"`
+	TaskCompleteCount           *float64         `json:"task_cnt"`
+	DCMedalGet                  map[string]int   `json:"dc_medal_get"`
+	DCBallGet                   map[string]int64 `json:"dc_ball_get"`
+	DCBallChain                 map[string]int   `json:"dc_ball_chain"`
+	LAchieve                    []interface{}    `json:"l_achieve"`
+	DCPalettaBallGet            map[string]int   `json:"dc_palball_get"`
+	DCPalettaBallJackpot        map[string]int   `json:"dc_palball_jp"`
+	DCBlackBoxShopUsed          map[string]int   `json:"dc_bbox_shop"`
+	DCFerrettaLotteryItem       map[string]int   `json:"dc_ferlot_item"`
+	DCFerrettaLotteryItemUsed   map[string]int   `json:"dc_ferlot_useitem"`
+	LPerkLevels                 []interface{}    `json:"l_perks"`
+	LPerkUsedCredits            []interface{}    `json:"l_perks_credit"`
+	TotemAltarUnlockCount       *int             `json:"totem_altars"`
+	TotemAltarUnlockUsedCredits *int64           `json:"totem_altars_credit"`
+	LTotemLevels                []interface{}    `json:"l_totems"`
+	LTotemUsedCredits           []interface{}    `json:"l_totems_credit"`
+	LTotemPlacements            []interface{}    `json:"l_totems_set"`
+	UserId                      *string          `json:"user_id"`
 }
 
+// ErrInvalidSaveData is returned (wrapped) by ParseSaveData when the payload cannot be decoded.
+var ErrInvalidSaveData = errors.New("invalid save data")
+
 // ParseSaveData decodes URL-encoded JSON into a minimal SaveData for insert.
 // It does *not* fill ID/CreatedAt/UpdatedAt — those come from the DB.
-func ParseSaveData(raw string) (*SaveData, error) {
+// The warnings compare the keys with the schema of the save's version; in SchemaStrict mode a save that
+// violates it is rejected with a *SaveSchemaError, still along with the warnings.
+// Unknown keys are kept in ExtraFields.
+func ParseSaveData(raw string, mode SaveSchemaMode) (*SaveData, []SaveWarning, error) {
 	decoded, err := decodeSavePayload(raw)
 	if err != nil {
-		return nil, err
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidSaveData, err)
 	}
 
-	var m struct {
-		Legacy                      *int             `json:"legacy"`
-		Version                     *int             `json:"version"`
-		Credit                      json.RawMessage  `json:"credit"`
-		CreditAll                   json.RawMessage  `json:"credit_all"`
-		MedalIn                     *int             `json:"medal_in"`
-		MedalGet                    *int64           `json:"medal_get"`
-		BallGet                     *int64           `json:"ball_get"`
-		BallChain                   *int             `json:"ball_chain"`
-		SlotStart                   *int64           `json:"slot_start"`
-		SlotStartFev                *int64           `json:"slot_startfev"`
-		SlotHit                     *int64           `json:"slot_hit"`
-		SlotGetFev                  *int64           `json:"slot_getfev"`
-		SqrGet                      json.RawMessage  `json:"sqr_get"`
-		SqrStep                     *int64           `json:"sqr_step"`
-		JackGet                     json.RawMessage  `json:"jack_get"`
-		JackStartMax                *int64           `json:"jack_startmax"`
-		JackTotalMax                *int             `json:"jack_totalmax"`
-		UltGet                      *int             `json:"ult_get"`
-		UltComboMax                 *int             `json:"ult_combomax"`
-		UltTotalMax                 *int             `json:"ult_totalmax"`
-		RmShbiGet                   *int             `json:"rmshbi_get"`
-		BuyShbi                     *int             `json:"buy_shbi"`
-		FirstBoot                   *json.Number     `json:"firstboot"`
-		LastSave                    *json.Number     `json:"lastsave"`
-		Playtime                    *int64           `json:"playtime"`
-		BstpStep                    json.RawMessage  `json:"bstp_step"`
-		BstpRwd                     json.RawMessage  `json:"bstp_rwd"`
-		BuyTotal                    *int             `json:"buy_total"`
-		SkillPoint                  json.RawMessage  `json:"sp"`
-		BlackBox                    *float64         `json:"bbox"`
-		BlackBoxTotal               *float64         `json:"bbox_all"`
-		SpUse                       json.RawMessage  `json:"sp_use"`
-		HideRecord                  *int             `json:"hide_record"`
-		CpMMax                      *float64         `json:"cpm_max"`
-		JackTotalMaxV2              *int64           `json:"jack_totalmax_v2"`
-		UltimateTotalMaxV2          *int64           `json:"ult_totalmax_v2"`
-		PalettaBallGet              *int             `json:"palball_get"`
-		PalettaLotteryAttemptTier0  *float64         `json:"pallot_lot_t0"`
-		PalettaLotteryAttemptTier1  *float64         `json:"pallot_lot_t1"`
-		PalettaLotteryAttemptTier2  *float64         `json:"pallot_lot_t2"`
-		PalettaLotteryAttemptTier3  *float64         `json:"pallot_lot_t3"`
-		PalettaLotteryAttemptTier4  *float64         `json:"pallot_lot_t4"`
-		JackpotSuperGetTotal        *int             `json:"jacksp_get_all"`
-		JackpotSuperGetTier0        *int             `json:"jacksp_get_t0"`
-		JackpotSuperGetTier1        *int             `json:"jacksp_get_t1"`
-		JackpotSuperGetTier2        *int             `json:"jacksp_get_t2"`
-		JackpotSuperGetTier3        *int             `json:"jacksp_get_t3"`
-		JackpotSuperGetTier4        *int             `json:"jacksp_get_t4"`
-		JackpotSuperStartMax        *int64           `json:"jacksp_startmax"`
-		JackpotSuperTotalMax        *int64           `json:"jacksp_totalmax"`
-		FerrettaBallGet             *float64         `json:"ferball_get"`
-		FerrettaLotteryAttempt      *float64         `json:"ferlot_lot"`
-		JackpotFerrettaGetTotal     *float64         `json:"jackfr_get_all"`
-		JackpotFerrettaGetTier0     *float64         `json:"jackfr_get_t0"`
-		JackpotFerrettaGetTier1     *float64         `json:"jackfr_get_t1"`
-		JackpotFerrettaGetTier2     *float64         `json:"jackfr_get_t2"`
-		JackpotFerrettaGetTier3     *float64         `json:"jackfr_get_t3"`
-		JackpotFerrettaGetTier4     *float64         `json:"jackfr_get_t4"`
-		JackpotFerrettaStartMax     json.RawMessage  `json:"jackfr_startmax"`
-		JackpotFerrettaTotalMax     json.RawMessage  `json:"jackfr_totalmax"`
-		FerrettaLotteryHit          *float64         `json:"ferlot_hit"`
-		FerrettaLotteryLose         *float64         `json:"ferlot_lose"`
-		FerrettaLotteryChance       *float64         `json:"ferlot_chance"`
-		FerrettaLotteryActives      *float64         `json:"ferlot_act"`
-		FerrettaLotteryLines        *float64         `json:"ferlot_lines"`
-		BlackBoxShopUsed            *float64         `json:"bbox_shop"`
-		FerrettaLotteryMaxLines     *float64         `json:"ferlot_maxln"`
-		BlackBoxUsedFerrettaItem    *float64         `json:"bbox_used_ferlot"`
-		GetMedalTower               *float64         `json:"get_medaltower"`
-		TaskCompleteCount           *float64         `json:"task_cnt"`
-		DCMedalGet                  map[string]int   `json:"dc_medal_get"`
-		DCBallGet                   map[string]int64 `json:"dc_ball_get"`
-		DCBallChain                 map[string]int   `json:"dc_ball_chain"`
-		LAchieve                    []interface{}    `json:"l_achieve"`
-		DCPalettaBallGet            map[string]int   `json:"dc_palball_get"`
-		DCPalettaBallJackpot        map[string]int   `json:"dc_palball_jp"`
-		DCBlackBoxShopUsed          map[string]int   `json:"dc_bbox_shop"`
-		DCFerrettaLotteryItem       map[string]int   `json:"dc_ferlot_item"`
-		DCFerrettaLotteryItemUsed   map[string]int   `json:"dc_ferlot_useitem"`
-		LPerkLevels                 []interface{}    `json:"l_perks"`
-		LPerkUsedCredits            []interface{}    `json:"l_perks_credit"`
-		TotemAltarUnlockCount       *int             `json:"totem_altars"`
-		TotemAltarUnlockUsedCredits *int64           `json:"totem_altars_credit"`
-		LTotemLevels                []interface{}    `json:"l_totems"`
-		LTotemUsedCredits           []interface{}    `json:"l_totems_credit"`
-		LTotemPlacements            []interface{}    `json:"l_totems_set"`
-		UserId                      *string          `json:"user_id"`
-	}
+	var m savePayload
 	if err := json.Unmarshal([]byte(decoded), &m); err != nil {
-		return nil, err
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidSaveData, err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(decoded), &fields); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidSaveData, err)
+	}
+	warnings := checkSaveSchema(slices.Collect(maps.Keys(fields)), safeInt(m.Version))
+	if violations := schemaViolations(warnings); mode == SchemaStrict && len(violations) > 0 {
+		return nil, warnings, &SaveSchemaError{Version: safeInt(m.Version), Violations: violations}
 	}
 
 	// ---------- ここで数値⇔文字列を吸収 ----------
@@ -311,8 +335,9 @@ func ParseSaveData(raw string) (*SaveData, error) {
 		LTotemLevels:                parseIntArray(m.LTotemLevels),
 		LTotemUsedCredits:           parseInt64Array(m.LTotemUsedCredits),
 		LTotemPlacements:            parseIntArray(m.LTotemPlacements),
+		ExtraFields:                 extraFields(fields, warnings),
 	}
-	return sd, nil
+	return sd, warnings, nil
 }
 
 func decodeSavePayload(raw string) (string, error) {
@@ -518,6 +543,9 @@ func (sd *SaveData) ToModel() *models.SaveDataV2 {
 		LTotems:         &sd.LTotemLevels,
 		LTotemsCredit:   &sd.LTotemUsedCredits,
 		LTotemsSet:      &sd.LTotemPlacements,
+
+		// keys the server does not know, returned as the client sent them
+		AdditionalProperties: sd.modelExtraFields(),
 	}
 
 	return m
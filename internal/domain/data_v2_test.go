@@ -1,13 +1,50 @@
 package domain
 
 import (
+	"bytes"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"flag"
+	"math/rand/v2"
 	"net/url"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 )
 
+var updateGolden = flag.Bool("update", false, "rewrite testdata/saves/*.golden from the current ParseSaveData")
+
+// payloadEncodings are the ways a client may send the data parameter.
+var payloadEncodings = map[string]func(string) string{
+	"urlencoded": url.QueryEscape,
+	"base64":     func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) },
+	"base64url":  func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) },
+}
+
+// saveCorpus returns the payloads in testdata/saves by file name. There is one per save version that added keys
+// in ClientJsonKeyDefines.cs, each with every key up to that version, and v19_quirks with the shapes ParseSaveData
+// tolerates (numbers as strings and the reverse) plus keys the server reads that the client file does not list.
+func saveCorpus(tb testing.TB) map[string]string {
+	tb.Helper()
+	paths, err := filepath.Glob(filepath.Join("testdata", "saves", "*.json"))
+	if err != nil || len(paths) == 0 {
+		tb.Fatalf("no save corpus: %v", err)
+	}
+	corpus := make(map[string]string, len(paths))
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			tb.Fatalf("read %s: %v", path, err)
+		}
+		corpus[strings.TrimSuffix(filepath.Base(path), ".json")] = string(b)
+	}
+	return corpus
+}
+
 func TestParseSaveData_Base64AndUrlEncoded(t *testing.T) {
 	payload := `{"legacy":1,"version":4,"credit":"100","credit_all":200,"medal_in":3,"medal_get":4,"ball_get":5,"ball_chain":6,"sqr_get":"7","jack_get":8,"firstboot":12345,"lastsave":23456,"playtime":789,"jackfr_startmax":"1234","jackfr_totalmax":5678,"ferlot_lines":9.9,"bbox_shop":2.2,"ferlot_maxln":5.8,"bbox_used_ferlot":4.4,"get_medaltower":7.2,"task_cnt":3.9,"dc_bbox_shop":{"item-1":3},"dc_ferlot_item":{"item-2":4},"dc_ferlot_useitem":{"item-3":5},"l_achieve":["a",2],"l_perks":[1,2],"l_perks_credit":[10,20],"l_totems":[3],"l_totems_credit":[30],"l_totems_set":[2]}`
 	base64Payload := base64.RawURLEncoding.EncodeToString([]byte(payload))
@@ -15,7 +52,7 @@ func TestParseSaveData_Base64AndUrlEncoded(t *testing.T) {
 
 	assertParsed := func(t *testing.T, raw string) {
 		t.Helper()
-		sd, err := ParseSaveData(raw)
+		sd, _, err := ParseSaveData(raw, SchemaLenient)
 		if err != nil {
 			t.Fatalf("ParseSaveData error: %v", err)
 		}
@@ -185,3 +222,194 @@ func TestSaveDataToModel(t *testing.T) {
 		t.Fatalf("LAchieve: got %#v", model.LAchieve)
 	}
 }
+
+func TestParseSaveData_Golden(t *testing.T) {
+	for name, payload := range saveCorpus(t) {
+		t.Run(name, func(t *testing.T) {
+			var got []byte
+			for enc, encode := range payloadEncodings {
+				sd, warnings, err := ParseSaveData(encode(payload), SchemaStrict)
+				if err != nil {
+					t.Fatalf("%s: %v", enc, err)
+				}
+				if len(warnings) > 0 {
+					t.Fatalf("%s: the corpus follows the schema of its version: %v", enc, warnings)
+				}
+				b, err := json.MarshalIndent(sd, "", "  ")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got != nil && !bytes.Equal(b, got) {
+					t.Fatalf("%s parses differently:\n%s\nwant\n%s", enc, b, got)
+				}
+				got = b
+			}
+			got = append(got, '\n')
+
+			golden := filepath.Join("testdata", "saves", name+".golden")
+			if *updateGolden {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("read golden (run with -update to create it): %v", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Fatalf("ParseSaveData changed; if intended, run with -update and review the diff:\n%s", got)
+			}
+		})
+	}
+}
+
+// nonEmpty returns a copy of sd with empty maps and lists set to nil, as ParseSaveData does not tell a missing
+// list from an empty one.
+func nonEmpty(sd *SaveData) SaveData {
+	c := *sd
+	v := reflect.ValueOf(&c).Elem()
+	for i := range v.NumField() {
+		f := v.Field(i)
+		if (f.Kind() == reflect.Map || f.Kind() == reflect.Slice) && f.Len() == 0 {
+			f.SetZero()
+		}
+	}
+	return c
+}
+
+// notInModel are the SaveData fields SaveDataV2 does not carry.
+var notInModel = map[string]bool{"ID": true, "UserId": true, "CreatedAt": true, "UpdatedAt": true, "UnlockedAchievements": true}
+
+// randomSaveData fills every field SaveDataV2 carries. Integers stay below 2^53 because the client stores
+// them as doubles.
+func randomSaveData(rng *rand.Rand) *SaveData {
+	sd := &SaveData{}
+	v := reflect.ValueOf(sd).Elem()
+	for i := range v.NumField() {
+		if notInModel[v.Type().Field(i).Name] {
+			continue
+		}
+		f := v.Field(i)
+		switch f.Interface().(type) {
+		case int:
+			f.SetInt(rng.Int64N(1 << 31))
+		case int64:
+			f.SetInt(rng.Int64N(1 << 53))
+		case float64:
+			f.SetFloat(float64(rng.IntN(1<<20)) / 8)
+		case map[string]int, map[string]int64:
+			f.Set(reflect.MakeMap(f.Type()))
+			for range rng.IntN(4) {
+				f.SetMapIndex(reflect.ValueOf(strconv.Itoa(rng.IntN(200))), reflect.ValueOf(rng.Int64N(1<<31)).Convert(f.Type().Elem()))
+			}
+		case []string, []int, []int64:
+			f.Set(reflect.MakeSlice(f.Type(), rng.IntN(4), 4))
+			for j := range f.Len() {
+				if f.Index(j).Kind() == reflect.String {
+					f.Index(j).SetString("ach_" + strconv.Itoa(rng.IntN(500)))
+				} else {
+					f.Index(j).SetInt(rng.Int64N(1 << 31))
+				}
+			}
+		case SaveExtraFields:
+			// keys no save version defines, with compact values so that they come back byte for byte
+			for j := range rng.IntN(3) {
+				if f.IsNil() {
+					f.Set(reflect.MakeMap(f.Type()))
+				}
+				value := json.RawMessage(strconv.Itoa(rng.IntN(1 << 20)))
+				if j%2 == 1 {
+					value = json.RawMessage(`{"n":[` + strconv.Itoa(rng.IntN(100)) + `]}`)
+				}
+				f.SetMapIndex(reflect.ValueOf("zz_extra_"+strconv.Itoa(j)), reflect.ValueOf(value))
+			}
+		default:
+			panic("randomSaveData: unhandled field " + v.Type().Field(i).Name)
+		}
+	}
+	return sd
+}
+
+func TestSaveDataToModel_RoundTrip(t *testing.T) {
+	roundTrip := func(t *testing.T, want *SaveData) {
+		t.Helper()
+		payload, err := json.Marshal(want.ToModel())
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, _, err := ParseSaveData(base64.StdEncoding.EncodeToString(payload), SchemaLenient)
+		if err != nil {
+			t.Fatalf("parse %s: %v", payload, err)
+		}
+		if g, w := nonEmpty(got), nonEmpty(want); !reflect.DeepEqual(g, w) {
+			t.Fatalf("round trip through ToModel changed the save:\ngot  %+v\nwant %+v", g, w)
+		}
+	}
+
+	for name, payload := range saveCorpus(t) {
+		t.Run(name, func(t *testing.T) {
+			sd, _, err := ParseSaveData(url.QueryEscape(payload), SchemaLenient)
+			if err != nil {
+				t.Fatal(err)
+			}
+			roundTrip(t, sd)
+		})
+	}
+	t.Run("random", func(t *testing.T) {
+		rng := rand.New(rand.NewPCG(1, 2))
+		for range 500 {
+			roundTrip(t, randomSaveData(rng))
+		}
+	})
+}
+
+func FuzzParseSaveData(f *testing.F) {
+	for _, payload := range saveCorpus(f) {
+		for _, encode := range payloadEncodings {
+			f.Add(encode(payload))
+		}
+	}
+	f.Add("")
+	f.Add("%ZZ")
+	f.Add(`{"credit":"1e400","l_achieve":[null,{}],"l_perks":["x"]}`)
+	f.Fuzz(func(t *testing.T, raw string) {
+		sd, warnings, err := ParseSaveData(raw, SchemaLenient)
+		if err != nil {
+			if !errors.Is(err, ErrInvalidSaveData) {
+				t.Fatalf("error does not wrap ErrInvalidSaveData: %v", err)
+			}
+			return
+		}
+		if _, err := json.Marshal(sd.ToModel()); err != nil {
+			t.Fatalf("accepted save does not marshal: %v", err)
+		}
+		// Strict mode rejects exactly the saves with violations, reporting the same warnings.
+		_, strictWarnings, err := ParseSaveData(raw, SchemaStrict)
+		if (err != nil) != (len(schemaViolations(warnings)) > 0) || !reflect.DeepEqual(strictWarnings, warnings) {
+			t.Fatalf("strict: %v with %v, lenient warnings %v", err, strictWarnings, warnings)
+		}
+	})
+}
+
+func FuzzDecodeSavePayload(f *testing.F) {
+	for _, payload := range saveCorpus(f) {
+		f.Add(payload)
+	}
+	f.Add(`{"credit":1}`)
+	f.Add("not-base64")
+	f.Add(" [1]")
+	f.Fuzz(func(t *testing.T, s string) {
+		_, _ = decodeSavePayload(s)
+		if !looksLikeJSON(s) {
+			return
+		}
+		// Any JSON document survives every encoding the client may use.
+		for enc, encode := range payloadEncodings {
+			got, err := decodeSavePayload(encode(s))
+			if err != nil || got != s {
+				t.Fatalf("%s: got %q, %v want %q", enc, got, err, s)
+			}
+		}
+	})
+}
@@ -0,0 +1,7 @@
+package domain
+
+// SaveIngested is published after a v4 save has been committed.
+// Save carries the stored values, including ID and UnlockedAchievements set by the repository.
+type SaveIngested struct {
+	Save *SaveData
+}
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/openapi/models"
+)
+
+// RankedMetric declares one leaderboard over v3_user_latest_save_data. The ranking queries of GetStatisticsV4,
+// the per-metric ranking endpoint, the statistics cache invalidation and the column's value in ProjectRanking
+// (and so the upsert of v3_user_latest_save_data) are all driven by RankedMetrics.
+type RankedMetric struct {
+	// Name is the key of the ranking in StatisticsV4 and the {metric} of /v4/rankings/{metric}.
+	Name string
+	// Column is the column of v3_user_latest_save_data holding the value.
+	Column string
+	// Source derives the value from the user's latest save, as an int64 or a float64 (see LatestSaveColumn).
+	Source func(sd *SaveData) any
+	// Descending ranks larger values first.
+	Descending bool
+	// TieBreaker orders users with the same value, as an ORDER BY term on v3_user_latest_save_data.
+	TieBreaker string
+}
+
+// tieEarliestFirst ranks the user who reached the value first (their latest save is the oldest) higher.
+const tieEarliestFirst = "updated_at ASC"
+
+// RankedMetrics lists every leaderboard, in the order GetStatisticsV4 computes them.
+// Adding a leaderboard means adding an entry here, a migration adding its column and its StatisticsV4 property
+// in openapi.yaml. The registry is checked at init (see buildLatestSaveColumns).
+var RankedMetrics = []RankedMetric{
+	{Name: "achievements_count", Column: "achievements_count", Source: func(sd *SaveData) any { return int64(len(sd.LAchieve)) }, Descending: true, TieBreaker: tieEarliestFirst},
+	{Name: "jacksp_startmax", Column: "jacksp_startmax", Source: func(sd *SaveData) any { return sd.JackpotSuperStartMax }, Descending: true, TieBreaker: tieEarliestFirst},
+	{Name: "golden_palball_get", Column: "golden_palball_get", Source: func(sd *SaveData) any { return int64(sd.DCPalettaBallGet[goldenPalballID]) }, Descending: true, TieBreaker: tieEarliestFirst},
+	{Name: "jackfr_startmax", Column: "jackfr_startmax", Source: func(sd *SaveData) any { return sd.JackpotFerrettaStartMax }, Descending: true, TieBreaker: tieEarliestFirst},
+	{Name: "jackfr_totalmax", Column: "jackfr_totalmax", Source: func(sd *SaveData) any { return sd.JackpotFerrettaTotalMax }, Descending: true, TieBreaker: tieEarliestFirst},
+	{Name: "ferlot_lines", Column: "ferlot_lines", Source: func(sd *SaveData) any { return int64(sd.FerrettaLotteryLines) }, Descending: true, TieBreaker: tieEarliestFirst},
+	{Name: "cpm_max", Column: "cpm_max", Source: func(sd *SaveData) any { return sd.CpMMax }, Descending: true, TieBreaker: tieEarliestFirst},
+	{Name: "max_chain_rainbow", Column: "max_chain_rainbow", Source: func(sd *SaveData) any { return int64(sd.DCBallChain[rainbowChainBallID]) }, Descending: true, TieBreaker: tieEarliestFirst},
+	{Name: "jack_totalmax_v2", Column: "jack_totalmax_v2", Source: func(sd *SaveData) any { return sd.JackTotalMaxV2 }, Descending: true, TieBreaker: tieEarliestFirst},
+	{Name: "ult_combomax", Column: "ult_combomax", Source: func(sd *SaveData) any { return int64(sd.UltComboMax) }, Descending: true, TieBreaker: tieEarliestFirst},
+	{Name: "ult_totalmax_v2", Column: "ult_totalmax_v2", Source: func(sd *SaveData) any { return sd.UltimateTotalMaxV2 }, Descending: true, TieBreaker: tieEarliestFirst},
+	{Name: "blackbox_total", Column: "blackbox_total", Source: func(sd *SaveData) any { return sd.BlackBoxTotal }, Descending: true, TieBreaker: tieEarliestFirst},
+	{Name: "sp_use", Column: "sp_use", Source: func(sd *SaveData) any { return sd.SpUse }, Descending: true, TieBreaker: tieEarliestFirst},
+}
+
+// LookupRankedMetric returns the metric named name.
+func LookupRankedMetric(name string) (RankedMetric, bool) {
+	for _, m := range RankedMetrics {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return RankedMetric{}, false
+}
+
+// Value returns the metric's value in the projection, as a number.
+// It panics if the metric's column is not part of the projection (a metric missing from RankedMetrics).
+func (m RankedMetric) Value(p RankingProjection) float64 {
+	switch v := p.Column(m.Column).(type) {
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	default:
+		panic(fmt.Sprintf("ranked metric %s: unexpected value %T", m.Name, v))
+	}
+}
+
+// Better reports whether value a ranks above value b.
+func (m RankedMetric) Better(a, b float64) bool {
+	if m.Descending {
+		return a > b
+	}
+	return a < b
+}
+
+// RankingEntries returns the field of stats holding the ranking of the metric named name, or nil if there is none.
+func RankingEntries(stats *models.StatisticsV4, name string) **[]models.RankingEntry {
+	v := reflect.ValueOf(stats).Elem()
+	t := v.Type()
+	for i := range t.NumField() {
+		if tag, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ","); tag == name {
+			if p, ok := v.Field(i).Addr().Interface().(**[]models.RankingEntry); ok {
+				return p
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/openapi/models"
+)
+
+func TestRankedMetricsAreConsistent(t *testing.T) {
+	columns := RankingColumns()
+	seen := make(map[string]bool)
+	for _, m := range RankedMetrics {
+		if seen[m.Name] {
+			t.Errorf("metric %s is declared twice", m.Name)
+		}
+		seen[m.Name] = true
+		if !slices.Contains(columns, m.Column) {
+			t.Errorf("metric %s: column %s is not a RankingProjection column", m.Name, m.Column)
+		}
+		if RankingEntries(&models.StatisticsV4{}, m.Name) == nil {
+			t.Errorf("metric %s has no StatisticsV4 property", m.Name)
+		}
+	}
+}
+
+func TestLookupRankedMetric(t *testing.T) {
+	if m, ok := LookupRankedMetric("cpm_max"); !ok || m.Column != "cpm_max" {
+		t.Fatalf("cpm_max: got %+v, %v", m, ok)
+	}
+	// A projected column is not a leaderboard unless it is registered.
+	if _, ok := LookupRankedMetric("credit_all"); ok {
+		t.Fatal("credit_all should not be a ranked metric")
+	}
+}
+
+func TestRankedMetricValue(t *testing.T) {
+	p := ProjectRanking(&SaveData{
+		CpMMax:      12.5,
+		SpUse:       7,
+		LAchieve:    []string{"a", "b"},
+		DCBallChain: map[string]int{"3": 9},
+	})
+	tests := []struct {
+		metric string
+		want   float64
+	}{
+		{"cpm_max", 12.5},
+		{"sp_use", 7},
+		{"achievements_count", 2},
+		{"max_chain_rainbow", 9},
+		{"blackbox_total", 0},
+	}
+	for _, tt := range tests {
+		m, ok := LookupRankedMetric(tt.metric)
+		if !ok {
+			t.Fatalf("metric %s is not registered", tt.metric)
+		}
+		if got := m.Value(p); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.metric, got, tt.want)
+		}
+	}
+
+	// a metric whose column the projection does not have fails instead of ranking everyone at 0
+	defer func() {
+		if recover() == nil {
+			t.Fatal("an unregistered metric must panic")
+		}
+	}()
+	RankedMetric{Name: "unregistered", Column: "unregistered"}.Value(p)
+}
+
+func TestRankedMetricBetter(t *testing.T) {
+	desc := RankedMetric{Descending: true}
+	if !desc.Better(2, 1) || desc.Better(1, 2) || desc.Better(1, 1) {
+		t.Error("descending metric should rank larger values first")
+	}
+	asc := RankedMetric{}
+	if !asc.Better(1, 2) || asc.Better(2, 1) || asc.Better(1, 1) {
+		t.Error("ascending metric should rank smaller values first")
+	}
+}
+
+func TestRankingEntries(t *testing.T) {
+	stats := &models.StatisticsV4{}
+	list := []models.RankingEntry{{}}
+	*RankingEntries(stats, "jack_totalmax_v2") = &list
+	if stats.JackTotalmaxV2 == nil || len(*stats.JackTotalmaxV2) != 1 {
+		t.Fatalf("jack_totalmax_v2 was not set: %+v", stats)
+	}
+	if RankingEntries(stats, "total_medals") != nil {
+		t.Error("total_medals is not a ranking")
+	}
+}
@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// Moderation is a moderator's decision about a user.
+// While it is active the user is left out of every ranking and statistic; a banned user may also have saves rejected.
+type Moderation struct {
+	UserID string `db:"user_id" json:"user_id"`
+	// Banned also implies HiddenFromRankings.
+	Banned             bool   `db:"banned" json:"banned"`
+	HiddenFromRankings bool   `db:"hidden_from_rankings" json:"hidden_from_rankings"`
+	Reason             string `db:"reason" json:"reason"`
+	// ExpiresAt is nil for a decision that stays until it is lifted.
+	ExpiresAt *time.Time `db:"expires_at" json:"expires_at"`
+	// Moderator is the admin actor who last changed the decision.
+	Moderator string    `db:"moderator" json:"moderator"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// Active reports whether the decision is still in effect at now.
+func (m Moderation) Active(now time.Time) bool {
+	return m.ExpiresAt == nil || m.ExpiresAt.After(now)
+}
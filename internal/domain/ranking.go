@@ -0,0 +1,158 @@
+package domain
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Ball and chain ids the ranking columns are derived from.
+const (
+	rainbowChainBallID = "3"
+	goldenPalballID    = "100"
+)
+
+// LatestSaveColumn is a column of v3_user_latest_save_data and how a save fills it.
+type LatestSaveColumn struct {
+	Column string
+	// Source derives the value from the user's latest save, as an int64 or a float64.
+	// sd.LAchieve holds every achievement the user has unlocked (as the client sends it, or as GetSave restores it).
+	Source func(sd *SaveData) any
+}
+
+// baseLatestSaveColumns are the columns of v3_user_latest_save_data that are not leaderboards.
+var baseLatestSaveColumns = []LatestSaveColumn{
+	{Column: "version", Source: func(sd *SaveData) any { return int64(sd.Version) }},
+	{Column: "credit_all", Source: func(sd *SaveData) any { return sd.CreditAll }},
+	{Column: "playtime", Source: func(sd *SaveData) any { return sd.Playtime }},
+	{Column: "hide_record", Source: func(sd *SaveData) any { return int64(sd.HideRecord) }},
+}
+
+// latestSaveColumns are the columns a RankingProjection fills: baseLatestSaveColumns, then the column of each
+// RankedMetrics entry. columnIndex maps a column to its position. Both are built and checked at init.
+var (
+	latestSaveColumns []LatestSaveColumn
+	columnIndex       map[string]int
+)
+
+func init() {
+	latestSaveColumns, columnIndex = buildLatestSaveColumns(baseLatestSaveColumns, RankedMetrics)
+}
+
+// buildLatestSaveColumns lists the base columns and the metric columns, and panics if the registry is inconsistent:
+// a metric or column declared twice, a missing source or tie-breaker, or a source of another type than int64 or float64.
+func buildLatestSaveColumns(base []LatestSaveColumn, metrics []RankedMetric) ([]LatestSaveColumn, map[string]int) {
+	columns := make([]LatestSaveColumn, 0, len(base)+len(metrics))
+	index := make(map[string]int, len(base)+len(metrics))
+	add := func(c LatestSaveColumn) {
+		if c.Column == "" || c.Source == nil {
+			panic(fmt.Sprintf("latest save column %q has no source", c.Column))
+		}
+		if _, ok := index[c.Column]; ok {
+			panic(fmt.Sprintf("latest save column %q is declared twice", c.Column))
+		}
+		switch v := c.Source(&SaveData{}); v.(type) {
+		case int64, float64:
+		default:
+			panic(fmt.Sprintf("latest save column %q: source returns %T, not int64 or float64", c.Column, v))
+		}
+		index[c.Column] = len(columns)
+		columns = append(columns, c)
+	}
+	for _, c := range base {
+		add(c)
+	}
+	names := make(map[string]bool, len(metrics))
+	for _, m := range metrics {
+		if names[m.Name] {
+			panic(fmt.Sprintf("ranked metric %q is declared twice", m.Name))
+		}
+		names[m.Name] = true
+		if m.TieBreaker == "" {
+			panic(fmt.Sprintf("ranked metric %q has no tie-breaker", m.Name))
+		}
+		add(LatestSaveColumn{Column: m.Column, Source: m.Source})
+	}
+	return columns, index
+}
+
+// RankingProjection is what a save contributes to v3_user_latest_save_data, the table behind the rankings
+// and v4 statistics: the value of each RankingColumns column, an int64 or a float64. Ingestion, rollback and
+// rebuild all write ProjectRanking of the user's latest save, so a new ranked metric only needs its RankedMetrics
+// entry (with its source) and a migration adding the column.
+type RankingProjection []any
+
+// ProjectRanking derives the ranking columns of sd. sd.LAchieve must hold every achievement the user has unlocked.
+func ProjectRanking(sd *SaveData) RankingProjection {
+	p := make(RankingProjection, len(latestSaveColumns))
+	for i, c := range latestSaveColumns {
+		p[i] = c.Source(sd)
+	}
+	return p
+}
+
+// RankingColumns returns the columns of v3_user_latest_save_data a RankingProjection fills, in order.
+func RankingColumns() []string {
+	cols := make([]string, len(latestSaveColumns))
+	for i, c := range latestSaveColumns {
+		cols[i] = c.Column
+	}
+	return cols
+}
+
+// Values returns the projection's values in the order of RankingColumns.
+func (p RankingProjection) Values() []any {
+	return p
+}
+
+// Column returns the value of column in the projection. It panics if column is not one of RankingColumns.
+func (p RankingProjection) Column(column string) any {
+	i, ok := columnIndex[column]
+	if !ok {
+		panic(fmt.Sprintf("%q is not a latest save column", column))
+	}
+	return p[i]
+}
+
+// CreditAll is the credit_all of the projection.
+func (p RankingProjection) CreditAll() int64 {
+	return p.Column("credit_all").(int64)
+}
+
+// Hidden reports whether the user hides their record (hide_record).
+func (p RankingProjection) Hidden() bool {
+	return p.Column("hide_record").(int64) != 0
+}
+
+// ScanRankingProjection returns a projection and the destinations to scan the RankingColumns of a row into it.
+func ScanRankingProjection() (RankingProjection, []any) {
+	p := make(RankingProjection, len(latestSaveColumns))
+	dest := make([]any, len(latestSaveColumns))
+	for i, c := range latestSaveColumns {
+		_, float := c.Source(&SaveData{}).(float64)
+		dest[i] = projectionScanner{value: &p[i], float: float}
+	}
+	return p, dest
+}
+
+// projectionScanner scans a column into a projection value of the type its source returns.
+type projectionScanner struct {
+	value *any
+	float bool
+}
+
+func (s projectionScanner) Scan(src any) error {
+	if s.float {
+		var v sql.NullFloat64
+		if err := v.Scan(src); err != nil {
+			return err
+		}
+		*s.value = v.Float64
+		return nil
+	}
+	var v sql.NullInt64
+	if err := v.Scan(src); err != nil {
+		return err
+	}
+	*s.value = v.Int64
+	return nil
+}
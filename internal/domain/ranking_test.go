@@ -0,0 +1,148 @@
+package domain
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestProjectRanking(t *testing.T) {
+	tests := []struct {
+		name string
+		sd   SaveData
+		// want holds the columns that are not zero
+		want map[string]any
+	}{
+		{
+			name: "empty save",
+			sd:   SaveData{},
+		},
+		{
+			name: "copied columns",
+			sd: SaveData{
+				Version: 19, CreditAll: 1234, Playtime: 3600,
+				JackpotSuperStartMax: 1, JackpotFerrettaStartMax: 2, JackpotFerrettaTotalMax: 3, FerrettaLotteryLines: 4,
+				CpMMax: 12.5, JackTotalMaxV2: 5, UltComboMax: 6, UltimateTotalMaxV2: 7, BlackBoxTotal: 8, SpUse: 9, HideRecord: 1,
+			},
+			want: map[string]any{
+				"version": int64(19), "credit_all": int64(1234), "playtime": int64(3600),
+				"jacksp_startmax": int64(1), "jackfr_startmax": int64(2), "jackfr_totalmax": int64(3), "ferlot_lines": int64(4),
+				"cpm_max": 12.5, "jack_totalmax_v2": int64(5), "ult_combomax": int64(6), "ult_totalmax_v2": int64(7),
+				"blackbox_total": int64(8), "sp_use": int64(9), "hide_record": int64(1),
+			},
+		},
+		{
+			name: "achievements are counted",
+			sd:   SaveData{LAchieve: []string{"a", "b", "c"}},
+			want: map[string]any{"achievements_count": int64(3)},
+		},
+		{
+			name: "rainbow chain is ball 3",
+			sd:   SaveData{DCBallChain: map[string]int{"1": 40, "3": 12, "30": 99}},
+			want: map[string]any{"max_chain_rainbow": int64(12)},
+		},
+		{
+			name: "golden palball is ball 100",
+			sd:   SaveData{DCPalettaBallGet: map[string]int{"10": 5, "100": 7}},
+			want: map[string]any{"golden_palball_get": int64(7)},
+		},
+		{
+			name: "other balls do not count",
+			sd:   SaveData{DCBallChain: map[string]int{"2": 8}, DCPalettaBallGet: map[string]int{"1": 3}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ProjectRanking(&tt.sd)
+			for _, col := range RankingColumns() {
+				want, ok := tt.want[col]
+				if !ok {
+					want = reflect.Zero(reflect.TypeOf(got.Column(col))).Interface()
+				}
+				if got.Column(col) != want {
+					t.Errorf("%s: got %#v, want %#v", col, got.Column(col), want)
+				}
+			}
+			if p := ProjectRanking(&tt.sd); p.CreditAll() != tt.sd.CreditAll || p.Hidden() != (tt.sd.HideRecord != 0) {
+				t.Errorf("credit_all %d, hidden %v", p.CreditAll(), p.Hidden())
+			}
+		})
+	}
+}
+
+func TestRankingColumns(t *testing.T) {
+	cols := RankingColumns()
+	seen := make(map[string]bool, len(cols))
+	for i, c := range cols {
+		if c == "" || seen[c] {
+			t.Fatalf("column %d of RankingProjection is missing or declared twice: %q", i, c)
+		}
+		seen[c] = true
+	}
+
+	p := ProjectRanking(&SaveData{CreditAll: 5, HideRecord: 1})
+	values := p.Values()
+	if len(values) != len(cols) {
+		t.Fatalf("got %d values for %d columns", len(values), len(cols))
+	}
+	byColumn := make(map[string]any, len(cols))
+	for i, c := range cols {
+		byColumn[c] = values[i]
+	}
+	if !reflect.DeepEqual(byColumn["credit_all"], int64(5)) || !reflect.DeepEqual(byColumn["hide_record"], int64(1)) {
+		t.Fatalf("values by column: %v", byColumn)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("an unknown column must panic")
+		}
+	}()
+	p.Column("no_such_column")
+}
+
+func TestBuildLatestSaveColumns_RejectsInconsistentRegistries(t *testing.T) {
+	source := func(sd *SaveData) any { return sd.SpUse }
+	tests := map[string]struct {
+		base    []LatestSaveColumn
+		metrics []RankedMetric
+	}{
+		"no source":        {metrics: []RankedMetric{{Name: "a", Column: "a", TieBreaker: tieEarliestFirst}}},
+		"int source":       {base: []LatestSaveColumn{{Column: "a", Source: func(sd *SaveData) any { return sd.HideRecord }}}},
+		"duplicate column": {base: []LatestSaveColumn{{Column: "a", Source: source}}, metrics: []RankedMetric{{Name: "b", Column: "a", Source: source, TieBreaker: tieEarliestFirst}}},
+		"duplicate metric": {metrics: []RankedMetric{{Name: "a", Column: "a", Source: source, TieBreaker: tieEarliestFirst}, {Name: "a", Column: "b", Source: source, TieBreaker: tieEarliestFirst}}},
+		"no tie-breaker":   {metrics: []RankedMetric{{Name: "a", Column: "a", Source: source}}},
+		"unnamed base":     {base: []LatestSaveColumn{{Source: source}}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("expected a panic")
+				}
+			}()
+			buildLatestSaveColumns(tt.base, tt.metrics)
+		})
+	}
+}
+
+func TestScanRankingProjection(t *testing.T) {
+	want := ProjectRanking(&SaveData{CreditAll: 1 << 60, CpMMax: 2.5, SpUse: 3})
+	got, dest := ScanRankingProjection()
+	if len(dest) != len(want) {
+		t.Fatalf("got %d destinations for %d columns", len(dest), len(want))
+	}
+	for i, v := range want {
+		// MariaDB may return numbers as text
+		src := any([]byte(fmt.Sprint(v)))
+		if i%2 == 0 {
+			src = v
+		}
+		if err := dest[i].(interface{ Scan(any) error }).Scan(src); err != nil {
+			t.Fatalf("scan %v: %v", v, err)
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("scanned %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,36 @@
+package domain
+
+import "fmt"
+
+// LatestSaveMismatch is one value of a user's latest-save rows that differs from what their save history derives.
+type LatestSaveMismatch struct {
+	UserID string `json:"user_id"`
+	// Field is a column of v3_user_latest_save_data, "row" when the whole row is missing on one side,
+	// or "achievement:<id>" for a row of v3_user_latest_save_data_achievements.
+	Field    string `json:"field"`
+	Stored   string `json:"stored"`
+	Expected string `json:"expected"`
+}
+
+func (m LatestSaveMismatch) String() string {
+	return fmt.Sprintf("%s %s: stored=%s expected=%s", m.UserID, m.Field, m.Stored, m.Expected)
+}
+
+// RebuildReport summarizes one run over the latest-save rows of many users.
+type RebuildReport struct {
+	VerifyOnly bool
+	// Users is the number of users checked, Mismatched how many of them had at least one mismatch.
+	Users      int
+	Mismatched int
+	// Rebuilt is the number of users whose rows were rewritten; always 0 when only verifying.
+	Rebuilt int
+	// LastUserID is the last user checked, to resume an interrupted run after it.
+	LastUserID string
+}
+
+func (r RebuildReport) String() string {
+	if r.VerifyOnly {
+		return fmt.Sprintf("checked %d users, %d mismatched", r.Users, r.Mismatched)
+	}
+	return fmt.Sprintf("checked %d users, rebuilt %d mismatched", r.Users, r.Rebuilt)
+}
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RetentionPolicy decides which stored saves are kept as a user's history ages.
+// Saves younger than KeepAllDays are all kept; up to DailyDays the newest save of each UTC day is kept,
+// and beyond that the newest save of each ISO week.
+type RetentionPolicy struct {
+	KeepAllDays int
+	DailyDays   int
+}
+
+// Validate reports a policy whose tiers are empty or out of order.
+func (p RetentionPolicy) Validate() error {
+	if p.KeepAllDays < 1 {
+		return errors.New("keep-all days must be at least 1")
+	}
+	if p.DailyDays < p.KeepAllDays {
+		return fmt.Errorf("daily days (%d) must not be less than keep-all days (%d)", p.DailyDays, p.KeepAllDays)
+	}
+	return nil
+}
+
+// Cutoff is the time before which saves are thinned; younger saves are never candidates.
+func (p RetentionPolicy) Cutoff(now time.Time) time.Time {
+	return now.AddDate(0, 0, -p.KeepAllDays)
+}
+
+// RetentionCandidate is a stored save old enough to be thinned.
+type RetentionCandidate struct {
+	ID        int64     `db:"id"`
+	CreatedAt time.Time `db:"created_at"`
+	// Protected saves are never removed: the user's latest save, saves that unlocked or revoked achievements
+	// (the only record of when each achievement was unlocked) and saves with an anomaly flag.
+	Protected bool `db:"protected"`
+}
+
+// Prunable returns the ids of the saves of one user that the policy removes, in the order given.
+// Within each day or week the newest save survives, in addition to every protected save.
+func (p RetentionPolicy) Prunable(saves []RetentionCandidate, now time.Time) []int64 {
+	cutoff := p.Cutoff(now)
+	weeklyCutoff := now.AddDate(0, 0, -p.DailyDays)
+
+	bucket := func(t time.Time) string {
+		t = t.UTC()
+		if t.Before(weeklyCutoff) {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("w%d-%02d", year, week)
+		}
+		return t.Format("d2006-01-02")
+	}
+
+	// the newest save of each bucket
+	newest := make(map[string]RetentionCandidate)
+	for _, s := range saves {
+		if !s.CreatedAt.Before(cutoff) {
+			continue
+		}
+		key := bucket(s.CreatedAt)
+		if cur, ok := newest[key]; !ok || s.CreatedAt.After(cur.CreatedAt) || s.CreatedAt.Equal(cur.CreatedAt) && s.ID > cur.ID {
+			newest[key] = s
+		}
+	}
+
+	var ids []int64
+	for _, s := range saves {
+		if s.Protected || !s.CreatedAt.Before(cutoff) {
+			continue
+		}
+		if newest[bucket(s.CreatedAt)].ID == s.ID {
+			continue
+		}
+		ids = append(ids, s.ID)
+	}
+	return ids
+}
+
+// RetentionReport summarizes one retention run.
+type RetentionReport struct {
+	DryRun bool
+	// Users is the number of users with saves older than the keep-all window.
+	Users int
+	// Examined is the number of such saves, Protected how many of them can never be removed.
+	Examined  int
+	Protected int
+	// Removed is the number of saves deleted, or that would be deleted in a dry run.
+	Removed int64
+}
+
+func (r RetentionReport) String() string {
+	verb := "removed"
+	if r.DryRun {
+		verb = "would remove"
+	}
+	return fmt.Sprintf("%s %d of %d old saves from %d users (%d protected)", verb, r.Removed, r.Examined, r.Users, r.Protected)
+}
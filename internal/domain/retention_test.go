@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRetentionPolicy_Prunable(t *testing.T) {
+	now := time.Date(2026, 6, 30, 12, 0, 0, 0, time.UTC)
+	policy := RetentionPolicy{KeepAllDays: 7, DailyDays: 30}
+	daysAgo := func(days, hour int) time.Time {
+		return now.AddDate(0, 0, -days).Truncate(24 * time.Hour).Add(time.Duration(hour) * time.Hour)
+	}
+
+	saves := []RetentionCandidate{
+		// within the keep-all window: all kept
+		{ID: 1, CreatedAt: daysAgo(2, 1)},
+		{ID: 2, CreatedAt: daysAgo(2, 3)},
+		// one day in the daily tier: the newest (3) survives
+		{ID: 3, CreatedAt: daysAgo(10, 20)},
+		{ID: 4, CreatedAt: daysAgo(10, 8)},
+		{ID: 5, CreatedAt: daysAgo(10, 9), Protected: true},
+		// another day: a single save survives
+		{ID: 6, CreatedAt: daysAgo(11, 9)},
+		// the same ISO week (Mon 2026-05-04 .. Sun 2026-05-10) in the weekly tier: only 9 survives
+		{ID: 7, CreatedAt: time.Date(2026, 5, 4, 1, 0, 0, 0, time.UTC)},
+		{ID: 8, CreatedAt: time.Date(2026, 5, 6, 1, 0, 0, 0, time.UTC)},
+		{ID: 9, CreatedAt: time.Date(2026, 5, 10, 23, 0, 0, 0, time.UTC)},
+		// the following Monday starts a new week
+		{ID: 10, CreatedAt: time.Date(2026, 5, 11, 0, 0, 0, 0, time.UTC)},
+	}
+
+	got := policy.Prunable(saves, now)
+	if want := []int64{4, 7, 8}; !slices.Equal(got, want) {
+		t.Fatalf("Prunable = %v, want %v", got, want)
+	}
+
+	// pruning is idempotent: the survivors are stable
+	var kept []RetentionCandidate
+	for _, s := range saves {
+		if !slices.Contains(got, s.ID) {
+			kept = append(kept, s)
+		}
+	}
+	if again := policy.Prunable(kept, now); len(again) != 0 {
+		t.Fatalf("second run removes %v", again)
+	}
+}
+
+func TestRetentionPolicy_Validate(t *testing.T) {
+	for _, p := range []RetentionPolicy{{KeepAllDays: 0, DailyDays: 10}, {KeepAllDays: 30, DailyDays: 7}} {
+		if err := p.Validate(); err == nil {
+			t.Errorf("%+v: expected an error", p)
+		}
+	}
+	if err := (RetentionPolicy{KeepAllDays: 30, DailyDays: 30}).Validate(); err != nil {
+		t.Errorf("equal tiers: %v", err)
+	}
+}
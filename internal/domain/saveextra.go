@@ -0,0 +1,129 @@
+package domain
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// SaveExtraFields are the top-level keys of a save the server does not know (the unknown_key warnings),
+// kept verbatim so that the client loads them back and a later server can promote them to columns.
+// They are stored as a JSON object in v2_save_data.extra_fields; none is stored as NULL.
+type SaveExtraFields map[string]json.RawMessage
+
+// Scan reads the extra_fields column. Unmarshalling copies the values, so nothing aliases the driver's buffer.
+func (f *SaveExtraFields) Scan(src any) error {
+	var b []byte
+	switch v := src.(type) {
+	case nil:
+		*f = nil
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("scan extra fields: unsupported type %T", src)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		return fmt.Errorf("scan extra fields: %w", err)
+	}
+	if len(fields) == 0 {
+		fields = nil
+	}
+	*f = fields
+	return nil
+}
+
+// Value writes the extra_fields column.
+func (f SaveExtraFields) Value() (driver.Value, error) {
+	if len(f) == 0 {
+		return nil, nil
+	}
+	b, err := json.Marshal(map[string]json.RawMessage(f))
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// extraFields picks the unknown keys of a save out of its top-level fields.
+func extraFields(fields map[string]json.RawMessage, warnings []SaveWarning) SaveExtraFields {
+	var extra SaveExtraFields
+	for _, w := range warnings {
+		if w.Kind != WarningUnknownKey {
+			continue
+		}
+		if extra == nil {
+			extra = make(SaveExtraFields)
+		}
+		extra[w.Key] = fields[w.Key]
+	}
+	return extra
+}
+
+// modelExtraFields are the extra fields ToModel returns to the client. This includes a key the server has learnt
+// since the save was stored: until promote-save-field moves it into its column (and out of the extra fields),
+// the column is empty and the extra field, which SaveDataV2 marshals over it, is the value the client sent.
+func (sd *SaveData) modelExtraFields() map[string]interface{} {
+	if len(sd.ExtraFields) == 0 {
+		return nil
+	}
+	extra := make(map[string]interface{}, len(sd.ExtraFields))
+	for key, value := range sd.ExtraFields {
+		extra[key] = value
+	}
+	return extra
+}
+
+// PromotedValue converts an extra field to the value promote-save-field stores in a column: numbers and strings
+// as text the database converts to the column's type, booleans as 0 or 1, objects and arrays as compact JSON.
+// ok is false for null, which leaves the column as it is.
+func PromotedValue(raw json.RawMessage) (value any, ok bool, err error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, false, err
+	}
+	switch v := v.(type) {
+	case nil:
+		return nil, false, nil
+	case json.Number:
+		return v.String(), true, nil
+	case string:
+		return v, true, nil
+	case bool:
+		if v {
+			return 1, true, nil
+		}
+		return 0, true, nil
+	default:
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, raw); err != nil {
+			return nil, false, err
+		}
+		return compact.String(), true, nil
+	}
+}
+
+// SaveFieldPromotion summarizes one run moving an extra field of the stored saves into a column.
+type SaveFieldPromotion struct {
+	DryRun bool
+	Key    string
+	Column string
+	// Promoted is the number of saves whose value was moved, or would be in a dry run.
+	Promoted int64
+	// Dropped is the number of saves whose value was null: the key is removed and the column left as it is.
+	Dropped int64
+}
+
+func (p SaveFieldPromotion) String() string {
+	verb := "promoted"
+	if p.DryRun {
+		verb = "would promote"
+	}
+	return fmt.Sprintf("%s %q to column %s in %d saves (%d null values dropped)", verb, p.Key, p.Column, p.Promoted, p.Dropped)
+}
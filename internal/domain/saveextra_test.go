@@ -0,0 +1,95 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseSaveData_KeepsExtraFields(t *testing.T) {
+	raw := base64.RawURLEncoding.EncodeToString([]byte(`{"version":22,"playtime":5,"chg_ball":3,` +
+		`"zz_big":12345678901234567890,"zz_obj":{"a": [1, "x"]},"zz_null":null}`))
+	sd, _, err := ParseSaveData(raw, SchemaLenient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := SaveExtraFields{
+		"zz_big":  json.RawMessage(`12345678901234567890`),
+		"zz_obj":  json.RawMessage(`{"a": [1, "x"]}`),
+		"zz_null": json.RawMessage(`null`),
+	}
+	if !reflect.DeepEqual(sd.ExtraFields, want) {
+		t.Fatalf("extra fields: %s", sd.ExtraFields)
+	}
+
+	payload, err := json.Marshal(sd.ToModel())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	if string(loaded["zz_big"]) != `12345678901234567890` || string(loaded["zz_obj"]) != `{"a":[1,"x"]}` || string(loaded["zz_null"]) != `null` {
+		t.Fatalf("the load does not return the extra fields: %s", payload)
+	}
+	if _, ok := loaded["chg_ball"]; ok {
+		t.Fatalf("temporary keys are not kept: %s", payload)
+	}
+
+	// a key the server has learnt since is returned from the extra fields until it is promoted to its column
+	sd.ExtraFields["sp_use"] = json.RawMessage(`99`)
+	if got := loadedKey(t, sd, "sp_use"); got != `99` {
+		t.Fatalf("an unpromoted known key: sp_use %s", got)
+	}
+	delete(sd.ExtraFields, "sp_use")
+	sd.SpUse = 99
+	if got := loadedKey(t, sd, "sp_use"); got != `99` {
+		t.Fatalf("a promoted key: sp_use %s", got)
+	}
+}
+
+// loadedKey returns key of the save as the load returns it.
+func loadedKey(t *testing.T, sd *SaveData, key string) string {
+	t.Helper()
+	payload, err := json.Marshal(sd.ToModel())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &loaded); err != nil {
+		t.Fatal(err)
+	}
+	return string(loaded[key])
+}
+
+func TestSaveExtraFields_ScanValue(t *testing.T) {
+	fields := SaveExtraFields{"zz_a": json.RawMessage(`1`), "zz_b": json.RawMessage(`"x"`)}
+	v, err := fields.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := []byte(v.(string))
+
+	var got SaveExtraFields
+	if err := got.Scan(buf); err != nil {
+		t.Fatal(err)
+	}
+	copy(buf, make([]byte, len(buf)))
+	if !reflect.DeepEqual(got, fields) {
+		t.Fatalf("scan must not alias the driver's buffer: got %s", got)
+	}
+
+	for _, src := range []any{nil, "{}"} {
+		if err := got.Scan(src); err != nil || got != nil {
+			t.Fatalf("%v: got %v, %v", src, got, err)
+		}
+	}
+	if v, err := SaveExtraFields(nil).Value(); v != nil || err != nil {
+		t.Fatalf("no extra fields are stored as NULL: %v, %v", v, err)
+	}
+	if err := got.Scan(42); err == nil {
+		t.Fatalf("unsupported source types are an error")
+	}
+}
@@ -0,0 +1,113 @@
+// Code generated by go run ./tools/savekeys; DO NOT EDIT.
+// Source: ClientJsonKeyDefines.cs
+
+package domain
+
+// SaveKeys lists the JSON keys of ClientJsonKeyDefines.cs in file order.
+var SaveKeys = []SaveKey{
+	{Const: "Legacy", Key: "legacy", Summary: "レガシーフラグ (古いデータから移行したものか)", JSONType: "Double", GameType: "Int"},
+	{Const: "Version", Key: "version", Summary: "セーブデータバージョン", JSONType: "Double", GameType: "Int"},
+	{Const: "PlayTime", Key: "playtime", Summary: "プレイ時間総計", JSONType: "Double", GameType: "Long"},
+	{Const: "Credit", Key: "credit", Summary: "所持クレジット", JSONType: "String", GameType: "Long"},
+	{Const: "CreditTotal", Key: "credit_all", Summary: "総獲得クレジット", JSONType: "String", GameType: "Long"},
+	{Const: "MedalInsert", Key: "medal_in", Summary: "投入したメダルの数", JSONType: "Double", GameType: "Int"},
+	{Const: "MedalGet", Key: "medal_get", Summary: "獲得したメダルの数", JSONType: "Double", GameType: "Long", Since: 5},
+	{Const: "BallGet", Key: "ball_get", Summary: "シャルベボール獲得回数", JSONType: "Double", GameType: "Long"},
+	{Const: "BallChainMax", Key: "ball_chain", Summary: "シャルベボール最大チェイン数", JSONType: "Double", GameType: "Int"},
+	{Const: "SlotStart", Key: "slot_start", Summary: "ルーレット始動回数", JSONType: "Double", GameType: "Long"},
+	{Const: "SlotStartFever", Key: "slot_startfev", Summary: "フィーバー付きルーレット始動回数", JSONType: "Double", GameType: "Long", Since: 5},
+	{Const: "SlotHit", Key: "slot_hit", Summary: "ルーレット勝利回数", JSONType: "Double", GameType: "Long"},
+	{Const: "SlotFever", Key: "slot_getfev", Summary: "ルーレットフィーバー開始数", JSONType: "Double", GameType: "Long"},
+	{Const: "SugorokuGet", Key: "sqr_get", Summary: "すごろくを進行させた回数", JSONType: "Double", GameType: "Long", Since: 5},
+	{Const: "SugorokuStep", Key: "sqr_step", Summary: "すごろくで進んだマス数", JSONType: "Double", GameType: "Long"},
+	{Const: "JackpotGet", Key: "jack_get", Summary: "ジャックポット獲得回数", JSONType: "Double", GameType: "Long"},
+	{Const: "JackpotStartMax", Key: "jack_startmax", Summary: "ジャックポット最大スタート値", JSONType: "Double", GameType: "Long"},
+	{Const: "JackpotTotalMax", Key: "jack_totalmax", Summary: "ジャックポット最大値 (v1)", JSONType: "Double", GameType: "Int", Since: 5},
+	{Const: "UltimateGet", Key: "ult_get", Summary: "ULTIMATE MODE 発生回数", JSONType: "Double", GameType: "Int", Since: 5},
+	{Const: "UltimateComboMax", Key: "ult_combomax", Summary: "ULTIMATE MODE 最大コンボ回数", JSONType: "Double", GameType: "Int", Since: 5},
+	{Const: "UltimateTotalMax", Key: "ult_totalmax", Summary: "ULTIMATE MODE 最終結果最大値 (v1)", JSONType: "Double", GameType: "Int", Since: 5},
+	{Const: "RoomkeeperGet", Key: "rmshbi_get", Summary: "お部屋シャルベ獲得回数", JSONType: "Double", GameType: "Int", Since: 5},
+	{Const: "BonusStepStep", Key: "bstp_step", Summary: "ボーナスステップ進行回数", JSONType: "Double", GameType: "Long", Since: 5},
+	{Const: "BonusStepReward", Key: "bstp_rwd", Summary: "ボーナスステップリワード回数", JSONType: "Double", GameType: "Long", Since: 5},
+	{Const: "BuyTotalCount", Key: "buy_total", Summary: "ショップの購入ボタンを押した回数", JSONType: "Double", GameType: "Int", Since: 7},
+	{Const: "BuySherbi", Key: "buy_shbi", Summary: "ショップでシャルベを購入した回数", JSONType: "Double", GameType: "Int"},
+	{Const: "SkillPointUsed", Key: "sp_use", Summary: "スキルポイント消費数", JSONType: "Double", GameType: "Long", Since: 6},
+	{Const: "StrDateFirstBoot", Key: "firstboot", Summary: "セーブデータの作成日時 (Long で持ちたいので文字列) `DateTimeOffset.Now.ToUnixTimeSeconds().ToString()` で保存", JSONType: "String", GameType: "Long", Since: 5},
+	{Const: "StrDateLastSave", Key: "lastsave", Summary: "最終セーブ日時 (Long で持ちたいので文字列) `DateTimeOffset.Now.ToUnixTimeSeconds().ToString()` で保存", JSONType: "String", GameType: "Long", Since: 5},
+	{Const: "DictMedalGet", Key: "dc_medal_get", Summary: "[DataDictionary] 各メダル ID ごとの獲得数", JSONType: "Dictionary", GameType: "Dictionary", Since: 5},
+	{Const: "DictBallGet", Key: "dc_ball_get", Summary: "[DataDictionary] 各シャルベボール ID ごとの獲得数", JSONType: "Dictionary", GameType: "Dictionary", Since: 5},
+	{Const: "DictBallChain", Key: "dc_ball_chain", Summary: "[DataDictionary] 各シャルベボール ID ごとの最大チェイン数", JSONType: "Dictionary", GameType: "Dictionary", Since: 5},
+	{Const: "ListAchieveUnlock", Key: "l_achieve", Summary: "[DataList] 解除したアチーブメント ID のリスト", JSONType: "List", GameType: "List", Since: 5},
+	{Const: "HideRecord", Key: "hide_record", Summary: "[int] レコード非公開フラグ", JSONType: "Int", GameType: "Int", Since: 9},
+	{Const: "CpMMax", Key: "cpm_max", Summary: "[double] 記録された最大 CPM (毎分シャルベクレジット)", JSONType: "Double", GameType: "Double", Since: 9},
+	{Const: "JackpotTotalMaxV2", Key: "jack_totalmax_v2", Summary: "ジャックポット最大値 (v2)", JSONType: "Double", GameType: "Long", Since: 10},
+	{Const: "UltimateTotalMaxV2", Key: "ult_totalmax_v2", Summary: "ULTIMATE MODE 最終結果最大値 (v2)", JSONType: "Double", GameType: "Long", Since: 10},
+	{Const: "PalettaBallGet", Key: "palball_get", Summary: "パレッタボール獲得回数", JSONType: "Double", GameType: "Int", Since: 10},
+	{Const: "PalettaLotteryAttemptTier0", Key: "pallot_lot_t0", Summary: "パレッタ抽選機 Tier 0 挑戦回数", JSONType: "Double", GameType: "Int", Since: 10},
+	{Const: "PalettaLotteryAttemptTier1", Key: "pallot_lot_t1", Summary: "パレッタ抽選機 Tier 1 挑戦回数", JSONType: "Double", GameType: "Int", Since: 10},
+	{Const: "PalettaLotteryAttemptTier2", Key: "pallot_lot_t2", Summary: "パレッタ抽選機 Tier 2 挑戦回数", JSONType: "Double", GameType: "Int", Since: 10},
+	{Const: "PalettaLotteryAttemptTier3", Key: "pallot_lot_t3", Summary: "パレッタ抽選機 Tier 3 挑戦回数", JSONType: "Double", GameType: "Int", Since: 10},
+	{Const: "JackpotSuperGetTotal", Key: "jacksp_get_all", Summary: "スーパージャックポット (All Tier) 獲得回数", JSONType: "Double", GameType: "Int", Since: 10},
+	{Const: "JackpotSuperGetTier0", Key: "jacksp_get_t0", Summary: "スーパージャックポット (Tier 0) 獲得回数", JSONType: "Double", GameType: "Int", Since: 10},
+	{Const: "JackpotSuperGetTier1", Key: "jacksp_get_t1", Summary: "スーパージャックポット (Tier 1) 獲得回数", JSONType: "Double", GameType: "Int", Since: 10},
+	{Const: "JackpotSuperGetTier2", Key: "jacksp_get_t2", Summary: "スーパージャックポット (Tier 2) 獲得回数", JSONType: "Double", GameType: "Int", Since: 10},
+	{Const: "JackpotSuperGetTier3", Key: "jacksp_get_t3", Summary: "スーパージャックポット (Tier 3) 獲得回数", JSONType: "Double", GameType: "Int", Since: 10},
+	{Const: "JackpotSuperStartMax", Key: "jacksp_startmax", Summary: "スーパージャックポット最大スタート値", JSONType: "Double", GameType: "Long", Since: 10},
+	{Const: "JackpotSuperTotalMax", Key: "jacksp_totalmax", Summary: "スーパージャックポット最大値", JSONType: "Double", GameType: "Long", Since: 10},
+	{Const: "TaskCompleteCount", Key: "task_cnt", Summary: "タスク完了回数", JSONType: "Double", GameType: "Int", Since: 10},
+	{Const: "DictPalettaBallGet", Key: "dc_palball_get", Summary: "[DataDictionary] 各パレッタボール ID ごとの獲得数", JSONType: "Dictionary", GameType: "Dictionary (Int)", Since: 10},
+	{Const: "DictPalettaBallJackpot", Key: "dc_palball_jp", Summary: "[DataDictionary] 各パレッタボール ID ごとのJACKPOT獲得数", JSONType: "Dictionary", GameType: "Dictionary (Int)", Since: 10},
+	{Const: "ListPerkLevels", Key: "l_perks", Summary: "[DataList] パークレベル", JSONType: "List", GameType: "List (Int)", Since: 10},
+	{Const: "ListPerkUsedCredits", Key: "l_perks_credit", Summary: "[DataList] パークごとの消費したクレジット数", JSONType: "List", GameType: "List (Long)", Since: 10},
+	{Const: "TotemAltarUnlockCount", Key: "totem_altars", Summary: "[DataList] 解放済みの台座の数", JSONType: "Double", GameType: "Int", Since: 12},
+	{Const: "TotemAltarUnlockUsedCredits", Key: "totem_altars_credit", Summary: "[DataList] 台座解放に消費したクレジット (ロード時の整合性確認用)", JSONType: "Double", GameType: "Long", Since: 12},
+	{Const: "ListTotemLevels", Key: "l_totems", Summary: "[DataList] トーテムレベル", JSONType: "List", GameType: "List (Int)", Since: 12},
+	{Const: "ListTotemUsedCredits", Key: "l_totems_credit", Summary: "[DataList] トーテムごとの消費したクレジット数", JSONType: "List", GameType: "List (Long)", Since: 12},
+	{Const: "ListTotemPlacements", Key: "l_totems_set", Summary: "[DataList] セット中のトーテムIDのリスト", JSONType: "List", GameType: "List (int)", Since: 12},
+	{Const: "SkillPoint", Key: "sp", Summary: "スキルポイント", JSONType: "Double", GameType: "Long", Since: 14},
+	{Const: "BlackBox", Key: "bbox", Summary: "BlackBox（仮名）所持数", JSONType: "Double", GameType: "Int", Since: 14},
+	{Const: "BlackBoxTotal", Key: "bbox_all", Summary: "BlackBox（仮名）総獲得数", JSONType: "Double", GameType: "Int", Since: 14},
+	{Const: "PalettaLotteryAttemptTier4", Key: "pallot_lot_t4", Summary: "パレッタ抽選機 Tier 4 挑戦回数", JSONType: "Double", GameType: "Int", Since: 10},
+	{Const: "JackpotSuperGetTier4", Key: "jacksp_get_t4", Summary: "スーパージャックポット (Tier 4) 獲得回数", JSONType: "Double", GameType: "Int", Since: 16},
+	{Const: "FerrettaBallGet", Key: "ferball_get", Summary: "フェレッタボール獲得回数", JSONType: "Double", GameType: "Int", Since: 19},
+	{Const: "FerrettaLotteryAttempt", Key: "ferlot_lot", Summary: "フェレッタ抽選機挑戦回数", JSONType: "Double", GameType: "Int", Since: 19},
+	{Const: "JackpotFerrettaGetTotal", Key: "jackfr_get_all", Summary: "フェレッタジャックポット獲得数 (任意) 獲得回数", JSONType: "Double", GameType: "Int", Since: 19},
+	{Const: "JackpotFerrettaGetTier0", Key: "jackfr_get_t0", Summary: "フェレッタジャックポット獲得数 (シングル) 獲得回数", JSONType: "Double", GameType: "Int", Since: 19},
+	{Const: "JackpotFerrettaGetTier1", Key: "jackfr_get_t1", Summary: "フェレッタジャックポット獲得数 (ダブル) 獲得回数", JSONType: "Double", GameType: "Int", Since: 19},
+	{Const: "JackpotFerrettaGetTier2", Key: "jackfr_get_t2", Summary: "フェレッタジャックポット獲得数 (マッシブ) 獲得回数", JSONType: "Double", GameType: "Int", Since: 19},
+	{Const: "JackpotFerrettaGetTier3", Key: "jackfr_get_t3", Summary: "フェレッタジャックポット獲得数 (ヘブン) 獲得回数", JSONType: "Double", GameType: "Int", Since: 19},
+	{Const: "JackpotFerrettaGetTier4", Key: "jackfr_get_t4", Summary: "フェレッタジャックポット獲得数 (ギャラクシー) 獲得回数", JSONType: "Double", GameType: "Int", Since: 19},
+	{Const: "JackpotFerrettaStartMax", Key: "jackfr_startmax", Summary: "フェレッタジャックポット最大スタート値", JSONType: "Double", GameType: "Long", Since: 19},
+	{Const: "JackpotFerrettaTotalMax", Key: "jackfr_totalmax", Summary: "フェレッタジャックポット最終結果最大値", JSONType: "Double", GameType: "Long", Since: 19},
+	{Const: "FerrettaLotteryHit", Key: "ferlot_hit", Summary: "フェレッタチャンス結果が HIT になった回数 (アイテム効果は含まない)", JSONType: "Double", GameType: "Int", Since: 19},
+	{Const: "FerrettaLotteryLose", Key: "ferlot_lose", Summary: "フェレッタチャンス結果が LOSE になった回数 (アイテム効果は含まない)", JSONType: "Double", GameType: "Int", Since: 19},
+	{Const: "FerrettaLotteryChance", Key: "ferlot_chance", Summary: "フェレッタチャンス結果が CHANCE になった回数 (アイテム効果は含まない)", JSONType: "Double", GameType: "Int", Since: 19},
+	{Const: "FerrettaLotteryActives", Key: "ferlot_act", Summary: "フェレッタチャンスで獲得したマス数の合計 (アイテム含む、保証埋めしたマスは含まない)", JSONType: "Double", GameType: "Int", Since: 19},
+	{Const: "FerrettaLotteryLines", Key: "ferlot_lines", Summary: "フェレッタチャンスで獲得したライン数の合計", JSONType: "Double", GameType: "Int", Since: 19},
+	{Const: "BlackBoxShopUsed", Key: "bbox_shop", Summary: "黒箱ショップ合計利用回数", JSONType: "Double", GameType: "Int", Since: 19},
+	{Const: "DictBlackBoxShopUsed", Key: "dc_bbox_shop", Summary: "黒箱ショップ利用回数の Dictionary (キーはアイテム ID)", JSONType: "Dictionary", GameType: "Dictionary (string key, double value)", Since: 19},
+	{Const: "DictFerrettaLotteryItem", Key: "dc_ferlot_item", Summary: "フェレッタチャンス抽選で獲得したアイテムの Dictionary (キーはアイテム ID)", JSONType: "Dictionary", GameType: "Dictionary (string key, double value)", Since: 19},
+	{Const: "TempSkillPoint", Key: "sp", Summary: "スキルポイント", Temp: true, Obsolete: "Moved to JsonKeyDefines.SkillPoint"},
+	{Const: "TempChargeMedalSkill", Key: "chg_mdl_sp", Summary: "スキルメダル出現カウンター", Temp: true},
+	{Const: "TempChargeItemBall", Key: "chg_ball", Summary: "ボール出現カウンター", Temp: true},
+	{Const: "TempChargeUltimate", Key: "chg_ult", Summary: "Ultimate 発生カウンター", Temp: true},
+	{Const: "TempRouletteBonusStep", Key: "slot_bstp", Summary: "ルーレットのボーナスステップ現在値", Temp: true},
+	{Const: "TempSugorokuLoop", Key: "sqr_loop", Summary: "すごろくの現在周回数", Temp: true},
+	{Const: "TempSugorokuCream", Key: "sqr_cream", Summary: "すごろくのクリームストック数", Temp: true},
+	{Const: "TempSpChusenMedalInsert", Key: "pallot_mdl", Summary: "パレッタ抽選機メダル投入カウンター値", Temp: true},
+	{Const: "TempSpChusenGoldenProbBonus", Key: "pallot_gpb", Summary: "パレッタ抽選機ゴールデンボール率ボーナス値", Temp: true},
+	{Const: "TempSpChusenProgCounterTier0", Key: "pallot_pc0", Summary: "パレッタ抽選機プログレッシブカウンター Tier 0 上昇値", Temp: true},
+	{Const: "TempSpChusenProgCounterTier1", Key: "pallot_pc1", Summary: "パレッタ抽選機プログレッシブカウンター Tier 1 上昇値", Temp: true},
+	{Const: "TempSpChusenProgCounterTier2", Key: "pallot_pc2", Summary: "パレッタ抽選機プログレッシブカウンター Tier 2 上昇値", Temp: true},
+	{Const: "TempSpChusenProgCounterTier3", Key: "pallot_pc3", Summary: "パレッタ抽選機プログレッシブカウンター Tier 3 上昇値", Temp: true},
+	{Const: "TempSpChusenProgCounterTier4", Key: "pallot_pc4", Summary: "パレッタ抽選機プログレッシブカウンター Tier 4 上昇値", Temp: true},
+	{Const: "TempBlackBoxCredits", Key: "blackbox_credits", Summary: "BlackBox（仮名）所有数", Temp: true, Obsolete: "Moved to JsonKeyDefines.BlackBox"},
+	{Const: "TempGetBlackBox", Key: "blackbox_credits", Summary: "BlackBox（仮名）獲得数", Temp: true, Obsolete: "Moved to JsonKeyDefines.BlackBox"},
+	{Const: "TempTaskDay", Key: "task_day", Summary: "デイリータスクの日付情報", Temp: true},
+	{Const: "TempTaskList", Key: "task_list", Summary: "デイリータスクのリスト", Temp: true},
+	{Const: "TempFerrettaBingoState", Key: "ferlot_bingo", Summary: "フェレッタチャンスのビンゴ状態", Temp: true},
+	{Const: "TempFerrettaProgCounter", Key: "ferlot_pc", Summary: "フェレッタチャンスのプログレッシブカウンター上昇値", Temp: true},
+	{Const: "TempFerrettaSummonCount", Key: "ferlot_nx", Summary: "フェレッタボール出現カウンター", Temp: true},
+	{Const: "TempIsItemFromLottery", Key: "ferlot_item_ch", Summary: "フェレッタアイテムが抽選経由で獲得できているかのフラグ", Temp: true},
+	{Const: "TempFerrettaBingoItemState", Key: "ferlot_item", Summary: "フェレッタアイテム状態", Temp: true},
+}
@@ -0,0 +1,25 @@
+package domain
+
+//go:generate go run ../../tools/savekeys -in ../../ClientJsonKeyDefines.cs -out savekeys.gen.go
+
+// SaveKey is a save JSON key as the client defines it in ClientJsonKeyDefines.cs.
+type SaveKey struct {
+	Const    string // name of the C# constant
+	Key      string // JSON key
+	Summary  string
+	JSONType string // type in the JSON: Double, Int, String, List or Dictionary
+	GameType string // type in the game, with the element type of lists and dictionaries
+	Since    int    // save version that added the key; 0 for keys older than version 5
+	Temp     bool   // in the temporary-save region: in-game state the server does not store
+	Obsolete string // message of the constant's Obsolete attribute
+}
+
+// LookupSaveKey returns the definition of a stored (non-Temp) save key.
+func LookupSaveKey(key string) (SaveKey, bool) {
+	for _, k := range SaveKeys {
+		if k.Key == key && !k.Temp {
+			return k, true
+		}
+	}
+	return SaveKey{}, false
+}
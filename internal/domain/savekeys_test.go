@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/openapi/models"
+)
+
+func jsonKeys(v any) []string {
+	var keys []string
+	t := reflect.TypeOf(v)
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			keys = append(keys, name)
+		}
+	}
+	return keys
+}
+
+func TestSaveKeysAreParsed(t *testing.T) {
+	parsed := jsonKeys(savePayload{})
+	for _, k := range SaveKeys {
+		if !k.Temp && !slices.Contains(parsed, k.Key) {
+			t.Errorf("client key %q (%s, save version %d, JSON %s) is not read by ParseSaveData", k.Key, k.Const, k.Since, k.JSONType)
+		}
+	}
+}
+
+func TestSaveKeysAreInOpenAPI(t *testing.T) {
+	model := jsonKeys(models.SaveDataV2{})
+	for _, k := range SaveKeys {
+		if !k.Temp && !slices.Contains(model, k.Key) {
+			t.Errorf("client key %q (%s) is missing from SaveDataV2 in openapi.yaml", k.Key, k.Const)
+		}
+	}
+}
+
+func TestParsedKeysAreDefined(t *testing.T) {
+	for _, key := range jsonKeys(savePayload{}) {
+		if _, ok := LookupSaveKey(key); !ok && !slices.Contains(unlistedSaveKeys, key) {
+			t.Errorf("ParseSaveData reads %q, which is neither in ClientJsonKeyDefines.cs nor in unlistedSaveKeys", key)
+		}
+	}
+}
+
+func TestLookupSaveKey(t *testing.T) {
+	// sp is defined twice: as the stored skill points and as an obsolete temporary key.
+	k, ok := LookupSaveKey("sp")
+	if !ok || k.Const != "SkillPoint" || k.Since != 14 {
+		t.Fatalf("sp: got %+v, %v", k, ok)
+	}
+	if _, ok := LookupSaveKey("chg_ball"); ok {
+		t.Fatalf("temporary keys are not stored save keys")
+	}
+}
@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SaveSchemaMode says how ParseSaveData treats a save that does not match the schema of its declared version.
+// The schema is SaveKeys: every stored key is required from the save version that added it onward.
+type SaveSchemaMode string
+
+const (
+	// SchemaLenient accepts every save that decodes and reports the mismatches as warnings.
+	SchemaLenient SaveSchemaMode = "lenient"
+	// SchemaStrict also rejects saves missing a key of their version or holding a key added after it.
+	// Unknown keys are still only warnings, since a client newer than the server sends them.
+	SchemaStrict SaveSchemaMode = "strict"
+)
+
+// ParseSaveSchemaMode parses the name of a SaveSchemaMode.
+func ParseSaveSchemaMode(s string) (SaveSchemaMode, error) {
+	switch m := SaveSchemaMode(strings.ToLower(strings.TrimSpace(s))); m {
+	case SchemaLenient, SchemaStrict:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown save schema mode %q (want lenient or strict)", s)
+}
+
+// SaveWarningKind is the way a save departs from the schema of its version.
+type SaveWarningKind string
+
+const (
+	// WarningUnknownKey is a key neither ClientJsonKeyDefines.cs nor the server knows. Its value is kept in ExtraFields.
+	WarningUnknownKey SaveWarningKind = "unknown_key"
+	// WarningMissingKey is a key the declared version has but the save lacks. It is stored as zero.
+	WarningMissingKey SaveWarningKind = "missing_key"
+	// WarningNewerKey is a key added in a save version after the declared one.
+	WarningNewerKey SaveWarningKind = "newer_key"
+)
+
+// SaveWarning is one departure of a save from its schema.
+type SaveWarning struct {
+	Kind SaveWarningKind `json:"kind"`
+	Key  string          `json:"key"`
+}
+
+func (w SaveWarning) String() string {
+	return string(w.Kind) + ":" + w.Key
+}
+
+// SaveSchemaError rejects a save in SchemaStrict mode. It wraps ErrInvalidSaveData.
+type SaveSchemaError struct {
+	Version    int
+	Violations []SaveWarning
+}
+
+func (e *SaveSchemaError) Error() string {
+	violations := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		violations[i] = v.String()
+	}
+	return fmt.Sprintf("%v: save version %d: %s", ErrInvalidSaveData, e.Version, strings.Join(violations, ", "))
+}
+
+func (e *SaveSchemaError) Unwrap() error {
+	return ErrInvalidSaveData
+}
+
+// unlistedSaveKeys are keys the server reads that ClientJsonKeyDefines.cs does not list. They are never required.
+var unlistedSaveKeys = []string{"user_id", "ferlot_maxln", "bbox_used_ferlot", "get_medaltower", "dc_ferlot_useitem"}
+
+// checkSaveSchema compares the top-level keys of a save with the schema of version. Missing keys come in
+// SaveKeys order, the others sorted by key.
+func checkSaveSchema(keys []string, version int) []SaveWarning {
+	var warnings []SaveWarning
+	for _, k := range SaveKeys {
+		if !k.Temp && k.Since <= version && !slices.Contains(keys, k.Key) {
+			warnings = append(warnings, SaveWarning{Kind: WarningMissingKey, Key: k.Key})
+		}
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		k, ok := LookupSaveKey(key)
+		switch {
+		case ok && k.Since > version:
+			warnings = append(warnings, SaveWarning{Kind: WarningNewerKey, Key: key})
+		case !ok && !slices.Contains(unlistedSaveKeys, key) && !isTempSaveKey(key):
+			warnings = append(warnings, SaveWarning{Kind: WarningUnknownKey, Key: key})
+		}
+	}
+	return warnings
+}
+
+func isTempSaveKey(key string) bool {
+	return slices.ContainsFunc(SaveKeys, func(k SaveKey) bool { return k.Temp && k.Key == key })
+}
+
+// schemaViolations returns the warnings SchemaStrict rejects a save for.
+func schemaViolations(warnings []SaveWarning) []SaveWarning {
+	var violations []SaveWarning
+	for _, w := range warnings {
+		if w.Kind != WarningUnknownKey {
+			violations = append(violations, w)
+		}
+	}
+	return violations
+}
@@ -0,0 +1,122 @@
+package domain
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestCheckSaveSchema(t *testing.T) {
+	v04 := []string{"legacy", "version", "playtime", "credit", "credit_all", "medal_in", "ball_get", "ball_chain",
+		"slot_start", "slot_hit", "slot_getfev", "sqr_step", "jack_get", "jack_startmax", "buy_shbi"}
+
+	cases := []struct {
+		name    string
+		keys    []string
+		version int
+		want    []SaveWarning
+	}{
+		{name: "complete", keys: v04, version: 4},
+		{
+			name:    "missing",
+			keys:    without(v04, "credit", "buy_shbi"),
+			version: 4,
+			want:    []SaveWarning{{WarningMissingKey, "credit"}, {WarningMissingKey, "buy_shbi"}},
+		},
+		{
+			name:    "newer",
+			keys:    append(v04[:len(v04):len(v04)], "sp_use"),
+			version: 4,
+			want:    []SaveWarning{{WarningNewerKey, "sp_use"}},
+		},
+		{
+			name:    "unknown",
+			keys:    append(v04[:len(v04):len(v04)], "zz_new", "chg_ball", "ferlot_maxln", "a_new"),
+			version: 4,
+			want:    []SaveWarning{{WarningUnknownKey, "a_new"}, {WarningUnknownKey, "zz_new"}},
+		},
+		{
+			name:    "added in the declared version",
+			keys:    append(v04[:len(v04):len(v04)], "sp_use"),
+			version: 6,
+			want:    missingFrom(6, append(v04[:len(v04):len(v04)], "sp_use")),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := checkSaveSchema(tc.keys, tc.version); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("got %v want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func without(keys []string, drop ...string) []string {
+	return slices.DeleteFunc(slices.Clone(keys), func(k string) bool { return slices.Contains(drop, k) })
+}
+
+// missingFrom lists the stored keys up to version that keys lacks, as missing_key warnings.
+func missingFrom(version int, keys []string) []SaveWarning {
+	var want []SaveWarning
+	for _, k := range SaveKeys {
+		if !k.Temp && k.Since <= version && !slices.Contains(keys, k.Key) {
+			want = append(want, SaveWarning{WarningMissingKey, k.Key})
+		}
+	}
+	return want
+}
+
+func TestParseSaveData_SchemaModes(t *testing.T) {
+	raw := base64.RawURLEncoding.EncodeToString([]byte(`{"version":5,"playtime":100,"credit_all":"500","sp":3,"chg_ball":2,"new_key":1}`))
+
+	sd, warnings, err := ParseSaveData(raw, SchemaLenient)
+	if err != nil {
+		t.Fatalf("lenient: %v", err)
+	}
+	if sd.Playtime != 100 || sd.CreditAll != 500 || sd.SkillPoint != 3 {
+		t.Fatalf("lenient still parses the save: %+v", sd)
+	}
+	kinds := map[SaveWarningKind][]string{}
+	for _, w := range warnings {
+		kinds[w.Kind] = append(kinds[w.Kind], w.Key)
+	}
+	if !reflect.DeepEqual(kinds[WarningNewerKey], []string{"sp"}) || !reflect.DeepEqual(kinds[WarningUnknownKey], []string{"new_key"}) {
+		t.Fatalf("warnings: %v", warnings)
+	}
+	if !slices.Contains(kinds[WarningMissingKey], "medal_get") || slices.Contains(kinds[WarningMissingKey], "playtime") {
+		t.Fatalf("missing keys: %v", kinds[WarningMissingKey])
+	}
+
+	sd, strictWarnings, err := ParseSaveData(raw, SchemaStrict)
+	var schemaErr *SaveSchemaError
+	if !errors.As(err, &schemaErr) || !errors.Is(err, ErrInvalidSaveData) || sd != nil {
+		t.Fatalf("strict must reject the save: %v", err)
+	}
+	if schemaErr.Version != 5 || !reflect.DeepEqual(schemaErr.Violations, schemaViolations(warnings)) {
+		t.Fatalf("violations: %+v", schemaErr)
+	}
+	if !reflect.DeepEqual(strictWarnings, warnings) {
+		t.Fatalf("strict warnings: %v want %v", strictWarnings, warnings)
+	}
+
+	// Unknown keys alone do not fail strict mode.
+	raw = base64.RawURLEncoding.EncodeToString([]byte(`{"legacy":0,"version":0,"playtime":1,"credit":"0","credit_all":"0",` +
+		`"medal_in":0,"ball_get":0,"ball_chain":0,"slot_start":0,"slot_hit":0,"slot_getfev":0,"sqr_step":0,"jack_get":0,` +
+		`"jack_startmax":0,"buy_shbi":0,"new_key":1}`))
+	if _, warnings, err := ParseSaveData(raw, SchemaStrict); err != nil || !reflect.DeepEqual(warnings, []SaveWarning{{WarningUnknownKey, "new_key"}}) {
+		t.Fatalf("strict with an unknown key: %v, %v", warnings, err)
+	}
+}
+
+func TestParseSaveSchemaMode(t *testing.T) {
+	for in, want := range map[string]SaveSchemaMode{"lenient": SchemaLenient, " Strict ": SchemaStrict} {
+		if got, err := ParseSaveSchemaMode(in); err != nil || got != want {
+			t.Fatalf("%q: got %q, %v", in, got, err)
+		}
+	}
+	if _, err := ParseSaveSchemaMode("loose"); err == nil {
+		t.Fatalf("unknown modes are an error")
+	}
+}
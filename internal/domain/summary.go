@@ -0,0 +1,15 @@
+package domain
+
+import "fmt"
+
+// SummaryDrift is one v4 summary value that differs from a full recomputation.
+type SummaryDrift struct {
+	Table  string `json:"table"`
+	Key    string `json:"key"`
+	Stored int64  `json:"stored"`
+	Actual int64  `json:"actual"`
+}
+
+func (d SummaryDrift) String() string {
+	return fmt.Sprintf("%s[%s]: stored=%d actual=%d", d.Table, d.Key, d.Stored, d.Actual)
+}
@@ -0,0 +1,424 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/domain"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/openapi/models"
+)
+
+// AdminScope は管理 API のトークンに与える権限
+type AdminScope string
+
+const (
+	// ScopeReadStats は集計値の検査と監査ログの閲覧
+	ScopeReadStats AdminScope = "read-stats"
+	// ScopeModerate はランキングからの除外などのモデレーション
+	ScopeModerate AdminScope = "moderate"
+	// ScopeManageUsers はユーザーデータの閲覧・削除
+	ScopeManageUsers AdminScope = "manage-users"
+	// ScopeRollback はユーザーの最新セーブを過去のセーブへ戻す
+	ScopeRollback AdminScope = "rollback"
+)
+
+var adminScopes = []AdminScope{ScopeReadStats, ScopeModerate, ScopeManageUsers, ScopeRollback}
+
+// minAdminTokenLength より短いトークンは推測されやすいので設定時に拒否する
+const minAdminTokenLength = 16
+
+// 管理 API が受け付けるリクエストボディの上限（ボディはそのまま監査ログに残す）
+const maxAdminPayloadBytes = 64 << 10
+
+// 管理 API の一覧の件数
+const (
+	defaultAdminAuditLimit = 50
+	maxAdminAuditLimit     = 500
+)
+
+const adminActorKey = "admin_actor"
+
+// AdminToken は管理 API のトークン 1 つ分。トークンそのものは持たず、ハッシュだけを持つ。
+type AdminToken struct {
+	// Actor は監査ログに記録する操作者名
+	Actor  string
+	Scopes []AdminScope
+	hash   [sha256.Size]byte
+}
+
+func (t AdminToken) allows(scope AdminScope) bool {
+	return slices.Contains(t.Scopes, scope)
+}
+
+// ParseAdminTokens は ADMIN_TOKENS の値（"<actor>:<scope>+<scope>:<token>" のカンマ区切り）を解釈する。
+// scope に * を指定すると全スコープを与える。
+func ParseAdminTokens(spec string) ([]AdminToken, error) {
+	var tokens []AdminToken
+	seen := make(map[[sha256.Size]byte]bool)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 || parts[0] == "" {
+			return nil, fmt.Errorf("admin token %q: want <actor>:<scopes>:<token>", redactAdminEntry(entry))
+		}
+		actor, scopeList, secret := parts[0], parts[1], parts[2]
+		if len(secret) < minAdminTokenLength {
+			return nil, fmt.Errorf("admin token for %s: token must be at least %d characters", actor, minAdminTokenLength)
+		}
+
+		var scopes []AdminScope
+		for _, s := range strings.Split(scopeList, "+") {
+			switch scope := AdminScope(s); {
+			case s == "*":
+				scopes = append(scopes, adminScopes...)
+			case slices.Contains(adminScopes, scope):
+				scopes = append(scopes, scope)
+			default:
+				return nil, fmt.Errorf("admin token for %s: unknown scope %q", actor, s)
+			}
+		}
+
+		hash := sha256.Sum256([]byte(secret))
+		if seen[hash] {
+			return nil, fmt.Errorf("admin token for %s: the same token is configured twice", actor)
+		}
+		seen[hash] = true
+		tokens = append(tokens, AdminToken{Actor: actor, Scopes: scopes, hash: hash})
+	}
+	return tokens, nil
+}
+
+// redactAdminEntry はエラーメッセージにトークンが載らないよう、最初の 2 項目だけを残す
+func redactAdminEntry(entry string) string {
+	if parts := strings.SplitN(entry, ":", 3); len(parts) == 3 {
+		return parts[0] + ":" + parts[1] + ":***"
+	}
+	return "***"
+}
+
+// WithAdminTokens は管理 API のトークンを設定する（未設定なら管理 API は無効）。
+func WithAdminTokens(tokens []AdminToken) Option {
+	return func(h *Handler) { h.adminTokens = tokens }
+}
+
+// AdminRepository は管理 API が使うリポジトリのメソッド
+type AdminRepository interface {
+	InsertAdminAudit(ctx context.Context, entry *domain.AdminAuditEntry) error
+	ListAdminAudit(ctx context.Context, limit int, beforeID int64) ([]domain.AdminAuditEntry, error)
+	ListSignatureBypass(ctx context.Context, limit int, beforeID int64, userID string) ([]domain.SignatureBypassEntry, error)
+	ReconcileSummary(ctx context.Context, apply bool) ([]domain.SummaryDrift, error)
+	RollbackSave(ctx context.Context, userID string, saveID int64) (*domain.SaveData, error)
+	DeleteUserData(ctx context.Context, userID string) (int64, error)
+}
+
+// RegisterAdminRoutes は管理 API を g（例: /api/admin）に登録する。
+// トークンが未設定、またはリポジトリが管理 API に対応していなければ何も登録せず false を返す。
+func (h *Handler) RegisterAdminRoutes(g *echo.Group) bool {
+	adminRepo, ok := h.repo.(AdminRepository)
+	if !ok || len(h.adminTokens) == 0 {
+		return false
+	}
+	h.adminRepo = adminRepo
+
+	g.Use(h.adminAudit)
+	g.GET("/stats/drift", h.adminGetSummaryDrift, h.adminAuth(ScopeReadStats))
+	g.GET("/stats/save-schema", h.adminGetSaveSchemaStats, h.adminAuth(ScopeReadStats))
+	g.GET("/audit-log", h.adminListAuditLog, h.adminAuth(ScopeReadStats))
+	g.GET("/signature-bypass-log", h.adminListSignatureBypass, h.adminAuth(ScopeReadStats))
+	g.GET("/users/:user_id", h.adminGetUser, h.adminAuth(ScopeManageUsers))
+	g.DELETE("/users/:user_id", h.adminDeleteUser, h.adminAuth(ScopeManageUsers))
+	g.POST("/users/:user_id/rollback", h.adminRollbackUser, h.adminAuth(ScopeRollback))
+	h.registerModerationRoutes(g)
+	h.registerAnomalyRoutes(g)
+	return true
+}
+
+// authenticateAdmin は Bearer トークンに対応する設定を返す。
+// どのトークンとも全件比較し、一致したかどうかで処理時間が変わらないようにする。
+func (h *Handler) authenticateAdmin(header string) (AdminToken, bool) {
+	secret, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok || secret == "" {
+		return AdminToken{}, false
+	}
+	hash := sha256.Sum256([]byte(secret))
+
+	var found AdminToken
+	matched := 0
+	for _, t := range h.adminTokens {
+		if subtle.ConstantTimeCompare(hash[:], t.hash[:]) == 1 {
+			found = t
+			matched = 1
+		}
+	}
+	return found, matched == 1
+}
+
+// adminAuth はトークンを検証し、scope を持たなければ 403 を返す。
+func (h *Handler) adminAuth(scope AdminScope) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			token, ok := h.authenticateAdmin(c.Request().Header.Get(echo.HeaderAuthorization))
+			if !ok {
+				slog.WarnContext(c.Request().Context(), "admin authentication failed",
+					"route", c.Path(), "remote_ip", c.RealIP())
+				c.Response().Header().Set(echo.HeaderWWWAuthenticate, "Bearer")
+				return respondError(c, &apiError{status: http.StatusUnauthorized, code: models.UNAUTHORIZED, message: "invalid admin token"})
+			}
+			c.Set(adminActorKey, token.Actor)
+			if !token.allows(scope) {
+				return respondError(c, &apiError{
+					status:  http.StatusForbidden,
+					code:    models.FORBIDDEN,
+					message: "admin token lacks scope " + string(scope),
+					details: map[string]interface{}{"scope": string(scope)},
+				})
+			}
+			return next(c)
+		}
+	}
+}
+
+// adminAudit は認証できた管理 API へのリクエストを、結果（スコープ不足を含む）とともに監査ログへ記録する。
+func (h *Handler) adminAudit(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := c.Request()
+		var body []byte
+		if req.Body != nil {
+			var err error
+			body, err = io.ReadAll(io.LimitReader(req.Body, maxAdminPayloadBytes+1))
+			if err != nil {
+				return respondError(c, err)
+			}
+			if len(body) > maxAdminPayloadBytes {
+				return respondError(c, &apiError{
+					status:  http.StatusRequestEntityTooLarge,
+					code:    models.INVALIDPARAMETER,
+					message: "request body too large",
+				})
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		err := next(c)
+
+		actor, _ := c.Get(adminActorKey).(string)
+		if actor == "" {
+			return err
+		}
+		status := c.Response().Status
+		if err != nil {
+			status = toAPIError(err).status
+		}
+		entry := &domain.AdminAuditEntry{
+			Actor:    actor,
+			Action:   req.Method + " " + c.Path(),
+			Target:   adminTarget(c),
+			Payload:  adminPayload(c, body),
+			RemoteIP: c.RealIP(),
+			Status:   status,
+		}
+		// 操作自体は済んでいるので、記録できなくても応答は変えない
+		if auditErr := h.adminRepo.InsertAdminAudit(context.WithoutCancel(req.Context()), entry); auditErr != nil {
+			slog.ErrorContext(req.Context(), "failed to write admin audit log",
+				"actor", entry.Actor, "action", entry.Action, "target", entry.Target, "error", auditErr)
+		}
+		return err
+	}
+}
+
+func adminTarget(c echo.Context) string {
+	target, err := url.PathUnescape(c.Param("user_id"))
+	if err != nil {
+		return c.Param("user_id")
+	}
+	return target
+}
+
+// adminPayload はリクエストボディ、ボディが無ければクエリパラメータの JSON を返す
+func adminPayload(c echo.Context, body []byte) string {
+	if len(body) > 0 {
+		return string(body)
+	}
+	if query := c.QueryParams(); len(query) > 0 {
+		encoded, _ := json.Marshal(query)
+		return string(encoded)
+	}
+	return ""
+}
+
+// adminLimit は一覧系の limit を解釈する
+func adminLimit(c echo.Context) (int, error) {
+	raw := c.QueryParam("limit")
+	if raw == "" {
+		return defaultAdminAuditLimit, nil
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil || v < 1 || v > maxAdminAuditLimit {
+		return 0, invalidAdminParameter("limit", fmt.Sprintf("must be between 1 and %d", maxAdminAuditLimit))
+	}
+	return v, nil
+}
+
+func adminUserID(c echo.Context) (string, error) {
+	userID := adminTarget(c)
+	if userID == "" {
+		return "", errMissingParameter("user_id")
+	}
+	return userID, nil
+}
+
+// markStatisticsStale は管理操作でユーザーのデータが変わったとき、ユーザーのセーブから作る全キャッシュを作り直させる。
+// credit_all 分布はキャッシュせず v4_summary_* から毎回読むので対象外。
+func (h *Handler) markStatisticsStale() {
+	ctx := context.Background()
+	h.statisticsCacheV4.MarkStale(statisticsCacheV4Key)
+	h.achievementRatesCache.MarkStale(achievementRatesCacheKey)
+	h.medalTimeseriesCache.InvalidateAll(ctx)
+	h.saveActivityCache.InvalidateAll(ctx)
+	h.metricRankingCache.InvalidateAll(ctx)
+}
+
+// adminGetSummaryDrift は v4_summary_* と全件再計算の差分を返す（書き換えはしない）
+func (h *Handler) adminGetSummaryDrift(c echo.Context) error {
+	drifts, err := h.adminRepo.ReconcileSummary(c.Request().Context(), false)
+	if err != nil {
+		return respondError(c, err)
+	}
+	if drifts == nil {
+		drifts = []domain.SummaryDrift{}
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"drifts": drifts})
+}
+
+// adminListAuditLog は監査ログを新しい順に返す（before_id で続きを取得）
+func (h *Handler) adminListAuditLog(c echo.Context) error {
+	limit, beforeID, err := adminPage(c)
+	if err != nil {
+		return respondError(c, err)
+	}
+	entries, err := h.adminRepo.ListAdminAudit(c.Request().Context(), limit, beforeID)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"entries": entries})
+}
+
+// adminListSignatureBypass は署名バイパストークンで通したリクエストを新しい順に返す（user_id で絞り込み可）
+func (h *Handler) adminListSignatureBypass(c echo.Context) error {
+	limit, beforeID, err := adminPage(c)
+	if err != nil {
+		return respondError(c, err)
+	}
+	entries, err := h.adminRepo.ListSignatureBypass(c.Request().Context(), limit, beforeID, c.QueryParam("user_id"))
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"entries": entries})
+}
+
+// adminPage は一覧系の limit / before_id を解釈する
+func adminPage(c echo.Context) (limit int, beforeID int64, err error) {
+	limit, err = adminLimit(c)
+	if err != nil {
+		return 0, 0, err
+	}
+	if raw := c.QueryParam("before_id"); raw != "" {
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || v < 1 {
+			return 0, 0, invalidAdminParameter("before_id", "must be a positive integer")
+		}
+		beforeID = v
+	}
+	return limit, beforeID, nil
+}
+
+// adminGetUser はユーザーの最新セーブと直近の保存履歴を返す
+func (h *Handler) adminGetUser(c echo.Context) error {
+	userID, err := adminUserID(c)
+	if err != nil {
+		return respondError(c, err)
+	}
+	ctx := c.Request().Context()
+	sd, err := h.repo.GetLatestSave(ctx, userID)
+	if err != nil {
+		return respondError(c, err)
+	}
+	history, _, err := h.repo.GetSaveHistory(ctx, userID, 20, nil)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"user_id":        userID,
+		"latest_save_id": sd.ID,
+		"latest":         sd.ToModel(),
+		"history":        history,
+	})
+}
+
+// adminDeleteUser はユーザーの全セーブと最新セーブの集計を削除する
+func (h *Handler) adminDeleteUser(c echo.Context) error {
+	userID, err := adminUserID(c)
+	if err != nil {
+		return respondError(c, err)
+	}
+	deleted, err := h.adminRepo.DeleteUserData(c.Request().Context(), userID)
+	if err != nil {
+		return respondError(c, err)
+	}
+	h.markStatisticsStale()
+	return c.JSON(http.StatusOK, map[string]interface{}{"user_id": userID, "deleted_saves": deleted})
+}
+
+type adminRollbackRequest struct {
+	SaveID int64 `json:"save_id"`
+}
+
+// adminRollbackUser はユーザーの最新セーブを save_id のセーブに戻す
+func (h *Handler) adminRollbackUser(c echo.Context) error {
+	userID, err := adminUserID(c)
+	if err != nil {
+		return respondError(c, err)
+	}
+	var req adminRollbackRequest
+	if err := json.NewDecoder(c.Request().Body).Decode(&req); err != nil {
+		return respondError(c, invalidAdminParameter("save_id", "request body must be JSON like {\"save_id\": 123}"))
+	}
+	if req.SaveID < 1 {
+		return respondError(c, errMissingParameter("save_id"))
+	}
+
+	sd, err := h.adminRepo.RollbackSave(c.Request().Context(), userID, req.SaveID)
+	if err != nil {
+		return respondError(c, err)
+	}
+	h.markStatisticsStale()
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"user_id":       userID,
+		"restored_from": req.SaveID,
+		"save_id":       sd.ID,
+	})
+}
+
+func invalidAdminParameter(name, reason string) error {
+	return &apiError{
+		status:  http.StatusBadRequest,
+		code:    models.INVALIDPARAMETER,
+		message: "invalid parameter " + name,
+		details: map[string]interface{}{"parameter": name, "reason": reason},
+	}
+}
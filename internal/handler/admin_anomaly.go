@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/domain"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/openapi/models"
+)
+
+// レビューのメモの最大文字数（anomaly_flags.review_note の長さ）
+const maxAnomalyReviewNoteLength = 1024
+
+// anomalyStatusAll は一覧で全ての状態を返す指定
+const anomalyStatusAll = "all"
+
+var errAnomalyNotFound = &apiError{status: http.StatusNotFound, code: models.NOTFOUND, message: "anomaly flag not found"}
+
+// registerAnomalyRoutes はリポジトリが対応していれば不審なセーブのレビュー用の管理 API を登録する
+func (h *Handler) registerAnomalyRoutes(g *echo.Group) {
+	anomalyRepo, ok := h.repo.(AnomalyRepository)
+	if !ok {
+		return
+	}
+	h.anomalyRepo = anomalyRepo
+
+	g.GET("/anomalies", h.adminListAnomalies, h.adminAuth(ScopeModerate))
+	g.POST("/anomalies/:id/review", h.adminReviewAnomaly, h.adminAuth(ScopeModerate))
+}
+
+// adminListAnomalies は検出した不審なセーブを新しい順に返す。
+// status（既定は open、all で全て）と user_id で絞り込み、before_id で続きを取得する。
+func (h *Handler) adminListAnomalies(c echo.Context) error {
+	limit, beforeID, err := adminPage(c)
+	if err != nil {
+		return respondError(c, err)
+	}
+	status := c.QueryParam("status")
+	switch {
+	case status == "":
+		status = domain.AnomalyStatusOpen
+	case status == anomalyStatusAll:
+		status = ""
+	case !domain.ValidAnomalyReview(status):
+		return respondError(c, invalidAdminParameter("status", "must be open, confirmed, dismissed or all"))
+	}
+	flags, err := h.anomalyRepo.ListAnomalyFlags(c.Request().Context(), status, c.QueryParam("user_id"), limit, beforeID)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"anomalies": flags})
+}
+
+type adminAnomalyReviewRequest struct {
+	Status string `json:"status"`
+	Note   string `json:"note"`
+}
+
+// adminReviewAnomaly はレビューの結果（confirmed / dismissed、open で差し戻し）を記録する。
+// 利用停止などの措置は別途モデレーション API で行う。
+func (h *Handler) adminReviewAnomaly(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id < 1 {
+		return respondError(c, invalidAdminParameter("id", "must be a positive integer"))
+	}
+	var req adminAnomalyReviewRequest
+	if err := json.NewDecoder(c.Request().Body).Decode(&req); err != nil {
+		return respondError(c, invalidAdminParameter("body", `request body must be JSON like {"status": "confirmed", "note": "..."}`))
+	}
+	if req.Status == "" {
+		return respondError(c, errMissingParameter("status"))
+	}
+	if !domain.ValidAnomalyReview(req.Status) {
+		return respondError(c, invalidAdminParameter("status", "must be open, confirmed or dismissed"))
+	}
+	if utf8.RuneCountInString(req.Note) > maxAnomalyReviewNoteLength {
+		return respondError(c, invalidAdminParameter("note", fmt.Sprintf("must be at most %d characters", maxAnomalyReviewNoteLength)))
+	}
+
+	actor, _ := c.Get(adminActorKey).(string)
+	flag, err := h.anomalyRepo.ReviewAnomalyFlag(c.Request().Context(), id, req.Status, actor, req.Note)
+	if errors.Is(err, sql.ErrNoRows) {
+		return respondError(c, errAnomalyNotFound)
+	}
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"anomaly": flag})
+}
@@ -0,0 +1,283 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/domain"
+)
+
+// anomalyRepo はセーブの推移と検出結果をメモリ上に保持する
+type anomalyRepo struct {
+	*adminRepo
+	// saves はユーザーごとのセーブ（古い順）。id は全体で昇順
+	saves map[string][]domain.SaveSample
+	flags []domain.AnomalyFlag
+	// lastSaveID・leaseOwner・leaseUntil は anomaly_scan_state の 1 行
+	lastSaveID *int64
+	leaseOwner string
+	leaseUntil time.Time
+	// listedAfter は ListUsersSavedAfter に渡された afterID
+	listedAfter []int64
+}
+
+func newAnomalyRepo() *anomalyRepo {
+	return &anomalyRepo{
+		adminRepo: &adminRepo{stubRepo: &stubRepo{}},
+		saves:     make(map[string][]domain.SaveSample),
+	}
+}
+
+func (r *anomalyRepo) ClaimAnomalyScan(ctx context.Context, owner string, lease time.Duration, since time.Time) (int64, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.leaseOwner != owner && time.Now().Before(r.leaseUntil) {
+		return 0, false, nil
+	}
+	r.leaseOwner, r.leaseUntil = owner, time.Now().Add(lease)
+	if r.lastSaveID == nil {
+		var id int64
+		for _, saves := range r.saves {
+			for _, s := range saves {
+				if s.CreatedAt.Before(since) {
+					id = max(id, s.ID)
+				}
+			}
+		}
+		r.lastSaveID = &id
+	}
+	return *r.lastSaveID, true, nil
+}
+
+func (r *anomalyRepo) AdvanceAnomalyScan(ctx context.Context, owner string, lastID int64, lease time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.leaseOwner == owner {
+		r.lastSaveID, r.leaseUntil = &lastID, time.Now().Add(lease)
+	}
+	return nil
+}
+
+func (r *anomalyRepo) ListUsersSavedAfter(ctx context.Context, afterID int64, limit int) ([]string, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.listedAfter = append(r.listedAfter, afterID)
+	var users []string
+	lastID := afterID
+	for userID, saves := range r.saves {
+		if last := saves[len(saves)-1].ID; last > afterID && len(users) < limit {
+			users = append(users, userID)
+			lastID = max(lastID, last)
+		}
+	}
+	return users, lastID, nil
+}
+
+func (r *anomalyRepo) GetSaveSamples(ctx context.Context, userID string, limit int) ([]domain.SaveSample, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	saves := r.saves[userID]
+	return saves[max(len(saves)-limit, 0):], nil
+}
+
+func (r *anomalyRepo) InsertAnomalyFlags(ctx context.Context, flags []domain.AnomalyFlag) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var inserted int64
+next:
+	for _, f := range flags {
+		for _, existing := range r.flags {
+			if existing.SaveID == f.SaveID && existing.Kind == f.Kind {
+				continue next
+			}
+		}
+		f.ID = int64(len(r.flags) + 1)
+		r.flags = append(r.flags, f)
+		inserted++
+	}
+	return inserted, nil
+}
+
+func (r *anomalyRepo) ListAnomalyFlags(ctx context.Context, status, userID string, limit int, beforeID int64) ([]domain.AnomalyFlag, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := []domain.AnomalyFlag{}
+	for i := len(r.flags) - 1; i >= 0 && len(list) < limit; i-- {
+		f := r.flags[i]
+		if (status == "" || f.Status == status) && (userID == "" || f.UserID == userID) && (beforeID == 0 || f.ID < beforeID) {
+			list = append(list, f)
+		}
+	}
+	return list, nil
+}
+
+func (r *anomalyRepo) ReviewAnomalyFlag(ctx context.Context, id int64, status, reviewer, note string) (*domain.AnomalyFlag, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if id < 1 || id > int64(len(r.flags)) {
+		return nil, sql.ErrNoRows
+	}
+	now := time.Now()
+	f := &r.flags[id-1]
+	f.Status, f.ReviewedBy, f.ReviewNote, f.ReviewedAt = status, reviewer, note, &now
+	flag := *f
+	return &flag, nil
+}
+
+func TestAnalyzeAnomalies(t *testing.T) {
+	repo := newAnomalyRepo()
+	now := time.Now()
+	repo.saves["honest"] = []domain.SaveSample{
+		{ID: 1, Playtime: 600, CreditAll: 1000, CpmMax: 100, CreatedAt: now},
+		{ID: 3, Playtime: 1200, CreditAll: 1800, CpmMax: 100, CreatedAt: now},
+	}
+	repo.saves["cheater"] = []domain.SaveSample{
+		{ID: 2, Playtime: 600, CreditAll: 1000, CpmMax: 100, CreatedAt: now},
+		{ID: 4, Playtime: 660, CreditAll: 900000, CpmMax: 100, CreatedAt: now},
+	}
+	h := New(repo)
+	var advanced []int64
+	advance := func(lastID int64) error {
+		advanced = append(advanced, lastID)
+		return nil
+	}
+
+	lastID, err := h.analyzeAnomalies(context.Background(), repo, 0, advance)
+	if err != nil {
+		t.Fatalf("analyzeAnomalies: %v", err)
+	}
+	if lastID != 4 || len(advanced) != 1 || advanced[0] != 4 {
+		t.Fatalf("lastID = %d, advanced %v, want 4", lastID, advanced)
+	}
+	if len(repo.flags) != 1 || repo.flags[0].UserID != "cheater" || repo.flags[0].Kind != domain.AnomalyCreditRate || repo.flags[0].SaveID != 4 {
+		t.Fatalf("flags = %+v", repo.flags)
+	}
+
+	// 新しいセーブが無ければ何もしない。同じセーブを解析し直しても重複しない
+	if lastID, err = h.analyzeAnomalies(context.Background(), repo, lastID, advance); err != nil || lastID != 4 {
+		t.Fatalf("second run: lastID = %d, err = %v", lastID, err)
+	}
+	if _, err := h.analyzeAnomalies(context.Background(), repo, 0, advance); err != nil || len(repo.flags) != 1 {
+		t.Fatalf("re-analysis duplicated flags: %+v (err %v)", repo.flags, err)
+	}
+}
+
+func TestScanAnomalies_LeaseAndWatermark(t *testing.T) {
+	ctx := context.Background()
+	repo := newAnomalyRepo()
+	now := time.Now()
+	repo.saves["old"] = []domain.SaveSample{{ID: 1, Playtime: 600, CreditAll: 1000, CpmMax: 100, CreatedAt: now.Add(-48 * time.Hour)}}
+	repo.saves["cheater"] = []domain.SaveSample{
+		{ID: 2, Playtime: 600, CreditAll: 1000, CpmMax: 100, CreatedAt: now},
+		{ID: 3, Playtime: 660, CreditAll: 900000, CpmMax: 100, CreatedAt: now},
+	}
+	h := New(repo)
+
+	// 最初の解析は anomalyInitialLookback 分だけ遡り、解析した位置を記録する
+	if err := h.scanAnomalies(ctx, repo, "replica-a", time.Minute); err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	if len(repo.flags) != 1 || repo.lastSaveID == nil || *repo.lastSaveID != 3 || repo.listedAfter[0] != 1 {
+		t.Fatalf("first scan: flags %+v, listed after %v", repo.flags, repo.listedAfter)
+	}
+
+	// リースが切れるまで他のレプリカは解析しない
+	repo.saves["cheater"] = append(repo.saves["cheater"], domain.SaveSample{ID: 4, Playtime: 720, CreditAll: 2000000, CpmMax: 100, CreatedAt: now})
+	if err := h.scanAnomalies(ctx, repo, "replica-b", time.Minute); err != nil || len(repo.flags) != 1 {
+		t.Fatalf("scan without the lease: flags %+v, err %v", repo.flags, err)
+	}
+
+	// リースが切れたら引き継ぎ、記録された続きから解析する
+	repo.leaseUntil = now.Add(-time.Second)
+	if err := h.scanAnomalies(ctx, repo, "replica-b", time.Minute); err != nil {
+		t.Fatalf("scan after the lease expired: %v", err)
+	}
+	if len(repo.flags) != 2 || repo.flags[1].SaveID != 4 || *repo.lastSaveID != 4 || repo.listedAfter[len(repo.listedAfter)-1] != 3 {
+		t.Fatalf("takeover: flags %+v, listed after %v", repo.flags, repo.listedAfter)
+	}
+	if repo.leaseOwner != "replica-b" {
+		t.Fatalf("lease owner = %q", repo.leaseOwner)
+	}
+}
+
+func TestStartAnomalyAnalyzer_Disabled(t *testing.T) {
+	if New(&stubRepo{}).StartAnomalyAnalyzer(context.Background(), time.Minute) {
+		t.Fatalf("analyzer started without repository support")
+	}
+	if New(newAnomalyRepo()).StartAnomalyAnalyzer(context.Background(), 0) {
+		t.Fatalf("analyzer started with interval 0")
+	}
+}
+
+func TestAdminAnomalies_ListAndReview(t *testing.T) {
+	repo := newAnomalyRepo()
+	repo.flags = []domain.AnomalyFlag{
+		{ID: 1, UserID: "user-1", Kind: domain.AnomalyCreditRate, SaveID: 10, Score: 3, Evidence: json.RawMessage(`{}`), Status: domain.AnomalyStatusOpen},
+		{ID: 2, UserID: "user-2", Kind: domain.AnomalyAchievementBurst, SaveID: 11, Score: 2, Evidence: json.RawMessage(`{}`), Status: domain.AnomalyStatusDismissed},
+	}
+	tokens, err := ParseAdminTokens("ops:*:" + testAdminToken + ", viewer:read-stats:" + testViewerToken)
+	if err != nil {
+		t.Fatalf("ParseAdminTokens: %v", err)
+	}
+	e := echo.New()
+	if !New(repo, WithAdminTokens(tokens)).RegisterAdminRoutes(e.Group("/admin")) {
+		t.Fatalf("admin routes were not registered")
+	}
+
+	list := func(query string) []domain.AnomalyFlag {
+		t.Helper()
+		rec := adminRequest(e, http.MethodGet, "/admin/anomalies"+query, testAdminToken, "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("list %q: got %d %s", query, rec.Code, rec.Body.String())
+		}
+		var body struct {
+			Anomalies []domain.AnomalyFlag `json:"anomalies"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return body.Anomalies
+	}
+
+	if rec := adminRequest(e, http.MethodGet, "/admin/anomalies", testViewerToken, ""); rec.Code != http.StatusForbidden {
+		t.Fatalf("without moderate scope: got %d", rec.Code)
+	}
+	if got := list(""); len(got) != 1 || got[0].ID != 1 {
+		t.Fatalf("default (open) list: %+v", got)
+	}
+	if got := list("?status=all"); len(got) != 2 {
+		t.Fatalf("all: %+v", got)
+	}
+	if rec := adminRequest(e, http.MethodGet, "/admin/anomalies?status=closed", testAdminToken, ""); rec.Code != http.StatusBadRequest {
+		t.Fatalf("unknown status: got %d", rec.Code)
+	}
+
+	for name, body := range map[string]string{
+		"no status":      `{"note": "looks fine"}`,
+		"unknown status": `{"status": "closed"}`,
+		"not JSON":       `confirmed`,
+	} {
+		if rec := adminRequest(e, http.MethodPost, "/admin/anomalies/1/review", testAdminToken, body); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: got %d %s", name, rec.Code, rec.Body.String())
+		}
+	}
+	if rec := adminRequest(e, http.MethodPost, "/admin/anomalies/99/review", testAdminToken, `{"status": "confirmed"}`); rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown id: got %d", rec.Code)
+	}
+
+	rec := adminRequest(e, http.MethodPost, "/admin/anomalies/1/review", testAdminToken, `{"status": "confirmed", "note": "credit edited"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("review: got %d %s", rec.Code, rec.Body.String())
+	}
+	if f := repo.flags[0]; f.Status != domain.AnomalyStatusConfirmed || f.ReviewedBy != "ops" || f.ReviewNote != "credit edited" || f.ReviewedAt == nil {
+		t.Fatalf("reviewed flag: %+v", f)
+	}
+	if got := list(""); len(got) != 0 {
+		t.Fatalf("reviewed flag is still open: %+v", got)
+	}
+}
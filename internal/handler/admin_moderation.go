@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/domain"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/openapi/models"
+)
+
+// モデレーション理由の最大文字数（user_moderation.reason の長さ）
+const maxModerationReasonLength = 1024
+
+// ModerationRepository はモデレーション（利用停止・ランキングからの非表示）を扱うリポジトリのメソッド
+type ModerationRepository interface {
+	UpsertModeration(ctx context.Context, m domain.Moderation) (*domain.Moderation, error)
+	GetModeration(ctx context.Context, userID string) (*domain.Moderation, error)
+	DeleteModeration(ctx context.Context, userID string) error
+	ListModerations(ctx context.Context, includeExpired bool, limit int) ([]domain.Moderation, error)
+	IsUserBanned(ctx context.Context, userID string) (bool, error)
+}
+
+// WithRejectBannedSaves は利用停止中のユーザーからのセーブを 403 で拒否する。
+// 無効なら保存は受け付け、ランキング・統計からだけ除外する。
+func WithRejectBannedSaves(reject bool) Option {
+	return func(h *Handler) { h.rejectBannedSaves = reject }
+}
+
+// registerModerationRoutes はリポジトリが対応していればモデレーション用の管理 API を登録する
+func (h *Handler) registerModerationRoutes(g *echo.Group) {
+	moderationRepo, ok := h.repo.(ModerationRepository)
+	if !ok {
+		return
+	}
+	h.moderationRepo = moderationRepo
+
+	g.GET("/moderation", h.adminListModerations, h.adminAuth(ScopeModerate))
+	g.GET("/users/:user_id/moderation", h.adminGetModeration, h.adminAuth(ScopeModerate))
+	g.PUT("/users/:user_id/moderation", h.adminPutModeration, h.adminAuth(ScopeModerate))
+	g.DELETE("/users/:user_id/moderation", h.adminDeleteModeration, h.adminAuth(ScopeModerate))
+}
+
+// checkBanned は設定で有効なとき、利用停止中のユーザーなら errUserBanned を返す
+func (h *Handler) checkBanned(ctx context.Context, userID string) error {
+	if !h.rejectBannedSaves {
+		return nil
+	}
+	moderationRepo, ok := h.repo.(ModerationRepository)
+	if !ok {
+		return nil
+	}
+	banned, err := moderationRepo.IsUserBanned(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if banned {
+		return errUserBanned
+	}
+	return nil
+}
+
+var errModerationNotFound = &apiError{status: http.StatusNotFound, code: models.NOTFOUND, message: "moderation not found"}
+
+// adminListModerations はモデレーションを更新の新しい順に返す（include_expired=true で期限切れも含める）
+func (h *Handler) adminListModerations(c echo.Context) error {
+	limit, err := adminLimit(c)
+	if err != nil {
+		return respondError(c, err)
+	}
+	includeExpired := false
+	if raw := c.QueryParam("include_expired"); raw != "" {
+		includeExpired, err = strconv.ParseBool(raw)
+		if err != nil {
+			return respondError(c, invalidAdminParameter("include_expired", "must be true or false"))
+		}
+	}
+	moderations, err := h.moderationRepo.ListModerations(c.Request().Context(), includeExpired, limit)
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{"moderations": moderations})
+}
+
+// adminGetModeration はユーザーのモデレーションを返す（期限切れでも返し、active で判別する）
+func (h *Handler) adminGetModeration(c echo.Context) error {
+	userID, err := adminUserID(c)
+	if err != nil {
+		return respondError(c, err)
+	}
+	m, err := h.moderationRepo.GetModeration(c.Request().Context(), userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return respondError(c, errModerationNotFound)
+	}
+	if err != nil {
+		return respondError(c, err)
+	}
+	return c.JSON(http.StatusOK, moderationResponse(m))
+}
+
+type adminModerationRequest struct {
+	Banned             bool       `json:"banned"`
+	HiddenFromRankings bool       `json:"hidden_from_rankings"`
+	Reason             string     `json:"reason"`
+	ExpiresAt          *time.Time `json:"expires_at"`
+}
+
+// adminPutModeration はユーザーを利用停止・ランキングから非表示にする（既存の設定は置き換える）
+func (h *Handler) adminPutModeration(c echo.Context) error {
+	userID, err := adminUserID(c)
+	if err != nil {
+		return respondError(c, err)
+	}
+	var req adminModerationRequest
+	if err := json.NewDecoder(c.Request().Body).Decode(&req); err != nil {
+		return respondError(c, invalidAdminParameter("body",
+			`request body must be JSON like {"banned": true, "hidden_from_rankings": false, "reason": "...", "expires_at": null}`))
+	}
+	if !req.Banned && !req.HiddenFromRankings {
+		return respondError(c, invalidAdminParameter("banned", "set banned or hidden_from_rankings; use DELETE to lift a moderation"))
+	}
+	if req.Reason == "" {
+		return respondError(c, errMissingParameter("reason"))
+	}
+	if utf8.RuneCountInString(req.Reason) > maxModerationReasonLength {
+		return respondError(c, invalidAdminParameter("reason", fmt.Sprintf("must be at most %d characters", maxModerationReasonLength)))
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return respondError(c, invalidAdminParameter("expires_at", "must be in the future"))
+	}
+
+	actor, _ := c.Get(adminActorKey).(string)
+	m, err := h.moderationRepo.UpsertModeration(c.Request().Context(), domain.Moderation{
+		UserID:             userID,
+		Banned:             req.Banned,
+		HiddenFromRankings: req.HiddenFromRankings || req.Banned,
+		Reason:             req.Reason,
+		ExpiresAt:          req.ExpiresAt,
+		Moderator:          actor,
+	})
+	if err != nil {
+		return respondError(c, err)
+	}
+	h.markStatisticsStale()
+	return c.JSON(http.StatusOK, moderationResponse(m))
+}
+
+// adminDeleteModeration はユーザーのモデレーションを解除する
+func (h *Handler) adminDeleteModeration(c echo.Context) error {
+	userID, err := adminUserID(c)
+	if err != nil {
+		return respondError(c, err)
+	}
+	err = h.moderationRepo.DeleteModeration(c.Request().Context(), userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return respondError(c, errModerationNotFound)
+	}
+	if err != nil {
+		return respondError(c, err)
+	}
+	h.markStatisticsStale()
+	return c.JSON(http.StatusOK, map[string]interface{}{"user_id": userID, "lifted": true})
+}
+
+// moderationResponse はモデレーションに現在有効かどうかを添える
+func moderationResponse(m *domain.Moderation) map[string]interface{} {
+	return map[string]interface{}{
+		"moderation": m,
+		"active":     m.Active(time.Now()),
+	}
+}
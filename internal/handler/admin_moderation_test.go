@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/domain"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/openapi"
+)
+
+// moderationRepo はモデレーションをメモリ上に保持する
+type moderationRepo struct {
+	*adminRepo
+	moderations map[string]domain.Moderation
+}
+
+func newModerationRepo() *moderationRepo {
+	return &moderationRepo{
+		adminRepo:   &adminRepo{stubRepo: &stubRepo{}},
+		moderations: make(map[string]domain.Moderation),
+	}
+}
+
+func (r *moderationRepo) UpsertModeration(ctx context.Context, m domain.Moderation) (*domain.Moderation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.moderations[m.UserID] = m
+	return &m, nil
+}
+
+func (r *moderationRepo) GetModeration(ctx context.Context, userID string) (*domain.Moderation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, ok := r.moderations[userID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &m, nil
+}
+
+func (r *moderationRepo) DeleteModeration(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.moderations[userID]; !ok {
+		return sql.ErrNoRows
+	}
+	delete(r.moderations, userID)
+	return nil
+}
+
+func (r *moderationRepo) ListModerations(ctx context.Context, includeExpired bool, limit int) ([]domain.Moderation, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := []domain.Moderation{}
+	for _, m := range r.moderations {
+		if includeExpired || m.Active(time.Now()) {
+			list = append(list, m)
+		}
+	}
+	return list, nil
+}
+
+func (r *moderationRepo) IsUserBanned(ctx context.Context, userID string) (bool, error) {
+	m, err := r.GetModeration(ctx, userID)
+	if err != nil {
+		return false, nil
+	}
+	return m.Banned && m.Active(time.Now()), nil
+}
+
+func newModerationServer(t *testing.T, repo *moderationRepo) *echo.Echo {
+	t.Helper()
+	tokens, err := ParseAdminTokens("ops:*:" + testAdminToken + ", viewer:read-stats:" + testViewerToken)
+	if err != nil {
+		t.Fatalf("ParseAdminTokens: %v", err)
+	}
+	h := New(repo, WithAdminTokens(tokens))
+	e := echo.New()
+	if !h.RegisterAdminRoutes(e.Group("/admin")) {
+		t.Fatalf("admin routes were not registered")
+	}
+	return e
+}
+
+func TestAdminModeration_PutGetDelete(t *testing.T) {
+	repo := newModerationRepo()
+	e := newModerationServer(t, repo)
+
+	rec := adminRequest(e, http.MethodPut, "/admin/users/user-2/moderation", testViewerToken, `{"banned": true, "reason": "cheat"}`)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("without moderate scope: got %d", rec.Code)
+	}
+
+	for name, body := range map[string]string{
+		"no flag":         `{"reason": "cheat"}`,
+		"no reason":       `{"banned": true}`,
+		"already expired": `{"banned": true, "reason": "cheat", "expires_at": "2000-01-01T00:00:00Z"}`,
+		"not JSON":        `banned`,
+	} {
+		rec := adminRequest(e, http.MethodPut, "/admin/users/user-2/moderation", testAdminToken, body)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: got %d %s", name, rec.Code, rec.Body.String())
+		}
+	}
+	if len(repo.moderations) != 0 {
+		t.Fatalf("invalid requests reached the repository: %+v", repo.moderations)
+	}
+
+	expires := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	rec = adminRequest(e, http.MethodPut, "/admin/users/user-2/moderation", testAdminToken,
+		`{"banned": true, "reason": "cheat", "expires_at": "`+expires.Format(time.RFC3339)+`"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("put: got %d %s", rec.Code, rec.Body.String())
+	}
+	got := repo.moderations["user-2"]
+	if !got.Banned || !got.HiddenFromRankings || got.Moderator != "ops" || got.ExpiresAt == nil || !got.ExpiresAt.Equal(expires) {
+		t.Fatalf("stored moderation: %+v", got)
+	}
+
+	rec = adminRequest(e, http.MethodGet, "/admin/users/user-2/moderation", testAdminToken, "")
+	var body struct {
+		Moderation domain.Moderation `json:"moderation"`
+		Active     bool              `json:"active"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if rec.Code != http.StatusOK || !body.Active || body.Moderation.Reason != "cheat" {
+		t.Fatalf("get: got %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = adminRequest(e, http.MethodGet, "/admin/moderation", testAdminToken, "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"user_id":"user-2"`) {
+		t.Fatalf("list: got %d %s", rec.Code, rec.Body.String())
+	}
+
+	rec = adminRequest(e, http.MethodDelete, "/admin/users/user-2/moderation", testAdminToken, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("delete: got %d %s", rec.Code, rec.Body.String())
+	}
+	rec = adminRequest(e, http.MethodDelete, "/admin/users/user-2/moderation", testAdminToken, "")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("delete twice: got %d", rec.Code)
+	}
+	rec = adminRequest(e, http.MethodGet, "/admin/users/user-2/moderation", testAdminToken, "")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("get after delete: got %d", rec.Code)
+	}
+}
+
+func TestGetV4Data_RejectsBannedUser(t *testing.T) {
+	setTestSecrets(t)
+
+	userID := "user-1"
+	data := base64.RawURLEncoding.EncodeToString([]byte(`{"playtime":100,"credit_all":500,"version":4}`))
+	q := url.Values{}
+	q.Set("data", data)
+	q.Set("user_id", userID)
+	q.Set("sig", makeV4SaveSig(userID, userID, data))
+
+	for _, reject := range []bool{true, false} {
+		repo := newModerationRepo()
+		repo.moderations[userID] = domain.Moderation{UserID: userID, Banned: true, HiddenFromRankings: true}
+		e := echo.New()
+		openapi.RegisterHandlers(e, New(repo, WithRejectBannedSaves(reject)))
+
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v4/data?"+q.Encode(), nil))
+
+		if reject {
+			if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "USER_BANNED") {
+				t.Fatalf("reject: got %d %s", rec.Code, rec.Body.String())
+			}
+			if repo.insertedSave != nil {
+				t.Fatalf("banned save should not be stored")
+			}
+			continue
+		}
+		if rec.Code != http.StatusOK || repo.insertedSave == nil {
+			t.Fatalf("without rejection: got %d %s", rec.Code, rec.Body.String())
+		}
+	}
+}
@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/domain"
+)
+
+// adminRepo は管理 API の呼び出しと監査ログを記録する
+type adminRepo struct {
+	*stubRepo
+
+	mu         sync.Mutex
+	audit      []domain.AdminAuditEntry
+	rollbacks  []int64
+	deletedFor []string
+
+	bypassQueries []string
+}
+
+func (r *adminRepo) InsertAdminAudit(ctx context.Context, entry *domain.AdminAuditEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry.ID = int64(len(r.audit) + 1)
+	r.audit = append(r.audit, *entry)
+	return nil
+}
+
+func (r *adminRepo) ListAdminAudit(ctx context.Context, limit int, beforeID int64) ([]domain.AdminAuditEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]domain.AdminAuditEntry(nil), r.audit...), nil
+}
+
+func (r *adminRepo) ListSignatureBypass(ctx context.Context, limit int, beforeID int64, userID string) ([]domain.SignatureBypassEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bypassQueries = append(r.bypassQueries, userID)
+	return []domain.SignatureBypassEntry{{ID: 7, Route: "/v4/data", UserID: userID, RemoteIP: "192.0.2.10"}}, nil
+}
+
+func (r *adminRepo) ReconcileSummary(ctx context.Context, apply bool) ([]domain.SummaryDrift, error) {
+	return []domain.SummaryDrift{{Table: "v4_summary_totals", Key: "total_medals", Stored: 1, Actual: 2}}, nil
+}
+
+func (r *adminRepo) RollbackSave(ctx context.Context, userID string, saveID int64) (*domain.SaveData, error) {
+	if saveID == 404 {
+		return nil, sql.ErrNoRows
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rollbacks = append(r.rollbacks, saveID)
+	return &domain.SaveData{ID: 900, UserId: userID}, nil
+}
+
+func (r *adminRepo) DeleteUserData(ctx context.Context, userID string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.deletedFor = append(r.deletedFor, userID)
+	return 3, nil
+}
+
+const (
+	testAdminToken  = "ops-token-0123456789"
+	testViewerToken = "viewer-token-0123456789"
+)
+
+func newAdminServer(t *testing.T) (*echo.Echo, *adminRepo) {
+	t.Helper()
+	tokens, err := ParseAdminTokens("ops:*:" + testAdminToken + ", viewer:read-stats:" + testViewerToken)
+	if err != nil {
+		t.Fatalf("ParseAdminTokens: %v", err)
+	}
+	repo := &adminRepo{stubRepo: &stubRepo{}}
+	h := New(repo, WithAdminTokens(tokens))
+	e := echo.New()
+	if !h.RegisterAdminRoutes(e.Group("/admin")) {
+		t.Fatalf("admin routes were not registered")
+	}
+	return e, repo
+}
+
+func adminRequest(e *echo.Echo, method, path, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	}
+	if body != "" {
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestParseAdminTokens(t *testing.T) {
+	tokens, err := ParseAdminTokens("alice:read-stats+rollback:" + testAdminToken + ",bob:*:" + testViewerToken)
+	if err != nil {
+		t.Fatalf("ParseAdminTokens: %v", err)
+	}
+	if len(tokens) != 2 || tokens[0].Actor != "alice" || !tokens[0].allows(ScopeRollback) || tokens[0].allows(ScopeModerate) {
+		t.Fatalf("unexpected tokens: %+v", tokens)
+	}
+	if !tokens[1].allows(ScopeModerate) || !tokens[1].allows(ScopeManageUsers) {
+		t.Fatalf("* should grant every scope: %+v", tokens[1].Scopes)
+	}
+
+	for name, spec := range map[string]string{
+		"missing token": "alice:read-stats",
+		"short token":   "alice:read-stats:short",
+		"unknown scope": "alice:delete-everything:" + testAdminToken,
+		"duplicate":     "alice:*:" + testAdminToken + ",bob:*:" + testAdminToken,
+	} {
+		_, err := ParseAdminTokens(spec)
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+			continue
+		}
+		if strings.Contains(err.Error(), testAdminToken) {
+			t.Errorf("%s: error leaks the token: %v", name, err)
+		}
+	}
+}
+
+func TestAdminAPI_RequiresTokenWithScope(t *testing.T) {
+	e, repo := newAdminServer(t)
+
+	rec := adminRequest(e, http.MethodGet, "/admin/stats/drift", "", "")
+	if rec.Code != http.StatusUnauthorized || !strings.Contains(rec.Body.String(), "UNAUTHORIZED") {
+		t.Fatalf("without token: got %d %s", rec.Code, rec.Body.String())
+	}
+	rec = adminRequest(e, http.MethodGet, "/admin/stats/drift", "wrong-token-0123456789", "")
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("wrong token: got %d", rec.Code)
+	}
+
+	rec = adminRequest(e, http.MethodDelete, "/admin/users/user-2", testViewerToken, "")
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "FORBIDDEN") {
+		t.Fatalf("missing scope: got %d %s", rec.Code, rec.Body.String())
+	}
+	if len(repo.deletedFor) != 0 {
+		t.Fatalf("forbidden request reached the repository")
+	}
+
+	rec = adminRequest(e, http.MethodGet, "/admin/stats/drift", testViewerToken, "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "total_medals") {
+		t.Fatalf("drift: got %d %s", rec.Code, rec.Body.String())
+	}
+
+	// 認証できなかったリクエストは記録せず、スコープ不足は記録する
+	if len(repo.audit) != 2 {
+		t.Fatalf("audit entries: got %+v", repo.audit)
+	}
+	if got := repo.audit[0]; got.Actor != "viewer" || got.Status != http.StatusForbidden || got.Target != "user-2" {
+		t.Fatalf("forbidden request audit: %+v", got)
+	}
+}
+
+func TestAdminAPI_RollbackIsAudited(t *testing.T) {
+	e, repo := newAdminServer(t)
+
+	rec := adminRequest(e, http.MethodPost, "/admin/users/user-2/rollback", testAdminToken, `{"save_id": 42}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("rollback: got %d %s", rec.Code, rec.Body.String())
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body["save_id"] != float64(900) || body["restored_from"] != float64(42) {
+		t.Fatalf("unexpected response: %v", body)
+	}
+	if len(repo.rollbacks) != 1 || repo.rollbacks[0] != 42 {
+		t.Fatalf("rollbacks: %v", repo.rollbacks)
+	}
+
+	rec = adminRequest(e, http.MethodPost, "/admin/users/user-2/rollback", testAdminToken, `{"save_id": 404}`)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("unknown save: got %d", rec.Code)
+	}
+
+	want := []domain.AdminAuditEntry{
+		{ID: 1, Actor: "ops", Action: "POST /admin/users/:user_id/rollback", Target: "user-2", Payload: `{"save_id": 42}`, RemoteIP: "192.0.2.1", Status: http.StatusOK},
+		{ID: 2, Actor: "ops", Action: "POST /admin/users/:user_id/rollback", Target: "user-2", Payload: `{"save_id": 404}`, RemoteIP: "192.0.2.1", Status: http.StatusNotFound},
+	}
+	if len(repo.audit) != len(want) {
+		t.Fatalf("audit entries: got %+v", repo.audit)
+	}
+	for i := range want {
+		if repo.audit[i] != want[i] {
+			t.Fatalf("audit[%d]: got %+v want %+v", i, repo.audit[i], want[i])
+		}
+	}
+}
+
+func TestAdminAPI_DisabledWithoutTokens(t *testing.T) {
+	h := New(&adminRepo{stubRepo: &stubRepo{}})
+	if h.RegisterAdminRoutes(echo.New().Group("/admin")) {
+		t.Fatalf("admin routes should stay disabled without tokens")
+	}
+}
+
+func TestAdminAPI_ListSignatureBypass(t *testing.T) {
+	e, repo := newAdminServer(t)
+
+	rec := adminRequest(e, http.MethodGet, "/admin/signature-bypass-log?user_id=user-2&limit=10", testViewerToken, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("list: got %d %s", rec.Code, rec.Body.String())
+	}
+	var body struct {
+		Entries []domain.SignatureBypassEntry `json:"entries"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(body.Entries) != 1 || body.Entries[0].UserID != "user-2" || body.Entries[0].Route != "/v4/data" {
+		t.Fatalf("unexpected entries: %+v", body.Entries)
+	}
+
+	rec = adminRequest(e, http.MethodGet, "/admin/signature-bypass-log?before_id=0", testViewerToken, "")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid before_id: got %d", rec.Code)
+	}
+	if len(repo.bypassQueries) != 1 {
+		t.Fatalf("queries: %v", repo.bypassQueries)
+	}
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/domain"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// anomalySampleSize はユーザーごとに解析する直近のセーブ数
+	anomalySampleSize = 20
+	// anomalyUserBatch は 1 回の問い合わせで取る、新しいセーブのあるユーザー数
+	anomalyUserBatch = 500
+	// anomalyInitialLookback は最初の解析でどこまで遡るか（以降は記録した続きから）
+	anomalyInitialLookback = 24 * time.Hour
+	// anomalyLeaseIntervals は解析のリースの長さ（解析間隔の何倍か）。
+	// 解析していたレプリカが止まると、リースが切れてから他のレプリカが引き継ぐ
+	anomalyLeaseIntervals = 3
+)
+
+// AnomalyRepository は不審なセーブの検出とそのレビューに使うリポジトリのメソッド
+type AnomalyRepository interface {
+	ClaimAnomalyScan(ctx context.Context, owner string, lease time.Duration, since time.Time) (int64, bool, error)
+	AdvanceAnomalyScan(ctx context.Context, owner string, lastID int64, lease time.Duration) error
+	ListUsersSavedAfter(ctx context.Context, afterID int64, limit int) ([]string, int64, error)
+	GetSaveSamples(ctx context.Context, userID string, limit int) ([]domain.SaveSample, error)
+	InsertAnomalyFlags(ctx context.Context, flags []domain.AnomalyFlag) (int64, error)
+	ListAnomalyFlags(ctx context.Context, status, userID string, limit int, beforeID int64) ([]domain.AnomalyFlag, error)
+	ReviewAnomalyFlag(ctx context.Context, id int64, status, reviewer, note string) (*domain.AnomalyFlag, error)
+}
+
+// StartAnomalyAnalyzer は interval ごとに、前回以降にセーブしたユーザーの直近のセーブの推移を解析し、
+// 不審なものを anomaly_flags に記録する。解析は DB のリースを持つ 1 つのレプリカだけが行い、
+// どこまで解析したかも DB に残すので、再起動しても続きから解析する（最初は anomalyInitialLookback 分だけ遡る）。
+// リポジトリが対応していない、または interval が 0 なら何もせず false を返す。ctx が終わると止まる。
+func (h *Handler) StartAnomalyAnalyzer(ctx context.Context, interval time.Duration) bool {
+	repo, ok := h.repo.(AnomalyRepository)
+	if !ok || interval <= 0 {
+		return false
+	}
+	owner := anomalyScanOwner()
+	lease := anomalyLeaseIntervals * interval
+	go func() {
+		for {
+			if err := h.scanAnomalies(ctx, repo, owner, lease); err != nil && ctx.Err() == nil {
+				slog.WarnContext(ctx, "anomaly analysis failed", "error", err)
+			}
+
+			timer := time.NewTimer(interval)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+	}()
+	return true
+}
+
+// anomalyScanOwner はリースを持つレプリカの名前（ホスト名と起動ごとの乱数）
+func anomalyScanOwner() string {
+	host, _ := os.Hostname()
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return host + "/" + hex.EncodeToString(b)
+}
+
+// scanAnomalies はリースを取れたら、記録された続きから解析する。他のレプリカがリースを持っていれば何もしない。
+func (h *Handler) scanAnomalies(ctx context.Context, repo AnomalyRepository, owner string, lease time.Duration) error {
+	afterID, ok, err := repo.ClaimAnomalyScan(ctx, owner, lease, time.Now().Add(-anomalyInitialLookback))
+	if err != nil || !ok {
+		return err
+	}
+	_, err = h.analyzeAnomalies(ctx, repo, afterID, func(lastID int64) error {
+		return repo.AdvanceAnomalyScan(ctx, owner, lastID, lease)
+	})
+	return err
+}
+
+// analyzeAnomalies は afterID より後にセーブしたユーザーを全て解析し、解析済みのセーブ id を返す。
+// ユーザーのまとまりを解析し終えるごとに、その位置を advance に渡す（リースの延長と進み具合の記録）。
+// 途中で失敗したら、そこまでに解析できた位置を返す（次回は続きから）。
+func (h *Handler) analyzeAnomalies(ctx context.Context, repo AnomalyRepository, afterID int64, advance func(lastID int64) error) (_ int64, err error) {
+	ctx, span := tracing.Start(ctx, "anomaly.Analyze")
+	defer func() { tracing.End(span, err) }()
+
+	var users, flagged int64
+	defer func() {
+		span.SetAttributes(attribute.Int64("anomaly.users", users), attribute.Int64("anomaly.flags", flagged))
+		if flagged > 0 {
+			slog.WarnContext(ctx, "anomalies flagged", "users", users, "flags", flagged)
+		}
+	}()
+
+	for {
+		batch, lastID, err := repo.ListUsersSavedAfter(ctx, afterID, anomalyUserBatch)
+		if err != nil {
+			return afterID, err
+		}
+		for _, userID := range batch {
+			samples, err := repo.GetSaveSamples(ctx, userID, anomalySampleSize)
+			if err != nil {
+				return afterID, err
+			}
+			flags, err := domain.AnalyzeSaves(userID, samples, domain.DefaultAnomalyThresholds)
+			if err != nil {
+				return afterID, err
+			}
+			if len(flags) > 0 {
+				n, err := repo.InsertAnomalyFlags(ctx, flags)
+				flagged += n
+				if err != nil {
+					return afterID, err
+				}
+			}
+			users++
+		}
+		afterID = lastID
+		if err := advance(afterID); err != nil {
+			return afterID, err
+		}
+		if len(batch) < anomalyUserBatch {
+			return afterID, nil
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	encodingBrotli = "br"
+	encodingGzip   = "gzip"
+
+	// compressMinBytes 未満のレスポンスは圧縮しない（ヘッダ分で逆に大きくなるため）
+	compressMinBytes = 1024
+
+	// リクエストごとに圧縮するレスポンスは速度優先のレベルにする
+	dynamicBrotliLevel = 4
+)
+
+// negotiateEncoding は Accept-Encoding から br / gzip / "" （無圧縮）を選ぶ。
+// q 値が同じなら圧縮率の高い br を優先する。
+func negotiateEncoding(acceptEncoding string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+	best, bestQ := "", 0.0
+	consider := func(encoding string, q float64) {
+		if q > bestQ || (q == bestQ && q > 0 && encoding == encodingBrotli) {
+			best, bestQ = encoding, q
+		}
+	}
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, q := parseEncodingQ(part)
+		switch name {
+		case encodingBrotli, encodingGzip:
+			consider(name, q)
+		case "*":
+			consider(encodingBrotli, q)
+		}
+	}
+	return best
+}
+
+func parseEncodingQ(part string) (string, float64) {
+	name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+	q := 1.0
+	for _, param := range strings.Split(params, ";") {
+		key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if !ok || strings.TrimSpace(key) != "q" {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return strings.ToLower(strings.TrimSpace(name)), 0
+		}
+		q = parsed
+	}
+	return strings.ToLower(strings.TrimSpace(name)), q
+}
+
+// CompressMiddleware は Accept-Encoding に応じて compressMinBytes 以上のレスポンスを br / gzip で圧縮する。
+// ハンドラが Content-Encoding を設定済み（圧縮済みの snapshot など）の場合はそのまま通す。
+func CompressMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Request().Method == http.MethodHead {
+				return next(c)
+			}
+			c.Response().Header().Add(echo.HeaderVary, echo.HeaderAcceptEncoding)
+			encoding := negotiateEncoding(c.Request().Header.Get(echo.HeaderAcceptEncoding))
+			if encoding == "" {
+				return next(c)
+			}
+
+			res := c.Response()
+			cw := &compressWriter{ResponseWriter: res.Writer, encoding: encoding, status: http.StatusOK}
+			res.Writer = cw
+			defer func() {
+				_ = cw.Close()
+				res.Writer = cw.ResponseWriter
+			}()
+			return next(c)
+		}
+	}
+}
+
+// compressWriter は最初の compressMinBytes を溜めてから圧縮するかどうかを決める。
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	status   int
+	buf      []byte
+	decided  bool
+	enc      io.WriteCloser
+}
+
+func (w *compressWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if w.decided {
+		if w.enc != nil {
+			return w.enc.Write(b)
+		}
+		return w.ResponseWriter.Write(b)
+	}
+
+	header := w.Header()
+	if header.Get(echo.HeaderContentEncoding) != "" || !bodyAllowed(w.status) {
+		if err := w.passthrough(); err != nil {
+			return 0, err
+		}
+		return w.ResponseWriter.Write(b)
+	}
+
+	w.buf = append(w.buf, b...)
+	if len(w.buf) >= compressMinBytes {
+		if err := w.startCompression(); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+func (w *compressWriter) startCompression() error {
+	w.decided = true
+	header := w.Header()
+	header.Set(echo.HeaderContentEncoding, w.encoding)
+	header.Del(echo.HeaderContentLength)
+	w.ResponseWriter.WriteHeader(w.status)
+
+	switch w.encoding {
+	case encodingBrotli:
+		w.enc = brotli.NewWriterLevel(w.ResponseWriter, dynamicBrotliLevel)
+	default:
+		w.enc = gzip.NewWriter(w.ResponseWriter)
+	}
+	buf := w.buf
+	w.buf = nil
+	_, err := w.enc.Write(buf)
+	return err
+}
+
+func (w *compressWriter) passthrough() error {
+	w.decided = true
+	w.ResponseWriter.WriteHeader(w.status)
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(buf)
+	return err
+}
+
+// Close は溜めたままのレスポンスを書き出し、圧縮ストリームを閉じる。
+func (w *compressWriter) Close() error {
+	if !w.decided {
+		return w.passthrough()
+	}
+	if w.enc != nil {
+		return w.enc.Close()
+	}
+	return nil
+}
+
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		if len(w.buf) > 0 && bodyAllowed(w.status) && w.Header().Get(echo.HeaderContentEncoding) == "" {
+			_ = w.startCompression()
+		} else {
+			_ = w.passthrough()
+		}
+	}
+	if f, ok := w.enc.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func bodyAllowed(status int) bool {
+	return status >= http.StatusOK && status != http.StatusNoContent && status != http.StatusNotModified
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	"github.com/labstack/echo/v4"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/openapi/models"
+)
+
+func TestNegotiateEncoding(t *testing.T) {
+	cases := []struct {
+		header string
+		want   string
+	}{
+		{"", ""},
+		{"identity", ""},
+		{"gzip", encodingGzip},
+		{"gzip, deflate, br", encodingBrotli},
+		{"br;q=0.5, gzip;q=0.8", encodingGzip},
+		{"br;q=0, gzip", encodingGzip},
+		{"gzip;q=0", ""},
+		{"*", encodingBrotli},
+		{"GZIP", encodingGzip},
+	}
+	for _, tc := range cases {
+		if got := negotiateEncoding(tc.header); got != tc.want {
+			t.Errorf("negotiateEncoding(%q): got %q want %q", tc.header, got, tc.want)
+		}
+	}
+}
+
+func decompress(t *testing.T, encoding string, body []byte) []byte {
+	t.Helper()
+	var r io.Reader
+	switch encoding {
+	case encodingGzip:
+		gz, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("gzip reader: %v", err)
+		}
+		r = gz
+	case encodingBrotli:
+		r = brotli.NewReader(bytes.NewReader(body))
+	default:
+		return body
+	}
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("decompress %s: %v", encoding, err)
+	}
+	return out
+}
+
+func TestCompressMiddleware(t *testing.T) {
+	large := strings.Repeat("medal ", 1000)
+	e := echo.New()
+	e.Use(CompressMiddleware())
+	e.GET("/large", func(c echo.Context) error { return c.String(http.StatusOK, large) })
+	e.GET("/small", func(c echo.Context) error { return c.String(http.StatusOK, "ok") })
+	e.GET("/empty", func(c echo.Context) error { return c.NoContent(http.StatusNotModified) })
+
+	for _, encoding := range []string{encodingGzip, encodingBrotli} {
+		req := httptest.NewRequest(http.MethodGet, "/large", nil)
+		req.Header.Set(echo.HeaderAcceptEncoding, encoding)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		if got := rec.Header().Get(echo.HeaderContentEncoding); got != encoding {
+			t.Fatalf("%s: Content-Encoding got %q", encoding, got)
+		}
+		if rec.Body.Len() >= len(large) {
+			t.Fatalf("%s: body was not compressed (%d bytes)", encoding, rec.Body.Len())
+		}
+		if got := string(decompress(t, encoding, rec.Body.Bytes())); got != large {
+			t.Fatalf("%s: round trip mismatch", encoding)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/small", nil)
+	req.Header.Set(echo.HeaderAcceptEncoding, "gzip")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Header().Get(echo.HeaderContentEncoding) != "" || rec.Body.String() != "ok" {
+		t.Fatalf("small response should not be compressed: %q %q", rec.Header().Get(echo.HeaderContentEncoding), rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/empty", nil)
+	req.Header.Set(echo.HeaderAcceptEncoding, "br")
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Fatalf("304: got %d body=%q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestGetV4Statistics_PrecompressedSnapshot(t *testing.T) {
+	setTestSecrets(t)
+	entries := make([]models.RankingEntry, 0, 200)
+	for i := 0; i < 200; i++ {
+		userID, value := "user", int64(i)
+		entries = append(entries, models.RankingEntry{UserId: &userID, Value: &value})
+	}
+	repo := &stubRepo{statsV4: &models.StatisticsV4{CpmMax: &entries}}
+	e := newTestServer(t, repo)
+	e.Use(CompressMiddleware())
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v4/statistics", nil))
+	identity := rec.Body.Bytes()
+	identityETag := rec.Header().Get("ETag")
+
+	req := httptest.NewRequest(http.MethodGet, "/v4/statistics", nil)
+	req.Header.Set(echo.HeaderAcceptEncoding, "gzip, br")
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if got := rec.Header().Get(echo.HeaderContentEncoding); got != encodingBrotli {
+		t.Fatalf("Content-Encoding: got %q", got)
+	}
+	if !bytes.Equal(decompress(t, encodingBrotli, rec.Body.Bytes()), identity) {
+		t.Fatalf("brotli body does not match identity body")
+	}
+	brETag := rec.Header().Get("ETag")
+	if brETag == identityETag || !strings.HasSuffix(brETag, `-br"`) {
+		t.Fatalf("br ETag: got %q identity %q", brETag, identityETag)
+	}
+
+	// どの符号化版の ETag でも 304 になる
+	for _, etag := range []string{brETag, identityETag} {
+		req = httptest.NewRequest(http.MethodGet, "/v4/statistics", nil)
+		req.Header.Set(echo.HeaderAcceptEncoding, "gzip")
+		req.Header.Set("If-None-Match", etag)
+		rec = httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		if rec.Code != http.StatusNotModified {
+			t.Fatalf("If-None-Match %s: got %d", etag, rec.Code)
+		}
+	}
+	if repo.statsV4Calls != 1 {
+		t.Fatalf("expected a single load, calls=%d", repo.statsV4Calls)
+	}
+}
@@ -48,6 +48,25 @@ func TestDecodeBase64ID(t *testing.T) {
 	}
 }
 
+func FuzzDecodeUserIDParam(f *testing.F) {
+	f.Add("user-1")
+	f.Add(base64.RawURLEncoding.EncodeToString([]byte("user-1")))
+	f.Add("user%201")
+	f.Add("%ZZ")
+	f.Add(" ")
+	f.Fuzz(func(t *testing.T, userID string) {
+		_, _ = decodeUserIDParam(userID)
+		// Every base64 form of an ID decodes back to it.
+		for _, enc := range []*base64.Encoding{base64.RawURLEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.StdEncoding} {
+			encoded := enc.EncodeToString([]byte(userID))
+			got, err := decodeUserIDParam(encoded)
+			if err != nil || got != userID {
+				t.Fatalf("%q: got %q, %v want %q", encoded, got, err, userID)
+			}
+		}
+	})
+}
+
 func TestNormalizeBase64(t *testing.T) {
 	got := normalizeBase64("a-b_c==")
 	if got != "a+b/c" {
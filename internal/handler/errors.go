@@ -0,0 +1,144 @@
+package handler
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/domain"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/openapi/models"
+)
+
+var (
+	errInvalidUserID    = errors.New("invalid user_id")
+	errInvalidSignature = errors.New("invalid signature")
+	errDuplicateSave    = errors.New("duplicate save data")
+	errUserBanned       = errors.New("user is banned")
+)
+
+// apiError はクライアントへ返すエラー（HTTP ステータスと安定したエラーコード）を表す。
+type apiError struct {
+	status  int
+	code    models.ErrorCode
+	message string
+	details map[string]interface{}
+}
+
+func (e *apiError) Error() string {
+	if reason, ok := e.details["reason"].(string); ok && reason != "" {
+		return e.message + ": " + reason
+	}
+	return e.message
+}
+
+// errMissingParameter は必須パラメータが空だったことを表す。
+func errMissingParameter(name string) error {
+	return &apiError{
+		status:  http.StatusBadRequest,
+		code:    models.MISSINGPARAMETER,
+		message: "missing " + name,
+		details: map[string]interface{}{"parameter": name},
+	}
+}
+
+// errUnknownMetric は登録されていないランキング指標が指定されたことを表す。
+func errUnknownMetric(metric string) error {
+	return &apiError{
+		status:  http.StatusNotFound,
+		code:    models.NOTFOUND,
+		message: "unknown ranking metric " + metric,
+		details: map[string]interface{}{"parameter": "metric"},
+	}
+}
+
+// toAPIError はドメイン/リポジトリのエラーをエラーコードへ対応付ける。
+// 対応付けはここに集約し、未知のエラーは内部情報を伏せて INTERNAL_ERROR とする。
+func toAPIError(err error) *apiError {
+	var ae *apiError
+	if errors.As(err, &ae) {
+		return ae
+	}
+	// strict モードで拒否したセーブは、どのキーが version に合わないかを返す
+	var schemaErr *domain.SaveSchemaError
+	if errors.As(err, &schemaErr) {
+		violations := make([]string, len(schemaErr.Violations))
+		for i, v := range schemaErr.Violations {
+			violations[i] = v.String()
+		}
+		return &apiError{
+			status:  http.StatusBadRequest,
+			code:    models.INVALIDSAVEDATA,
+			message: "save data does not match its save version",
+			details: map[string]interface{}{"version": schemaErr.Version, "violations": violations},
+		}
+	}
+
+	switch {
+	case errors.Is(err, errInvalidUserID):
+		return &apiError{status: http.StatusBadRequest, code: models.INVALIDUSERID, message: "invalid user_id"}
+	case errors.Is(err, errInvalidSignature):
+		return &apiError{status: http.StatusUnauthorized, code: models.INVALIDSIGNATURE, message: "invalid signature"}
+	case errors.Is(err, domain.ErrInvalidSaveData):
+		return &apiError{status: http.StatusBadRequest, code: models.INVALIDSAVEDATA, message: "save data could not be parsed"}
+	case errors.Is(err, errDuplicateSave):
+		return &apiError{status: http.StatusConflict, code: models.DUPLICATESAVE, message: "duplicate save data"}
+	case errors.Is(err, errUserBanned):
+		return &apiError{status: http.StatusForbidden, code: models.USERBANNED, message: "user is banned"}
+	case errors.Is(err, sql.ErrNoRows):
+		return &apiError{status: http.StatusNotFound, code: models.NOTFOUND, message: "save data not found"}
+	}
+
+	// echo がルーティング・パラメータのバインド・ミドルウェアで返すエラー。ステータスはそのまま返す
+	var he *echo.HTTPError
+	if errors.As(err, &he) && he.Code < http.StatusInternalServerError {
+		switch he.Code {
+		case http.StatusBadRequest:
+			return &apiError{status: he.Code, code: models.INVALIDPARAMETER, message: fmt.Sprint(he.Message)}
+		case http.StatusUnauthorized:
+			return &apiError{status: he.Code, code: models.UNAUTHORIZED, message: http.StatusText(he.Code)}
+		case http.StatusForbidden:
+			return &apiError{status: he.Code, code: models.FORBIDDEN, message: http.StatusText(he.Code)}
+		case http.StatusNotFound:
+			return &apiError{status: he.Code, code: models.NOTFOUND, message: http.StatusText(he.Code)}
+		case http.StatusMethodNotAllowed:
+			return &apiError{status: he.Code, code: models.METHODNOTALLOWED, message: http.StatusText(he.Code)}
+		case http.StatusRequestEntityTooLarge:
+			return &apiError{status: he.Code, code: models.PAYLOADTOOLARGE, message: http.StatusText(he.Code)}
+		default:
+			return &apiError{status: he.Code, code: models.REQUESTREJECTED, message: fmt.Sprint(he.Message)}
+		}
+	}
+
+	return &apiError{status: http.StatusInternalServerError, code: models.INTERNALERROR, message: "internal server error"}
+}
+
+// respondError はエラーをエラーコード付きの JSON で返す。
+// 元のエラー文言はリクエストログにだけ残し、クライアントには返さない。
+func respondError(ctx echo.Context, err error) error {
+	apiErr := toAPIError(err)
+	ctx.Set(errorReasonKey, err.Error())
+
+	body := models.Error{
+		Code:    apiErr.code,
+		Message: apiErr.message,
+	}
+	if len(apiErr.details) > 0 {
+		body.Details = &apiErr.details
+	}
+	return ctx.JSON(apiErr.status, body)
+}
+
+// HTTPErrorHandler は echo 既定のエラーハンドラを置き換え、
+// ルーティングやパラメータのバインドで発生したエラーも同じ形式で返す。
+func HTTPErrorHandler(err error, ctx echo.Context) {
+	if ctx.Response().Committed {
+		return
+	}
+	if ctx.Request().Method == http.MethodHead {
+		_ = ctx.NoContent(toAPIError(err).status)
+		return
+	}
+	_ = respondError(ctx, err)
+}
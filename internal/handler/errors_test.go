@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/domain"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/openapi/models"
+)
+
+func assertErrorResponse(t *testing.T, rec *httptest.ResponseRecorder, status int, code models.ErrorCode) models.Error {
+	t.Helper()
+	if rec.Code != status {
+		t.Fatalf("status: got %d want %d body=%s", rec.Code, status, rec.Body.String())
+	}
+	var body models.Error
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("error body is not JSON: %v body=%s", err, rec.Body.String())
+	}
+	if body.Code != code {
+		t.Fatalf("code: got %q want %q", body.Code, code)
+	}
+	if body.Message == "" {
+		t.Fatalf("message should not be empty")
+	}
+	return body
+}
+
+func TestToAPIError(t *testing.T) {
+	cases := []struct {
+		name   string
+		err    error
+		status int
+		code   models.ErrorCode
+	}{
+		{"no rows", fmt.Errorf("get latest: %w", sql.ErrNoRows), http.StatusNotFound, models.NOTFOUND},
+		{"parse error", fmt.Errorf("%w: unexpected EOF", domain.ErrInvalidSaveData), http.StatusBadRequest, models.INVALIDSAVEDATA},
+		{"signature", errInvalidSignature, http.StatusUnauthorized, models.INVALIDSIGNATURE},
+		{"duplicate", errDuplicateSave, http.StatusConflict, models.DUPLICATESAVE},
+		{"user id", errInvalidUserID, http.StatusBadRequest, models.INVALIDUSERID},
+		{"missing", errMissingParameter("sig"), http.StatusBadRequest, models.MISSINGPARAMETER},
+		{"bind error", echo.NewHTTPError(http.StatusBadRequest, "Invalid format for parameter limit"), http.StatusBadRequest, models.INVALIDPARAMETER},
+		{"route not found", echo.ErrNotFound, http.StatusNotFound, models.NOTFOUND},
+		{"method not allowed", echo.ErrMethodNotAllowed, http.StatusMethodNotAllowed, models.METHODNOTALLOWED},
+		{"body limit", echo.ErrStatusRequestEntityTooLarge, http.StatusRequestEntityTooLarge, models.PAYLOADTOOLARGE},
+		{"rate limit", echo.ErrTooManyRequests, http.StatusTooManyRequests, models.REQUESTREJECTED},
+		{"echo 5xx", echo.ErrServiceUnavailable, http.StatusInternalServerError, models.INTERNALERROR},
+		{"unknown", errors.New("Error 1054: Unknown column 'x'"), http.StatusInternalServerError, models.INTERNALERROR},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := toAPIError(tc.err)
+			if got.status != tc.status || got.code != tc.code {
+				t.Fatalf("got (%d, %s) want (%d, %s)", got.status, got.code, tc.status, tc.code)
+			}
+		})
+	}
+}
+
+func TestRespondError_HidesInternalError(t *testing.T) {
+	setTestSecrets(t)
+	repo := &stubRepo{statsV4Err: errors.New("Error 1146: Table 'app.v3_user_latest_save_data' doesn't exist")}
+	e := newTestServer(t, repo)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v4/statistics", nil))
+
+	assertErrorResponse(t, rec, http.StatusInternalServerError, models.INTERNALERROR)
+	if strings.Contains(rec.Body.String(), "1146") {
+		t.Fatalf("internal error leaked to response: %s", rec.Body.String())
+	}
+}
+
+func TestRespondError_MissingParameterDetails(t *testing.T) {
+	setTestSecrets(t)
+	e := newTestServer(t, &stubRepo{})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v4/data/verify?data=&user_id=user-1&sig=x", nil))
+
+	body := assertErrorResponse(t, rec, http.StatusBadRequest, models.MISSINGPARAMETER)
+	if body.Details == nil || (*body.Details)["parameter"] != "data" {
+		t.Fatalf("details: got %v", body.Details)
+	}
+}
+
+func TestHTTPErrorHandler_BindError(t *testing.T) {
+	setTestSecrets(t)
+	e := newTestServer(t, &stubRepo{})
+	e.HTTPErrorHandler = HTTPErrorHandler
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v4/statistics/medals/timeseries?days=abc", nil))
+
+	assertErrorResponse(t, rec, http.StatusBadRequest, models.INVALIDPARAMETER)
+}
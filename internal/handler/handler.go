@@ -5,10 +5,12 @@ import (
 	"log"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
-	"github.com/motoki317/sc"
 	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/domain"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/pkg/cache"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/pkg/eventbus"
 	"github.com/pikachu0310/very-big-medal-pusher-data-server/openapi/models"
 )
 
@@ -24,21 +26,63 @@ const statisticsCacheV4TTL = 5 * time.Minute
 // 実績取得率キャッシュTTL
 const achievementRatesCacheTTL = time.Hour
 
+// セーブ保存による再計算の最短間隔（保存が集中しても重いクエリを連発しない）
+const (
+	statisticsCacheV4MinRefreshInterval = 30 * time.Second
+	achievementRatesMinRefreshInterval  = time.Minute
+)
+
 // メダル推移キャッシュTTL
 const medalTimeseriesCacheTTL = time.Hour
 
 // セーブアクティビティキャッシュTTL
 const saveActivityCacheTTL = 10 * time.Minute
 
+// 指標別ランキングキャッシュTTL（保存では無効化しないので短め）
+const metricRankingCacheTTL = time.Minute
+
 type Handler struct {
 	repo                  Repository
-	rankingCache          *sc.Cache[string, []models.GameData]
-	totalMedalsCache      *sc.Cache[string, int]
-	statisticsCacheV3     *sc.Cache[string, *models.StatisticsV3]
-	statisticsCacheV4     *sc.Cache[string, *models.StatisticsV4]
-	achievementRatesCache *sc.Cache[string, *models.AchievementRates]
-	medalTimeseriesCache  *sc.Cache[string, *models.MedalTimeseriesResponse]
-	saveActivityCache     *sc.Cache[string, *models.SaveActivityResponse]
+	cacheBackend          cache.Backend
+	rankingCache          cache.Cache[[]models.GameData]
+	totalMedalsCache      cache.Cache[int]
+	statisticsCacheV3     cache.Cache[*models.StatisticsV3]
+	statisticsCacheV4     *staleCache[*snapshot]
+	achievementRatesCache *staleCache[*snapshot]
+	medalTimeseriesCache  *keyedCache[*snapshot]
+	saveActivityCache     *keyedCache[*snapshot]
+	metricRankingCache    *keyedCache[*snapshot]
+
+	// rankingIndex はキャッシュ中の v4 統計に載っているユーザーと足切り値（無効化の判定用）
+	rankingIndex atomic.Pointer[rankingIndex]
+	events       eventbus.Bus[domain.SaveIngested]
+	// warm は起動時の温めの進み具合（readiness 用）
+	warm warmState
+
+	// 管理 API（RegisterAdminRoutes で有効になる）
+	adminTokens []AdminToken
+	adminRepo   AdminRepository
+	// moderationRepo はモデレーション用の管理 API が使う（リポジトリが対応している場合のみ）
+	moderationRepo ModerationRepository
+	// anomalyRepo は不審なセーブのレビュー用の管理 API が使う（リポジトリが対応している場合のみ）
+	anomalyRepo AnomalyRepository
+	// rejectBannedSaves が true なら利用停止中のユーザーのセーブを拒否する
+	rejectBannedSaves bool
+	// saveSchemaMode はセーブのスキーマ検査の方式、saveWarnings はその警告の集計
+	saveSchemaMode domain.SaveSchemaMode
+	saveWarnings   *saveWarningStats
+
+	// bypassPolicy は署名バイパストークンを受け付けるルート・送信元
+	bypassPolicy SignatureBypassPolicy
+}
+
+// Option は New の追加設定
+type Option func(*Handler)
+
+// WithCacheBackend はキャッシュの置き場所を指定する（既定はプロセス内）。
+// Redis を指定すると複数レプリカで同じ値を共有し、再計算も 1 レプリカだけが行う。
+func WithCacheBackend(backend cache.Backend) Option {
+	return func(h *Handler) { h.cacheBackend = backend }
 }
 
 type Repository interface {
@@ -49,6 +93,7 @@ type Repository interface {
 	GetMedalTimeseries(ctx context.Context, days int) (*models.MedalTimeseriesResponse, error)
 	GetSaveActivity(ctx context.Context, hours int) (*models.SaveActivityResponse, error)
 	GetCreditAllDistribution(ctx context.Context) (*models.CreditAllDistributionResponse, error)
+	GetRankingV4(ctx context.Context, metric string, limit int) ([]models.RankingEntry, error)
 
 	ExistsSameSave(ctx context.Context, userID string, playtime int64) (bool, error)
 	InsertSaveV4(ctx context.Context, sd *domain.SaveData) error
@@ -57,19 +102,22 @@ type Repository interface {
 	GetAchievementUnlockHistory(ctx context.Context, userID string, limit int) ([]models.AchievementUnlockEntry, int, error)
 }
 
-func New(repo Repository) *Handler {
-	h := &Handler{repo: repo}
+func New(repo Repository, opts ...Option) *Handler {
+	h := &Handler{repo: repo, saveSchemaMode: domain.SchemaLenient, saveWarnings: newSaveWarningStats()}
+	for _, opt := range opts {
+		opt(h)
+	}
 
 	// ランキング全般キャッシュ (キー: "sortBy:limit")
-	rankCache, err := sc.New(
-		func(ctx context.Context, key string) ([]models.GameData, error) {
+	rankCache, err := cache.New(h.cacheBackend, "rankings",
+		tracedLoader(func(ctx context.Context, key string) ([]models.GameData, error) {
 			parts := strings.Split(key, ":")
 			sortBy := parts[0]
 			limit, _ := strconv.Atoi(parts[1])
 			return h.repo.GetRankings(ctx, sortBy, limit)
-		},
+		}),
 		rankingsCacheTTL, rankingsCacheTTL,
-		sc.WithLRUBackend(500),
+		cache.WithLRU(500),
 	)
 	if err != nil {
 		log.Fatalf("failed to create ranking cache: %v", err)
@@ -77,10 +125,10 @@ func New(repo Repository) *Handler {
 	h.rankingCache = rankCache
 
 	// 全ユーザーのメダル合計キャッシュ (固定キー)
-	medalCache, err := sc.New(
-		func(ctx context.Context, _ string) (int, error) {
+	medalCache, err := cache.New(h.cacheBackend, "total_medals",
+		tracedLoader(func(ctx context.Context, _ string) (int, error) {
 			return h.repo.GetTotalMedals(ctx)
-		},
+		}),
 		rankingsCacheTTL, rankingsCacheTTL,
 	)
 	if err != nil {
@@ -92,7 +140,7 @@ func New(repo Repository) *Handler {
 		GetStatisticsV3(ctx context.Context) (*models.StatisticsV3, error)
 	}); ok {
 		// v3 統計データキャッシュの初期化
-		statsCacheV3, err := sc.New(
+		statsCacheV3, err := cache.New(h.cacheBackend, "statistics_v3",
 			func(ctx context.Context, key string) (*models.StatisticsV3, error) {
 				// key は使わないので無視
 				return v3Repo.GetStatisticsV3(ctx)
@@ -108,68 +156,98 @@ func New(repo Repository) *Handler {
 	}
 
 	// v4 統計データキャッシュの初期化
-	statsCacheV4, err := sc.New(
-		func(ctx context.Context, key string) (*models.StatisticsV4, error) {
+	statsCacheV4, err := cache.New(h.cacheBackend, "statistics_v4",
+		tracedLoader(snapshotLoader(func(ctx context.Context, key string) (*models.StatisticsV4, error) {
 			// key は使わないので無視
 			return repo.GetStatisticsV4(ctx)
-		},
+		})),
 		statisticsCacheV4TTL, // freshFor: 5分
 		statisticsCacheV4TTL, // ttl:      5分
 		// 単一キーなのでバックエンドはデフォルトの map で十分
+		cache.WithCodec[*snapshot](snapshotCodec{}),
+		// 全ランキングのクエリで数十秒かかることがある
+		cache.WithLockTTL(time.Minute),
 	)
 	if err != nil {
 		log.Fatalf("failed to create statistics v4 cache: %v", err)
 	}
-	h.statisticsCacheV4 = statsCacheV4
+	h.statisticsCacheV4 = newStaleCache(statsCacheV4, statisticsCacheV4MinRefreshInterval)
 
 	// achievements rate キャッシュ
-	achievementsCache, err := sc.New(
-		func(ctx context.Context, key string) (*models.AchievementRates, error) {
+	achievementsCache, err := cache.New(h.cacheBackend, "achievement_rates",
+		tracedLoader(snapshotLoader(func(ctx context.Context, key string) (*models.AchievementRates, error) {
 			return h.repo.GetAchievementRates(ctx)
-		},
+		})),
 		achievementRatesCacheTTL,
 		achievementRatesCacheTTL,
+		cache.WithCodec[*snapshot](snapshotCodec{}),
 	)
 	if err != nil {
 		log.Fatalf("failed to create achievement rates cache: %v", err)
 	}
-	h.achievementRatesCache = achievementsCache
+	h.achievementRatesCache = newStaleCache(achievementsCache, achievementRatesMinRefreshInterval)
 
 	// メダル推移キャッシュ（日単位）
-	medalTimeseriesCache, err := sc.New(
-		func(ctx context.Context, key string) (*models.MedalTimeseriesResponse, error) {
+	medalTimeseriesCache, err := cache.New(h.cacheBackend, "medal_timeseries",
+		tracedLoader(snapshotLoader(func(ctx context.Context, key string) (*models.MedalTimeseriesResponse, error) {
 			days, _ := strconv.Atoi(key)
 			if days <= 0 {
 				days = 30
 			}
 			return h.repo.GetMedalTimeseries(ctx, days)
-		},
+		})),
 		medalTimeseriesCacheTTL,
 		medalTimeseriesCacheTTL,
-		sc.WithLRUBackend(32),
+		cache.WithLRU(32),
+		cache.WithCodec[*snapshot](snapshotCodec{}),
 	)
 	if err != nil {
 		log.Fatalf("failed to create medal timeseries cache: %v", err)
 	}
-	h.medalTimeseriesCache = medalTimeseriesCache
+	h.medalTimeseriesCache = newKeyedCache(medalTimeseriesCache, 32)
 
 	// セーブアクティビティキャッシュ（時間単位）
-	saveActivityCache, err := sc.New(
-		func(ctx context.Context, key string) (*models.SaveActivityResponse, error) {
+	saveActivityCache, err := cache.New(h.cacheBackend, "save_activity",
+		tracedLoader(snapshotLoader(func(ctx context.Context, key string) (*models.SaveActivityResponse, error) {
 			hours, _ := strconv.Atoi(key)
 			if hours <= 0 {
 				hours = 168
 			}
 			return h.repo.GetSaveActivity(ctx, hours)
-		},
+		})),
 		saveActivityCacheTTL,
 		saveActivityCacheTTL,
-		sc.WithLRUBackend(32),
+		cache.WithLRU(32),
+		cache.WithCodec[*snapshot](snapshotCodec{}),
 	)
 	if err != nil {
 		log.Fatalf("failed to create save activity cache: %v", err)
 	}
-	h.saveActivityCache = saveActivityCache
+	h.saveActivityCache = newKeyedCache(saveActivityCache, 32)
+
+	// 指標別ランキングキャッシュ (キー: "metric:limit")
+	metricRankingCache, err := cache.New(h.cacheBackend, "metric_ranking",
+		tracedLoader(snapshotLoader(func(ctx context.Context, key string) (*models.RankingV4, error) {
+			metric, rawLimit, _ := strings.Cut(key, ":")
+			limit, _ := strconv.Atoi(rawLimit)
+			entries, err := h.repo.GetRankingV4(ctx, metric, limit)
+			if err != nil {
+				return nil, err
+			}
+			return &models.RankingV4{Metric: metric, Entries: entries}, nil
+		})),
+		metricRankingCacheTTL,
+		metricRankingCacheTTL,
+		cache.WithLRU(64),
+		cache.WithCodec[*snapshot](snapshotCodec{}),
+	)
+	if err != nil {
+		log.Fatalf("failed to create metric ranking cache: %v", err)
+	}
+	h.metricRankingCache = newKeyedCache(metricRankingCache, 64)
+
+	// セーブ保存時に、結果が変わるキャッシュだけを裏で作り直す
+	h.events.Subscribe(h.invalidateOnSave)
 
 	return h
 }
@@ -10,7 +10,7 @@ import (
 func (h *Handler) GetCreditAllDistribution(ctx echo.Context) error {
 	resp, err := h.repo.GetCreditAllDistribution(ctx.Request().Context())
 	if err != nil {
-		return ctx.String(http.StatusInternalServerError, err.Error())
+		return respondError(ctx, err)
 	}
 
 	return ctx.JSON(http.StatusOK, resp)
@@ -6,9 +6,7 @@ import (
 	"database/sql"
 	"errors"
 	"net/http"
-	"net/url"
 	"strconv"
-	"strings"
 
 	"github.com/labstack/echo/v4"
 	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/domain"
@@ -28,42 +26,44 @@ const (
 func (h *Handler) GetV4Data(ctx echo.Context, params models.GetV4DataParams) error {
 	userID, err := decodeUserIDParam(params.UserId)
 	if err != nil {
-		return ctx.String(http.StatusBadRequest, "invalid user_id")
+		return respondError(ctx, errInvalidUserID)
 	}
 	if userID == "" {
-		return ctx.String(http.StatusBadRequest, "missing user_id")
+		return respondError(ctx, errMissingParameter("user_id"))
 	}
 
-	// クエリパラメータを正しい順序で構築して署名検証を行う
-	dataEncoded := strings.ReplaceAll(url.QueryEscape(params.Data), "+", "%20")
-	userIdEncoded := strings.ReplaceAll(url.QueryEscape(params.UserId), "+", "%20")
-	signingStr := "data=" + dataEncoded + "&user_id=" + userIdEncoded
-
 	// 署名検証
-	if !verifySignature(signingStr, params.Sig, generateUserSecretV4(userID)) {
-		return ctx.String(http.StatusUnauthorized, "invalid signature")
+	if !h.checkSignature(ctx, userID, saveSigningStringV4(params.Data, params.UserId), params.Sig, generateUserSecretV4(userID)) {
+		return respondError(ctx, errInvalidSignature)
+	}
+
+	// 利用停止中のユーザー（設定で拒否する場合のみ）
+	if err := h.checkBanned(ctx.Request().Context(), userID); err != nil {
+		return respondError(ctx, err)
 	}
 
 	// JSON 部分をパース
-	sd, err := domain.ParseSaveData(params.Data)
+	sd, warnings, err := domain.ParseSaveData(params.Data, h.saveSchemaMode)
+	h.reportSaveWarnings(ctx, warnings, err)
 	if err != nil {
-		return ctx.String(http.StatusBadRequest, err.Error())
+		return respondError(ctx, err)
 	}
 
 	// 重複チェック
 	exists, err := h.repo.ExistsSameSave(ctx.Request().Context(), userID, sd.Playtime)
 	if err != nil {
-		return ctx.String(http.StatusInternalServerError, err.Error())
+		return respondError(ctx, err)
 	}
 	if exists {
-		return ctx.String(http.StatusConflict, "duplicate save data")
+		return respondError(ctx, errDuplicateSave)
 	}
 
 	// 保存（v2_save_data に保存し、v3_user_latest_save_data を更新）
 	sd.UserId = userID
 	if err := h.repo.InsertSaveV4(ctx.Request().Context(), sd); err != nil {
-		return ctx.String(http.StatusInternalServerError, err.Error())
+		return respondError(ctx, err)
 	}
+	h.events.Publish(ctx.Request().Context(), domain.SaveIngested{Save: sd})
 
 	return ctx.JSON(http.StatusOK, "success")
 }
@@ -72,24 +72,20 @@ func (h *Handler) GetV4Data(ctx echo.Context, params models.GetV4DataParams) err
 func (h *Handler) GetV4DataVerify(ctx echo.Context, params models.GetV4DataVerifyParams) error {
 	userID, err := decodeUserIDParam(params.UserId)
 	if err != nil {
-		return ctx.String(http.StatusBadRequest, "invalid user_id")
+		return respondError(ctx, errInvalidUserID)
 	}
 	if userID == "" {
-		return ctx.String(http.StatusBadRequest, "missing user_id")
+		return respondError(ctx, errMissingParameter("user_id"))
 	}
 	if params.Data == "" {
-		return ctx.String(http.StatusBadRequest, "missing data")
+		return respondError(ctx, errMissingParameter("data"))
 	}
 	if params.Sig == "" {
-		return ctx.String(http.StatusBadRequest, "missing signature")
+		return respondError(ctx, errMissingParameter("sig"))
 	}
 
-	dataEncoded := strings.ReplaceAll(url.QueryEscape(params.Data), "+", "%20")
-	userIdEncoded := strings.ReplaceAll(url.QueryEscape(params.UserId), "+", "%20")
-	signingStr := "data=" + dataEncoded + "&user_id=" + userIdEncoded
-
-	if !verifySignature(signingStr, params.Sig, generateUserSecretV4(userID)) {
-		return ctx.String(http.StatusUnauthorized, "invalid signature")
+	if !h.checkSignature(ctx, userID, saveSigningStringV4(params.Data, params.UserId), params.Sig, generateUserSecretV4(userID)) {
+		return respondError(ctx, errInvalidSignature)
 	}
 
 	return ctx.JSON(http.StatusOK, models.SignatureVerifyResponse{Valid: true})
@@ -103,18 +99,18 @@ func (h *Handler) GetV4UsersUserIdData(
 ) error {
 	decodedUserID, err := decodeUserIDParam(userId)
 	if err != nil {
-		return ctx.String(http.StatusBadRequest, "invalid user_id")
+		return respondError(ctx, errInvalidUserID)
 	}
 	if decodedUserID == "" {
-		return ctx.String(http.StatusBadRequest, "missing user_id")
+		return respondError(ctx, errMissingParameter("user_id"))
 	}
 
 	// 署名必須
 	if params.Sig == "" {
-		return ctx.String(http.StatusBadRequest, "missing signature")
+		return respondError(ctx, errMissingParameter("sig"))
 	}
-	if !verifyUserSignatureV4(userId, decodedUserID, params.Sig) {
-		return ctx.String(http.StatusUnauthorized, "invalid signature")
+	if !h.checkUserSignatureV4(ctx, userId, decodedUserID, params.Sig) {
+		return respondError(ctx, errInvalidSignature)
 	}
 
 	// 最新セーブデータ取得（v2_save_data を参照）
@@ -124,7 +120,7 @@ func (h *Handler) GetV4UsersUserIdData(
 			sd, err = h.repo.GetLatestSave(ctx.Request().Context(), userId)
 		}
 		if err != nil {
-			return ctx.String(http.StatusNotFound, err.Error())
+			return respondError(ctx, err)
 		}
 	}
 
@@ -132,7 +128,7 @@ func (h *Handler) GetV4UsersUserIdData(
 	model := sd.ToModel()
 	signed, err := buildSignedSaveData(model)
 	if err != nil {
-		return ctx.String(http.StatusInternalServerError, err.Error())
+		return respondError(ctx, err)
 	}
 	return ctx.JSON(http.StatusOK, signed)
 }
@@ -145,17 +141,17 @@ func (h *Handler) GetV4UsersUserIdDataVerify(
 ) error {
 	decodedUserID, err := decodeUserIDParam(userId)
 	if err != nil {
-		return ctx.String(http.StatusBadRequest, "invalid user_id")
+		return respondError(ctx, errInvalidUserID)
 	}
 	if decodedUserID == "" {
-		return ctx.String(http.StatusBadRequest, "missing user_id")
+		return respondError(ctx, errMissingParameter("user_id"))
 	}
 
 	if params.Sig == "" {
-		return ctx.String(http.StatusBadRequest, "missing signature")
+		return respondError(ctx, errMissingParameter("sig"))
 	}
-	if !verifyUserSignatureV4(userId, decodedUserID, params.Sig) {
-		return ctx.String(http.StatusUnauthorized, "invalid signature")
+	if !h.checkUserSignatureV4(ctx, userId, decodedUserID, params.Sig) {
+		return respondError(ctx, errInvalidSignature)
 	}
 
 	return ctx.JSON(http.StatusOK, models.SignatureVerifyResponse{Valid: true})
@@ -164,22 +160,23 @@ func (h *Handler) GetV4UsersUserIdDataVerify(
 // GetV4Statistics は v4 エンドポイントで最適化された統計データを返す
 func (h *Handler) GetV4Statistics(ctx echo.Context) error {
 	// キャッシュから取得
-	stats, err := h.statisticsCacheV4.Get(ctx.Request().Context(), statisticsCacheV4Key)
+	snap, err := cachedGet(ctx.Request().Context(), h.statisticsCacheV4, "statistics_v4", statisticsCacheV4Key)
 	if err != nil {
-		return ctx.String(http.StatusInternalServerError, err.Error())
+		return respondError(ctx, err)
 	}
+	h.observeStatistics(ctx.Request().Context(), snap)
 
-	return ctx.JSON(http.StatusOK, stats)
+	return writeSnapshot(ctx, snap, statisticsCacheV4TTL)
 }
 
 // GetV4AchievementsRates は v4 エンドポイントで実績取得率を返す
 func (h *Handler) GetV4AchievementsRates(ctx echo.Context) error {
-	rates, err := h.achievementRatesCache.Get(ctx.Request().Context(), achievementRatesCacheKey)
+	snap, err := cachedGet(ctx.Request().Context(), h.achievementRatesCache, "achievement_rates", achievementRatesCacheKey)
 	if err != nil {
-		return ctx.String(http.StatusInternalServerError, err.Error())
+		return respondError(ctx, err)
 	}
 
-	return ctx.JSON(http.StatusOK, rates)
+	return writeSnapshot(ctx, snap, achievementRatesCacheTTL)
 }
 
 // GetV4UsersUserIdSaves は v4 エンドポイントでユーザーのセーブ履歴を返す
@@ -190,18 +187,18 @@ func (h *Handler) GetV4UsersUserIdSaves(
 ) error {
 	decodedUserID, err := decodeUserIDParam(userId)
 	if err != nil {
-		return ctx.String(http.StatusBadRequest, "invalid user_id")
+		return respondError(ctx, errInvalidUserID)
 	}
 	if decodedUserID == "" {
-		return ctx.String(http.StatusBadRequest, "missing user_id")
+		return respondError(ctx, errMissingParameter("user_id"))
 	}
 
 	// 署名必須
 	if params.Sig == "" {
-		return ctx.String(http.StatusBadRequest, "missing signature")
+		return respondError(ctx, errMissingParameter("sig"))
 	}
-	if !verifyUserSignatureV4(userId, decodedUserID, params.Sig) {
-		return ctx.String(http.StatusUnauthorized, "invalid signature")
+	if !h.checkUserSignatureV4(ctx, userId, decodedUserID, params.Sig) {
+		return respondError(ctx, errInvalidSignature)
 	}
 
 	// limit/before の整形
@@ -218,12 +215,12 @@ func (h *Handler) GetV4UsersUserIdSaves(
 
 	entries, hasMore, err := h.repo.GetSaveHistory(ctx.Request().Context(), decodedUserID, limit, params.Before)
 	if err != nil {
-		return ctx.String(http.StatusInternalServerError, err.Error())
+		return respondError(ctx, err)
 	}
 	if len(entries) == 0 && userId != "" && userId != decodedUserID {
 		entries, hasMore, err = h.repo.GetSaveHistory(ctx.Request().Context(), userId, limit, params.Before)
 		if err != nil {
-			return ctx.String(http.StatusInternalServerError, err.Error())
+			return respondError(ctx, err)
 		}
 	}
 
@@ -245,17 +242,17 @@ func (h *Handler) GetV4UsersUserIdAchievementsHistory(
 ) error {
 	decodedUserID, err := decodeUserIDParam(userId)
 	if err != nil {
-		return ctx.String(http.StatusBadRequest, "invalid user_id")
+		return respondError(ctx, errInvalidUserID)
 	}
 	if decodedUserID == "" {
-		return ctx.String(http.StatusBadRequest, "missing user_id")
+		return respondError(ctx, errMissingParameter("user_id"))
 	}
 
 	if params.Sig == "" {
-		return ctx.String(http.StatusBadRequest, "missing signature")
+		return respondError(ctx, errMissingParameter("sig"))
 	}
-	if !verifyUserSignatureV4(userId, decodedUserID, params.Sig) {
-		return ctx.String(http.StatusUnauthorized, "invalid signature")
+	if !h.checkUserSignatureV4(ctx, userId, decodedUserID, params.Sig) {
+		return respondError(ctx, errInvalidSignature)
 	}
 
 	limit := 500
@@ -271,12 +268,12 @@ func (h *Handler) GetV4UsersUserIdAchievementsHistory(
 
 	entries, total, err := h.repo.GetAchievementUnlockHistory(ctx.Request().Context(), decodedUserID, limit)
 	if err != nil {
-		return ctx.String(http.StatusInternalServerError, err.Error())
+		return respondError(ctx, err)
 	}
 	if total == 0 && len(entries) == 0 && userId != "" && userId != decodedUserID {
 		entries, total, err = h.repo.GetAchievementUnlockHistory(ctx.Request().Context(), userId, limit)
 		if err != nil {
-			return ctx.String(http.StatusInternalServerError, err.Error())
+			return respondError(ctx, err)
 		}
 	}
 
@@ -303,12 +300,12 @@ func (h *Handler) GetV4StatisticsMedalsTimeseries(ctx echo.Context, params model
 		days = 180
 	}
 
-	resp, err := h.medalTimeseriesCache.Get(ctx.Request().Context(), strconv.Itoa(days))
+	snap, err := cachedGet(ctx.Request().Context(), h.medalTimeseriesCache, "medal_timeseries", strconv.Itoa(days))
 	if err != nil {
-		return ctx.String(http.StatusInternalServerError, err.Error())
+		return respondError(ctx, err)
 	}
 
-	return ctx.JSON(http.StatusOK, resp)
+	return writeSnapshot(ctx, snap, medalTimeseriesCacheTTL)
 }
 
 // GetV4StatisticsSavesActivity はセーブ投稿の時間別推移を返す
@@ -324,12 +321,37 @@ func (h *Handler) GetV4StatisticsSavesActivity(ctx echo.Context, params models.G
 		hours = 720
 	}
 
-	resp, err := h.saveActivityCache.Get(ctx.Request().Context(), strconv.Itoa(hours))
+	snap, err := cachedGet(ctx.Request().Context(), h.saveActivityCache, "save_activity", strconv.Itoa(hours))
 	if err != nil {
-		return ctx.String(http.StatusInternalServerError, err.Error())
+		return respondError(ctx, err)
 	}
 
-	return ctx.JSON(http.StatusOK, resp)
+	return writeSnapshot(ctx, snap, saveActivityCacheTTL)
+}
+
+// GetV4RankingsMetric は指標 1 つ分のランキングを返す（指標は domain.RankedMetrics に登録されたもの）
+func (h *Handler) GetV4RankingsMetric(ctx echo.Context, metric string, params models.GetV4RankingsMetricParams) error {
+	if _, ok := domain.LookupRankedMetric(metric); !ok {
+		return respondError(ctx, errUnknownMetric(metric))
+	}
+
+	limit := 100
+	if params.Limit != nil {
+		limit = *params.Limit
+	}
+	if limit < 1 {
+		limit = 1
+	}
+	if limit > 1000 {
+		limit = 1000
+	}
+
+	snap, err := cachedGet(ctx.Request().Context(), h.metricRankingCache, "metric_ranking", metric+":"+strconv.Itoa(limit))
+	if err != nil {
+		return respondError(ctx, err)
+	}
+
+	return writeSnapshot(ctx, snap, metricRankingCacheTTL)
 }
 
 // generateUserSecretV4 は v4 用のユーザーシークレットを生成する
@@ -75,6 +75,10 @@ type stubRepo struct {
 
 	creditAllDistribution    *models.CreditAllDistributionResponse
 	creditAllDistributionErr error
+
+	metricRanking      []models.RankingEntry
+	metricRankingErr   error
+	metricRankingCalls []string
 }
 
 func (s *stubRepo) GetRankings(ctx context.Context, sortBy string, limit int) ([]models.GameData, error) {
@@ -113,6 +117,11 @@ func (s *stubRepo) GetCreditAllDistribution(ctx context.Context) (*models.Credit
 	return s.creditAllDistribution, s.creditAllDistributionErr
 }
 
+func (s *stubRepo) GetRankingV4(ctx context.Context, metric string, limit int) ([]models.RankingEntry, error) {
+	s.metricRankingCalls = append(s.metricRankingCalls, metric+":"+strconv.Itoa(limit))
+	return s.metricRanking, s.metricRankingErr
+}
+
 func (s *stubRepo) ExistsSameSave(ctx context.Context, userID string, playtime int64) (bool, error) {
 	return s.existsSameSave, s.existsErr
 }
@@ -202,6 +211,10 @@ func (r *flowRepo) GetCreditAllDistribution(ctx context.Context) (*models.Credit
 	return &models.CreditAllDistributionResponse{}, nil
 }
 
+func (r *flowRepo) GetRankingV4(ctx context.Context, metric string, limit int) ([]models.RankingEntry, error) {
+	return []models.RankingEntry{}, nil
+}
+
 func (r *flowRepo) ExistsSameSave(ctx context.Context, userID string, playtime int64) (bool, error) {
 	for _, sd := range r.saves {
 		if sd.UserId == userID && sd.Playtime == playtime {
@@ -324,9 +337,7 @@ func TestGetV4Data_Duplicate(t *testing.T) {
 	rec := httptest.NewRecorder()
 	e.ServeHTTP(rec, req)
 
-	if rec.Code != http.StatusConflict {
-		t.Fatalf("status: got %d body=%s", rec.Code, rec.Body.String())
-	}
+	assertErrorResponse(t, rec, http.StatusConflict, models.DUPLICATESAVE)
 	if repo.insertedSave != nil {
 		t.Fatalf("InsertSaveV4 should not be called on duplicate")
 	}
@@ -345,9 +356,7 @@ func TestGetV4Data_InvalidSignature(t *testing.T) {
 	rec := httptest.NewRecorder()
 	e.ServeHTTP(rec, req)
 
-	if rec.Code != http.StatusUnauthorized {
-		t.Fatalf("status: got %d body=%s", rec.Code, rec.Body.String())
-	}
+	assertErrorResponse(t, rec, http.StatusUnauthorized, models.INVALIDSIGNATURE)
 }
 
 func TestGetV4Data_ParseError(t *testing.T) {
@@ -367,9 +376,7 @@ func TestGetV4Data_ParseError(t *testing.T) {
 	rec := httptest.NewRecorder()
 	e.ServeHTTP(rec, req)
 
-	if rec.Code != http.StatusBadRequest {
-		t.Fatalf("status: got %d body=%s", rec.Code, rec.Body.String())
-	}
+	assertErrorResponse(t, rec, http.StatusBadRequest, models.INVALIDSAVEDATA)
 }
 
 func TestGetV4DataVerify(t *testing.T) {
@@ -454,6 +461,43 @@ func TestGetV4UsersUserIdData_Success(t *testing.T) {
 	}
 }
 
+// サーバーが知らないキーは保存され、ロードでそのまま返る
+func TestV4Data_UnknownKeysRoundTrip(t *testing.T) {
+	setTestSecrets(t)
+	repo := &stubRepo{}
+	e := newTestServer(t, repo)
+
+	rec := postSave(e, "user-1", `{"version":22,"playtime":100,"zz_new":{"b":[1,2]},"zz_big":12345678901234567890}`)
+	if rec.Code != http.StatusOK || repo.insertedSave == nil {
+		t.Fatalf("save: %d %s", rec.Code, rec.Body.String())
+	}
+	repo.latestSave = repo.insertedSave
+
+	q := url.Values{}
+	q.Set("sig", makeLoadSig("user-1"))
+	req := httptest.NewRequest(http.MethodGet, "/v4/users/"+base64.RawURLEncoding.EncodeToString([]byte("user-1"))+"/data?"+q.Encode(), nil)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("load: %d %s", rec.Code, rec.Body.String())
+	}
+	var resp models.SignedSaveData
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(resp.Data)
+	if err != nil {
+		t.Fatalf("decode data: %v", err)
+	}
+	var loaded map[string]json.RawMessage
+	if err := json.Unmarshal(decoded, &loaded); err != nil {
+		t.Fatalf("unmarshal data: %v", err)
+	}
+	if string(loaded["zz_new"]) != `{"b":[1,2]}` || string(loaded["zz_big"]) != `12345678901234567890` || string(loaded["playtime"]) != `100` {
+		t.Fatalf("loaded save: %s", decoded)
+	}
+}
+
 func TestGetV4UsersUserIdData_FallbackToRawUserID(t *testing.T) {
 	setTestSecrets(t)
 	rawUserID := "dekapu_debug"
@@ -501,9 +545,7 @@ func TestGetV4UsersUserIdData_MissingSignature(t *testing.T) {
 	rec := httptest.NewRecorder()
 	e.ServeHTTP(rec, req)
 
-	if rec.Code != http.StatusBadRequest {
-		t.Fatalf("status: got %d body=%s", rec.Code, rec.Body.String())
-	}
+	assertErrorResponse(t, rec, http.StatusBadRequest, models.MISSINGPARAMETER)
 }
 
 func TestGetV4UsersUserIdData_InvalidSignature(t *testing.T) {
@@ -515,9 +557,7 @@ func TestGetV4UsersUserIdData_InvalidSignature(t *testing.T) {
 	rec := httptest.NewRecorder()
 	e.ServeHTTP(rec, req)
 
-	if rec.Code != http.StatusUnauthorized {
-		t.Fatalf("status: got %d body=%s", rec.Code, rec.Body.String())
-	}
+	assertErrorResponse(t, rec, http.StatusUnauthorized, models.INVALIDSIGNATURE)
 }
 
 func TestGetV4UsersUserIdDataVerify(t *testing.T) {
@@ -704,6 +744,58 @@ func TestGetV4StatisticsSavesActivity_HoursClamp(t *testing.T) {
 	}
 }
 
+func TestGetV4RankingsMetric(t *testing.T) {
+	setTestSecrets(t)
+	userID, value := "user-1", int64(42)
+	repo := &stubRepo{
+		metricRanking: []models.RankingEntry{{UserId: &userID, Value: &value}},
+	}
+	e := newTestServer(t, repo)
+
+	req := httptest.NewRequest(http.MethodGet, "/v4/rankings/cpm_max", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d body=%s", rec.Code, rec.Body.String())
+	}
+	if len(repo.metricRankingCalls) != 1 || repo.metricRankingCalls[0] != "cpm_max:100" {
+		t.Fatalf("default limit: got %#v", repo.metricRankingCalls)
+	}
+	var got models.RankingV4
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if got.Metric != "cpm_max" || len(got.Entries) != 1 || *got.Entries[0].Value != 42 {
+		t.Fatalf("body: got %+v", got)
+	}
+
+	// 同じ指標・件数はキャッシュから返す
+	req = httptest.NewRequest(http.MethodGet, "/v4/rankings/cpm_max?limit=100", nil)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || len(repo.metricRankingCalls) != 1 {
+		t.Fatalf("cached: status %d calls %#v", rec.Code, repo.metricRankingCalls)
+	}
+}
+
+func TestGetV4RankingsMetric_UnknownMetric(t *testing.T) {
+	setTestSecrets(t)
+	repo := &stubRepo{}
+	e := newTestServer(t, repo)
+
+	req := httptest.NewRequest(http.MethodGet, "/v4/rankings/credit_all", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status: got %d body=%s", rec.Code, rec.Body.String())
+	}
+	if len(repo.metricRankingCalls) != 0 {
+		t.Fatalf("repository should not be called: %#v", repo.metricRankingCalls)
+	}
+}
+
 func TestV4Flow_SaveThenLoadThenStatistics(t *testing.T) {
 	setTestSecrets(t)
 	repo := &flowRepo{}
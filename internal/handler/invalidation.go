@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"math"
+
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/domain"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/openapi/models"
+)
+
+// statisticsRankingLimit は GetStatisticsV4 の各ランキングの上限件数
+const statisticsRankingLimit = 1000
+
+// rankingIndex はキャッシュ中の統計について、各ランキングの掲載ユーザーと足切り値を保持する。
+type rankingIndex struct {
+	// etag は元にした統計スナップショットの ETag
+	etag     string
+	rankings []rankingMembers
+}
+
+type rankingMembers struct {
+	metric    domain.RankedMetric
+	members   map[string]float64
+	full      bool
+	threshold float64
+}
+
+func newRankingIndex(stats *models.StatisticsV4, etag string) *rankingIndex {
+	idx := &rankingIndex{etag: etag}
+	for _, metric := range domain.RankedMetrics {
+		m := rankingMembers{metric: metric, members: make(map[string]float64)}
+		if field := domain.RankingEntries(stats, metric.Name); field != nil && *field != nil {
+			entries := *field
+			for _, e := range *entries {
+				if e.UserId == nil || e.Value == nil {
+					continue
+				}
+				m.members[*e.UserId] = float64(*e.Value)
+				m.threshold = float64(*e.Value)
+			}
+			m.full = len(*entries) >= statisticsRankingLimit
+		}
+		idx.rankings = append(idx.rankings, m)
+	}
+	return idx
+}
+
+// affects は sd の保存によっていずれかのランキングの内容が変わるかを返す。
+// 掲載中のユーザーの値が変わった / 非公開になった、または足切り値を超えた場合に true。
+func (idx *rankingIndex) affects(sd *domain.SaveData) bool {
+	projection := domain.ProjectRanking(sd)
+	for _, m := range idx.rankings {
+		current, listed := m.members[sd.UserId]
+		if sd.HideRecord != 0 {
+			if listed {
+				return true
+			}
+			continue
+		}
+		value := m.metric.Value(projection)
+		if listed {
+			// 統計の値は整数に丸めて返しているので、丸めた値が変わったときだけ見た目が変わる
+			if math.Round(value) != current {
+				return true
+			}
+			continue
+		}
+		if !m.full || m.metric.Better(value, m.threshold) {
+			return true
+		}
+	}
+	return false
+}
+
+// observeStatistics は返そうとしている統計スナップショットから rankingIndex を作り直す。
+// 共有キャッシュでは他のレプリカが作った統計を返すことがあるので、ローダーではなく読み出し側で追従する。
+func (h *Handler) observeStatistics(ctx context.Context, snap *snapshot) {
+	if idx := h.rankingIndex.Load(); idx != nil && idx.etag == snap.etag {
+		return
+	}
+	var stats models.StatisticsV4
+	if err := json.Unmarshal(snap.body, &stats); err != nil {
+		slog.WarnContext(ctx, "failed to index statistics for invalidation", "error", err)
+		return
+	}
+	h.rankingIndex.Store(newRankingIndex(&stats, snap.etag))
+}
+
+// invalidateOnSave は保存されたセーブがキャッシュ中の統計を変える場合だけ、該当キャッシュを古いとみなす。
+func (h *Handler) invalidateOnSave(ctx context.Context, ev domain.SaveIngested) {
+	if ev.Save == nil {
+		return
+	}
+	if idx := h.rankingIndex.Load(); idx != nil && idx.affects(ev.Save) {
+		slog.DebugContext(ctx, "statistics cache marked stale", "user_id", ev.Save.UserId)
+		h.statisticsCacheV4.MarkStale(statisticsCacheV4Key)
+	}
+	if len(ev.Save.UnlockedAchievements) > 0 {
+		slog.DebugContext(ctx, "achievement rates cache marked stale", "user_id", ev.Save.UserId)
+		h.achievementRatesCache.MarkStale(achievementRatesCacheKey)
+	}
+}
@@ -0,0 +1,318 @@
+package handler
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/labstack/echo/v4"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/domain"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/pkg/cache"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/openapi"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/openapi/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// fullRanking は listed を上位に置き、残りを lowest の値で埋めた満員のランキングを作る
+func fullRanking(lowest int64, listed map[string]int64) *[]models.RankingEntry {
+	list := make([]models.RankingEntry, 0, statisticsRankingLimit)
+	for userID, value := range listed {
+		userID, value := userID, value
+		list = append(list, models.RankingEntry{UserId: &userID, Value: &value})
+	}
+	for i := len(list); i < statisticsRankingLimit; i++ {
+		userID, value := fmt.Sprintf("filler-%d", i), lowest
+		list = append(list, models.RankingEntry{UserId: &userID, Value: &value})
+	}
+	return &list
+}
+
+// saturatedStatistics は全ランキングを満員・高い足切りにした統計を返す（個別のランキングは呼び出し側で上書きする）
+func saturatedStatistics() *models.StatisticsV4 {
+	stats := &models.StatisticsV4{}
+	for _, m := range domain.RankedMetrics {
+		*domain.RankingEntries(stats, m.Name) = fullRanking(1_000_000, nil)
+	}
+	return stats
+}
+
+func TestRankingIndexAffects(t *testing.T) {
+	stats := saturatedStatistics()
+	stats.CpmMax = fullRanking(100, map[string]int64{"leader": 500})
+	stats.SpUse = fullRanking(5, map[string]int64{"leader": 10})
+	idx := newRankingIndex(stats, "")
+
+	cases := []struct {
+		name string
+		save domain.SaveData
+		want bool
+	}{
+		{"below threshold", domain.SaveData{UserId: "newcomer", CpMMax: 50}, false},
+		{"above threshold", domain.SaveData{UserId: "newcomer", CpMMax: 150}, true},
+		{"listed value unchanged", domain.SaveData{UserId: "leader", CpMMax: 500.4, SpUse: 10}, false},
+		{"listed value changed", domain.SaveData{UserId: "leader", CpMMax: 600, SpUse: 10}, true},
+		{"listed user hides record", domain.SaveData{UserId: "leader", CpMMax: 500, SpUse: 10, HideRecord: 1}, true},
+		{"unlisted user hides record", domain.SaveData{UserId: "newcomer", CpMMax: 900, HideRecord: 1}, false},
+	}
+	for _, tc := range cases {
+		if got := idx.affects(&tc.save); got != tc.want {
+			t.Errorf("%s: got %v want %v", tc.name, got, tc.want)
+		}
+	}
+
+	if !newRankingIndex(&models.StatisticsV4{}, "").affects(&domain.SaveData{UserId: "anyone"}) {
+		t.Errorf("a ranking with free slots should always be affected")
+	}
+}
+
+func TestStaleCache_ThrottlesRefresh(t *testing.T) {
+	var mu sync.Mutex
+	loads := 0
+	c, err := cache.New(cache.Backend{}, "test", func(ctx context.Context, key string) (int, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		loads++
+		return loads, nil
+	}, time.Hour, time.Hour)
+	if err != nil {
+		t.Fatalf("cache.New: %v", err)
+	}
+	sc := newStaleCache(c, 50*time.Millisecond)
+	if _, err := sc.Get(context.Background(), "k"); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	for i := 0; i < 10; i++ {
+		sc.MarkStale("k")
+	}
+	time.Sleep(200 * time.Millisecond)
+	for i := 0; i < 10; i++ {
+		sc.MarkStale("k")
+	}
+	time.Sleep(200 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	// 各回とも「最初の無効化で即時に 1 回 + 間隔内の残りをまとめて 1 回」、それに初回の取得を足して 5 回
+	if loads != 5 {
+		t.Fatalf("refresh was not throttled: %d loads", loads)
+	}
+}
+
+// blockingCache は Invalidate が ctx の期限まで戻らないキャッシュ（応答しない Redis の代わり）
+type blockingCache struct {
+	cache.Cache[int]
+	invalidated chan error
+}
+
+func (c *blockingCache) Invalidate(ctx context.Context, key string) {
+	<-ctx.Done()
+	c.invalidated <- ctx.Err()
+}
+
+func TestStaleCache_MarkStaleDoesNotWaitForBackend(t *testing.T) {
+	c := &blockingCache{invalidated: make(chan error, 2)}
+	sc := newStaleCache[int](c, 0)
+	sc.invalidateTimeout = 50 * time.Millisecond
+
+	start := time.Now()
+	sc.MarkStale("a")
+	sc.MarkStale("b")
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Fatalf("MarkStale waited for the backend: %v", elapsed)
+	}
+	for range 2 {
+		select {
+		case err := <-c.invalidated:
+			if err != context.DeadlineExceeded {
+				t.Fatalf("invalidation ended with %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("the invalidation has no deadline")
+		}
+	}
+}
+
+func TestKeyedCache_InvalidateAllReloadsEveryKey(t *testing.T) {
+	var mu sync.Mutex
+	loads := map[string]int{}
+	c, err := cache.New(cache.Backend{}, "test", func(ctx context.Context, key string) (int, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		loads[key]++
+		return loads[key], nil
+	}, time.Hour, time.Hour)
+	if err != nil {
+		t.Fatalf("cache.New: %v", err)
+	}
+	kc := newKeyedCache(c, 2)
+	ctx := context.Background()
+	for _, key := range []string{"a", "b", "c"} {
+		if _, err := kc.Get(ctx, key); err != nil {
+			t.Fatalf("Get %s: %v", key, err)
+		}
+	}
+
+	kc.InvalidateAll(ctx)
+	deadline := time.Now().Add(time.Second)
+	for {
+		a, _ := kc.Get(ctx, "a")
+		b, _ := kc.Get(ctx, "b")
+		if a == 2 && b == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("keys were not reloaded: a=%d b=%d", a, b)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	// maxKeys を超えたキーは覚えないので、TTL まで作り直さない
+	if v, _ := kc.Get(ctx, "c"); v != 1 {
+		t.Fatalf("c: got %d", v)
+	}
+}
+
+// syncStatsRepo は裏での再取得と並行に読まれても安全なように統計の呼び出しを数える
+type syncStatsRepo struct {
+	*stubRepo
+	mu    sync.Mutex
+	stats *models.StatisticsV4
+	calls int
+	rates int
+}
+
+func (r *syncStatsRepo) GetStatisticsV4(ctx context.Context) (*models.StatisticsV4, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls++
+	return r.stats, nil
+}
+
+func (r *syncStatsRepo) GetAchievementRates(ctx context.Context) (*models.AchievementRates, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rates++
+	return &models.AchievementRates{}, nil
+}
+
+func (r *syncStatsRepo) InsertSaveV4(ctx context.Context, sd *domain.SaveData) error {
+	if len(sd.LAchieve) > 0 {
+		sd.UnlockedAchievements = sd.LAchieve
+	}
+	return r.stubRepo.InsertSaveV4(ctx, sd)
+}
+
+func (r *syncStatsRepo) counts() (int, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.calls, r.rates
+}
+
+func postV4Save(t *testing.T, e *echo.Echo, userID string, payload string) {
+	t.Helper()
+	data := base64.RawURLEncoding.EncodeToString([]byte(payload))
+	q := url.Values{}
+	q.Set("data", data)
+	q.Set("user_id", userID)
+	q.Set("sig", makeV4SaveSig(userID, userID, data))
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v4/data?"+q.Encode(), nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("save: got %d body=%s", rec.Code, rec.Body.String())
+	}
+}
+
+func waitForCounts(t *testing.T, repo *syncStatsRepo, stats, rates int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		s, r := repo.counts()
+		if s == stats && r == rates {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("loads: got stats=%d rates=%d want stats=%d rates=%d", s, r, stats, rates)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestGetV4Data_InvalidatesAffectedCaches(t *testing.T) {
+	setTestSecrets(t)
+	repo := &syncStatsRepo{
+		stubRepo: &stubRepo{},
+		stats:    saturatedStatistics(),
+	}
+	repo.stats.CpmMax = fullRanking(100, map[string]int64{"leader": 500})
+	h := New(repo)
+	h.statisticsCacheV4.minRefreshInterval = 0
+	h.achievementRatesCache.minRefreshInterval = 0
+	e := echo.New()
+	openapi.RegisterHandlers(e, h)
+
+	for _, path := range []string{"/v4/statistics", "/v4/achievements/rates"} {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s: got %d", path, rec.Code)
+		}
+	}
+	waitForCounts(t, repo, 1, 1)
+
+	// ランキングにも実績にも影響しないセーブでは作り直さない
+	postV4Save(t, e, "user-2", `{"playtime":1,"cpm_max":50}`)
+	time.Sleep(20 * time.Millisecond)
+	waitForCounts(t, repo, 1, 1)
+
+	// 足切りを超えたら統計だけ作り直す
+	postV4Save(t, e, "user-2", `{"playtime":2,"cpm_max":150}`)
+	waitForCounts(t, repo, 2, 1)
+
+	// 新しい実績が解除されたら取得率を作り直す
+	postV4Save(t, e, "user-2", `{"playtime":3,"cpm_max":50,"l_achieve":["a1"]}`)
+	waitForCounts(t, repo, 2, 2)
+}
+
+func TestSharedCacheBackend_LoadsOnceAcrossReplicas(t *testing.T) {
+	setTestSecrets(t)
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	backend := cache.Backend{Redis: client, Prefix: "test:"}
+
+	repo := &syncStatsRepo{stubRepo: &stubRepo{}, stats: saturatedStatistics()}
+	repo.stats.CpmMax = fullRanking(100, map[string]int64{"leader": 500})
+
+	replicas := make([]*echo.Echo, 2)
+	for i := range replicas {
+		h := New(repo, WithCacheBackend(backend))
+		h.statisticsCacheV4.minRefreshInterval = 0
+		replicas[i] = echo.New()
+		openapi.RegisterHandlers(replicas[i], h)
+	}
+
+	var etags []string
+	for _, e := range replicas {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v4/statistics", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("statistics: got %d", rec.Code)
+		}
+		etags = append(etags, rec.Header().Get("ETag"))
+	}
+	if etags[0] != etags[1] {
+		t.Fatalf("replicas served different statistics: %v", etags)
+	}
+	waitForCounts(t, repo, 1, 0)
+
+	// どちらのレプリカへの保存でも、共有キャッシュが作り直される
+	postV4Save(t, replicas[1], "user-2", `{"playtime":1,"cpm_max":150}`)
+	waitForCounts(t, repo, 2, 0)
+}
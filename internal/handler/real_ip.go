@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+
+	"github.com/labstack/echo/v4"
+)
+
+// NewIPExtractor は c.RealIP()（リクエストログ・監査ログ・署名バイパスの記録）が返す送信元 IP の決め方を
+// trustedProxies（TRUSTED_PROXY_CIDRS）から作る。
+// 空なら nil で、echo 既定どおり X-Forwarded-For / X-Real-IP をそのまま使う（偽装できるので記録にだけ使う）。
+// 指定した場合は、X-Forwarded-For を右からたどり、指定範囲のプロキシを経由した分だけ信用する。
+func NewIPExtractor(trustedProxies string) (echo.IPExtractor, error) {
+	networks, err := parseNetworks(trustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("trusted proxy %w", err)
+	}
+	if len(networks) == 0 {
+		return nil, nil
+	}
+	return trustedIPExtractor(networks), nil
+}
+
+// trustedIPExtractor は networks のプロキシを経由した分だけ X-Forwarded-For を信用する。
+// networks が空ならヘッダーを無視して接続元のアドレスを使う。
+func trustedIPExtractor(networks []netip.Prefix) echo.IPExtractor {
+	if len(networks) == 0 {
+		return echo.ExtractIPDirect()
+	}
+	// echo 既定のループバック・リンクローカル・プライベートアドレスの信用は外し、指定範囲だけにする
+	opts := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, network := range networks {
+		opts = append(opts, echo.TrustIPRange(prefixToIPNet(network)))
+	}
+	return echo.ExtractIPFromXFFHeader(opts...)
+}
+
+func prefixToIPNet(prefix netip.Prefix) *net.IPNet {
+	return &net.IPNet{
+		IP:   prefix.Addr().AsSlice(),
+		Mask: net.CIDRMask(prefix.Bits(), prefix.Addr().BitLen()),
+	}
+}
@@ -1,14 +1,46 @@
 package handler
 
 import (
+	"log/slog"
+	"net/http"
+	"regexp"
 	"strings"
 	"unicode/utf8"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/pkg/logging"
 )
 
-func RequestLogMiddleware(baseURL string) echo.MiddlewareFunc {
+// errorReasonKey は 4xx/5xx 応答の理由をリクエストログへ渡すための echo.Context キー
+const errorReasonKey = "error_reason"
+
+// requestIDPattern はクライアント指定の X-Request-Id として受け付ける形式。
+// ログやレスポンスヘッダにそのまま載るので、それ以外の値は捨てて採番し直す。
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestIDMiddleware は X-Request-Id を採番（またはクライアント指定値を採用）してレスポンスヘッダに返し、
+// リクエストの context に格納してリポジトリ呼び出しまで伝播させる。
+func RequestIDMiddleware() echo.MiddlewareFunc {
+	requestID := middleware.RequestIDWithConfig(middleware.RequestIDConfig{
+		RequestIDHandler: func(c echo.Context, id string) {
+			req := c.Request()
+			c.SetRequest(req.WithContext(logging.WithRequestID(req.Context(), id)))
+		},
+	})
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		withID := requestID(next)
+		return func(c echo.Context) error {
+			header := c.Request().Header
+			if id := header.Get(echo.HeaderXRequestID); id != "" && !requestIDPattern.MatchString(id) {
+				header.Del(echo.HeaderXRequestID)
+			}
+			return withID(c)
+		}
+	}
+}
+
+func RequestLogMiddleware(baseURL string, logger *slog.Logger) echo.MiddlewareFunc {
 	skippedPaths := map[string]struct{}{
 		strings.TrimRight(baseURL, "/") + "/ping":          {},
 		strings.TrimRight(baseURL, "/") + "/v4/statistics": {},
@@ -16,15 +48,17 @@ func RequestLogMiddleware(baseURL string) echo.MiddlewareFunc {
 
 	return middleware.RequestLoggerWithConfig(middleware.RequestLoggerConfig{
 		LogStatus:    true,
+		LogLatency:   true,
+		LogMethod:    true,
 		LogRemoteIP:  true,
 		LogUserAgent: true,
+		LogError:     true,
 		LogValuesFunc: func(c echo.Context, v middleware.RequestLoggerValues) error {
-			if shouldSkipRequestLog(c, skippedPaths) {
+			// 正常系の高頻度エンドポイントは省略するが、エラーは必ず残す
+			if v.Status < http.StatusBadRequest && shouldSkipRequestLog(c, skippedPaths) {
 				return nil
 			}
 
-			api := formatAPIPath(baseURL, c)
-			userName := requestLogUserName(c)
 			userAgent := v.UserAgent
 			if userAgent == "" {
 				userAgent = "-"
@@ -34,12 +68,43 @@ func RequestLogMiddleware(baseURL string) echo.MiddlewareFunc {
 				ip = "-"
 			}
 
-			c.Logger().Infof("[%s] status=%d user_name=%q user_agent=%q ip=%s", api, v.Status, userName, userAgent, ip)
+			attrs := []slog.Attr{
+				slog.String("api", formatAPIPath(baseURL, c)),
+				slog.String("method", v.Method),
+				slog.Int("status", v.Status),
+				slog.Int64("latency_ms", v.Latency.Milliseconds()),
+				slog.String("user_name", requestLogUserName(c)),
+				slog.String("user_agent", userAgent),
+				slog.String("ip", ip),
+			}
+
+			level := slog.LevelInfo
+			switch {
+			case v.Status >= http.StatusInternalServerError:
+				level = slog.LevelError
+			case v.Status >= http.StatusBadRequest:
+				level = slog.LevelWarn
+			}
+			if level > slog.LevelInfo {
+				attrs = append(attrs, slog.String("reason", requestLogErrorReason(c, v)))
+			}
+
+			logger.LogAttrs(c.Request().Context(), level, "request", attrs...)
 			return nil
 		},
 	})
 }
 
+func requestLogErrorReason(c echo.Context, v middleware.RequestLoggerValues) string {
+	if reason, ok := c.Get(errorReasonKey).(string); ok && reason != "" {
+		return reason
+	}
+	if v.Error != nil {
+		return v.Error.Error()
+	}
+	return http.StatusText(v.Status)
+}
+
 func shouldSkipRequestLog(c echo.Context, skippedPaths map[string]struct{}) bool {
 	if _, ok := skippedPaths[c.Request().URL.Path]; ok {
 		return true
@@ -2,22 +2,44 @@ package handler
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 
 	"github.com/labstack/echo/v4"
-	echolog "github.com/labstack/gommon/log"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/pkg/logging"
 )
 
+func newRequestLogTestServer(logs *bytes.Buffer) *echo.Echo {
+	e := echo.New()
+	e.Use(RequestIDMiddleware())
+	e.Use(RequestLogMiddleware("/api", logging.New(logs, slog.LevelInfo)))
+	return e
+}
+
+func decodeLogLines(t *testing.T, logs *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var entries []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("log line is not JSON: %v\n%s", err, line)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
 func TestRequestLogMiddlewareFormatsAndRedacts(t *testing.T) {
 	var logs bytes.Buffer
-	e := echo.New()
-	e.Logger.SetOutput(&logs)
-	e.Logger.SetLevel(echolog.INFO)
-	e.Logger.SetHeader("")
-	e.Use(RequestLogMiddleware("/api"))
+	e := newRequestLogTestServer(&logs)
 	e.GET("/api/v4/users/:user_id/data", func(c echo.Context) error {
 		return c.String(http.StatusOK, "ok")
 	})
@@ -29,23 +51,36 @@ func TestRequestLogMiddlewareFormatsAndRedacts(t *testing.T) {
 
 	e.ServeHTTP(rec, req)
 
-	got := logs.String()
-	want := `[/v4/users/{user_id}/data] status=200 user_name="pikachu0310" user_agent="unity" ip=192.1.1.1`
-	if !strings.Contains(got, want) {
-		t.Fatalf("log output missing expected entry:\nwant contains: %s\ngot: %s", want, got)
+	entries := decodeLogLines(t, &logs)
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 log entry, got %d: %s", len(entries), logs.String())
 	}
-	if strings.Contains(got, "payload") || strings.Contains(got, "secret") {
-		t.Fatalf("log output leaked query data or signature: %s", got)
+	entry := entries[0]
+	want := map[string]any{
+		"level":      "INFO",
+		"api":        "/v4/users/{user_id}/data",
+		"method":     http.MethodGet,
+		"status":     float64(http.StatusOK),
+		"user_name":  "pikachu0310",
+		"user_agent": "unity",
+		"ip":         "192.1.1.1",
+	}
+	for key, value := range want {
+		if entry[key] != value {
+			t.Fatalf("%s: got %#v want %#v (entry=%v)", key, entry[key], value, entry)
+		}
+	}
+	if entry["request_id"] == nil || entry["request_id"] != rec.Header().Get(echo.HeaderXRequestID) {
+		t.Fatalf("request_id: got %#v header=%q", entry["request_id"], rec.Header().Get(echo.HeaderXRequestID))
+	}
+	if strings.Contains(logs.String(), "payload") || strings.Contains(logs.String(), "secret") {
+		t.Fatalf("log output leaked query data or signature: %s", logs.String())
 	}
 }
 
 func TestRequestLogMiddlewareSkipsV4Statistics(t *testing.T) {
 	var logs bytes.Buffer
-	e := echo.New()
-	e.Logger.SetOutput(&logs)
-	e.Logger.SetLevel(echolog.INFO)
-	e.Logger.SetHeader("")
-	e.Use(RequestLogMiddleware("/api"))
+	e := newRequestLogTestServer(&logs)
 	e.GET("/api/v4/statistics", func(c echo.Context) error {
 		return c.String(http.StatusOK, "ok")
 	})
@@ -62,11 +97,7 @@ func TestRequestLogMiddlewareSkipsV4Statistics(t *testing.T) {
 
 func TestRequestLogMiddlewareSkipsPing(t *testing.T) {
 	var logs bytes.Buffer
-	e := echo.New()
-	e.Logger.SetOutput(&logs)
-	e.Logger.SetLevel(echolog.INFO)
-	e.Logger.SetHeader("")
-	e.Use(RequestLogMiddleware("/api"))
+	e := newRequestLogTestServer(&logs)
 	e.GET("/api/ping", func(c echo.Context) error {
 		return c.String(http.StatusOK, "pong")
 	})
@@ -80,3 +111,85 @@ func TestRequestLogMiddlewareSkipsPing(t *testing.T) {
 		t.Fatalf("expected no log output for /ping, got: %s", logs.String())
 	}
 }
+
+func TestRequestLogMiddlewareLogsErrorReason(t *testing.T) {
+	var logs bytes.Buffer
+	e := newRequestLogTestServer(&logs)
+	e.GET("/api/v4/statistics", func(c echo.Context) error {
+		return respondError(c, errors.New("db is down"))
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v4/statistics", nil)
+	req.Header.Set(echo.HeaderXRequestID, "req-123")
+	rec := httptest.NewRecorder()
+
+	e.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get(echo.HeaderXRequestID); got != "req-123" {
+		t.Fatalf("X-Request-Id: got %q", got)
+	}
+	entries := decodeLogLines(t, &logs)
+	if len(entries) != 1 {
+		t.Fatalf("expected error to be logged even for skipped path, got: %s", logs.String())
+	}
+	entry := entries[0]
+	if entry["level"] != "ERROR" || entry["reason"] != "db is down" || entry["request_id"] != "req-123" {
+		t.Fatalf("unexpected entry: %v", entry)
+	}
+	if strings.Contains(rec.Body.String(), "db is down") {
+		t.Fatalf("internal error leaked to response: %s", rec.Body.String())
+	}
+}
+
+func TestRequestIDMiddlewareRejectsMalformedIDs(t *testing.T) {
+	var logs bytes.Buffer
+	e := newRequestLogTestServer(&logs)
+	e.GET("/api/ping", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+
+	for _, id := range []string{"bad id", "id\x1b[31m", "日本語", strings.Repeat("a", 65)} {
+		req := httptest.NewRequest(http.MethodGet, "/api/ping", nil)
+		req.Header.Set(echo.HeaderXRequestID, id)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		got := rec.Header().Get(echo.HeaderXRequestID)
+		if got == id || !requestIDPattern.MatchString(got) {
+			t.Fatalf("X-Request-Id %q: got %q", id, got)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/ping", nil)
+	req.Header.Set(echo.HeaderXRequestID, "trace.01-AB_"+strings.Repeat("z", 52))
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if got := rec.Header().Get(echo.HeaderXRequestID); got != req.Header.Get(echo.HeaderXRequestID) {
+		t.Fatalf("a well-formed id is kept: got %q", got)
+	}
+}
+
+func TestRequestLogMiddlewareLogsClientErrorAsWarn(t *testing.T) {
+	var logs bytes.Buffer
+	e := newRequestLogTestServer(&logs)
+	e.GET("/api/v4/data", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid format for parameter limit")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v4/data", nil)
+	rec := httptest.NewRecorder()
+
+	e.ServeHTTP(rec, req)
+
+	entries := decodeLogLines(t, &logs)
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 log entry, got: %s", logs.String())
+	}
+	entry := entries[0]
+	if entry["level"] != "WARN" || entry["status"] != float64(http.StatusBadRequest) {
+		t.Fatalf("unexpected entry: %v", entry)
+	}
+	if reason, _ := entry["reason"].(string); !strings.Contains(reason, "Invalid format for parameter limit") {
+		t.Fatalf("reason: got %#v", entry["reason"])
+	}
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/labstack/echo/v4"
+	oapimiddleware "github.com/oapi-codegen/echo-middleware"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/openapi/models"
+)
+
+var openapiPathParamPattern = regexp.MustCompile(`\{([^}]+)\}`)
+
+// RequestValidatorMiddleware は openapi.yaml に基づいてリクエストパラメータを検証する。
+// swagger.Servers には baseURL が設定されている前提（/api/v4/... のまま照合する）。
+// spec にないルート（/openapi.yaml, /swagger など）と、410 を返すだけの deprecated なルートは検証しない。
+func RequestValidatorMiddleware(swagger *openapi3.T, baseURL string) echo.MiddlewareFunc {
+	validated := make(map[string]struct{})
+	for path, item := range swagger.Paths.Map() {
+		echoPath := strings.TrimRight(baseURL, "/") + openapiPathParamPattern.ReplaceAllString(path, ":$1")
+		for method, op := range item.Operations() {
+			if op.Deprecated {
+				continue
+			}
+			validated[method+" "+echoPath] = struct{}{}
+		}
+	}
+
+	return oapimiddleware.OapiRequestValidatorWithOptions(swagger, &oapimiddleware.Options{
+		SilenceServersWarning: true,
+		Skipper: func(c echo.Context) bool {
+			_, ok := validated[c.Request().Method+" "+c.Path()]
+			return !ok
+		},
+		ErrorHandler: func(c echo.Context, err *echo.HTTPError) error {
+			return respondError(c, validationError(err))
+		},
+	})
+}
+
+// validationError は検証エラーを、どのパラメータが不正かを details に含めた apiError に変換する。
+func validationError(err *echo.HTTPError) error {
+	var reqErr *openapi3filter.RequestError
+	if !errors.As(err.Internal, &reqErr) || reqErr.Parameter == nil {
+		return err
+	}
+
+	param := reqErr.Parameter
+	details := map[string]interface{}{
+		"parameter": param.Name,
+		"in":        param.In,
+	}
+	if errors.Is(reqErr.Err, openapi3filter.ErrInvalidRequired) {
+		return &apiError{
+			status:  http.StatusBadRequest,
+			code:    models.MISSINGPARAMETER,
+			message: "missing " + param.Name,
+			details: details,
+		}
+	}
+
+	reason := reqErr.Reason
+	var schemaErr *openapi3.SchemaError
+	if errors.As(reqErr.Err, &schemaErr) {
+		reason = schemaErr.Reason
+	} else if reason == "" && reqErr.Err != nil {
+		reason = reqErr.Err.Error()
+	}
+	if reason != "" {
+		details["reason"] = reason
+	}
+	return &apiError{
+		status:  http.StatusBadRequest,
+		code:    models.INVALIDPARAMETER,
+		message: "invalid parameter " + param.Name,
+		details: details,
+	}
+}
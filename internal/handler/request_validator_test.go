@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/labstack/echo/v4"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/openapi"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/openapi/models"
+)
+
+// newValidatedTestServer は main.go と同じく /api 配下に登録し、検証ミドルウェアを有効にしたサーバーを返す。
+func newValidatedTestServer(t *testing.T, repo Repository) *echo.Echo {
+	t.Helper()
+	swagger, err := openapi.GetSwagger()
+	if err != nil {
+		t.Fatalf("load swagger: %v", err)
+	}
+	swagger.Servers = openapi3.Servers{&openapi3.Server{URL: "/api"}}
+
+	e := echo.New()
+	e.HTTPErrorHandler = HTTPErrorHandler
+	e.Use(RequestValidatorMiddleware(swagger, "/api"))
+	openapi.RegisterHandlersWithBaseURL(e, New(repo), "/api")
+	e.GET("/api/openapi.yaml", func(c echo.Context) error {
+		return c.Blob(http.StatusOK, "application/yaml", []byte("openapi: 3.0.0"))
+	})
+	return e
+}
+
+func TestRequestValidator_RejectsInvalidParameters(t *testing.T) {
+	setTestSecrets(t)
+	cases := []struct {
+		name      string
+		target    string
+		code      models.ErrorCode
+		parameter string
+	}{
+		{"days below minimum", "/api/v4/statistics/medals/timeseries?days=0", models.INVALIDPARAMETER, "days"},
+		{"days above maximum", "/api/v4/statistics/medals/timeseries?days=181", models.INVALIDPARAMETER, "days"},
+		{"days not integer", "/api/v4/statistics/medals/timeseries?days=abc", models.INVALIDPARAMETER, "days"},
+		{"hours above maximum", "/api/v4/statistics/saves/activity?hours=721", models.INVALIDPARAMETER, "hours"},
+		{"limit above maximum", "/api/v4/users/user-1/saves?sig=x&limit=101", models.INVALIDPARAMETER, "limit"},
+		{"achievements limit below minimum", "/api/v4/users/user-1/achievements/history?sig=x&limit=0", models.INVALIDPARAMETER, "limit"},
+		{"before not date-time", "/api/v4/users/user-1/saves?sig=x&before=yesterday", models.INVALIDPARAMETER, "before"},
+		{"missing sig", "/api/v4/users/user-1/data", models.MISSINGPARAMETER, "sig"},
+		{"missing data", "/api/v4/data?user_id=user-1&sig=x", models.MISSINGPARAMETER, "data"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &stubRepo{}
+			e := newValidatedTestServer(t, repo)
+
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.target, nil))
+
+			body := assertErrorResponse(t, rec, http.StatusBadRequest, tc.code)
+			if body.Details == nil || (*body.Details)["parameter"] != tc.parameter {
+				t.Fatalf("details: got %v", body.Details)
+			}
+			if repo.medalTimeseriesCalls != nil || repo.saveActivityCalls != nil || repo.saveHistoryLimit != 0 {
+				t.Fatalf("repository should not be called for rejected request")
+			}
+		})
+	}
+}
+
+func TestRequestValidator_AcceptsValidRequest(t *testing.T) {
+	setTestSecrets(t)
+	repo := &stubRepo{medalTimeseries: &models.MedalTimeseriesResponse{}}
+	e := newValidatedTestServer(t, repo)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v4/statistics/medals/timeseries?days=180", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d body=%s", rec.Code, rec.Body.String())
+	}
+	if len(repo.medalTimeseriesCalls) != 1 || repo.medalTimeseriesCalls[0] != 180 {
+		t.Fatalf("unexpected calls: %v", repo.medalTimeseriesCalls)
+	}
+}
+
+func TestRequestValidator_SkipsDeprecatedAndCustomRoutes(t *testing.T) {
+	setTestSecrets(t)
+	e := newValidatedTestServer(t, &stubRepo{})
+
+	// 410 を返すだけの旧ルートは enum 外の値でも検証せずに 410 を返す
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/rankings?sort=unknown", nil))
+	if rec.Code != http.StatusGone {
+		t.Fatalf("deprecated route: got %d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/openapi.yaml", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("openapi.yaml: got %d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v4/unknown", nil))
+	assertErrorResponse(t, rec, http.StatusNotFound, models.NOTFOUND)
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/domain"
+)
+
+const (
+	// saveWarningsHeader はセーブのスキーマ警告（"missing_key:medal_get" のカンマ区切り）をクライアントへ返すヘッダ
+	saveWarningsHeader = "X-Save-Warnings"
+	// saveWarningsHeaderMaxLen は saveWarningsHeader の最大長。超える分は省き、末尾に "+省いた数" を付ける
+	saveWarningsHeaderMaxLen = 1024
+	// saveWarningMaxUnknownKeys は unknown_key をキーごとに数える最大のキー数。
+	// キーはクライアントが自由に送れるので、それ以降の新しいキーはまとめて数える
+	saveWarningMaxUnknownKeys = 100
+)
+
+// WithSaveSchemaMode はセーブのスキーマ検査の方式を指定する（既定は lenient）。
+// strict ではセーブの version にあるべきキーが欠けている・後の version のキーがあるセーブを 400 で拒否する。
+func WithSaveSchemaMode(mode domain.SaveSchemaMode) Option {
+	return func(h *Handler) { h.saveSchemaMode = mode }
+}
+
+// saveWarningStats は起動してから受け付けたセーブのスキーマ警告の集計（プロセスごと、再起動で 0 に戻る）
+type saveWarningStats struct {
+	mu       sync.Mutex
+	since    time.Time
+	saves    int64 // 警告のあったセーブの数
+	rejected int64 // strict で拒否したセーブの数
+	counts   map[domain.SaveWarning]int64
+	// unknownKeys は counts にある unknown_key のキー数、otherUnknown はそれ以外の unknown_key の件数
+	unknownKeys  int
+	otherUnknown int64
+}
+
+func newSaveWarningStats() *saveWarningStats {
+	return &saveWarningStats{since: time.Now(), counts: make(map[domain.SaveWarning]int64)}
+}
+
+func (s *saveWarningStats) record(warnings []domain.SaveWarning, rejected bool) {
+	if len(warnings) == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.saves++
+	if rejected {
+		s.rejected++
+	}
+	for _, w := range warnings {
+		if _, ok := s.counts[w]; !ok && w.Kind == domain.WarningUnknownKey {
+			if s.unknownKeys >= saveWarningMaxUnknownKeys {
+				s.otherUnknown++
+				continue
+			}
+			s.unknownKeys++
+		}
+		s.counts[w]++
+	}
+}
+
+// saveWarningCount は警告の種類・キーごとの件数
+type saveWarningCount struct {
+	domain.SaveWarning
+	Count int64 `json:"count"`
+}
+
+// saveSchemaStats は GET /admin/stats/save-schema の応答
+type saveSchemaStats struct {
+	Mode             domain.SaveSchemaMode `json:"mode"`
+	Since            time.Time             `json:"since"`
+	SavesWithWarning int64                 `json:"saves_with_warnings"`
+	RejectedSaves    int64                 `json:"rejected_saves"`
+	Warnings         []saveWarningCount    `json:"warnings"`
+	// OtherUnknownKeys は saveWarningMaxUnknownKeys を超えた unknown_key の件数（キーごとには数えない）
+	OtherUnknownKeys int64 `json:"other_unknown_keys"`
+}
+
+// snapshot は件数の多い順（同数ならキー順）に並べた集計を返す
+func (s *saveWarningStats) snapshot(mode domain.SaveSchemaMode) saveSchemaStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := saveSchemaStats{
+		Mode:             mode,
+		Since:            s.since,
+		SavesWithWarning: s.saves,
+		RejectedSaves:    s.rejected,
+		Warnings:         make([]saveWarningCount, 0, len(s.counts)),
+		OtherUnknownKeys: s.otherUnknown,
+	}
+	for w, n := range s.counts {
+		stats.Warnings = append(stats.Warnings, saveWarningCount{SaveWarning: w, Count: n})
+	}
+	slices.SortFunc(stats.Warnings, func(a, b saveWarningCount) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Kind, b.Kind), cmp.Compare(a.Key, b.Key))
+	})
+	return stats
+}
+
+// reportSaveWarnings は警告を集計し、あればヘッダでクライアントへ返す
+func (h *Handler) reportSaveWarnings(ctx echo.Context, warnings []domain.SaveWarning, parseErr error) {
+	if len(warnings) == 0 {
+		return
+	}
+	h.saveWarnings.record(warnings, parseErr != nil)
+	ctx.Response().Header().Set(saveWarningsHeader, saveWarningsHeaderValue(warnings))
+}
+
+// saveWarningsHeaderValue は警告をカンマ区切りにする。saveWarningsHeaderMaxLen に収まらない分は省き、
+// 末尾に "+省いた数" を付ける
+func saveWarningsHeaderValue(warnings []domain.SaveWarning) string {
+	var b strings.Builder
+	for i, w := range warnings {
+		value := w.String()
+		if i > 0 {
+			value = "," + value
+		}
+		// 後ろに省略の印が付いても収まるか（最後の警告なら印は要らない）
+		rest := len(warnings) - i - 1
+		reserve := 0
+		if rest > 0 {
+			reserve = len(",+") + len(strconv.Itoa(rest))
+		}
+		if b.Len()+len(value)+reserve > saveWarningsHeaderMaxLen {
+			if b.Len() > 0 {
+				b.WriteString(",")
+			}
+			b.WriteString("+" + strconv.Itoa(len(warnings)-i))
+			return b.String()
+		}
+		b.WriteString(value)
+	}
+	return b.String()
+}
+
+// adminGetSaveSchemaStats は起動してからのセーブのスキーマ警告の件数を返す
+func (h *Handler) adminGetSaveSchemaStats(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.saveWarnings.snapshot(h.saveSchemaMode))
+}
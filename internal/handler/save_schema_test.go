@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/domain"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/openapi"
+)
+
+// newSchemaServer はセーブ API と管理 API を同じ Handler で立てる
+func newSchemaServer(t *testing.T, mode domain.SaveSchemaMode) (*echo.Echo, *stubRepo) {
+	t.Helper()
+	setTestSecrets(t)
+	tokens, err := ParseAdminTokens("viewer:read-stats:" + testViewerToken)
+	if err != nil {
+		t.Fatalf("ParseAdminTokens: %v", err)
+	}
+	repo := &adminRepo{stubRepo: &stubRepo{}}
+	h := New(repo, WithAdminTokens(tokens), WithSaveSchemaMode(mode))
+	e := echo.New()
+	openapi.RegisterHandlers(e, h)
+	if !h.RegisterAdminRoutes(e.Group("/admin")) {
+		t.Fatalf("admin routes were not registered")
+	}
+	return e, repo.stubRepo
+}
+
+func postSave(e *echo.Echo, userID, payload string) *httptest.ResponseRecorder {
+	data := base64.RawURLEncoding.EncodeToString([]byte(payload))
+	q := url.Values{}
+	q.Set("data", data)
+	q.Set("user_id", userID)
+	q.Set("sig", makeV4SaveSig(userID, userID, data))
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v4/data?"+q.Encode(), nil))
+	return rec
+}
+
+// version 4 のセーブに v6 で追加された sp_use と、どこにも定義の無いキーを混ぜる
+const mismatchedSave = `{"playtime":100,"credit_all":500,"version":4,"sp_use":1,"zz_new":2}`
+
+func TestGetV4Data_SchemaLenient(t *testing.T) {
+	e, repo := newSchemaServer(t, domain.SchemaLenient)
+
+	rec := postSave(e, "user-1", mismatchedSave)
+	if rec.Code != http.StatusOK || repo.insertedSave == nil {
+		t.Fatalf("lenient should store the save: %d %s", rec.Code, rec.Body.String())
+	}
+	warnings := strings.Split(rec.Header().Get(saveWarningsHeader), ",")
+	for _, want := range []string{"missing_key:credit", "newer_key:sp_use", "unknown_key:zz_new"} {
+		if !slices.Contains(warnings, want) {
+			t.Fatalf("%s: %v", saveWarningsHeader, warnings)
+		}
+	}
+
+	rec = postSave(e, "user-2", `{"legacy":0,"version":0,"playtime":1,"credit":"0","credit_all":"0","medal_in":0,"ball_get":0,`+
+		`"ball_chain":0,"slot_start":0,"slot_hit":0,"slot_getfev":0,"sqr_step":0,"jack_get":0,"jack_startmax":0,"buy_shbi":0}`)
+	if rec.Code != http.StatusOK || rec.Header().Get(saveWarningsHeader) != "" {
+		t.Fatalf("a save matching its version has no warnings: %d %q", rec.Code, rec.Header().Get(saveWarningsHeader))
+	}
+
+	rec = adminRequest(e, http.MethodGet, "/admin/stats/save-schema", testViewerToken, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("stats: %d %s", rec.Code, rec.Body.String())
+	}
+	var stats saveSchemaStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("stats body: %v", err)
+	}
+	if stats.Mode != domain.SchemaLenient || stats.SavesWithWarning != 1 || stats.RejectedSaves != 0 {
+		t.Fatalf("stats: %+v", stats)
+	}
+	if len(stats.Warnings) != len(warnings) || stats.Warnings[0].Count != 1 {
+		t.Fatalf("warning counts: %+v", stats.Warnings)
+	}
+}
+
+func TestGetV4Data_SchemaStrict(t *testing.T) {
+	e, repo := newSchemaServer(t, domain.SchemaStrict)
+
+	rec := postSave(e, "user-1", mismatchedSave)
+	body := assertErrorResponse(t, rec, http.StatusBadRequest, "INVALID_SAVE_DATA")
+	if repo.insertedSave != nil {
+		t.Fatalf("strict should not store the save")
+	}
+	if body.Details == nil {
+		t.Fatalf("details are missing")
+	}
+	violations, _ := (*body.Details)["violations"].([]interface{})
+	if !slices.Contains(violations, interface{}("newer_key:sp_use")) || slices.Contains(violations, interface{}("unknown_key:zz_new")) {
+		t.Fatalf("violations: %v", violations)
+	}
+	if !strings.Contains(rec.Header().Get(saveWarningsHeader), "unknown_key:zz_new") {
+		t.Fatalf("a rejected save still reports its warnings: %q", rec.Header().Get(saveWarningsHeader))
+	}
+
+	rec = adminRequest(e, http.MethodGet, "/admin/stats/save-schema", testViewerToken, "")
+	var stats saveSchemaStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("stats body: %v", err)
+	}
+	if stats.Mode != domain.SchemaStrict || stats.SavesWithWarning != 1 || stats.RejectedSaves != 1 {
+		t.Fatalf("stats: %+v", stats)
+	}
+}
+
+func TestGetV4Data_SchemaWarningsBounded(t *testing.T) {
+	e, _ := newSchemaServer(t, domain.SchemaLenient)
+
+	// 知らないキーはクライアントが自由に送れるので、集計もヘッダも上限で打ち切る
+	fields := []string{`"playtime":100`, `"credit_all":500`, `"version":4`}
+	const unknownKeys = saveWarningMaxUnknownKeys + 50
+	for i := range unknownKeys {
+		fields = append(fields, `"zz_unknown_key_`+strconv.Itoa(i)+`":1`)
+	}
+	rec := postSave(e, "user-1", "{"+strings.Join(fields, ",")+"}")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("save: %d %s", rec.Code, rec.Body.String())
+	}
+	header := rec.Header().Get(saveWarningsHeader)
+	values := strings.Split(header, ",")
+	omitted, err := strconv.Atoi(strings.TrimPrefix(values[len(values)-1], "+"))
+	if len(header) > saveWarningsHeaderMaxLen || err != nil || len(values)-1+omitted < unknownKeys {
+		t.Fatalf("%s (%d bytes): %q", saveWarningsHeader, len(header), header)
+	}
+
+	rec = adminRequest(e, http.MethodGet, "/admin/stats/save-schema", testViewerToken, "")
+	var stats saveSchemaStats
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("stats body: %v", err)
+	}
+	var counted int
+	for _, w := range stats.Warnings {
+		if w.Kind == domain.WarningUnknownKey {
+			counted++
+		}
+	}
+	if counted != saveWarningMaxUnknownKeys || stats.OtherUnknownKeys != unknownKeys-saveWarningMaxUnknownKeys {
+		t.Fatalf("unknown keys counted %d, other %d", counted, stats.OtherUnknownKeys)
+	}
+}
+
+func TestSaveWarningsHeaderValue(t *testing.T) {
+	short := []domain.SaveWarning{{Kind: domain.WarningMissingKey, Key: "medal_get"}, {Kind: domain.WarningUnknownKey, Key: "foo"}}
+	if got := saveWarningsHeaderValue(short); got != "missing_key:medal_get,unknown_key:foo" {
+		t.Fatalf("short: %q", got)
+	}
+	long := []domain.SaveWarning{{Kind: domain.WarningUnknownKey, Key: strings.Repeat("x", saveWarningsHeaderMaxLen)}, short[0]}
+	if got := saveWarningsHeaderValue(long); got != "+2" {
+		t.Fatalf("long: %q", got)
+	}
+}
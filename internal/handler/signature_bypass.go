@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"context"
+	"crypto/hmac"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/domain"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/pkg/config"
+)
+
+// SignatureBypassPolicy は署名バイパストークンを受け付けるルートと送信元。
+// ゼロ値は制限なし（トークンが一致すれば全ルート・全送信元で受け付ける）。
+type SignatureBypassPolicy struct {
+	baseURL  string
+	routes   map[string]bool
+	networks []netip.Prefix
+	// extractIP は networks と照らす送信元 IP の決め方（nil なら接続元のアドレス）
+	extractIP echo.IPExtractor
+}
+
+// ParseSignatureBypassPolicy は SIGNATURE_BYPASS_ROUTES / SIGNATURE_BYPASS_CIDRS の値を解釈する。
+// routes は baseURL 以下のパス（/v4/users/{user_id}/data または /v4/users/:user_id/data）、
+// cidrs は CIDR か IP アドレスで、どちらもカンマ区切り。空なら制限しない。
+// 送信元は c.RealIP() ではなく、trustedProxies（TRUSTED_PROXY_CIDRS）のプロキシを経由した X-Forwarded-For か、
+// 空なら接続元のアドレスで判定する（ヘッダーを偽装して許可範囲を名乗れないようにする）。
+func ParseSignatureBypassPolicy(baseURL, routes, cidrs, trustedProxies string) (SignatureBypassPolicy, error) {
+	p := SignatureBypassPolicy{baseURL: baseURL}
+	for _, route := range strings.Split(routes, ",") {
+		route = strings.TrimSpace(route)
+		if route == "" {
+			continue
+		}
+		if !strings.HasPrefix(route, "/") {
+			return SignatureBypassPolicy{}, fmt.Errorf("signature bypass route %q: must start with /", route)
+		}
+		if p.routes == nil {
+			p.routes = make(map[string]bool)
+		}
+		p.routes[strings.ReplaceAll(route, ":user_id", "{user_id}")] = true
+	}
+	networks, err := parseNetworks(cidrs)
+	if err != nil {
+		return SignatureBypassPolicy{}, fmt.Errorf("signature bypass %w", err)
+	}
+	p.networks = networks
+	proxies, err := parseNetworks(trustedProxies)
+	if err != nil {
+		return SignatureBypassPolicy{}, fmt.Errorf("trusted proxy %w", err)
+	}
+	p.extractIP = trustedIPExtractor(proxies)
+	return p, nil
+}
+
+// parseNetworks はカンマ区切りの CIDR / IP アドレスを解釈する。IP アドレスはそれだけを含む範囲になる。
+func parseNetworks(cidrs string) ([]netip.Prefix, error) {
+	var networks []netip.Prefix
+	for _, cidr := range strings.Split(cidrs, ",") {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			addr, addrErr := netip.ParseAddr(cidr)
+			if addrErr != nil {
+				return nil, fmt.Errorf("CIDR %q: %w", cidr, err)
+			}
+			addr = addr.Unmap()
+			prefix = netip.PrefixFrom(addr, addr.BitLen())
+		}
+		networks = append(networks, prefix.Masked())
+	}
+	return networks, nil
+}
+
+// WithSignatureBypassPolicy はバイパストークンを受け付けるルート・送信元を制限する。
+func WithSignatureBypassPolicy(p SignatureBypassPolicy) Option {
+	return func(h *Handler) { h.bypassPolicy = p }
+}
+
+func (p SignatureBypassPolicy) allowsRoute(route string) bool {
+	return len(p.routes) == 0 || p.routes[route]
+}
+
+// remoteIP は networks と照らす送信元 IP を返す
+func (p SignatureBypassPolicy) remoteIP(req *http.Request) string {
+	if p.extractIP == nil {
+		return echo.ExtractIPDirect()(req)
+	}
+	return p.extractIP(req)
+}
+
+func (p SignatureBypassPolicy) allowsIP(ip string) bool {
+	if len(p.networks) == 0 {
+		return true
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range p.networks {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// SignatureBypassRecorder はバイパストークンの使用を保存するリポジトリ。
+// 対応していないリポジトリ（-memory など）ではバイパストークンを受け付けない。
+type SignatureBypassRecorder interface {
+	InsertSignatureBypass(ctx context.Context, entry *domain.SignatureBypassEntry) error
+}
+
+// bypassSignature は sig がバイパストークンで、ルートと送信元が許可されていれば true を返す。
+// 通したリクエストは記録し、記録できなければ通さない（記録の無いバイパスを作らない）。
+// 記録する送信元は c.RealIP()、送信元の制限は SignatureBypassPolicy.remoteIP で判定する。
+func (h *Handler) bypassSignature(c echo.Context, userID, sig string) bool {
+	token := config.GetSignatureBypassToken()
+	if token == "" || sig == "" || !hmac.Equal([]byte(sig), []byte(token)) {
+		return false
+	}
+
+	ctx := c.Request().Context()
+	entry := &domain.SignatureBypassEntry{
+		Route:    formatAPIPath(h.bypassPolicy.baseURL, c),
+		UserID:   userID,
+		RemoteIP: c.RealIP(),
+	}
+	checkedIP := h.bypassPolicy.remoteIP(c.Request())
+	if !h.bypassPolicy.allowsRoute(entry.Route) || !h.bypassPolicy.allowsIP(checkedIP) {
+		slog.WarnContext(ctx, "signature bypass rejected",
+			"route", entry.Route, "user_id", entry.UserID, "remote_ip", entry.RemoteIP, "checked_ip", checkedIP)
+		return false
+	}
+
+	recorder, ok := h.repo.(SignatureBypassRecorder)
+	if !ok {
+		slog.ErrorContext(ctx, "signature bypass cannot be recorded by this repository; rejecting it",
+			"route", entry.Route, "user_id", entry.UserID)
+		return false
+	}
+	slog.WarnContext(ctx, "signature bypass used",
+		"route", entry.Route, "user_id", entry.UserID, "remote_ip", entry.RemoteIP)
+	if err := recorder.InsertSignatureBypass(context.WithoutCancel(ctx), entry); err != nil {
+		slog.ErrorContext(ctx, "failed to record signature bypass; rejecting it",
+			"route", entry.Route, "user_id", entry.UserID, "error", err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/domain"
+)
+
+// bypassRepo はバイパストークンの使用記録を保持する
+type bypassRepo struct {
+	*stubRepo
+
+	mu        sync.Mutex
+	entries   []domain.SignatureBypassEntry
+	insertErr error
+}
+
+func (r *bypassRepo) InsertSignatureBypass(ctx context.Context, entry *domain.SignatureBypassEntry) error {
+	if r.insertErr != nil {
+		return r.insertErr
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry.ID = int64(len(r.entries) + 1)
+	r.entries = append(r.entries, *entry)
+	return nil
+}
+
+// bypassContext は route に対する remoteIP からのリクエストのコンテキストを作る
+func bypassContext(route, remoteIP string) echo.Context {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = net.JoinHostPort(remoteIP, "12345")
+	c := echo.New().NewContext(req, httptest.NewRecorder())
+	c.SetPath(route)
+	return c
+}
+
+func TestSignatureBypass_RecordsUse(t *testing.T) {
+	t.Setenv("SIGNATURE_BYPASS_TOKEN", "bypass-token")
+	repo := &bypassRepo{stubRepo: &stubRepo{}}
+	policy, err := ParseSignatureBypassPolicy("/api", "", "", "")
+	if err != nil {
+		t.Fatalf("ParseSignatureBypassPolicy: %v", err)
+	}
+	h := New(repo, WithSignatureBypassPolicy(policy))
+
+	if !h.checkSignature(bypassContext("/api/v4/data", "192.0.2.10"), "user-1", "data=test", "bypass-token", []byte("secret")) {
+		t.Fatalf("expected bypass token to pass signature verification")
+	}
+	if !h.checkUserSignatureV4(bypassContext("/api/v4/users/:user_id/data", "192.0.2.11"), "user-2", "user-2", "bypass-token") {
+		t.Fatalf("expected bypass token to pass user signature verification")
+	}
+	if h.checkSignature(bypassContext("/api/v4/data", "192.0.2.10"), "user-1", "data=test", "invalid", []byte("secret")) {
+		t.Fatalf("expected invalid signature to fail")
+	}
+
+	want := []domain.SignatureBypassEntry{
+		{ID: 1, Route: "/v4/data", UserID: "user-1", RemoteIP: "192.0.2.10"},
+		{ID: 2, Route: "/v4/users/{user_id}/data", UserID: "user-2", RemoteIP: "192.0.2.11"},
+	}
+	if len(repo.entries) != len(want) {
+		t.Fatalf("entries: got %+v", repo.entries)
+	}
+	for i := range want {
+		if repo.entries[i] != want[i] {
+			t.Fatalf("entries[%d]: got %+v want %+v", i, repo.entries[i], want[i])
+		}
+	}
+}
+
+func TestSignatureBypass_EmptyTokenDisablesBypass(t *testing.T) {
+	t.Setenv("SIGNATURE_BYPASS_TOKEN", "")
+	repo := &bypassRepo{stubRepo: &stubRepo{}}
+	h := New(repo)
+
+	if h.checkSignature(bypassContext("/v4/data", "192.0.2.10"), "user-1", "data=test", "bypass-token", []byte("secret")) {
+		t.Fatalf("expected bypass to be disabled when token is empty")
+	}
+	if h.checkUserSignatureV4(bypassContext("/v4/users/:user_id/data", "192.0.2.10"), "user-1", "user-1", "bypass-token") {
+		t.Fatalf("expected bypass to be disabled when token is empty")
+	}
+	if len(repo.entries) != 0 {
+		t.Fatalf("nothing should be recorded: %+v", repo.entries)
+	}
+}
+
+func TestSignatureBypass_RestrictedRoutesAndNetworks(t *testing.T) {
+	t.Setenv("SIGNATURE_BYPASS_TOKEN", "bypass-token")
+	repo := &bypassRepo{stubRepo: &stubRepo{}}
+	policy, err := ParseSignatureBypassPolicy("/api", "/v4/users/:user_id/data, /v4/data/verify", "10.0.0.0/8, 2001:db8::1", "")
+	if err != nil {
+		t.Fatalf("ParseSignatureBypassPolicy: %v", err)
+	}
+	h := New(repo, WithSignatureBypassPolicy(policy))
+
+	for _, tc := range []struct {
+		route, ip string
+		allowed   bool
+	}{
+		{"/api/v4/users/:user_id/data", "10.1.2.3", true},
+		{"/api/v4/data/verify", "2001:db8::1", true},
+		{"/api/v4/data", "10.1.2.3", false},
+		{"/api/v4/users/:user_id/data", "192.0.2.10", false},
+		{"/api/v4/users/:user_id/data", "2001:db8::2", false},
+	} {
+		got := h.checkUserSignatureV4(bypassContext(tc.route, tc.ip), "user-1", "user-1", "bypass-token")
+		if got != tc.allowed {
+			t.Errorf("%s from %s: got %v want %v", tc.route, tc.ip, got, tc.allowed)
+		}
+	}
+	if len(repo.entries) != 2 {
+		t.Fatalf("only allowed requests should be recorded: %+v", repo.entries)
+	}
+}
+
+func TestSignatureBypass_ForgedForwardedForIsIgnored(t *testing.T) {
+	t.Setenv("SIGNATURE_BYPASS_TOKEN", "bypass-token")
+	repo := &bypassRepo{stubRepo: &stubRepo{}}
+
+	for _, tc := range []struct {
+		trustedProxies, remoteIP string
+		allowed                  bool
+	}{
+		// プロキシを信用しない設定では、許可外の接続元が X-Forwarded-For で許可範囲を名乗っても通さない
+		{"", "192.0.2.10", false},
+		// 信用するプロキシ経由なら X-Forwarded-For の送信元で判定する
+		{"192.0.2.0/24", "192.0.2.10", true},
+		{"192.0.2.0/24", "198.51.100.7", false},
+	} {
+		policy, err := ParseSignatureBypassPolicy("/api", "", "10.0.0.0/8", tc.trustedProxies)
+		if err != nil {
+			t.Fatalf("ParseSignatureBypassPolicy: %v", err)
+		}
+		h := New(repo, WithSignatureBypassPolicy(policy))
+		extractor, err := NewIPExtractor(tc.trustedProxies)
+		if err != nil {
+			t.Fatalf("NewIPExtractor(%q): %v", tc.trustedProxies, err)
+		}
+		e := echo.New()
+		e.IPExtractor = extractor
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = net.JoinHostPort(tc.remoteIP, "12345")
+		req.Header.Set(echo.HeaderXForwardedFor, "10.1.2.3")
+		req.Header.Set(echo.HeaderXRealIP, "10.1.2.3")
+		c := e.NewContext(req, httptest.NewRecorder())
+		c.SetPath("/api/v4/data")
+
+		if got := h.checkSignature(c, "user-1", "data=test", "bypass-token", []byte("secret")); got != tc.allowed {
+			t.Errorf("trusted %q, from %s: got %v want %v", tc.trustedProxies, tc.remoteIP, got, tc.allowed)
+		}
+	}
+	if len(repo.entries) != 1 || repo.entries[0].RemoteIP != "10.1.2.3" {
+		t.Fatalf("entries: %+v", repo.entries)
+	}
+	if _, err := NewIPExtractor("proxy.example.com"); err == nil {
+		t.Fatalf("expected an error for an invalid trusted proxy")
+	}
+	if _, err := ParseSignatureBypassPolicy("/api", "", "", "proxy.example.com"); err == nil {
+		t.Fatalf("expected an error for an invalid trusted proxy in the policy")
+	}
+}
+
+func TestNewIPExtractor_KeepsForwardedForWithoutTrustedProxies(t *testing.T) {
+	// 信用するプロキシが無くても、ログ・監査の送信元はプロキシではなく X-Forwarded-For のクライアントにする
+	extractor, err := NewIPExtractor("")
+	if err != nil {
+		t.Fatalf("NewIPExtractor: %v", err)
+	}
+	e := echo.New()
+	e.IPExtractor = extractor
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = net.JoinHostPort("192.0.2.10", "12345")
+	req.Header.Set(echo.HeaderXForwardedFor, "198.51.100.7")
+	if got := e.NewContext(req, httptest.NewRecorder()).RealIP(); got != "198.51.100.7" {
+		t.Fatalf("RealIP: %s", got)
+	}
+}
+
+func TestSignatureBypass_RejectedWhenNotRecorded(t *testing.T) {
+	t.Setenv("SIGNATURE_BYPASS_TOKEN", "bypass-token")
+	h := New(&bypassRepo{stubRepo: &stubRepo{}, insertErr: errors.New("db down")})
+
+	if h.checkSignature(bypassContext("/v4/data", "192.0.2.10"), "user-1", "data=test", "bypass-token", []byte("secret")) {
+		t.Fatalf("bypass without a record should be rejected")
+	}
+}
+
+func TestSignatureBypass_RejectedWithoutRecorder(t *testing.T) {
+	t.Setenv("SIGNATURE_BYPASS_TOKEN", "bypass-token")
+	h := New(&stubRepo{})
+
+	if h.checkSignature(bypassContext("/v4/data", "192.0.2.10"), "user-1", "data=test", "bypass-token", []byte("secret")) {
+		t.Fatalf("bypass should be rejected when the repository cannot record it")
+	}
+}
+
+func TestParseSignatureBypassPolicy_Errors(t *testing.T) {
+	for name, spec := range map[string][2]string{
+		"relative route": {"v4/data", ""},
+		"bad CIDR":       {"", "10.0.0.0/33"},
+		"not an address": {"", "example.com"},
+	} {
+		if _, err := ParseSignatureBypassPolicy("/api", spec[0], spec[1], ""); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -4,33 +4,77 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
+	"net/url"
 	"strings"
 
+	"github.com/labstack/echo/v4"
 	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/pkg/config"
 )
 
-func verifySignature(data, sig string, secret []byte) bool {
-	if bypassSignature(sig) {
-		return true
+// checkSignature は data の署名を検証する。バイパストークンは許可されたルート・送信元からのみ受け付け、使用を記録する。
+func (h *Handler) checkSignature(c echo.Context, userID, data, sig string, secret []byte) bool {
+	return h.bypassSignature(c, userID, sig) || verifySignature(data, sig, secret)
+}
+
+// checkUserSignatureV4 は user_id の署名を検証する（バイパストークンの扱いは checkSignature と同じ）。
+func (h *Handler) checkUserSignatureV4(c echo.Context, rawUserID, decodedUserID, sig string) bool {
+	return h.bypassSignature(c, decodedUserID, sig) || verifyUserSignatureV4(rawUserID, decodedUserID, sig)
+}
+
+// saveSigningStringV4 は v4 のセーブの署名対象（クエリの順序とエンコードをクライアントに合わせる）
+func saveSigningStringV4(data, rawUserID string) string {
+	dataEncoded := strings.ReplaceAll(url.QueryEscape(data), "+", "%20")
+	userIDEncoded := strings.ReplaceAll(url.QueryEscape(rawUserID), "+", "%20")
+	return "data=" + dataEncoded + "&user_id=" + userIDEncoded
+}
+
+// SaveSignatureV4 は v4 のセーブ（/v4/data・/v4/data/verify）に期待される署名を返す。
+// rawUserID はクエリに載っていたままの user_id。
+func SaveSignatureV4(rawUserID, data string) (string, error) {
+	userID, err := decodeUserIDParam(rawUserID)
+	if err != nil {
+		return "", err
 	}
+	return signHex(saveSigningStringV4(data, rawUserID), generateUserSecretV4(userID)), nil
+}
+
+// UserSignature は user_id（/v4/users/{user_id}/... の sig）に期待される署名を返す
+func UserSignature(userID string) string {
+	return signHex(userID, []byte(config.GetSecretKeyLoadV2()))
+}
+
+// VerifySaveSignatureV4 は v4 のセーブの署名を検証する（バイパストークンは受け付けない）
+func VerifySaveSignatureV4(rawUserID, data, sig string) (bool, error) {
+	userID, err := decodeUserIDParam(rawUserID)
+	if err != nil {
+		return false, err
+	}
+	return verifySignature(saveSigningStringV4(data, rawUserID), sig, generateUserSecretV4(userID)), nil
+}
+
+// VerifyUserSignatureV4 は user_id の署名を検証する（デコード前後のどちらの署名も受け付ける）
+func VerifyUserSignatureV4(rawUserID, sig string) (bool, error) {
+	userID, err := decodeUserIDParam(rawUserID)
+	if err != nil {
+		return false, err
+	}
+	return verifyUserSignatureV4(rawUserID, userID, sig), nil
+}
+
+func signHex(data string, secret []byte) string {
 	mac := hmac.New(sha256.New, secret)
 	mac.Write([]byte(data))
-	expected := hex.EncodeToString(mac.Sum(nil))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func verifySignature(data, sig string, secret []byte) bool {
+	expected := signHex(data, secret)
 	return hmac.Equal([]byte(expected), []byte(sig))
 }
 
 // 署名検証：sig == HMAC-SHA256( key=<LoadSecret>, msg=userID )
 func verifyUserSignature(userID, sig string) bool {
-	if bypassSignature(sig) {
-		return true
-	}
-	secret := []byte(config.GetSecretKeyLoadV2())
-
-	mac := hmac.New(sha256.New, secret)
-	mac.Write([]byte(userID))
-	expected := hex.EncodeToString(mac.Sum(nil))
-
-	return strings.EqualFold(sig, expected)
+	return strings.EqualFold(sig, UserSignature(userID))
 }
 
 // verifyUserSignatureV4 tries both decoded and raw user_id to keep compatibility with
@@ -44,11 +88,3 @@ func verifyUserSignatureV4(rawUserID, decodedUserID, sig string) bool {
 	}
 	return false
 }
-
-func bypassSignature(sig string) bool {
-	token := config.GetSignatureBypassToken()
-	if token == "" || sig == "" {
-		return false
-	}
-	return hmac.Equal([]byte(sig), []byte(token))
-}
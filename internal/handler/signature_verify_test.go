@@ -26,22 +26,6 @@ func TestSignatureVerifyHMAC(t *testing.T) {
 	}
 }
 
-func TestSignatureVerifyBypass(t *testing.T) {
-	t.Setenv("SIGNATURE_BYPASS_TOKEN", "bypass-token")
-
-	if !verifySignature("data=test", "bypass-token", []byte("secret")) {
-		t.Fatalf("expected bypass token to allow signature verification")
-	}
-}
-
-func TestSignatureVerifyBypassEmptyToken(t *testing.T) {
-	t.Setenv("SIGNATURE_BYPASS_TOKEN", "")
-
-	if verifySignature("data=test", "bypass-token", []byte("secret")) {
-		t.Fatalf("expected bypass to be disabled when token is empty")
-	}
-}
-
 func TestUserSignatureVerifyHMAC(t *testing.T) {
 	t.Setenv("SIGNATURE_BYPASS_TOKEN", "")
 	t.Setenv("LOAD", "load-secret")
@@ -57,22 +41,6 @@ func TestUserSignatureVerifyHMAC(t *testing.T) {
 	}
 }
 
-func TestUserSignatureVerifyBypass(t *testing.T) {
-	t.Setenv("SIGNATURE_BYPASS_TOKEN", "bypass-token")
-
-	if !verifyUserSignature("user-1", "bypass-token") {
-		t.Fatalf("expected bypass token to allow user signature verification")
-	}
-}
-
-func TestUserSignatureVerifyBypassEmptyToken(t *testing.T) {
-	t.Setenv("SIGNATURE_BYPASS_TOKEN", "")
-
-	if verifyUserSignature("user-1", "bypass-token") {
-		t.Fatalf("expected bypass to be disabled when token is empty")
-	}
-}
-
 func TestUserSignatureVerifyV4(t *testing.T) {
 	t.Setenv("SIGNATURE_BYPASS_TOKEN", "")
 	t.Setenv("LOAD", "load-secret")
@@ -88,3 +56,29 @@ func TestUserSignatureVerifyV4(t *testing.T) {
 		t.Fatalf("expected invalid user signature to fail")
 	}
 }
+
+func TestSaveSignatureV4_MatchesClient(t *testing.T) {
+	setTestSecrets(t)
+
+	rawUserID := "user 1"
+	data := `{"playtime":100}`
+	want := makeV4SaveSig(rawUserID, rawUserID, data)
+
+	got, err := SaveSignatureV4(rawUserID, data)
+	if err != nil || got != want {
+		t.Fatalf("SaveSignatureV4 = %q, %v; want %q", got, err, want)
+	}
+	for sig, valid := range map[string]bool{want: true, "invalid": false} {
+		ok, err := VerifySaveSignatureV4(rawUserID, data, sig)
+		if err != nil || ok != valid {
+			t.Errorf("VerifySaveSignatureV4(%q) = %v, %v", sig, ok, err)
+		}
+	}
+
+	if got := UserSignature("user-1"); got != makeLoadSig("user-1") {
+		t.Fatalf("UserSignature = %q", got)
+	}
+	if ok, err := VerifyUserSignatureV4("user-1", makeLoadSig("user-1")); err != nil || !ok {
+		t.Fatalf("VerifyUserSignatureV4 = %v, %v", ok, err)
+	}
+}
@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andybalholm/brotli"
+	"github.com/labstack/echo/v4"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// snapshot はキャッシュに載せる、エンコード済みのレスポンス。
+// ポーリングのたびに再シリアライズ・再圧縮せず、ETag による条件付き GET にも使う。
+type snapshot struct {
+	body         []byte
+	gzipBody     []byte
+	brotliBody   []byte
+	etag         string
+	lastModified time.Time
+	createdAt    time.Time
+}
+
+// snapshotBrotliLevel はキャッシュ生成時の一度きりなので、圧縮率を優先する
+const snapshotBrotliLevel = 9
+
+// newSnapshot は v を JSON にエンコードし、同じストリームから gzip / br の圧縮版も作る。
+// ETag は無圧縮の内容から計算し、圧縮版には符号化ごとの接尾辞を付ける。
+func newSnapshot(ctx context.Context, v any) (_ *snapshot, err error) {
+	_, span := tracing.Start(ctx, "json.encode", attribute.String("type", fmt.Sprintf("%T", v)))
+	defer func() { tracing.End(span, err) }()
+
+	var plain, gzBuf, brBuf bytes.Buffer
+	gz, err := gzip.NewWriterLevel(&gzBuf, gzip.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	br := brotli.NewWriterLevel(&brBuf, snapshotBrotliLevel)
+	if err := json.NewEncoder(io.MultiWriter(&plain, gz, br)).Encode(v); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	if err := br.Close(); err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(plain.Bytes())
+	now := time.Now()
+	snap := &snapshot{
+		body:         plain.Bytes(),
+		etag:         `"` + hex.EncodeToString(sum[:16]) + `"`,
+		lastModified: now.UTC().Truncate(time.Second),
+		createdAt:    now,
+	}
+	if plain.Len() >= compressMinBytes {
+		snap.gzipBody = gzBuf.Bytes()
+		snap.brotliBody = brBuf.Bytes()
+	}
+	span.SetAttributes(
+		attribute.Int("size.identity", len(snap.body)),
+		attribute.Int("size.gzip", len(snap.gzipBody)),
+		attribute.Int("size.br", len(snap.brotliBody)),
+	)
+	return snap, nil
+}
+
+// variant は符号化に対応する本文と ETag を返す。圧縮版が無ければ無圧縮版を返す。
+func (s *snapshot) variant(encoding string) (body []byte, etag string, contentEncoding string) {
+	switch {
+	case encoding == encodingBrotli && s.brotliBody != nil:
+		return s.brotliBody, strings.TrimSuffix(s.etag, `"`) + `-br"`, encodingBrotli
+	case encoding == encodingGzip && s.gzipBody != nil:
+		return s.gzipBody, strings.TrimSuffix(s.etag, `"`) + `-gzip"`, encodingGzip
+	default:
+		return s.body, s.etag, ""
+	}
+}
+
+// snapshotLoader はキャッシュのローダーを包み、取得した値を snapshot にして返す。
+func snapshotLoader[V any](fn func(ctx context.Context, key string) (V, error)) func(ctx context.Context, key string) (*snapshot, error) {
+	return func(ctx context.Context, key string) (*snapshot, error) {
+		v, err := fn(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		return newSnapshot(ctx, v)
+	}
+}
+
+// writeSnapshot は Accept-Encoding に合った版を ETag / Last-Modified / Cache-Control 付きで返す。
+// If-None-Match（無ければ If-Modified-Since）が一致すれば 304 を返す。
+// max-age はキャッシュの TTL から経過時間を引いた残り時間で、サーバー側の更新より長くクライアントに保持させない。
+func writeSnapshot(ctx echo.Context, snap *snapshot, ttl time.Duration) error {
+	maxAge := int((ttl - time.Since(snap.createdAt)).Seconds())
+	if maxAge < 0 {
+		maxAge = 0
+	}
+	body, etag, contentEncoding := snap.variant(negotiateEncoding(ctx.Request().Header.Get(echo.HeaderAcceptEncoding)))
+
+	header := ctx.Response().Header()
+	header.Set(echo.HeaderCacheControl, "public, max-age="+strconv.Itoa(maxAge))
+	header.Set("ETag", etag)
+	header.Set(echo.HeaderLastModified, snap.lastModified.Format(http.TimeFormat))
+	if !headerContains(header, echo.HeaderVary, echo.HeaderAcceptEncoding) {
+		header.Add(echo.HeaderVary, echo.HeaderAcceptEncoding)
+	}
+
+	if notModified(ctx.Request(), snap) {
+		return ctx.NoContent(http.StatusNotModified)
+	}
+	if contentEncoding != "" {
+		header.Set(echo.HeaderContentEncoding, contentEncoding)
+	}
+	header.Set(echo.HeaderContentLength, strconv.Itoa(len(body)))
+	return ctx.Blob(http.StatusOK, echo.MIMEApplicationJSON, body)
+}
+
+func notModified(req *http.Request, snap *snapshot) bool {
+	if inm := req.Header.Get("If-None-Match"); inm != "" {
+		return etagMatches(inm, snap.etag)
+	}
+	if ims := req.Header.Get(echo.HeaderIfModifiedSince); ims != "" {
+		t, err := http.ParseTime(ims)
+		return err == nil && !snap.lastModified.After(t)
+	}
+	return false
+}
+
+// etagMatches は If-None-Match のリストに etag（いずれかの符号化版を含む）があるかを弱い比較で判定する。
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || etagBase(candidate) == etag {
+			return true
+		}
+	}
+	return false
+}
+
+func etagBase(etag string) string {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, suffix := range []string{`-br"`, `-gzip"`} {
+		if strings.HasSuffix(etag, suffix) {
+			return strings.TrimSuffix(etag, suffix) + `"`
+		}
+	}
+	return etag
+}
+
+func headerContains(header http.Header, key, value string) bool {
+	for _, v := range header.Values(key) {
+		for _, part := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// snapshotWire は共有キャッシュ（Redis）に置くときの snapshot の形
+type snapshotWire struct {
+	Body         []byte
+	GzipBody     []byte
+	BrotliBody   []byte
+	ETag         string
+	LastModified time.Time
+	CreatedAt    time.Time
+}
+
+// snapshotCodec は snapshot を圧縮版ごと共有キャッシュに置くための cache.Codec
+type snapshotCodec struct{}
+
+func (snapshotCodec) Marshal(s *snapshot) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(snapshotWire{
+		Body:         s.body,
+		GzipBody:     s.gzipBody,
+		BrotliBody:   s.brotliBody,
+		ETag:         s.etag,
+		LastModified: s.lastModified,
+		CreatedAt:    s.createdAt,
+	})
+	return buf.Bytes(), err
+}
+
+func (snapshotCodec) Unmarshal(data []byte) (*snapshot, error) {
+	var w snapshotWire
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&w); err != nil {
+		return nil, err
+	}
+	return &snapshot{
+		body:         w.Body,
+		gzipBody:     w.GzipBody,
+		brotliBody:   w.BrotliBody,
+		etag:         w.ETag,
+		lastModified: w.LastModified,
+		createdAt:    w.CreatedAt,
+	}, nil
+}
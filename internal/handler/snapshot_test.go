@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/openapi/models"
+)
+
+func TestGetV4Statistics_ConditionalGet(t *testing.T) {
+	setTestSecrets(t)
+	total := 42
+	repo := &stubRepo{statsV4: &models.StatisticsV4{TotalMedals: &total}}
+	e := newTestServer(t, repo)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v4/statistics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d body=%s", rec.Code, rec.Body.String())
+	}
+	etag := rec.Header().Get("ETag")
+	if etag == "" || !strings.HasPrefix(etag, `"`) {
+		t.Fatalf("ETag: got %q", etag)
+	}
+	if rec.Header().Get(echo.HeaderLastModified) == "" {
+		t.Fatalf("Last-Modified is missing")
+	}
+	cacheControl := rec.Header().Get(echo.HeaderCacheControl)
+	maxAge, err := strconv.Atoi(strings.TrimPrefix(cacheControl, "public, max-age="))
+	if err != nil || maxAge <= 0 || maxAge > int(statisticsCacheV4TTL.Seconds()) {
+		t.Fatalf("Cache-Control: got %q", cacheControl)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/v4/statistics", nil)
+	req.Header.Set("If-None-Match", `"other", W/`+etag)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified {
+		t.Fatalf("status: got %d want 304", rec.Code)
+	}
+	if rec.Body.Len() != 0 {
+		t.Fatalf("304 must not have a body: %q", rec.Body.String())
+	}
+	if rec.Header().Get("ETag") != etag {
+		t.Fatalf("304 ETag: got %q want %q", rec.Header().Get("ETag"), etag)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/v4/statistics", nil)
+	req.Header.Set("If-None-Match", `"stale"`)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("mismatched ETag: got %d", rec.Code)
+	}
+	if repo.statsV4Calls != 1 {
+		t.Fatalf("expected a single load, calls=%d", repo.statsV4Calls)
+	}
+}
+
+func TestGetV4StatisticsSavesActivity_IfModifiedSince(t *testing.T) {
+	setTestSecrets(t)
+	repo := &stubRepo{saveActivity: &models.SaveActivityResponse{}}
+	e := newTestServer(t, repo)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v4/statistics/saves/activity", nil))
+	lastModified := rec.Header().Get(echo.HeaderLastModified)
+
+	req := httptest.NewRequest(http.MethodGet, "/v4/statistics/saves/activity", nil)
+	req.Header.Set(echo.HeaderIfModifiedSince, lastModified)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotModified {
+		t.Fatalf("status: got %d want 304", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/v4/statistics/saves/activity", nil)
+	req.Header.Set(echo.HeaderIfModifiedSince, time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat))
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d want 200", rec.Code)
+	}
+}
+
+func TestSnapshotETagIsStable(t *testing.T) {
+	total := 1
+	a, err := newSnapshot(t.Context(), &models.StatisticsV4{TotalMedals: &total})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := newSnapshot(t.Context(), &models.StatisticsV4{TotalMedals: &total})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.etag != b.etag {
+		t.Fatalf("same value produced different ETags: %s %s", a.etag, b.etag)
+	}
+	total = 2
+	c, err := newSnapshot(t.Context(), &models.StatisticsV4{TotalMedals: &total})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.etag == c.etag {
+		t.Fatalf("different values produced the same ETag")
+	}
+}
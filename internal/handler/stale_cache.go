@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/pkg/cache"
+)
+
+// staleCacheInvalidateTimeout は MarkStale が裏で行う無効化（Redis では EXISTS と dirty の SET）の期限
+const staleCacheInvalidateTimeout = 5 * time.Second
+
+// cacheGetter は cachedGet が扱うキャッシュの共通部分。
+type cacheGetter[V any] interface {
+	Get(ctx context.Context, key string) (V, error)
+}
+
+// staleCache はセーブ保存による無効化を受けるキャッシュ。
+// 作り直しの間は直前の値を返す（cache.Cache.Invalidate）ので読み手は待たされず、
+// 保存が集中しても minRefreshInterval に 1 回しか作り直さない。
+type staleCache[V any] struct {
+	cache.Cache[V]
+	// minRefreshInterval より短い間隔で無効化されたら、まとめて次の機会に一度だけ作り直す
+	minRefreshInterval time.Duration
+	// invalidateTimeout は裏で行う無効化の期限（既定は staleCacheInvalidateTimeout）
+	invalidateTimeout time.Duration
+
+	mu          sync.Mutex
+	lastRefresh map[string]time.Time
+	pending     map[string]*time.Timer
+}
+
+func newStaleCache[V any](c cache.Cache[V], minRefreshInterval time.Duration) *staleCache[V] {
+	return &staleCache[V]{
+		Cache:              c,
+		minRefreshInterval: minRefreshInterval,
+		invalidateTimeout:  staleCacheInvalidateTimeout,
+		lastRefresh:        make(map[string]time.Time),
+		pending:            make(map[string]*time.Timer),
+	}
+}
+
+// MarkStale は key を古いとみなし、裏で作り直させる。
+// 無効化はバックエンドへの通信を伴うことがあるので別の goroutine で行い、呼び出し元（セーブ保存）はブロックしない。
+func (c *staleCache[V]) MarkStale(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, scheduled := c.pending[key]; scheduled {
+		return
+	}
+	if wait := c.minRefreshInterval - time.Since(c.lastRefresh[key]); wait > 0 {
+		c.pending[key] = time.AfterFunc(wait, func() {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			delete(c.pending, key)
+			c.refreshLocked(key)
+		})
+		return
+	}
+	c.refreshLocked(key)
+}
+
+// refreshLocked は作り直した時刻を記録し、無効化を裏で始める。c.mu を持ったまま呼ぶ。
+func (c *staleCache[V]) refreshLocked(key string) {
+	c.lastRefresh[key] = time.Now()
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), c.invalidateTimeout)
+		defer cancel()
+		c.Cache.Invalidate(ctx, key)
+	}()
+}
+
+// keyedCache は使われたキーを覚えておき、管理操作のときに全キーをまとめて作り直せるようにしたキャッシュ。
+// キーはパラメータ検証済みの値に限られるが、念のため maxKeys を超えた分は覚えない（その分は TTL で入れ替わる）。
+type keyedCache[V any] struct {
+	cache.Cache[V]
+	maxKeys int
+
+	mu   sync.Mutex
+	keys map[string]struct{}
+}
+
+func newKeyedCache[V any](c cache.Cache[V], maxKeys int) *keyedCache[V] {
+	return &keyedCache[V]{Cache: c, maxKeys: maxKeys, keys: make(map[string]struct{})}
+}
+
+func (c *keyedCache[V]) Get(ctx context.Context, key string) (V, error) {
+	c.remember(key)
+	return c.Cache.Get(ctx, key)
+}
+
+func (c *keyedCache[V]) Warm(ctx context.Context, key string, within time.Duration) (time.Time, error) {
+	c.remember(key)
+	return c.Cache.Warm(ctx, key, within)
+}
+
+func (c *keyedCache[V]) remember(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.keys) < c.maxKeys {
+		c.keys[key] = struct{}{}
+	}
+}
+
+// InvalidateAll はこれまでに使われた全キーを裏で作り直させる。作り直しの間も Get は直前の値を返す。
+func (c *keyedCache[V]) InvalidateAll(ctx context.Context) {
+	c.mu.Lock()
+	keys := make([]string, 0, len(c.keys))
+	for key := range c.keys {
+		keys = append(keys, key)
+	}
+	c.mu.Unlock()
+	for _, key := range keys {
+		c.Cache.Invalidate(ctx, key)
+	}
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingMiddleware はルートごとに server span を開始する。
+// span 名はパスパラメータを含まないルート（例: "GET /api/v4/users/:user_id/data"）にする。
+func TracingMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			ctx := otel.GetTextMapPropagator().Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+
+			route := c.Path()
+			if route == "" {
+				route = req.URL.Path
+			}
+			ctx, span := tracing.Tracer().Start(ctx, req.Method+" "+route,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("http.request.method", req.Method),
+					attribute.String("http.route", route),
+				),
+			)
+			defer span.End()
+			c.SetRequest(req.WithContext(ctx))
+
+			err := next(c)
+			status := c.Response().Status
+			if err != nil {
+				// エラーはまだレスポンスに書かれていないので、エラー側からステータスを決める
+				status = http.StatusInternalServerError
+				var he *echo.HTTPError
+				if errors.As(err, &he) {
+					status = he.Code
+				}
+			}
+			span.SetAttributes(attribute.Int("http.response.status_code", status))
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+			return err
+		}
+	}
+}
+
+// cacheLoadMarker はキャッシュのローダーが実行されたか（= ミス）を記録する。
+// sc はローダーに context.WithoutCancel(ctx) を渡すため、context の値はローダーまで届く。
+type cacheLoadMarker struct {
+	loaded atomic.Bool
+}
+
+type cacheLoadMarkerKey struct{}
+
+// markCacheLoad はローダー内から呼び、キャッシュミスであったことを記録する。
+func markCacheLoad(ctx context.Context) {
+	if m, ok := ctx.Value(cacheLoadMarkerKey{}).(*cacheLoadMarker); ok {
+		m.loaded.Store(true)
+	}
+}
+
+// cachedGet は sc.Cache.Get を span で包み、ヒット/ミスを属性として記録する。
+// stale な値を返しつつ裏で更新する場合はヒット扱いになる（リクエストはローダーを待たないため）。
+// 他のリクエストが実行中のロードに相乗りした場合もヒット扱いになるが、待ち時間は span の長さに現れる。
+func cachedGet[V any](ctx context.Context, cache cacheGetter[V], name, key string) (_ V, err error) {
+	ctx, span := tracing.Start(ctx, "cache.Get "+name,
+		attribute.String("cache.name", name),
+		attribute.String("cache.key", key),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	marker := &cacheLoadMarker{}
+	v, err := cache.Get(context.WithValue(ctx, cacheLoadMarkerKey{}, marker), key)
+	span.SetAttributes(attribute.Bool("cache.hit", !marker.loaded.Load()))
+	return v, err
+}
+
+// tracedLoader はキャッシュのローダーを包み、呼ばれたことを cachedGet に伝える。
+func tracedLoader[V any](fn func(ctx context.Context, key string) (V, error)) func(ctx context.Context, key string) (V, error) {
+	return func(ctx context.Context, key string) (V, error) {
+		markCacheLoad(ctx)
+		return fn(ctx, key)
+	}
+}
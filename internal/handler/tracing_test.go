@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/openapi"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/openapi/models"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func useSpanRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+	return recorder
+}
+
+func spanAttr(span sdktrace.ReadOnlySpan, key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestTracing_StatisticsCacheHitMiss(t *testing.T) {
+	recorder := useSpanRecorder(t)
+	total := 42
+	repo := &stubRepo{statsV4: &models.StatisticsV4{TotalMedals: &total}}
+
+	e := echo.New()
+	e.Use(TracingMiddleware())
+	openapi.RegisterHandlers(e, New(repo))
+
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v4/statistics", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status: got %d body=%s", rec.Code, rec.Body.String())
+		}
+	}
+
+	var hits []bool
+	var routeSpans, encodeSpans int
+	for _, span := range recorder.Ended() {
+		switch span.Name() {
+		case "cache.Get statistics_v4":
+			v, ok := spanAttr(span, "cache.hit")
+			if !ok {
+				t.Fatalf("cache span has no cache.hit attribute")
+			}
+			hits = append(hits, v.AsBool())
+		case "GET /v4/statistics":
+			routeSpans++
+			if v, _ := spanAttr(span, "http.response.status_code"); v.AsInt64() != http.StatusOK {
+				t.Fatalf("status_code attribute: got %v", v.AsInt64())
+			}
+		case "json.encode":
+			encodeSpans++
+		}
+	}
+	if len(hits) != 2 || hits[0] || !hits[1] {
+		t.Fatalf("expected miss then hit, got %v", hits)
+	}
+	// エンコードはキャッシュのロード時に一度だけ行われる
+	if routeSpans != 2 || encodeSpans != 1 {
+		t.Fatalf("route spans=%d encode spans=%d", routeSpans, encodeSpans)
+	}
+}
+
+func TestTracing_RecordsErrorStatus(t *testing.T) {
+	recorder := useSpanRecorder(t)
+
+	e := echo.New()
+	e.Use(TracingMiddleware())
+	e.GET("/v4/data", func(c echo.Context) error {
+		return echo.NewHTTPError(http.StatusBadRequest, "bad")
+	})
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v4/data", nil))
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	if v, _ := spanAttr(spans[0], "http.response.status_code"); v.AsInt64() != http.StatusBadRequest {
+		t.Fatalf("status_code attribute: got %v", v.AsInt64())
+	}
+}
@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// 温めておくメダル推移の日数（Web の表示切り替えと API の既定値・上限）
+var warmMedalTimeseriesDays = []int{7, 30, 90, 180}
+
+// 温めておくセーブアクティビティの時間数（1 日・既定の 1 週間・上限の 30 日）
+var warmSaveActivityHours = []int{24, 168, 720}
+
+const (
+	// warmMaxLead は期限の最大どれだけ前に作り直すか（TTL の 1/10 と小さい方）
+	warmMaxLead = time.Minute
+	// warmRetryInterval は温めに失敗したときの再試行間隔
+	warmRetryInterval = 10 * time.Second
+	// warmMinInterval は同じ対象を温め直す最短間隔（他のレプリカが作り直し中の場合など）
+	warmMinInterval = 5 * time.Second
+)
+
+// cacheWarmer は Warm を持つキャッシュ（cache.Cache と staleCache）
+type cacheWarmer interface {
+	Warm(ctx context.Context, key string, within time.Duration) (time.Time, error)
+}
+
+// warmTarget は温めておくキャッシュの 1 キー
+type warmTarget struct {
+	name  string
+	key   string
+	ttl   time.Duration
+	cache cacheWarmer
+	// after は温め終えた後の処理（統計の rankingIndex の更新など）
+	after func(ctx context.Context) error
+}
+
+func (t warmTarget) id() string { return t.name + ":" + t.key }
+
+// lead は期限のどれだけ前に作り直すか
+func (t warmTarget) lead() time.Duration {
+	return min(t.ttl/10, warmMaxLead)
+}
+
+// warmState は最初の温めが済んでいない対象を持つ（readiness 用）
+type warmState struct {
+	mu      sync.Mutex
+	pending map[string]struct{}
+}
+
+func (h *Handler) warmTargets() []warmTarget {
+	targets := []warmTarget{
+		{
+			name: "statistics_v4", key: statisticsCacheV4Key, ttl: statisticsCacheV4TTL,
+			cache: h.statisticsCacheV4,
+			after: func(ctx context.Context) error {
+				// 保存時の無効化判定に使うので、最初のリクエストを待たずに用意しておく
+				snap, err := h.statisticsCacheV4.Get(ctx, statisticsCacheV4Key)
+				if err != nil {
+					return err
+				}
+				h.observeStatistics(ctx, snap)
+				return nil
+			},
+		},
+		{
+			name: "achievement_rates", key: achievementRatesCacheKey, ttl: achievementRatesCacheTTL,
+			cache: h.achievementRatesCache,
+		},
+	}
+	for _, days := range warmMedalTimeseriesDays {
+		targets = append(targets, warmTarget{
+			name: "medal_timeseries", key: strconv.Itoa(days), ttl: medalTimeseriesCacheTTL,
+			cache: h.medalTimeseriesCache,
+		})
+	}
+	for _, hours := range warmSaveActivityHours {
+		targets = append(targets, warmTarget{
+			name: "save_activity", key: strconv.Itoa(hours), ttl: saveActivityCacheTTL,
+			cache: h.saveActivityCache,
+		})
+	}
+	return targets
+}
+
+// StartWarmer は重い集計のキャッシュを裏で温め始める。
+// 起動直後に一通り作り、以降は各キーが古くなる少し前に作り直すので、リクエストが集計を待つことはない。
+// 最初の温めが全て済むまで Ready は 503 を返す。ctx が終わると止まる。
+func (h *Handler) StartWarmer(ctx context.Context) {
+	targets := h.warmTargets()
+
+	h.warm.mu.Lock()
+	h.warm.pending = make(map[string]struct{}, len(targets))
+	for _, t := range targets {
+		h.warm.pending[t.id()] = struct{}{}
+	}
+	h.warm.mu.Unlock()
+
+	for _, t := range targets {
+		go h.runWarmTarget(ctx, t)
+	}
+}
+
+func (h *Handler) runWarmTarget(ctx context.Context, t warmTarget) {
+	for {
+		wait := warmRetryInterval
+		freshUntil, err := h.warmOnce(ctx, t)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.WarnContext(ctx, "failed to warm cache", "cache", t.name, "key", t.key, "error", err)
+		} else {
+			h.markWarmed(t)
+			wait = max(time.Until(freshUntil)-t.lead(), warmMinInterval)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+func (h *Handler) warmOnce(ctx context.Context, t warmTarget) (_ time.Time, err error) {
+	ctx, span := tracing.Start(ctx, "cache.Warm "+t.name,
+		attribute.String("cache.name", t.name),
+		attribute.String("cache.key", t.key),
+	)
+	defer func() { tracing.End(span, err) }()
+
+	freshUntil, err := t.cache.Warm(ctx, t.key, t.lead())
+	if err != nil {
+		return freshUntil, err
+	}
+	if t.after != nil {
+		err = t.after(ctx)
+	}
+	return freshUntil, err
+}
+
+func (h *Handler) markWarmed(t warmTarget) {
+	h.warm.mu.Lock()
+	defer h.warm.mu.Unlock()
+	if _, ok := h.warm.pending[t.id()]; !ok {
+		return
+	}
+	delete(h.warm.pending, t.id())
+	if len(h.warm.pending) == 0 {
+		slog.Info("cache warmup completed")
+	}
+}
+
+// pendingWarmups は最初の温めが済んでいない対象を返す（StartWarmer を呼んでいなければ空）
+func (h *Handler) pendingWarmups() []string {
+	h.warm.mu.Lock()
+	defer h.warm.mu.Unlock()
+	pending := make([]string, 0, len(h.warm.pending))
+	for id := range h.warm.pending {
+		pending = append(pending, id)
+	}
+	slices.Sort(pending)
+	return pending
+}
+
+// Ready は readiness probe 用。最初のキャッシュの温めが済むまでは 503 と未完了の対象を返す。
+func (h *Handler) Ready(ctx echo.Context) error {
+	if pending := h.pendingWarmups(); len(pending) > 0 {
+		return ctx.JSON(http.StatusServiceUnavailable, map[string]interface{}{
+			"status":  "warming",
+			"pending": pending,
+		})
+	}
+	return ctx.JSON(http.StatusOK, map[string]interface{}{"status": "ready"})
+}
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/openapi"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/openapi/models"
+)
+
+// warmRepo は統計の取得を release まで止め、温められたキーを記録する
+type warmRepo struct {
+	*syncStatsRepo
+	release chan struct{}
+
+	mu    sync.Mutex
+	days  []int
+	hours []int
+}
+
+func (r *warmRepo) GetStatisticsV4(ctx context.Context) (*models.StatisticsV4, error) {
+	<-r.release
+	return r.syncStatsRepo.GetStatisticsV4(ctx)
+}
+
+func (r *warmRepo) GetMedalTimeseries(ctx context.Context, days int) (*models.MedalTimeseriesResponse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.days = append(r.days, days)
+	return &models.MedalTimeseriesResponse{}, nil
+}
+
+func (r *warmRepo) GetSaveActivity(ctx context.Context, hours int) (*models.SaveActivityResponse, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hours = append(r.hours, hours)
+	return &models.SaveActivityResponse{}, nil
+}
+
+func readiness(t *testing.T, e *echo.Echo) (int, map[string]interface{}) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	var body map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode readiness: %v", err)
+	}
+	return rec.Code, body
+}
+
+func TestWarmer_ReadyAfterFirstWarm(t *testing.T) {
+	repo := &warmRepo{
+		syncStatsRepo: &syncStatsRepo{stubRepo: &stubRepo{}, stats: saturatedStatistics()},
+		release:       make(chan struct{}),
+	}
+	h := New(repo)
+	e := echo.New()
+	openapi.RegisterHandlers(e, h)
+	e.GET("/ready", h.Ready)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	h.StartWarmer(ctx)
+
+	// 統計の集計が終わるまでは準備中
+	deadline := time.Now().Add(time.Second)
+	for {
+		code, body := readiness(t, e)
+		if code != http.StatusServiceUnavailable {
+			t.Fatalf("readiness while warming: got %d", code)
+		}
+		if pending, _ := body["pending"].([]interface{}); len(pending) == 1 && pending[0] == "statistics_v4:"+statisticsCacheV4Key {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("only statistics should remain pending, got %v", body["pending"])
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	close(repo.release)
+	deadline = time.Now().Add(time.Second)
+	for {
+		if code, _ := readiness(t, e); code == http.StatusOK {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("never became ready")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if h.rankingIndex.Load() == nil {
+		t.Fatalf("ranking index should be built by the warmer")
+	}
+	repo.mu.Lock()
+	days, hours := slices.Sorted(slices.Values(repo.days)), slices.Sorted(slices.Values(repo.hours))
+	repo.mu.Unlock()
+	if !slices.Equal(days, warmMedalTimeseriesDays) || !slices.Equal(hours, warmSaveActivityHours) {
+		t.Fatalf("warmed days=%v hours=%v", days, hours)
+	}
+
+	// 最初のリクエストは温めた値を返し、集計を待たない
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v4/statistics", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("statistics: got %d", rec.Code)
+	}
+	if stats, rates := repo.counts(); stats != 1 || rates != 1 {
+		t.Fatalf("loads: got stats=%d rates=%d", stats, rates)
+	}
+}
+
+func TestReady_WithoutWarmer(t *testing.T) {
+	h := New(&stubRepo{})
+	e := echo.New()
+	e.GET("/ready", h.Ready)
+	if code, _ := readiness(t, e); code != http.StatusOK {
+		t.Fatalf("got %d", code)
+	}
+}
@@ -0,0 +1,56 @@
+-- +goose Up
+-- v4 統計の集計値を保存時に差分更新するためのサマリーテーブル
+-- 値は InsertSaveV4 のトランザクション内で更新し、ずれた場合は recompute-stats で再計算する
+
+CREATE TABLE v4_summary_totals (
+    name VARCHAR(64) NOT NULL,
+    value BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE v4_summary_achievement_users (
+    achievement_id VARCHAR(255) NOT NULL,
+    user_count BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (achievement_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE v4_summary_credit_digits (
+    digits INT NOT NULL,
+    user_count BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (digits)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- 既存データから初期値を作成
+INSERT INTO v4_summary_totals (name, value)
+SELECT 'total_medals', COALESCE(SUM(credit_all), 0) FROM v3_user_latest_save_data WHERE hide_record = 0
+UNION ALL
+SELECT 'visible_users', COUNT(*) FROM v3_user_latest_save_data WHERE hide_record = 0
+UNION ALL
+SELECT 'achievement_users', COUNT(DISTINCT user_id) FROM v3_user_latest_save_data_achievements;
+
+INSERT INTO v4_summary_achievement_users (achievement_id, user_count)
+SELECT achievement_id, COUNT(*)
+FROM v3_user_latest_save_data_achievements
+GROUP BY achievement_id;
+
+INSERT INTO v4_summary_credit_digits (digits, user_count)
+SELECT
+  CASE
+    WHEN credit_all IS NULL OR credit_all < 1000 THEN 3
+    ELSE CHAR_LENGTH(CAST(credit_all AS CHAR))
+  END AS digits,
+  COUNT(*)
+FROM v3_user_latest_save_data
+WHERE hide_record = 0
+GROUP BY digits;
+
+-- +goose Down
+DROP TABLE IF EXISTS v4_summary_credit_digits;
+DROP TABLE IF EXISTS v4_summary_achievement_users;
+DROP TABLE IF EXISTS v4_summary_totals;
@@ -0,0 +1,21 @@
+-- +goose Up
+-- 管理 API（/api/admin）へのリクエストの監査ログ
+-- 認証できたリクエストはスコープ不足（403）も含めて全て記録する
+
+CREATE TABLE admin_audit_log (
+    id BIGINT NOT NULL AUTO_INCREMENT,
+    actor VARCHAR(64) NOT NULL,
+    action VARCHAR(255) NOT NULL,
+    target VARCHAR(255) NOT NULL DEFAULT '',
+    payload TEXT NOT NULL,
+    remote_ip VARCHAR(64) NOT NULL DEFAULT '',
+    status INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (id),
+    INDEX idx_admin_audit_log_target (target),
+    INDEX idx_admin_audit_log_created_at (created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- +goose Down
+DROP TABLE IF EXISTS admin_audit_log;
@@ -0,0 +1,16 @@
+-- +goose Up
+-- 管理 API の巻き戻しで取り消した実績の記録
+-- v2_save_data_achievements（解除）と合わせ、セーブ履歴から実績の状態を再計算できるようにする
+-- save_id は巻き戻しで保存し直したセーブ。同じ実績は、最後の解除より後に取り消されていれば未解除
+
+CREATE TABLE v2_save_data_achievement_revocations (
+    save_id INT NOT NULL,
+    achievement_id VARCHAR(255) NOT NULL,
+
+    PRIMARY KEY (save_id, achievement_id),
+    INDEX idx_v2_save_data_achievement_revocations_achievement_id (achievement_id),
+    FOREIGN KEY (save_id) REFERENCES v2_save_data (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- +goose Down
+DROP TABLE IF EXISTS v2_save_data_achievement_revocations;
@@ -0,0 +1,17 @@
+-- +goose Up
+-- 署名バイパストークン（SIGNATURE_BYPASS_TOKEN）で署名検証を通したリクエストの記録
+
+CREATE TABLE signature_bypass_log (
+    id BIGINT NOT NULL AUTO_INCREMENT,
+    route VARCHAR(255) NOT NULL,
+    user_id VARCHAR(255) NOT NULL,
+    remote_ip VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (id),
+    INDEX idx_signature_bypass_log_user_id (user_id),
+    INDEX idx_signature_bypass_log_created_at (created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- +goose Down
+DROP TABLE IF EXISTS signature_bypass_log;
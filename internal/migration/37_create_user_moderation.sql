@@ -0,0 +1,21 @@
+-- +goose Up
+-- 管理者によるユーザーのモデレーション（利用停止・ランキングからの非表示）
+-- 有効な行（expires_at が NULL か未来）があるユーザーは、ランキング・統計のすべてから除外する
+-- expires_at は UTC で保存し、UTC_TIMESTAMP() と比較する
+
+CREATE TABLE user_moderation (
+    user_id VARCHAR(255) NOT NULL,
+    banned BOOLEAN NOT NULL DEFAULT FALSE,
+    hidden_from_rankings BOOLEAN NOT NULL DEFAULT FALSE,
+    reason VARCHAR(1024) NOT NULL DEFAULT '',
+    expires_at DATETIME NULL,
+    moderator VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (user_id),
+    INDEX idx_user_moderation_expires_at (expires_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- +goose Down
+DROP TABLE IF EXISTS user_moderation;
@@ -0,0 +1,25 @@
+-- +goose Up
+-- セーブの推移から自動検出した不審なユーザーの記録（管理 API でレビューする）
+-- 同じセーブ・同じ種類の検出は 1 件だけ（再解析しても重複しない）
+
+CREATE TABLE anomaly_flags (
+    id BIGINT NOT NULL AUTO_INCREMENT,
+    user_id VARCHAR(255) NOT NULL,
+    kind VARCHAR(32) NOT NULL,
+    save_id BIGINT NOT NULL,
+    score DOUBLE NOT NULL,
+    evidence TEXT NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'open',
+    reviewed_by VARCHAR(64) NOT NULL DEFAULT '',
+    review_note VARCHAR(1024) NOT NULL DEFAULT '',
+    reviewed_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (id),
+    UNIQUE KEY uq_anomaly_flags_save_kind (save_id, kind),
+    INDEX idx_anomaly_flags_status (status, id),
+    INDEX idx_anomaly_flags_user_id (user_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- +goose Down
+DROP TABLE IF EXISTS anomaly_flags;
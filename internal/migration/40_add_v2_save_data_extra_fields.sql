@@ -0,0 +1,10 @@
+-- +goose Up
+-- サーバーがまだ知らないトップレベルのセーブキー（domain.SaveExtraFields）を JSON オブジェクトのまま残す
+-- 無ければ NULL。後で列を追加したら promote-save-field で過去のセーブの値を列へ移す
+
+ALTER TABLE v2_save_data
+    ADD COLUMN IF NOT EXISTS extra_fields JSON NULL AFTER get_medaltower;
+
+-- +goose Down
+ALTER TABLE v2_save_data
+    DROP COLUMN IF EXISTS extra_fields;
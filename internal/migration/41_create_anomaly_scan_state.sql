@@ -0,0 +1,18 @@
+-- +goose Up
+-- 不審なセーブの検出（StartAnomalyAnalyzer）の進み具合。1 行だけ持つ
+-- last_save_id は解析済みのセーブ id（NULL ならまだ解析していない）。再起動しても続きから解析する
+-- lease_owner / lease_until は解析中のレプリカとリースの期限（UTC）。期限内は他のレプリカは解析しない
+
+CREATE TABLE anomaly_scan_state (
+    id TINYINT NOT NULL,
+    last_save_id BIGINT NULL DEFAULT NULL,
+    lease_owner VARCHAR(255) NOT NULL DEFAULT '',
+    lease_until DATETIME NULL DEFAULT NULL,
+
+    PRIMARY KEY (id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+INSERT INTO anomaly_scan_state (id) VALUES (1);
+
+-- +goose Down
+DROP TABLE IF EXISTS anomaly_scan_state;
@@ -1,26 +1,109 @@
 package migration
 
 import (
-	"database/sql"
+	"context"
 	"embed"
 	"fmt"
+	"io/fs"
 
+	"github.com/jmoiron/sqlx"
 	"github.com/pressly/goose/v3"
 )
 
 //go:embed *.sql
 var embedMigrations embed.FS
 
-func MigrateTables(db *sql.DB) error {
-	goose.SetBaseFS(embedMigrations)
+// SQLite 用のマイグレーション（DB_DRIVER=sqlite）。MariaDB 用と同じバージョン番号で並行して管理する
+//
+//go:embed sqlite/*.sql
+var embedSQLiteMigrations embed.FS
 
-	if err := goose.SetDialect("mysql"); err != nil {
+// source は db のドライバーに合うマイグレーションの方言と SQL ファイルを返す
+func source(db *sqlx.DB) (goose.Dialect, fs.FS, error) {
+	switch driver := db.DriverName(); driver {
+	case "mysql":
+		return goose.DialectMySQL, embedMigrations, nil
+	case "sqlite", "sqlite3":
+		migrations, err := fs.Sub(embedSQLiteMigrations, "sqlite")
+		if err != nil {
+			return "", nil, err
+		}
+		return goose.DialectSQLite3, migrations, nil
+	default:
+		return "", nil, fmt.Errorf("no migrations for driver %q", driver)
+	}
+}
+
+func MigrateTables(db *sqlx.DB) error {
+	dialect, migrations, err := source(db)
+	if err != nil {
+		return err
+	}
+	goose.SetBaseFS(migrations)
+
+	if err := goose.SetDialect(string(dialect)); err != nil {
 		return fmt.Errorf("set dialect: %w", err)
 	}
 
-	if err := goose.Up(db, "."); err != nil {
+	if err := goose.Up(db.DB, "."); err != nil {
 		return fmt.Errorf("up migration: %w", err)
 	}
 
 	return nil
 }
+
+func newProvider(db *sqlx.DB) (*goose.Provider, error) {
+	dialect, migrations, err := source(db)
+	if err != nil {
+		return nil, err
+	}
+	p, err := goose.NewProvider(dialect, db.DB, migrations)
+	if err != nil {
+		return nil, fmt.Errorf("load migrations: %w", err)
+	}
+	return p, nil
+}
+
+// Up は未適用のマイグレーションを全て適用し、適用したものを返す
+func Up(ctx context.Context, db *sqlx.DB) ([]*goose.MigrationResult, error) {
+	p, err := newProvider(db)
+	if err != nil {
+		return nil, err
+	}
+	results, err := p.Up(ctx)
+	if err != nil {
+		return results, fmt.Errorf("up migration: %w", err)
+	}
+	return results, nil
+}
+
+// Down は最後に適用したマイグレーションを 1 つだけ戻す
+func Down(ctx context.Context, db *sqlx.DB) (*goose.MigrationResult, error) {
+	p, err := newProvider(db)
+	if err != nil {
+		return nil, err
+	}
+	result, err := p.Down(ctx)
+	if err != nil {
+		return result, fmt.Errorf("down migration: %w", err)
+	}
+	return result, nil
+}
+
+// Status はマイグレーションごとの適用状況を古い順に返す
+func Status(ctx context.Context, db *sqlx.DB) ([]*goose.MigrationStatus, error) {
+	p, err := newProvider(db)
+	if err != nil {
+		return nil, err
+	}
+	return p.Status(ctx)
+}
+
+// HasPending は未適用のマイグレーションがあるかを返す
+func HasPending(ctx context.Context, db *sqlx.DB) (bool, error) {
+	p, err := newProvider(db)
+	if err != nil {
+		return false, err
+	}
+	return p.HasPending(ctx)
+}
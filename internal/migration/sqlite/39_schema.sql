@@ -0,0 +1,457 @@
+-- +goose Up
+-- SQLite 用のスキーマ（DB_DRIVER=sqlite）。MariaDB のマイグレーション 1〜39 を適用した後と同じテーブルを 1 つにまとめたもの
+-- バージョン番号は MariaDB 側にそろえ、以降のマイグレーションは同じ番号で両方に追加する
+-- ON UPDATE CURRENT_TIMESTAMP の代わりに、updated_at を変えない UPDATE ではトリガーで現在時刻に更新する
+-- 時刻はすべて UTC の 'YYYY-MM-DD HH:MM:SS' 形式の文字列で、CURRENT_TIMESTAMP と文字列のまま比較できる
+
+CREATE TABLE v1_game_data (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id VARCHAR(255) NOT NULL,
+    version INT NOT NULL,
+    have_medal INT NOT NULL DEFAULT 0,
+    in_medal INT NOT NULL,
+    out_medal INT NOT NULL,
+    slot_hit INT NOT NULL,
+    get_shirbe INT NOT NULL,
+    start_slot INT NOT NULL,
+    shirbe_buy300 INT NOT NULL,
+    medal_1 INT NOT NULL,
+    medal_2 INT NOT NULL,
+    medal_3 INT NOT NULL,
+    medal_4 INT NOT NULL,
+    medal_5 INT NOT NULL,
+    R_medal INT NOT NULL,
+    total_play_time INT NOT NULL,
+    fever INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    max_chain_item INT NOT NULL DEFAULT 0,
+    max_chain_orange INT NOT NULL DEFAULT 0,
+    max_chain_rainbow INT NOT NULL DEFAULT 0,
+    sugoroku_steps INT NOT NULL DEFAULT 0,
+    jackpots INT NOT NULL DEFAULT 0,
+    max_jackpot_win INT NOT NULL DEFAULT 0,
+    max_total_jackpot INT NOT NULL DEFAULT 0,
+    max_total_ultimate INT NOT NULL DEFAULT 0
+);
+CREATE INDEX idx_game_data_user_created_at ON v1_game_data (user_id, created_at);
+
+CREATE TABLE v2_save_data (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id varchar(255) NOT NULL,
+    legacy TINYINT NOT NULL,
+    version INT NOT NULL,
+    credit BIGINT NOT NULL DEFAULT 0,
+    credit_all BIGINT NOT NULL DEFAULT 0,
+    medal_in INT NOT NULL DEFAULT 0,
+    medal_get BIGINT NOT NULL DEFAULT 0,
+    ball_get BIGINT NOT NULL DEFAULT 0,
+    ball_chain INT NOT NULL DEFAULT 0,
+    slot_start BIGINT NOT NULL DEFAULT 0,
+    slot_startfev BIGINT NOT NULL DEFAULT 0,
+    slot_hit BIGINT NOT NULL DEFAULT 0,
+    slot_getfev BIGINT NOT NULL DEFAULT 0,
+    sqr_get BIGINT NOT NULL DEFAULT 0,
+    sqr_step BIGINT NOT NULL DEFAULT 0,
+    jack_get BIGINT NOT NULL DEFAULT 0,
+    jack_startmax BIGINT NOT NULL DEFAULT 0,
+    jack_totalmax INT NOT NULL DEFAULT 0,
+    ult_get INT NOT NULL DEFAULT 0,
+    ult_combomax INT NOT NULL DEFAULT 0,
+    ult_totalmax INT NOT NULL DEFAULT 0,
+    rmshbi_get INT NOT NULL DEFAULT 0,
+    bstp_step BIGINT NOT NULL DEFAULT 0,
+    bstp_rwd BIGINT NOT NULL DEFAULT 0,
+    buy_total INT NOT NULL DEFAULT 0,
+    skill_point BIGINT NOT NULL DEFAULT 0,
+    blackbox INT NOT NULL DEFAULT 0,
+    blackbox_total BIGINT NOT NULL DEFAULT 0,
+    sp_use BIGINT NOT NULL DEFAULT 0,
+    hide_record INT NOT NULL DEFAULT 0,
+    cpm_max DOUBLE NOT NULL DEFAULT 0,
+    palball_get INT NOT NULL DEFAULT 0,
+    pallot_lot_t0 INT NOT NULL DEFAULT 0,
+    pallot_lot_t1 INT NOT NULL DEFAULT 0,
+    pallot_lot_t2 INT NOT NULL DEFAULT 0,
+    pallot_lot_t3 INT NOT NULL DEFAULT 0,
+    pallot_lot_t4 INT NOT NULL DEFAULT 0,
+    task_cnt INT NOT NULL DEFAULT 0,
+    totem_altars INT NOT NULL DEFAULT 0,
+    totem_altars_credit BIGINT NOT NULL DEFAULT 0,
+    buy_shbi INT NOT NULL DEFAULT 0,
+    firstboot BIGINT NOT NULL,
+    lastsave BIGINT NOT NULL,
+    playtime INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    jack_totalmax_v2 BIGINT NOT NULL DEFAULT 0,
+    ult_totalmax_v2 BIGINT NOT NULL DEFAULT 0,
+    jacksp_get_all INT NOT NULL DEFAULT 0,
+    jacksp_get_t0 INT NOT NULL DEFAULT 0,
+    jacksp_get_t1 INT NOT NULL DEFAULT 0,
+    jacksp_get_t2 INT NOT NULL DEFAULT 0,
+    jacksp_get_t3 INT NOT NULL DEFAULT 0,
+    jacksp_get_t4 INT NOT NULL DEFAULT 0,
+    jacksp_startmax BIGINT NOT NULL DEFAULT 0,
+    jacksp_totalmax BIGINT NOT NULL DEFAULT 0,
+    ferball_get INT NOT NULL DEFAULT 0,
+    ferlot_lot INT NOT NULL DEFAULT 0,
+    jackfr_get_all INT NOT NULL DEFAULT 0,
+    jackfr_get_t0 INT NOT NULL DEFAULT 0,
+    jackfr_get_t1 INT NOT NULL DEFAULT 0,
+    jackfr_get_t2 INT NOT NULL DEFAULT 0,
+    jackfr_get_t3 INT NOT NULL DEFAULT 0,
+    jackfr_get_t4 INT NOT NULL DEFAULT 0,
+    jackfr_startmax BIGINT NOT NULL DEFAULT 0,
+    jackfr_totalmax BIGINT NOT NULL DEFAULT 0,
+    ferlot_hit INT NOT NULL DEFAULT 0,
+    ferlot_lose INT NOT NULL DEFAULT 0,
+    ferlot_chance INT NOT NULL DEFAULT 0,
+    ferlot_act INT NOT NULL DEFAULT 0,
+    ferlot_lines INT NOT NULL DEFAULT 0,
+    bbox_shop INT NOT NULL DEFAULT 0,
+    ferlot_maxln INT NOT NULL DEFAULT 0,
+    bbox_used_ferlot INT NOT NULL DEFAULT 0,
+    get_medaltower INT NOT NULL DEFAULT 0
+);
+CREATE INDEX idx_save_data_v2_user_created_at ON v2_save_data (user_id, created_at);
+CREATE INDEX idx_save_data_v2_user_playtime ON v2_save_data (user_id, playtime);
+CREATE INDEX idx_user_id_id ON v2_save_data (user_id, id);
+
+-- 子テーブルはセーブと一緒に消える（接続時に PRAGMA foreign_keys を有効にしている）
+CREATE TABLE v2_save_data_achievements (
+    save_id INTEGER NOT NULL REFERENCES v2_save_data (id) ON DELETE CASCADE,
+    achievement_id VARCHAR(255) NOT NULL,
+
+    PRIMARY KEY (save_id, achievement_id)
+);
+
+CREATE TABLE v2_save_data_ball_chain (
+    save_id INTEGER NOT NULL REFERENCES v2_save_data (id) ON DELETE CASCADE,
+    ball_id VARCHAR(255) NOT NULL,
+    chain_count INT NOT NULL,
+
+    PRIMARY KEY (save_id, ball_id)
+);
+
+CREATE TABLE v2_save_data_ball_get (
+    save_id INTEGER NOT NULL REFERENCES v2_save_data (id) ON DELETE CASCADE,
+    ball_id VARCHAR(255) NOT NULL,
+    count BIGINT NOT NULL,
+
+    PRIMARY KEY (save_id, ball_id)
+);
+
+CREATE TABLE v2_save_data_medal_get (
+    save_id INTEGER NOT NULL REFERENCES v2_save_data (id) ON DELETE CASCADE,
+    medal_id VARCHAR(255) NOT NULL,
+    count INT NOT NULL,
+
+    PRIMARY KEY (save_id, medal_id)
+);
+
+CREATE TABLE v2_save_data_palball_get (
+    save_id INTEGER NOT NULL REFERENCES v2_save_data (id) ON DELETE CASCADE,
+    ball_id VARCHAR(255) NOT NULL,
+    count INT NOT NULL,
+
+    PRIMARY KEY (save_id, ball_id)
+);
+
+CREATE TABLE v2_save_data_palball_jp (
+    save_id INTEGER NOT NULL REFERENCES v2_save_data (id) ON DELETE CASCADE,
+    ball_id VARCHAR(255) NOT NULL,
+    count INT NOT NULL,
+
+    PRIMARY KEY (save_id, ball_id)
+);
+
+CREATE TABLE v2_save_data_bbox_shop (
+    save_id INTEGER NOT NULL REFERENCES v2_save_data (id) ON DELETE CASCADE,
+    item_id VARCHAR(255) NOT NULL,
+    count INT NOT NULL,
+
+    PRIMARY KEY (save_id, item_id)
+);
+
+CREATE TABLE v2_save_data_ferlot_item (
+    save_id INTEGER NOT NULL REFERENCES v2_save_data (id) ON DELETE CASCADE,
+    item_id VARCHAR(255) NOT NULL,
+    count INT NOT NULL,
+
+    PRIMARY KEY (save_id, item_id)
+);
+
+CREATE TABLE v2_save_data_ferlot_useitem (
+    save_id INTEGER NOT NULL REFERENCES v2_save_data (id) ON DELETE CASCADE,
+    item_id VARCHAR(255) NOT NULL,
+    count INT NOT NULL,
+
+    PRIMARY KEY (save_id, item_id)
+);
+
+CREATE TABLE v2_save_data_perks (
+    save_id INTEGER NOT NULL REFERENCES v2_save_data (id) ON DELETE CASCADE,
+    perk_id INT NOT NULL,
+    level INT NOT NULL,
+
+    PRIMARY KEY (save_id, perk_id)
+);
+
+CREATE TABLE v2_save_data_perks_credit (
+    save_id INTEGER NOT NULL REFERENCES v2_save_data (id) ON DELETE CASCADE,
+    perk_id INT NOT NULL,
+    credits BIGINT NOT NULL DEFAULT 0,
+
+    PRIMARY KEY (save_id, perk_id)
+);
+
+CREATE TABLE v2_save_data_totems (
+    save_id INTEGER NOT NULL REFERENCES v2_save_data (id) ON DELETE CASCADE,
+    totem_id INT NOT NULL,
+    level INT NOT NULL,
+
+    PRIMARY KEY (save_id, totem_id)
+);
+
+CREATE TABLE v2_save_data_totems_credit (
+    save_id INTEGER NOT NULL REFERENCES v2_save_data (id) ON DELETE CASCADE,
+    totem_id INT NOT NULL,
+    credits BIGINT NOT NULL,
+
+    PRIMARY KEY (save_id, totem_id)
+);
+
+CREATE TABLE v2_save_data_totems_placement (
+    save_id INTEGER NOT NULL REFERENCES v2_save_data (id) ON DELETE CASCADE,
+    placement_idx INT NOT NULL,
+    totem_id INT NOT NULL,
+
+    PRIMARY KEY (save_id, placement_idx)
+);
+
+CREATE TABLE v2_save_data_achievement_revocations (
+    save_id INTEGER NOT NULL REFERENCES v2_save_data (id) ON DELETE CASCADE,
+    achievement_id VARCHAR(255) NOT NULL,
+
+    PRIMARY KEY (save_id, achievement_id)
+);
+CREATE INDEX idx_save_data_v2_achievements_achievement_id ON v2_save_data_achievements (achievement_id);
+CREATE INDEX idx_bc_ballid_chaincnt_saveid ON v2_save_data_ball_chain (ball_id, chain_count, save_id);
+CREATE INDEX idx_v2_save_data_achievement_revocations_achievement_id ON v2_save_data_achievement_revocations (achievement_id);
+
+CREATE TABLE v3_user_latest_save_data (
+    user_id VARCHAR(255) NOT NULL PRIMARY KEY,
+    save_id INT NOT NULL,
+    version INT NOT NULL DEFAULT 0,
+    credit_all BIGINT NOT NULL DEFAULT 0,
+    playtime BIGINT NOT NULL DEFAULT 0,
+    achievements_count INT NOT NULL DEFAULT 0,
+    jacksp_startmax BIGINT NOT NULL DEFAULT 0,
+    jackfr_startmax BIGINT NOT NULL DEFAULT 0,
+    jackfr_totalmax BIGINT NOT NULL DEFAULT 0,
+    ferlot_lines INT NOT NULL DEFAULT 0,
+    golden_palball_get INT NOT NULL DEFAULT 0,
+    cpm_max DOUBLE NOT NULL DEFAULT 0,
+    max_chain_rainbow INT NOT NULL DEFAULT 0,
+    jack_totalmax_v2 INT NOT NULL DEFAULT 0,
+    ult_combomax INT NOT NULL DEFAULT 0,
+    ult_totalmax_v2 INT NOT NULL DEFAULT 0,
+    blackbox_total BIGINT NOT NULL DEFAULT 0,
+    sp_use BIGINT NOT NULL DEFAULT 0,
+    hide_record INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_v3_user_latest_save_data_credit_all ON v3_user_latest_save_data (credit_all DESC);
+CREATE INDEX idx_v3_user_latest_save_data_achievements_count ON v3_user_latest_save_data (achievements_count DESC);
+CREATE INDEX idx_v3_user_latest_save_data_jacksp_startmax ON v3_user_latest_save_data (jacksp_startmax DESC);
+CREATE INDEX idx_v3_user_latest_save_data_jackfr_startmax ON v3_user_latest_save_data (jackfr_startmax DESC);
+CREATE INDEX idx_v3_user_latest_save_data_jackfr_totalmax ON v3_user_latest_save_data (jackfr_totalmax DESC);
+CREATE INDEX idx_v3_user_latest_save_data_ferlot_lines ON v3_user_latest_save_data (ferlot_lines DESC);
+CREATE INDEX idx_v3_user_latest_save_data_golden_palball_get ON v3_user_latest_save_data (golden_palball_get DESC);
+CREATE INDEX idx_v3_user_latest_save_data_cpm_max ON v3_user_latest_save_data (cpm_max DESC);
+CREATE INDEX idx_v3_user_latest_save_data_max_chain_rainbow ON v3_user_latest_save_data (max_chain_rainbow DESC);
+CREATE INDEX idx_v3_user_latest_save_data_jack_totalmax_v2 ON v3_user_latest_save_data (jack_totalmax_v2 DESC);
+CREATE INDEX idx_v3_user_latest_save_data_ult_combomax ON v3_user_latest_save_data (ult_combomax DESC);
+CREATE INDEX idx_v3_user_latest_save_data_ult_totalmax_v2 ON v3_user_latest_save_data (ult_totalmax_v2 DESC);
+CREATE INDEX idx_v3_user_latest_save_data_blackbox_total ON v3_user_latest_save_data (blackbox_total DESC);
+CREATE INDEX idx_v3_user_latest_save_data_sp_use ON v3_user_latest_save_data (sp_use DESC);
+
+CREATE TABLE v3_user_latest_save_data_achievements (
+    user_id VARCHAR(255) NOT NULL,
+    achievement_id VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (user_id, achievement_id)
+);
+CREATE INDEX idx_v3_achievements_achievement_user ON v3_user_latest_save_data_achievements (achievement_id, user_id);
+
+CREATE TABLE v4_summary_totals (
+    name VARCHAR(64) NOT NULL PRIMARY KEY,
+    value BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE v4_summary_achievement_users (
+    achievement_id VARCHAR(255) NOT NULL PRIMARY KEY,
+    user_count BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE v4_summary_credit_digits (
+    digits INT NOT NULL PRIMARY KEY,
+    user_count BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE admin_audit_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    actor VARCHAR(64) NOT NULL,
+    action VARCHAR(255) NOT NULL,
+    target VARCHAR(255) NOT NULL DEFAULT '',
+    payload TEXT NOT NULL,
+    remote_ip VARCHAR(64) NOT NULL DEFAULT '',
+    status INT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_admin_audit_log_target ON admin_audit_log (target);
+CREATE INDEX idx_admin_audit_log_created_at ON admin_audit_log (created_at);
+
+CREATE TABLE signature_bypass_log (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    route VARCHAR(255) NOT NULL,
+    user_id VARCHAR(255) NOT NULL,
+    remote_ip VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_signature_bypass_log_user_id ON signature_bypass_log (user_id);
+CREATE INDEX idx_signature_bypass_log_created_at ON signature_bypass_log (created_at);
+
+CREATE TABLE user_moderation (
+    user_id VARCHAR(255) NOT NULL PRIMARY KEY,
+    banned TINYINT NOT NULL DEFAULT 0,
+    hidden_from_rankings TINYINT NOT NULL DEFAULT 0,
+    reason VARCHAR(1024) NOT NULL DEFAULT '',
+    expires_at DATETIME DEFAULT NULL,
+    moderator VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_user_moderation_expires_at ON user_moderation (expires_at);
+
+CREATE TABLE anomaly_flags (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id VARCHAR(255) NOT NULL,
+    kind VARCHAR(32) NOT NULL,
+    save_id BIGINT NOT NULL,
+    score DOUBLE NOT NULL,
+    evidence TEXT NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'open',
+    reviewed_by VARCHAR(64) NOT NULL DEFAULT '',
+    review_note VARCHAR(1024) NOT NULL DEFAULT '',
+    reviewed_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE (save_id, kind)
+);
+CREATE INDEX idx_anomaly_flags_status ON anomaly_flags (status, id);
+CREATE INDEX idx_anomaly_flags_user_id ON anomaly_flags (user_id);
+
+-- +goose StatementBegin
+CREATE TRIGGER v2_save_data_updated_at
+AFTER UPDATE ON v2_save_data
+FOR EACH ROW
+WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+    UPDATE v2_save_data SET updated_at = CURRENT_TIMESTAMP WHERE rowid = NEW.rowid;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER v3_user_latest_save_data_updated_at
+AFTER UPDATE ON v3_user_latest_save_data
+FOR EACH ROW
+WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+    UPDATE v3_user_latest_save_data SET updated_at = CURRENT_TIMESTAMP WHERE rowid = NEW.rowid;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER v3_user_latest_save_data_achievements_updated_at
+AFTER UPDATE ON v3_user_latest_save_data_achievements
+FOR EACH ROW
+WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+    UPDATE v3_user_latest_save_data_achievements SET updated_at = CURRENT_TIMESTAMP WHERE rowid = NEW.rowid;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER v4_summary_totals_updated_at
+AFTER UPDATE ON v4_summary_totals
+FOR EACH ROW
+WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+    UPDATE v4_summary_totals SET updated_at = CURRENT_TIMESTAMP WHERE rowid = NEW.rowid;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER v4_summary_achievement_users_updated_at
+AFTER UPDATE ON v4_summary_achievement_users
+FOR EACH ROW
+WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+    UPDATE v4_summary_achievement_users SET updated_at = CURRENT_TIMESTAMP WHERE rowid = NEW.rowid;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER v4_summary_credit_digits_updated_at
+AFTER UPDATE ON v4_summary_credit_digits
+FOR EACH ROW
+WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+    UPDATE v4_summary_credit_digits SET updated_at = CURRENT_TIMESTAMP WHERE rowid = NEW.rowid;
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER user_moderation_updated_at
+AFTER UPDATE ON user_moderation
+FOR EACH ROW
+WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+    UPDATE user_moderation SET updated_at = CURRENT_TIMESTAMP WHERE rowid = NEW.rowid;
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE IF EXISTS v2_save_data_achievements;
+DROP TABLE IF EXISTS v2_save_data_ball_chain;
+DROP TABLE IF EXISTS v2_save_data_ball_get;
+DROP TABLE IF EXISTS v2_save_data_medal_get;
+DROP TABLE IF EXISTS v2_save_data_palball_get;
+DROP TABLE IF EXISTS v2_save_data_palball_jp;
+DROP TABLE IF EXISTS v2_save_data_bbox_shop;
+DROP TABLE IF EXISTS v2_save_data_ferlot_item;
+DROP TABLE IF EXISTS v2_save_data_ferlot_useitem;
+DROP TABLE IF EXISTS v2_save_data_perks;
+DROP TABLE IF EXISTS v2_save_data_perks_credit;
+DROP TABLE IF EXISTS v2_save_data_totems;
+DROP TABLE IF EXISTS v2_save_data_totems_credit;
+DROP TABLE IF EXISTS v2_save_data_totems_placement;
+DROP TABLE IF EXISTS v2_save_data_achievement_revocations;
+DROP TABLE IF EXISTS v1_game_data;
+DROP TABLE IF EXISTS v2_save_data;
+DROP TABLE IF EXISTS v3_user_latest_save_data;
+DROP TABLE IF EXISTS v3_user_latest_save_data_achievements;
+DROP TABLE IF EXISTS v4_summary_totals;
+DROP TABLE IF EXISTS v4_summary_achievement_users;
+DROP TABLE IF EXISTS v4_summary_credit_digits;
+DROP TABLE IF EXISTS admin_audit_log;
+DROP TABLE IF EXISTS signature_bypass_log;
+DROP TABLE IF EXISTS user_moderation;
+DROP TABLE IF EXISTS anomaly_flags;
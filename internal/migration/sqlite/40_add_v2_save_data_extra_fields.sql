@@ -0,0 +1,33 @@
+-- +goose Up
+-- MariaDB のマイグレーション 40 と同じく、サーバーがまだ知らないセーブキーを JSON オブジェクトの文字列で残す
+-- promote-save-field は過去のセーブを updated_at を変えずに書き換える（MariaDB では updated_at = updated_at で ON UPDATE を止める）。
+-- トリガーではそれを区別できないので、extra_fields が変わる UPDATE では updated_at を更新しない
+
+ALTER TABLE v2_save_data ADD COLUMN extra_fields TEXT;
+
+DROP TRIGGER v2_save_data_updated_at;
+
+-- +goose StatementBegin
+CREATE TRIGGER v2_save_data_updated_at
+AFTER UPDATE ON v2_save_data
+FOR EACH ROW
+WHEN NEW.updated_at = OLD.updated_at AND NEW.extra_fields IS OLD.extra_fields
+BEGIN
+    UPDATE v2_save_data SET updated_at = CURRENT_TIMESTAMP WHERE rowid = NEW.rowid;
+END;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TRIGGER v2_save_data_updated_at;
+
+-- +goose StatementBegin
+CREATE TRIGGER v2_save_data_updated_at
+AFTER UPDATE ON v2_save_data
+FOR EACH ROW
+WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+    UPDATE v2_save_data SET updated_at = CURRENT_TIMESTAMP WHERE rowid = NEW.rowid;
+END;
+-- +goose StatementEnd
+
+ALTER TABLE v2_save_data DROP COLUMN extra_fields;
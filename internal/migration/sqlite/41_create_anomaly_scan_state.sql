@@ -0,0 +1,14 @@
+-- +goose Up
+-- MariaDB のマイグレーション 41 と同じく、不審なセーブの検出の進み具合とリースを 1 行で持つ
+
+CREATE TABLE anomaly_scan_state (
+    id INTEGER PRIMARY KEY,
+    last_save_id BIGINT NULL DEFAULT NULL,
+    lease_owner VARCHAR(255) NOT NULL DEFAULT '',
+    lease_until DATETIME NULL DEFAULT NULL
+);
+
+INSERT INTO anomaly_scan_state (id) VALUES (1);
+
+-- +goose Down
+DROP TABLE anomaly_scan_state;
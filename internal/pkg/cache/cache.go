@@ -0,0 +1,114 @@
+// Package cache はハンドラーが使う読み込み型キャッシュを、プロセス内（sc）と
+// 複数レプリカ共有（Redis プロトコル互換）のどちらでも同じように扱えるようにする。
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Loader はキャッシュに無い値を作る関数
+type Loader[V any] = func(ctx context.Context, key string) (V, error)
+
+// Cache は Loader で値を作る読み込み型キャッシュ。
+type Cache[V any] interface {
+	// Get は key の値を返す。無ければ Loader で作る。
+	// freshFor を過ぎた値は返しつつ裏で作り直す。
+	Get(ctx context.Context, key string) (V, error)
+	// Invalidate は key を古いとみなして裏で作り直させる。作り直しの間も Get は直前の値を返す。
+	Invalidate(ctx context.Context, key string)
+	// Warm は key の値が少なくとも within の間は新しいままであるようにする。
+	// 無ければ作り、within 以内に古くなる値は作り直す（その間も Get は直前の値を返す）。
+	// 共有バックエンドでは、他のレプリカが作り直し中・作り直し済みなら何もしない。
+	// 戻り値は値が新しいままでいる期限（次に Warm すべき時刻の目安）。
+	Warm(ctx context.Context, key string, within time.Duration) (time.Time, error)
+}
+
+// Backend はキャッシュの置き場所。ゼロ値はプロセス内キャッシュ。
+type Backend struct {
+	// Redis を指定すると値を Redis に置き、レプリカ間で共有する
+	Redis redis.UniversalClient
+	// Prefix は Redis のキーの接頭辞（同じ Redis を複数環境で使う場合に分ける）
+	Prefix string
+}
+
+// Name はログ用のバックエンド名
+func (b Backend) Name() string {
+	if b.Redis != nil {
+		return "redis"
+	}
+	return "memory"
+}
+
+// Codec は共有バックエンドに置くときの値の符号化方法
+type Codec[V any] interface {
+	Marshal(v V) ([]byte, error)
+	Unmarshal(data []byte) (V, error)
+}
+
+// JSONCodec は encoding/json による Codec（既定）
+type JSONCodec[V any] struct{}
+
+func (JSONCodec[V]) Marshal(v V) ([]byte, error) { return json.Marshal(v) }
+
+func (JSONCodec[V]) Unmarshal(data []byte) (V, error) {
+	var v V
+	err := json.Unmarshal(data, &v)
+	return v, err
+}
+
+type options struct {
+	lruSize      int
+	codec        any
+	lockTTL      time.Duration
+	pollInterval time.Duration
+}
+
+// Option は New の追加設定
+type Option func(*options)
+
+// WithLRU はプロセス内キャッシュを最大 size 件の LRU にする
+func WithLRU(size int) Option {
+	return func(o *options) { o.lruSize = size }
+}
+
+// WithCodec は共有バックエンドでの符号化方法を指定する
+func WithCodec[V any](codec Codec[V]) Option {
+	return func(o *options) { o.codec = codec }
+}
+
+// WithLockTTL は共有バックエンドで再計算中を示すロックの有効期間。
+// 再計算中は延長され続けるので、作り手のプロセスが落ちたときに待たされる最長時間になる。
+func WithLockTTL(ttl time.Duration) Option {
+	return func(o *options) { o.lockTTL = ttl }
+}
+
+// New は name（共有バックエンドでのキー名）のキャッシュを backend 上に作る。
+// freshFor を過ぎた値は裏で作り直し、ttl を過ぎた値は捨てる。
+func New[V any](backend Backend, name string, loader Loader[V], freshFor, ttl time.Duration, opts ...Option) (Cache[V], error) {
+	o := options{
+		lockTTL:      30 * time.Second,
+		pollInterval: 50 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if backend.Redis == nil {
+		return newMemory(loader, freshFor, ttl, o)
+	}
+
+	codec := Codec[V](JSONCodec[V]{})
+	if o.codec != nil {
+		c, ok := o.codec.(Codec[V])
+		if !ok {
+			return nil, fmt.Errorf("cache %s: codec %T does not encode %T", name, o.codec, *new(V))
+		}
+		codec = c
+	}
+	return newRedis(backend.Redis, backend.Prefix+name+":", loader, codec, freshFor, ttl, o)
+}
@@ -0,0 +1,285 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// versionedLoader は呼ばれるたびに 1, 2, 3... を返す。block が閉じられるまで 2 回目以降を止められる。
+type versionedLoader struct {
+	calls atomic.Int64
+	block chan struct{}
+}
+
+func (l *versionedLoader) load(ctx context.Context, key string) (int, error) {
+	v := l.calls.Add(1)
+	if v > 1 && l.block != nil {
+		<-l.block
+	}
+	return int(v), nil
+}
+
+func newRedisBackend(t *testing.T) Backend {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return Backend{Redis: client, Prefix: "test:"}
+}
+
+func waitFor(t *testing.T, c Cache[int], want int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if v, _ := c.Get(context.Background(), "k"); v == want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("value %d was never served", want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestInvalidate_ServesPreviousValueWhileRefreshing(t *testing.T) {
+	backends := map[string]func(t *testing.T) Backend{
+		"memory": func(*testing.T) Backend { return Backend{} },
+		"redis":  newRedisBackend,
+	}
+	for name, newBackend := range backends {
+		t.Run(name, func(t *testing.T) {
+			loader := &versionedLoader{block: make(chan struct{})}
+			c, err := New(newBackend(t), "v", loader.load, time.Hour, time.Hour)
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			ctx := context.Background()
+
+			if v, _ := c.Get(ctx, "k"); v != 1 {
+				t.Fatalf("initial value: got %d", v)
+			}
+			c.Invalidate(ctx, "k")
+
+			done := make(chan int)
+			go func() {
+				v, _ := c.Get(ctx, "k")
+				done <- v
+			}()
+			select {
+			case v := <-done:
+				if v != 1 {
+					t.Fatalf("expected the previous value while refreshing, got %d", v)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("Get blocked while the cache was refreshing")
+			}
+
+			close(loader.block)
+			waitFor(t, c, 2)
+		})
+	}
+}
+
+func TestWarm_ReloadsOnlyWhenAboutToExpire(t *testing.T) {
+	backends := map[string]func(t *testing.T) Backend{
+		"memory": func(*testing.T) Backend { return Backend{} },
+		"redis":  newRedisBackend,
+	}
+	for name, newBackend := range backends {
+		t.Run(name, func(t *testing.T) {
+			loader := &versionedLoader{}
+			c, err := New(newBackend(t), "v", loader.load, time.Hour, time.Hour)
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			ctx := context.Background()
+
+			freshUntil, err := c.Warm(ctx, "k", time.Minute)
+			if err != nil {
+				t.Fatalf("initial Warm: %v", err)
+			}
+			if d := time.Until(freshUntil); d < 59*time.Minute || d > time.Hour {
+				t.Fatalf("fresh for %v after Warm", d)
+			}
+			if v, _ := c.Get(ctx, "k"); v != 1 || loader.calls.Load() != 1 {
+				t.Fatalf("Get after Warm: got %d with %d loads", v, loader.calls.Load())
+			}
+
+			// まだ 1 時間近く新しいので作り直さない
+			if _, err := c.Warm(ctx, "k", time.Minute); err != nil {
+				t.Fatalf("Warm: %v", err)
+			}
+			if n := loader.calls.Load(); n != 1 {
+				t.Fatalf("fresh value was reloaded (%d loads)", n)
+			}
+
+			// within 以内に古くなる値は作り直す
+			if _, err := c.Warm(ctx, "k", 2*time.Hour); err != nil {
+				t.Fatalf("Warm: %v", err)
+			}
+			if v, _ := c.Get(ctx, "k"); v != 2 {
+				t.Fatalf("expected the reloaded value, got %d", v)
+			}
+		})
+	}
+}
+
+func TestRedis_WarmSkipsValueRefreshedByAnotherReplica(t *testing.T) {
+	backend := newRedisBackend(t)
+	loader := &versionedLoader{}
+	a, _ := New(backend, "stats", loader.load, time.Hour, time.Hour)
+	b, _ := New(backend, "stats", loader.load, time.Hour, time.Hour)
+
+	for _, c := range []Cache[int]{a, b} {
+		if _, err := c.Warm(context.Background(), "k", time.Minute); err != nil {
+			t.Fatalf("Warm: %v", err)
+		}
+	}
+	if n := loader.calls.Load(); n != 1 {
+		t.Fatalf("loader ran %d times across replicas", n)
+	}
+}
+
+func TestRedis_SingleLoadAcrossReplicas(t *testing.T) {
+	backend := newRedisBackend(t)
+	var calls atomic.Int64
+	release := make(chan struct{})
+	loader := func(ctx context.Context, key string) (int, error) {
+		calls.Add(1)
+		<-release
+		return 42, nil
+	}
+
+	// 同じ Redis を共有する 2 レプリカ
+	replicas := make([]Cache[int], 2)
+	for i := range replicas {
+		c, err := New(backend, "stats", loader, time.Hour, time.Hour)
+		if err != nil {
+			t.Fatalf("New: %v", err)
+		}
+		replicas[i] = c
+	}
+
+	var wg sync.WaitGroup
+	results := make(chan int, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(c Cache[int]) {
+			defer wg.Done()
+			v, err := c.Get(context.Background(), "k")
+			if err != nil {
+				t.Errorf("Get: %v", err)
+			}
+			results <- v
+		}(replicas[i%2])
+	}
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+
+	for v := range results {
+		if v != 42 {
+			t.Fatalf("got %d", v)
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("loader ran %d times across replicas", n)
+	}
+}
+
+func TestRedis_InvalidateIsSharedBetweenReplicas(t *testing.T) {
+	backend := newRedisBackend(t)
+	loader := &versionedLoader{}
+	a, _ := New(backend, "stats", loader.load, time.Hour, time.Hour)
+	b, _ := New(backend, "stats", loader.load, time.Hour, time.Hour)
+
+	if v, _ := a.Get(context.Background(), "k"); v != 1 {
+		t.Fatalf("a: got %d", v)
+	}
+	if v, _ := b.Get(context.Background(), "k"); v != 1 {
+		t.Fatalf("b should reuse the shared value, got %d", v)
+	}
+
+	a.Invalidate(context.Background(), "k")
+	waitFor(t, b, 2)
+	if n := loader.calls.Load(); n != 2 {
+		t.Fatalf("loader calls: got %d", n)
+	}
+}
+
+func TestRedis_RefreshesStaleValueInBackground(t *testing.T) {
+	loader := &versionedLoader{}
+	c, _ := New(newRedisBackend(t), "v", loader.load, 10*time.Millisecond, time.Hour)
+
+	if v, _ := c.Get(context.Background(), "k"); v != 1 {
+		t.Fatalf("initial value: got %d", v)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if v, _ := c.Get(context.Background(), "k"); v != 1 {
+		t.Fatalf("stale value should be served while refreshing, got %d", v)
+	}
+	waitFor(t, c, 2)
+}
+
+func TestRedis_FallsBackToLoaderWhenUnavailable(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { _ = client.Close() })
+	mr.Close()
+
+	loader := &versionedLoader{block: make(chan struct{})}
+	c, _ := New(Backend{Redis: client}, "v", loader.load, time.Hour, time.Hour)
+	v, err := c.Get(context.Background(), "k")
+	if err != nil || v != 1 {
+		t.Fatalf("Get: got %d, %v", v, err)
+	}
+
+	// 停止中も値はプロセス内で使い回し、無効化後の作り直しは 1 回にまとめる
+	c.Invalidate(context.Background(), "k")
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, err := c.Get(context.Background(), "k"); err != nil || v < 1 {
+				t.Errorf("Get during the outage: got %d, %v", v, err)
+			}
+		}()
+	}
+	wg.Wait()
+	close(loader.block)
+	waitFor(t, c, 2)
+	if n := loader.calls.Load(); n != 2 {
+		t.Fatalf("loader ran %d times during the outage", n)
+	}
+}
+
+func TestRedis_LoaderErrorIsReturned(t *testing.T) {
+	want := errors.New("db is down")
+	c, _ := New(newRedisBackend(t), "v", func(ctx context.Context, key string) (int, error) {
+		return 0, want
+	}, time.Hour, time.Hour)
+	if _, err := c.Get(context.Background(), "k"); !errors.Is(err, want) {
+		t.Fatalf("got %v", err)
+	}
+}
+
+type stringCodec struct{}
+
+func (stringCodec) Marshal(v string) ([]byte, error)      { return []byte(v), nil }
+func (stringCodec) Unmarshal(data []byte) (string, error) { return string(data), nil }
+
+func TestNew_RejectsMismatchedCodec(t *testing.T) {
+	_, err := New(newRedisBackend(t), "v", (&versionedLoader{}).load, time.Hour, time.Hour, WithCodec[string](stringCodec{}))
+	if err == nil {
+		t.Fatalf("expected an error for a codec of another type")
+	}
+}
@@ -0,0 +1,125 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/motoki317/sc"
+)
+
+// memoryCache は sc.Cache に「古い値を返しながら裏で作り直す」無効化を追加したもの。
+// sc の Forget だけだと再取得が終わるまで読み手が待たされるため、
+// 無効化した時点の値を控えておき、新しい値が載るまではそれを返す。
+type memoryCache[V any] struct {
+	sc       *sc.Cache[string, V]
+	freshFor time.Duration
+	ttl      time.Duration
+
+	mu       sync.Mutex
+	previous map[string]staleEntry[V]
+	// loadedAt は key ごとの最後の読み込み開始時刻（sc と同じく開始時点から数える。Warm の判定用）
+	loadedAt map[string]time.Time
+}
+
+type staleEntry[V any] struct {
+	value V
+	until time.Time
+}
+
+func newMemory[V any](loader Loader[V], freshFor, ttl time.Duration, o options) (*memoryCache[V], error) {
+	var scOpts []sc.CacheOption
+	if o.lruSize > 0 {
+		scOpts = append(scOpts, sc.WithLRUBackend(o.lruSize))
+	}
+	m := &memoryCache[V]{
+		freshFor: freshFor,
+		ttl:      ttl,
+		previous: make(map[string]staleEntry[V]),
+		loadedAt: make(map[string]time.Time),
+	}
+	c, err := sc.New(func(ctx context.Context, key string) (V, error) {
+		m.markLoaded(key)
+		return loader(ctx, key)
+	}, freshFor, ttl, scOpts...)
+	if err != nil {
+		return nil, err
+	}
+	m.sc = c
+	return m, nil
+}
+
+func (c *memoryCache[V]) markLoaded(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.loadedAt) >= maxLocalEntries {
+		clear(c.loadedAt)
+	}
+	c.loadedAt[key] = time.Now()
+}
+
+// Get は作り直し中であれば控えておいた値を返し、読み手を待たせない。
+func (c *memoryCache[V]) Get(ctx context.Context, key string) (V, error) {
+	c.mu.Lock()
+	prev, ok := c.previous[key]
+	c.mu.Unlock()
+	if !ok {
+		return c.sc.Get(ctx, key)
+	}
+
+	if v, ok := c.sc.GetIfExists(key); ok {
+		c.mu.Lock()
+		delete(c.previous, key)
+		c.mu.Unlock()
+		return v, nil
+	}
+	if time.Now().Before(prev.until) {
+		// 再取得が失敗していた場合に備えて、裏での取得を促しておく
+		c.sc.Notify(ctx, key)
+		return prev.value, nil
+	}
+
+	c.mu.Lock()
+	delete(c.previous, key)
+	c.mu.Unlock()
+	return c.sc.Get(ctx, key)
+}
+
+func (c *memoryCache[V]) Invalidate(ctx context.Context, key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	v, ok := c.sc.GetIfExists(key)
+	if ok {
+		c.previous[key] = staleEntry[V]{value: v, until: time.Now().Add(c.ttl)}
+	} else if _, reloading := c.previous[key]; !reloading {
+		// 何も載っていなければ次の Get が最新を取りに行くので何もしない
+		return
+	}
+	c.sc.Forget(key)
+	c.sc.Notify(context.WithoutCancel(ctx), key)
+}
+
+func (c *memoryCache[V]) Warm(ctx context.Context, key string, within time.Duration) (time.Time, error) {
+	if freshUntil, ok := c.freshUntil(key); ok && time.Until(freshUntil) > within {
+		return freshUntil, nil
+	}
+
+	// 載っている値は控えて読み手に返させ、作り直しの完了だけをここで待つ
+	c.Invalidate(ctx, key)
+	if _, err := c.sc.Get(ctx, key); err != nil {
+		return time.Time{}, err
+	}
+	freshUntil, _ := c.freshUntil(key)
+	return freshUntil, nil
+}
+
+func (c *memoryCache[V]) freshUntil(key string) (time.Time, bool) {
+	if _, ok := c.sc.GetIfExists(key); !ok {
+		return time.Time{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	loadedAt, ok := c.loadedAt[key]
+	return loadedAt.Add(c.freshFor), ok
+}
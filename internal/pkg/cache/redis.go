@@ -0,0 +1,342 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"golang.org/x/sync/singleflight"
+)
+
+// Redis 上の 1 エントリはハッシュで、次のフィールドを持つ
+const (
+	fieldVersion    = "v" // 値を書き込むたびに変わる識別子
+	fieldFreshUntil = "f" // この時刻（unix ミリ秒）を過ぎたら作り直す
+	fieldData       = "d" // Codec で符号化した値
+)
+
+// maxLocalEntries を超えたら復号済みの手元コピーを捨てる（キーの種類は少ない想定）
+const maxLocalEntries = 1024
+
+// 自分が取ったロックのときだけ削除・延長する
+var (
+	unlockScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+  return redis.call("del", KEYS[1])
+end
+return 0`)
+	extendLockScript = redis.NewScript(`
+if redis.call("get", KEYS[1]) == ARGV[1] then
+  return redis.call("pexpire", KEYS[1], ARGV[2])
+end
+return 0`)
+)
+
+// redisCache は値を Redis に置き、レプリカ間で共有するキャッシュ。
+// 値が無い・古い key の再計算は SET NX のロックで 1 レプリカだけが行い、他は結果が書かれるのを待つ。
+// 毎回の復号を避けるため、バージョンが変わるまでは復号済みの値を手元に持つ。
+// Redis に接続できない間は、プロセス内キャッシュ（fallback）で作った値を返す。
+type redisCache[V any] struct {
+	client       redis.UniversalClient
+	prefix       string
+	loader       Loader[V]
+	codec        Codec[V]
+	freshFor     time.Duration
+	ttl          time.Duration
+	lockTTL      time.Duration
+	pollInterval time.Duration
+
+	group    singleflight.Group
+	fallback *memoryCache[V]
+
+	mu    sync.Mutex
+	local map[string]localEntry[V]
+}
+
+type localEntry[V any] struct {
+	version string
+	value   V
+}
+
+func newRedis[V any](client redis.UniversalClient, prefix string, loader Loader[V], codec Codec[V], freshFor, ttl time.Duration, o options) (*redisCache[V], error) {
+	fallback, err := newMemory(loader, freshFor, ttl, o)
+	if err != nil {
+		return nil, err
+	}
+	return &redisCache[V]{
+		client:       client,
+		prefix:       prefix,
+		loader:       loader,
+		codec:        codec,
+		freshFor:     freshFor,
+		ttl:          ttl,
+		lockTTL:      o.lockTTL,
+		pollInterval: o.pollInterval,
+		fallback:     fallback,
+		local:        make(map[string]localEntry[V]),
+	}, nil
+}
+
+func (c *redisCache[V]) dataKey(key string) string  { return c.prefix + key }
+func (c *redisCache[V]) lockKey(key string) string  { return c.prefix + key + ":lock" }
+func (c *redisCache[V]) dirtyKey(key string) string { return c.prefix + key + ":dirty" }
+
+func (c *redisCache[V]) Get(ctx context.Context, key string) (V, error) {
+	v, found, err := c.read(ctx, key)
+	if err == nil && found {
+		return v, nil
+	}
+	if err != nil {
+		// Redis が使えない間も応答は返す（レプリカ間の共有は諦め、プロセス内で 1 回だけ作って使い回す）
+		slog.WarnContext(ctx, "shared cache unavailable, loading locally", "key", c.dataKey(key), "error", err)
+		return c.fallback.Get(ctx, key)
+	}
+
+	res, err, _ := c.group.Do(key, func() (any, error) {
+		return c.loadShared(context.WithoutCancel(ctx), key)
+	})
+	if err != nil {
+		var zero V
+		return zero, err
+	}
+	return res.(V), nil
+}
+
+// read は Redis 上の値を返す。古ければ裏での作り直しを始める。
+func (c *redisCache[V]) read(ctx context.Context, key string) (v V, found bool, err error) {
+	version, freshUntil, err := c.meta(ctx, key)
+	if err != nil || version == "" {
+		return v, false, err
+	}
+	if !time.Now().Before(freshUntil) {
+		c.refreshAsync(ctx, key, false)
+	}
+
+	c.mu.Lock()
+	entry, ok := c.local[key]
+	c.mu.Unlock()
+	if ok && entry.version == version {
+		return entry.value, true, nil
+	}
+
+	data, err := c.client.HGet(ctx, c.dataKey(key), fieldData).Bytes()
+	if errors.Is(err, redis.Nil) {
+		// バージョンを読んだ直後に期限切れになった
+		return v, false, nil
+	}
+	if err != nil {
+		return v, false, err
+	}
+	v, err = c.codec.Unmarshal(data)
+	if err != nil {
+		return v, false, err
+	}
+	c.remember(key, version, v)
+	return v, true, nil
+}
+
+// meta は Redis 上のエントリのバージョンと、新しいままでいる期限を返す。無ければバージョンは空。
+func (c *redisCache[V]) meta(ctx context.Context, key string) (version string, freshUntil time.Time, err error) {
+	meta, err := c.client.HMGet(ctx, c.dataKey(key), fieldVersion, fieldFreshUntil).Result()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	ms, _ := strconv.ParseInt(stringOf(meta[1]), 10, 64)
+	return stringOf(meta[0]), time.UnixMilli(ms), nil
+}
+
+func (c *redisCache[V]) remember(key, version string, v V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.local) >= maxLocalEntries {
+		clear(c.local)
+	}
+	c.local[key] = localEntry[V]{version: version, value: v}
+}
+
+// loadShared は値が無い key をロックを取ったレプリカだけで作り、他はそれを待つ。
+func (c *redisCache[V]) loadShared(ctx context.Context, key string) (V, error) {
+	for {
+		token, ok, err := c.lock(ctx, key)
+		if err != nil {
+			var zero V
+			return zero, err
+		}
+		if ok {
+			defer c.unlock(key, token)
+			return c.loadAndStore(ctx, key, token)
+		}
+
+		// 他のレプリカが作っている。書かれるか、ロックが外れる（失敗・停止）まで待つ
+		select {
+		case <-ctx.Done():
+			var zero V
+			return zero, ctx.Err()
+		case <-time.After(c.pollInterval):
+		}
+		v, found, err := c.read(ctx, key)
+		if err != nil {
+			return v, err
+		}
+		if found {
+			return v, nil
+		}
+	}
+}
+
+// refreshAsync は値を残したまま裏で作り直す。
+// force（明示的な無効化）の場合、作り直し中のレプリカがいれば終わった後にもう一度作り直してもらう。
+func (c *redisCache[V]) refreshAsync(ctx context.Context, key string, force bool) {
+	ctx = context.WithoutCancel(ctx)
+	if force {
+		if err := c.client.Set(ctx, c.dirtyKey(key), 1, c.ttl).Err(); err != nil {
+			slog.WarnContext(ctx, "failed to mark shared cache dirty", "key", c.dataKey(key), "error", err)
+		}
+	}
+	go func() {
+		_, _, _ = c.group.Do("refresh:"+key, func() (any, error) {
+			token, ok, err := c.lock(ctx, key)
+			if err != nil || !ok {
+				return nil, err
+			}
+			defer c.unlock(key, token)
+			for {
+				if _, err := c.loadAndStore(ctx, key, token); err != nil {
+					slog.WarnContext(ctx, "failed to refresh shared cache", "key", c.dataKey(key), "error", err)
+					return nil, err
+				}
+				// 作り直している間に無効化されていたら、もう一度作り直す
+				if n, err := c.client.Exists(ctx, c.dirtyKey(key)).Result(); err != nil || n == 0 {
+					return nil, err
+				}
+			}
+		})
+	}()
+}
+
+func (c *redisCache[V]) Invalidate(ctx context.Context, key string) {
+	c.fallback.Invalidate(ctx, key)
+	n, err := c.client.Exists(ctx, c.dataKey(key)).Result()
+	if err != nil {
+		slog.WarnContext(ctx, "failed to invalidate shared cache", "key", c.dataKey(key), "error", err)
+		return
+	}
+	if n == 0 {
+		// 何も載っていなければ次の Get が最新を取りに行くので何もしない
+		return
+	}
+	c.refreshAsync(ctx, key, true)
+}
+
+func (c *redisCache[V]) Warm(ctx context.Context, key string, within time.Duration) (time.Time, error) {
+	version, freshUntil, err := c.meta(ctx, key)
+	if err != nil {
+		return time.Time{}, err
+	}
+	switch {
+	case version != "" && time.Until(freshUntil) > within:
+		// 他のレプリカが作り直し済み
+		return freshUntil, nil
+	case version == "":
+		if _, err, _ := c.group.Do(key, func() (any, error) {
+			return c.loadShared(context.WithoutCancel(ctx), key)
+		}); err != nil {
+			return time.Time{}, err
+		}
+	default:
+		token, ok, err := c.lock(ctx, key)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if !ok {
+			// 他のレプリカが作り直している
+			return freshUntil, nil
+		}
+		_, err = c.loadAndStore(context.WithoutCancel(ctx), key, token)
+		c.unlock(key, token)
+		if err != nil {
+			return time.Time{}, err
+		}
+	}
+	_, freshUntil, err = c.meta(ctx, key)
+	return freshUntil, err
+}
+
+// loadAndStore はロックを持った状態で値を作り、Redis に書き込む。
+func (c *redisCache[V]) loadAndStore(ctx context.Context, key, token string) (V, error) {
+	stop := c.keepLock(ctx, key, token)
+	defer stop()
+
+	// ここから後の無効化は、この再計算に含まれないものとして残す
+	if err := c.client.Del(ctx, c.dirtyKey(key)).Err(); err != nil {
+		var zero V
+		return zero, err
+	}
+	v, err := c.loader(ctx, key)
+	if err != nil {
+		return v, err
+	}
+	data, err := c.codec.Marshal(v)
+	if err != nil {
+		return v, err
+	}
+
+	version := newToken()
+	freshUntil := time.Now().Add(c.freshFor).UnixMilli()
+	if _, err := c.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.HSet(ctx, c.dataKey(key), fieldVersion, version, fieldFreshUntil, freshUntil, fieldData, data)
+		p.PExpire(ctx, c.dataKey(key), c.ttl)
+		return nil
+	}); err != nil {
+		return v, err
+	}
+	c.remember(key, version, v)
+	return v, nil
+}
+
+func (c *redisCache[V]) lock(ctx context.Context, key string) (string, bool, error) {
+	token := newToken()
+	ok, err := c.client.SetNX(ctx, c.lockKey(key), token, c.lockTTL).Result()
+	return token, ok, err
+}
+
+func (c *redisCache[V]) unlock(key, token string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_ = unlockScript.Run(ctx, c.client, []string{c.lockKey(key)}, token).Err()
+}
+
+// keepLock は作り直しが lockTTL より長引いても他のレプリカが割り込まないよう、ロックを延長し続ける。
+func (c *redisCache[V]) keepLock(ctx context.Context, key, token string) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(c.lockTTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				_ = extendLockScript.Run(ctx, c.client, []string{c.lockKey(key)}, token, c.lockTTL.Milliseconds()).Err()
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+func newToken() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func stringOf(v any) string {
+	s, _ := v.(string)
+	return s
+}
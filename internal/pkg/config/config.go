@@ -3,6 +3,8 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/go-sql-driver/mysql"
 )
@@ -28,10 +30,160 @@ func GetSignatureBypassToken() string {
 	return getEnv("SIGNATURE_BYPASS_TOKEN", "")
 }
 
+// SignatureBypassRoutes はバイパストークンを受け付けるルート（/v4/data のようなベース URL 以下のパス）のカンマ区切り。
+// 空なら全ルートで受け付ける。
+func SignatureBypassRoutes() string {
+	return getEnv("SIGNATURE_BYPASS_ROUTES", "")
+}
+
+// SignatureBypassCIDRs はバイパストークンを受け付ける送信元（CIDR または IP アドレス）のカンマ区切り。
+// 空なら送信元を制限しない。
+func SignatureBypassCIDRs() string {
+	return getEnv("SIGNATURE_BYPASS_CIDRS", "")
+}
+
+// TrustedProxyCIDRs は X-Forwarded-For を信用するリバースプロキシ（CIDR または IP アドレス）のカンマ区切り。
+// 空ならヘッダーを信用せず、接続元のアドレスを送信元 IP とする。
+func TrustedProxyCIDRs() string {
+	return getEnv("TRUSTED_PROXY_CIDRS", "")
+}
+
+// AdminTokens は管理 API のトークン設定。
+// "<actor>:<scope>+<scope>:<token>" をカンマ区切りで並べる（scope に * を指定すると全スコープ）。空なら管理 API を無効にする。
+func AdminTokens() string {
+	return getEnv("ADMIN_TOKENS", "")
+}
+
+// RejectBannedSaves は利用停止中のユーザーからのセーブを拒否するか（MODERATION_REJECT_BANNED_SAVES）。
+// 既定は false で、利用停止中でも保存は受け付けランキング・統計からだけ除外する。
+func RejectBannedSaves() bool {
+	reject, err := strconv.ParseBool(getEnv("MODERATION_REJECT_BANNED_SAVES", "false"))
+	return err == nil && reject
+}
+
+// SaveSchemaMode はセーブのスキーマ検査の方式（SAVE_SCHEMA_MODE、lenient または strict）。
+// 既定の lenient ではセーブの version に合わないキーがあっても保存し、警告を返すだけにする。
+func SaveSchemaMode() string {
+	return getEnv("SAVE_SCHEMA_MODE", "lenient")
+}
+
+// AnomalyScanInterval は不審なセーブの推移を検出する解析の間隔（ANOMALY_SCAN_INTERVAL、例: 5m）。
+// 0 なら解析しない。不正な値は既定の 5 分として扱う。
+func AnomalyScanInterval() time.Duration {
+	interval, err := time.ParseDuration(getEnv("ANOMALY_SCAN_INTERVAL", "5m"))
+	if err != nil || interval < 0 {
+		return 5 * time.Minute
+	}
+	return interval
+}
+
+// RetentionKeepAllDays は prune-saves で全セーブを残す日数（RETENTION_KEEP_ALL_DAYS）。
+// /v4/statistics/saves/activity が最大 30 日分の全セーブを数えるため、既定は 30。
+func RetentionKeepAllDays() int {
+	return positiveIntEnv("RETENTION_KEEP_ALL_DAYS", 30)
+}
+
+// RetentionDailyDays は prune-saves で 1 日 1 件に間引いて残す日数（RETENTION_DAILY_DAYS）。これより古いものは週 1 件。
+// /v4/statistics/medals/timeseries は最大 180 日分を各日の最新セーブで数えるため、既定は 180。
+func RetentionDailyDays() int {
+	return positiveIntEnv("RETENTION_DAILY_DAYS", 180)
+}
+
+// positiveIntEnv は正の整数の環境変数を読む。不正な値は defaultValue として扱う。
+func positiveIntEnv(key string, defaultValue int) int {
+	v, err := strconv.Atoi(getEnv(key, strconv.Itoa(defaultValue)))
+	if err != nil || v < 1 {
+		return defaultValue
+	}
+	return v
+}
+
+// MigrateOnStart は serve の起動時にマイグレーションを適用するか（MIGRATE_ON_START、既定 true）。
+// false にした場合はデプロイ前に migrate up を実行し、未適用のものが残っていれば起動しない。
+func MigrateOnStart() bool {
+	migrate, err := strconv.ParseBool(getEnv("MIGRATE_ON_START", "true"))
+	if err != nil {
+		return true
+	}
+	return migrate
+}
+
 func AppAddr() string {
 	return getEnv("APP_ADDR", ":8080")
 }
 
+// LogLevel は構造化ログの出力レベル（debug / info / warn / error）
+func LogLevel() string {
+	return getEnv("LOG_LEVEL", "info")
+}
+
+// TracingExporter はトレースの出力先（none / stdout / otlp）。既定は none で、トレースは完全に無効になる。
+// otlp の送信先は OTEL_EXPORTER_OTLP_ENDPOINT などの標準環境変数で指定する。
+func TracingExporter() string {
+	return getEnv("TRACING_EXPORTER", "none")
+}
+
+// TracingServiceName はトレースに付与する service.name
+func TracingServiceName() string {
+	return getEnv("TRACING_SERVICE_NAME", "very-big-medal-pusher-data-server")
+}
+
+// TracingSampleRatio はトレースのサンプリング率（0〜1）。不正な値は 1 として扱う。
+func TracingSampleRatio() float64 {
+	ratio, err := strconv.ParseFloat(getEnv("TRACING_SAMPLE_RATIO", "1"), 64)
+	if err != nil || ratio < 0 || ratio > 1 {
+		return 1
+	}
+	return ratio
+}
+
+// CacheBackend はレスポンスキャッシュの置き場所（memory / redis）。
+// 複数レプリカで動かす場合は redis にすると、キャッシュと再計算をレプリカ間で共有する。
+func CacheBackend() string {
+	return getEnv("CACHE_BACKEND", "memory")
+}
+
+// RedisAddr は CACHE_BACKEND=redis のときの接続先（host:port）
+func RedisAddr() string {
+	return getEnv("REDIS_ADDR", "localhost:6379")
+}
+
+// RedisPassword は Redis の AUTH パスワード（空なら認証しない）
+func RedisPassword() string {
+	return getEnv("REDIS_PASSWORD", "")
+}
+
+// RedisDB は使用する Redis の DB 番号。不正な値は 0 として扱う。
+func RedisDB() int {
+	db, err := strconv.Atoi(getEnv("REDIS_DB", "0"))
+	if err != nil || db < 0 {
+		return 0
+	}
+	return db
+}
+
+// CacheKeyPrefix は共有キャッシュのキーの接頭辞（同じ Redis を本番・検証で共有する場合に分ける）
+func CacheKeyPrefix() string {
+	return getEnv("CACHE_KEY_PREFIX", "medal-pusher:")
+}
+
+// DBDriver はつなぐデータベースの種類（DB_DRIVER）。mysql（既定、MariaDB）か sqlite。
+func DBDriver() string {
+	return getEnv("DB_DRIVER", "mysql")
+}
+
+// SQLitePath は DB_DRIVER=sqlite のときのデータベースファイル（SQLITE_PATH）。無ければ作る。
+func SQLitePath() string {
+	return getEnv("SQLITE_PATH", "app.db")
+}
+
+// SQLiteDSN は SQLitePath を開く DSN。
+// 外部キー（子テーブルの ON DELETE CASCADE）を有効にし、トランザクションは BEGIN IMMEDIATE で始めて書き込みを 1 つずつにする
+// （repository は SQLite では FOR UPDATE を付けない）。ロック待ちは busy_timeout の間だけ待つ。
+func SQLiteDSN() string {
+	return "file:" + SQLitePath() + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_txlock=immediate"
+}
+
 func MySQL() *mysql.Config {
 	c := mysql.NewConfig()
 
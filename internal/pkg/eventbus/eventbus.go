@@ -0,0 +1,30 @@
+package eventbus
+
+import (
+	"context"
+	"sync"
+)
+
+// Bus はプロセス内の同期イベントバス。
+// Publish は購読者を登録順に呼び出すので、購読者側で重い処理をする場合は自分でゴルーチンに逃がすこと。
+type Bus[T any] struct {
+	mu          sync.RWMutex
+	subscribers []func(ctx context.Context, event T)
+}
+
+// Subscribe は購読者を登録する。
+func (b *Bus[T]) Subscribe(fn func(ctx context.Context, event T)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers = append(b.subscribers, fn)
+}
+
+// Publish はイベントを全購読者に配送する。
+func (b *Bus[T]) Publish(ctx context.Context, event T) {
+	b.mu.RLock()
+	subscribers := b.subscribers
+	b.mu.RUnlock()
+	for _, fn := range subscribers {
+		fn(ctx, event)
+	}
+}
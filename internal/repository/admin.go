@@ -0,0 +1,286 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/domain"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/pkg/tracing"
+)
+
+// InsertAdminAudit records one admin API request.
+func (r *Repository) InsertAdminAudit(ctx context.Context, entry *domain.AdminAuditEntry) (err error) {
+	ctx, span := tracing.Start(ctx, "repository.InsertAdminAudit")
+	defer func() { tracing.End(span, err) }()
+
+	res, err := r.db.ExecContext(ctx, `
+INSERT INTO admin_audit_log (actor, action, target, payload, remote_ip, status)
+VALUES (?, ?, ?, ?, ?, ?)`,
+		entry.Actor, entry.Action, entry.Target, entry.Payload, entry.RemoteIP, entry.Status)
+	if err != nil {
+		return err
+	}
+	entry.ID, err = res.LastInsertId()
+	return err
+}
+
+// ListAdminAudit returns audit entries newest first. beforeID > 0 pages past that entry.
+func (r *Repository) ListAdminAudit(ctx context.Context, limit int, beforeID int64) (_ []domain.AdminAuditEntry, err error) {
+	ctx, span := tracing.Start(ctx, "repository.ListAdminAudit")
+	defer func() { tracing.End(span, err) }()
+
+	query := `
+SELECT id, actor, action, target, payload, remote_ip, status, created_at
+FROM admin_audit_log`
+	args := []any{}
+	if beforeID > 0 {
+		query += " WHERE id < ?"
+		args = append(args, beforeID)
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+	args = append(args, limit)
+
+	entries := []domain.AdminAuditEntry{}
+	if err := r.db.SelectContext(ctx, &entries, query, args...); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// InsertSignatureBypass records one request authorised with the signature bypass token.
+func (r *Repository) InsertSignatureBypass(ctx context.Context, entry *domain.SignatureBypassEntry) (err error) {
+	ctx, span := tracing.Start(ctx, "repository.InsertSignatureBypass")
+	defer func() { tracing.End(span, err) }()
+
+	res, err := r.db.ExecContext(ctx, `
+INSERT INTO signature_bypass_log (route, user_id, remote_ip)
+VALUES (?, ?, ?)`,
+		entry.Route, entry.UserID, entry.RemoteIP)
+	if err != nil {
+		return err
+	}
+	entry.ID, err = res.LastInsertId()
+	return err
+}
+
+// ListSignatureBypass returns bypass entries newest first. beforeID > 0 pages past that entry,
+// and a non-empty userID keeps only the entries for that user.
+func (r *Repository) ListSignatureBypass(ctx context.Context, limit int, beforeID int64, userID string) (_ []domain.SignatureBypassEntry, err error) {
+	ctx, span := tracing.Start(ctx, "repository.ListSignatureBypass")
+	defer func() { tracing.End(span, err) }()
+
+	query := `
+SELECT id, route, user_id, remote_ip, created_at
+FROM signature_bypass_log`
+	var conds []string
+	args := []any{}
+	if beforeID > 0 {
+		conds = append(conds, "id < ?")
+		args = append(args, beforeID)
+	}
+	if userID != "" {
+		conds = append(conds, "user_id = ?")
+		args = append(args, userID)
+	}
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+	args = append(args, limit)
+
+	entries := []domain.SignatureBypassEntry{}
+	if err := r.db.SelectContext(ctx, &entries, query, args...); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// unlockedAsOfQuery lists the achievements a user held right after a save (args: user, save, user, save).
+// An achievement counts if its latest unlock comes after its latest revocation by a rollback.
+const unlockedAsOfQuery = `
+SELECT a.achievement_id
+FROM v2_save_data_achievements a
+JOIN v2_save_data s ON s.id = a.save_id
+WHERE s.user_id = ? AND s.id <= ?
+GROUP BY a.achievement_id
+HAVING MAX(a.save_id) > COALESCE((
+  SELECT MAX(rv.save_id)
+  FROM v2_save_data_achievement_revocations rv
+  JOIN v2_save_data rs ON rs.id = rv.save_id
+  WHERE rs.user_id = ? AND rs.id <= ? AND rv.achievement_id = a.achievement_id
+), 0)
+ORDER BY a.achievement_id`
+
+// GetSave retrieves one save of the user. LAchieve holds the achievements unlocked up to and including it.
+func (r *Repository) GetSave(ctx context.Context, userID string, saveID int64) (_ *domain.SaveData, err error) {
+	ctx, span := tracing.Start(ctx, "repository.GetSave")
+	defer func() { tracing.End(span, err) }()
+
+	var sd domain.SaveData
+	if err := r.db.GetContext(ctx, &sd, `
+SELECT *
+FROM v2_save_data
+WHERE id = ? AND user_id = ?`, saveID, userID); err != nil {
+		return nil, err
+	}
+
+	// v2_save_data_achievements にはその保存で新たに解除した実績だけが入っている
+	sd.LAchieve = []string{}
+	if err := r.db.SelectContext(ctx, &sd.LAchieve, unlockedAsOfQuery, userID, saveID, userID, saveID); err != nil {
+		return nil, err
+	}
+
+	if err := r.loadSaveChildren(ctx, &sd); err != nil {
+		return nil, err
+	}
+	return &sd, nil
+}
+
+// RollbackSave makes an earlier save of the user their latest save again.
+// The save is stored again as a new row, so the newer saves stay in the history for review and the
+// latest-save tables and summaries are derived exactly as for a normal save.
+// Achievements unlocked after that save are revoked, and the revocation is recorded against the new row.
+func (r *Repository) RollbackSave(ctx context.Context, userID string, saveID int64) (_ *domain.SaveData, err error) {
+	ctx, span := tracing.Start(ctx, "repository.RollbackSave")
+	defer func() { tracing.End(span, err) }()
+
+	sd, err := r.GetSave(ctx, userID, saveID)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	newID, _, err := r.insertSaveV4Tx(ctx, tx, sd)
+	if err != nil {
+		return nil, err
+	}
+	if err := revokeAchievements(ctx, tx, userID, newID, sd.LAchieve); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	sd.ID = newID
+	return sd, nil
+}
+
+// revokeAchievements removes the user's unlocked achievements that are not in keep, recording them as revoked by saveID.
+func revokeAchievements(ctx context.Context, tx *sqlx.Tx, userID string, saveID int64, keep []string) error {
+	var current []string
+	if err := tx.SelectContext(ctx, &current, `
+SELECT achievement_id
+FROM v3_user_latest_save_data_achievements
+WHERE user_id = ?`+dialectOf(tx).forUpdate(), userID); err != nil {
+		return err
+	}
+
+	kept := make(map[string]bool, len(keep))
+	for _, id := range keep {
+		kept[id] = true
+	}
+	var revoked []string
+	for _, id := range current {
+		if kept[id] {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `
+DELETE FROM v3_user_latest_save_data_achievements
+WHERE user_id = ? AND achievement_id = ?`, userID, id); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `
+INSERT INTO v2_save_data_achievement_revocations (save_id, achievement_id)
+VALUES (?, ?)`, saveID, id); err != nil {
+			return err
+		}
+		revoked = append(revoked, id)
+	}
+	if len(revoked) == 0 {
+		return nil
+	}
+
+	// 表示上の寄与は変わらず、実績を持つユーザーかどうかと実績ごとの人数だけが変わる
+	c, err := loadSummaryContribution(ctx, tx, userID)
+	if err != nil {
+		return err
+	}
+	before := c
+	before.hasAchievements = true
+	return applySummaryChange(ctx, tx, before, c, nil, revoked)
+}
+
+// DeleteUserData removes every save of the user, their latest-save rows, anomaly flags and signature bypass records,
+// and returns the number of saves deleted. The user's moderation is kept on purpose, so that deleting a banned user's
+// data does not lift the ban, and so is admin_audit_log, which records the deletion itself.
+func (r *Repository) DeleteUserData(ctx context.Context, userID string) (_ int64, err error) {
+	ctx, span := tracing.Start(ctx, "repository.DeleteUserData")
+	defer func() { tracing.End(span, err) }()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	before, err := loadSummaryContribution(ctx, tx, userID)
+	if err != nil {
+		return 0, err
+	}
+	var achievements []string
+	if err := tx.SelectContext(ctx, &achievements, `
+SELECT achievement_id
+FROM v3_user_latest_save_data_achievements
+WHERE user_id = ?`+dialectOf(tx).forUpdate(), userID); err != nil {
+		return 0, err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM v3_user_latest_save_data_achievements WHERE user_id = ?`, userID); err != nil {
+		return 0, err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM v3_user_latest_save_data WHERE user_id = ?`, userID); err != nil {
+		return 0, err
+	}
+	// 子テーブルは ON DELETE CASCADE で消える
+	res, err := tx.ExecContext(ctx, `DELETE FROM v2_save_data WHERE user_id = ?`, userID)
+	if err != nil {
+		return 0, err
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	others := int64(len(achievements))
+	for _, table := range []string{"anomaly_flags", "signature_bypass_log"} {
+		res, err := tx.ExecContext(ctx, `DELETE FROM `+table+` WHERE user_id = ?`, userID)
+		if err != nil {
+			return 0, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		others += n
+	}
+	if deleted == 0 && others == 0 {
+		return 0, sql.ErrNoRows
+	}
+
+	if err := applySummaryChange(ctx, tx, before, summaryContribution{}, nil, achievements); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
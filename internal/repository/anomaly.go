@@ -0,0 +1,236 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/domain"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/pkg/tracing"
+)
+
+// anomalyFlagRow scans the evidence as text; see domain.AnomalyFlag.Evidence.
+type anomalyFlagRow struct {
+	domain.AnomalyFlag
+	EvidenceJSON string `db:"evidence"`
+}
+
+func (row anomalyFlagRow) flag() domain.AnomalyFlag {
+	f := row.AnomalyFlag
+	f.Evidence = json.RawMessage(row.EvidenceJSON)
+	return f
+}
+
+const anomalyFlagColumns = `id, user_id, kind, save_id, score, evidence, status, reviewed_by, review_note, reviewed_at, created_at`
+
+// LastSaveIDBefore returns the id of the newest save stored before t, or 0 if there is none.
+func (r *Repository) LastSaveIDBefore(ctx context.Context, t time.Time) (_ int64, err error) {
+	ctx, span := tracing.Start(ctx, "repository.LastSaveIDBefore")
+	defer func() { tracing.End(span, err) }()
+
+	// ids grow with created_at, so walking the primary key backwards stops at the first older save
+	var id int64
+	err = r.db.GetContext(ctx, &id, `
+SELECT id
+FROM v2_save_data
+WHERE created_at < ?
+ORDER BY id DESC
+LIMIT 1`, r.dialect().timeArg(t))
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return id, err
+}
+
+// ClaimAnomalyScan takes or renews the lease on the anomaly scan for owner, so that one replica scans at a time,
+// and returns the id of the last analyzed save to continue after. ok is false while another owner holds a lease
+// that has not expired. The first scan starts with the saves stored since the given time.
+func (r *Repository) ClaimAnomalyScan(ctx context.Context, owner string, lease time.Duration, since time.Time) (_ int64, ok bool, err error) {
+	ctx, span := tracing.Start(ctx, "repository.ClaimAnomalyScan")
+	defer func() { tracing.End(span, err) }()
+
+	d := r.dialect()
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, false, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	// lease_until is stored in UTC like user_moderation.expires_at
+	if _, err := tx.ExecContext(ctx, `
+UPDATE anomaly_scan_state
+SET lease_owner = ?, lease_until = ?
+WHERE id = 1 AND (lease_owner = ? OR lease_until IS NULL OR lease_until < `+d.utcNow()+`)`,
+		owner, d.timeArg(time.Now().Add(lease).UTC()), owner); err != nil {
+		return 0, false, err
+	}
+	// reading the owner back does not depend on the affected rows, which MariaDB leaves at 0 for an unchanged row
+	var state struct {
+		LastSaveID sql.NullInt64 `db:"last_save_id"`
+		LeaseOwner string        `db:"lease_owner"`
+	}
+	if err := tx.GetContext(ctx, &state, `SELECT last_save_id, lease_owner FROM anomaly_scan_state WHERE id = 1`); err != nil {
+		return 0, false, err
+	}
+	if state.LeaseOwner != owner {
+		return 0, false, nil
+	}
+	afterID := state.LastSaveID.Int64
+	if !state.LastSaveID.Valid {
+		if afterID, err = r.LastSaveIDBefore(ctx, since); err != nil {
+			return 0, false, err
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE anomaly_scan_state SET last_save_id = ? WHERE id = 1`, afterID); err != nil {
+			return 0, false, err
+		}
+	}
+	return afterID, true, tx.Commit()
+}
+
+// AdvanceAnomalyScan records that the saves up to lastID are analyzed and extends the lease of owner.
+// It changes nothing once the lease has passed to another owner.
+func (r *Repository) AdvanceAnomalyScan(ctx context.Context, owner string, lastID int64, lease time.Duration) (err error) {
+	ctx, span := tracing.Start(ctx, "repository.AdvanceAnomalyScan")
+	defer func() { tracing.End(span, err) }()
+
+	_, err = r.db.ExecContext(ctx, `
+UPDATE anomaly_scan_state
+SET last_save_id = ?, lease_until = ?
+WHERE id = 1 AND lease_owner = ?`, lastID, r.dialect().timeArg(time.Now().Add(lease).UTC()), owner)
+	return err
+}
+
+// ListUsersSavedAfter returns up to limit users who stored a save with an id above afterID, in the order of their
+// newest such save, and the id of the newest save among them. Passing that id back continues with the next users.
+func (r *Repository) ListUsersSavedAfter(ctx context.Context, afterID int64, limit int) (_ []string, _ int64, err error) {
+	ctx, span := tracing.Start(ctx, "repository.ListUsersSavedAfter")
+	defer func() { tracing.End(span, err) }()
+
+	var rows []struct {
+		UserID string `db:"user_id"`
+		LastID int64  `db:"last_id"`
+	}
+	if err := r.db.SelectContext(ctx, &rows, `
+SELECT user_id, MAX(id) AS last_id
+FROM v2_save_data
+WHERE id > ?
+GROUP BY user_id
+ORDER BY last_id
+LIMIT ?`, afterID, limit); err != nil {
+		return nil, afterID, err
+	}
+
+	users := make([]string, 0, len(rows))
+	lastID := afterID
+	for _, row := range rows {
+		users = append(users, row.UserID)
+		lastID = max(lastID, row.LastID)
+	}
+	return users, lastID, nil
+}
+
+// GetSaveSamples returns the user's latest saves, oldest first, with the number of achievements each unlocked.
+func (r *Repository) GetSaveSamples(ctx context.Context, userID string, limit int) (_ []domain.SaveSample, err error) {
+	ctx, span := tracing.Start(ctx, "repository.GetSaveSamples")
+	defer func() { tracing.End(span, err) }()
+
+	samples := []domain.SaveSample{}
+	if err := r.db.SelectContext(ctx, &samples, `
+SELECT
+  s.id, s.playtime, s.credit_all, s.cpm_max, s.created_at,
+  (SELECT COUNT(*) FROM v2_save_data_achievements a WHERE a.save_id = s.id) AS unlocked
+FROM v2_save_data s
+WHERE s.user_id = ?
+ORDER BY s.id DESC
+LIMIT ?`, userID, limit); err != nil {
+		return nil, err
+	}
+	slices.Reverse(samples)
+	return samples, nil
+}
+
+// InsertAnomalyFlags stores new flags and returns how many were new; a flag for the same save and kind is kept as is.
+func (r *Repository) InsertAnomalyFlags(ctx context.Context, flags []domain.AnomalyFlag) (_ int64, err error) {
+	ctx, span := tracing.Start(ctx, "repository.InsertAnomalyFlags")
+	defer func() { tracing.End(span, err) }()
+
+	var inserted int64
+	for _, f := range flags {
+		res, err := r.db.ExecContext(ctx, `
+`+r.dialect().insertIgnore()+` INTO anomaly_flags (user_id, kind, save_id, score, evidence, status)
+VALUES (?, ?, ?, ?, ?, ?)`,
+			f.UserID, f.Kind, f.SaveID, f.Score, string(f.Evidence), f.Status)
+		if err != nil {
+			return inserted, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return inserted, err
+		}
+		inserted += n
+	}
+	return inserted, nil
+}
+
+// ListAnomalyFlags returns flags newest first. Empty status or userID match every flag; beforeID > 0 pages past that flag.
+func (r *Repository) ListAnomalyFlags(ctx context.Context, status, userID string, limit int, beforeID int64) (_ []domain.AnomalyFlag, err error) {
+	ctx, span := tracing.Start(ctx, "repository.ListAnomalyFlags")
+	defer func() { tracing.End(span, err) }()
+
+	query := `SELECT ` + anomalyFlagColumns + ` FROM anomaly_flags`
+	var conds []string
+	args := []any{}
+	if status != "" {
+		conds = append(conds, "status = ?")
+		args = append(args, status)
+	}
+	if userID != "" {
+		conds = append(conds, "user_id = ?")
+		args = append(args, userID)
+	}
+	if beforeID > 0 {
+		conds = append(conds, "id < ?")
+		args = append(args, beforeID)
+	}
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+	args = append(args, limit)
+
+	var rows []anomalyFlagRow
+	if err := r.db.SelectContext(ctx, &rows, query, args...); err != nil {
+		return nil, err
+	}
+	flags := make([]domain.AnomalyFlag, 0, len(rows))
+	for _, row := range rows {
+		flags = append(flags, row.flag())
+	}
+	return flags, nil
+}
+
+// ReviewAnomalyFlag records a reviewer's decision on a flag. It returns sql.ErrNoRows for an unknown id.
+func (r *Repository) ReviewAnomalyFlag(ctx context.Context, id int64, status, reviewer, note string) (_ *domain.AnomalyFlag, err error) {
+	ctx, span := tracing.Start(ctx, "repository.ReviewAnomalyFlag")
+	defer func() { tracing.End(span, err) }()
+
+	if _, err := r.db.ExecContext(ctx, `
+UPDATE anomaly_flags
+SET status = ?, reviewed_by = ?, review_note = ?, reviewed_at = CURRENT_TIMESTAMP
+WHERE id = ?`, status, reviewer, note, id); err != nil {
+		return nil, err
+	}
+
+	var row anomalyFlagRow
+	if err := r.db.GetContext(ctx, &row, `SELECT `+anomalyFlagColumns+` FROM anomaly_flags WHERE id = ?`, id); err != nil {
+		return nil, err
+	}
+	f := row.flag()
+	return &f, nil
+}
@@ -3,40 +3,32 @@ package repository
 import (
 	"context"
 	"math"
+	"strconv"
 
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/pkg/tracing"
 	"github.com/pikachu0310/very-big-medal-pusher-data-server/openapi/models"
 )
 
-// GetCreditAllDistribution aggregates credit_all values from the latest save data table into digit-based ranges.
-func (r *Repository) GetCreditAllDistribution(ctx context.Context) (*models.CreditAllDistributionResponse, error) {
-	const totalUsersQuery = `
-SELECT
-  COUNT(*)
-FROM v3_user_latest_save_data
-WHERE hide_record = 0`
+// GetCreditAllDistribution returns the number of visible users per credit_all digit count.
+func (r *Repository) GetCreditAllDistribution(ctx context.Context) (_ *models.CreditAllDistributionResponse, err error) {
+	ctx, span := tracing.Start(ctx, "repository.GetCreditAllDistribution")
+	defer func() { tracing.End(span, err) }()
 
-	var totalUsers int64
-	if err := r.db.GetContext(ctx, &totalUsers, totalUsersQuery); err != nil {
+	// 集計値は InsertSaveV4 が v4_summary_* に差分で反映している
+	totalUsers, err := r.getSummaryTotal(ctx, summaryVisibleUsers)
+	if err != nil {
 		return nil, err
 	}
 
-	const distributionQuery = `
-SELECT
-  CASE
-    WHEN credit_all IS NULL OR credit_all < 1000 THEN 3
-    ELSE CAST(FLOOR(LOG10(credit_all)) + 1 AS SIGNED)
-  END AS digits,
-  COUNT(*) AS user_count
-FROM v3_user_latest_save_data
-WHERE hide_record = 0
-GROUP BY digits
-ORDER BY digits`
-
 	var rows []struct {
 		Digits    int   `db:"digits"`
 		UserCount int64 `db:"user_count"`
 	}
-	if err := r.db.SelectContext(ctx, &rows, distributionQuery); err != nil {
+	if err := r.db.SelectContext(ctx, &rows, `
+SELECT digits, user_count
+FROM v4_summary_credit_digits
+WHERE user_count > 0
+ORDER BY digits`); err != nil {
 		return nil, err
 	}
 
@@ -53,6 +45,16 @@ ORDER BY digits`
 		}
 	}
 
+	// 集計値はモデレーションを考慮しないので、対象ユーザーの分を差し引く
+	moderated, err := r.moderatedCredits(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, credit := range moderated {
+		totalUsers--
+		countsByDigits[creditDigits(credit)]--
+	}
+
 	if totalUsers == 0 {
 		maxDigits = 3
 	}
@@ -73,6 +75,14 @@ ORDER BY digits`
 	}, nil
 }
 
+// creditDigits returns the bucket of credit_all; everything below 1000 shares the 3-digit bucket.
+func creditDigits(creditAll int64) int {
+	if creditAll < 1000 {
+		return 3
+	}
+	return len(strconv.FormatInt(creditAll, 10))
+}
+
 func creditRangeForDigits(digits int) (int64, int64) {
 	if digits <= 3 {
 		return 0, 999
@@ -43,3 +43,24 @@ func TestCreditRangeForDigits(t *testing.T) {
 		t.Fatalf("digits=20: got %d-%d", min, max)
 	}
 }
+
+func TestCreditDigits(t *testing.T) {
+	cases := []struct {
+		credit int64
+		want   int
+	}{
+		{-5, 3},
+		{0, 3},
+		{999, 3},
+		{1000, 4},
+		{9999, 4},
+		{10000, 5},
+		{999_999_999_999_999_999, 18},
+		{math.MaxInt64, 19},
+	}
+	for _, tc := range cases {
+		if got := creditDigits(tc.credit); got != tc.want {
+			t.Errorf("creditDigits(%d): got %d want %d", tc.credit, got, tc.want)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/pkg/tracing"
 	"github.com/pikachu0310/very-big-medal-pusher-data-server/openapi/models"
 	"time"
 )
@@ -61,34 +62,38 @@ func (r *Repository) InsertGameData(ctx context.Context, data models.GameData) e
 	return nil
 }
 
-func (r *Repository) GetRankings(ctx context.Context, sortBy string, limit int) ([]models.GameData, error) {
-	validSortColumns := map[string]bool{
-		"have_medal":         true,
-		"in_medal":           true,
-		"out_medal":          true,
-		"slot_hit":           true,
-		"get_shirbe":         true,
-		"start_slot":         true,
-		"shirbe_buy300":      true,
-		"medal_1":            true,
-		"medal_2":            true,
-		"medal_3":            true,
-		"medal_4":            true,
-		"medal_5":            true,
-		"R_medal":            true,
-		"total_play_time":    true,
-		"fever":              true,
-		"max_chain_item":     true,
-		"max_chain_orange":   true,
-		"max_chain_rainbow":  true,
-		"sugoroku_steps":     true,
-		"max_jackpot_win":    true,
-		"jackpots":           true,
-		"max_total_jackpot":  true,
-		"max_total_ultimate": true,
-	}
+// validGameDataSortColumns are the game_data columns GetRankings can sort by.
+var validGameDataSortColumns = map[string]bool{
+	"have_medal":         true,
+	"in_medal":           true,
+	"out_medal":          true,
+	"slot_hit":           true,
+	"get_shirbe":         true,
+	"start_slot":         true,
+	"shirbe_buy300":      true,
+	"medal_1":            true,
+	"medal_2":            true,
+	"medal_3":            true,
+	"medal_4":            true,
+	"medal_5":            true,
+	"R_medal":            true,
+	"total_play_time":    true,
+	"fever":              true,
+	"max_chain_item":     true,
+	"max_chain_orange":   true,
+	"max_chain_rainbow":  true,
+	"sugoroku_steps":     true,
+	"max_jackpot_win":    true,
+	"jackpots":           true,
+	"max_total_jackpot":  true,
+	"max_total_ultimate": true,
+}
 
-	if !validSortColumns[sortBy] {
+func (r *Repository) GetRankings(ctx context.Context, sortBy string, limit int) (_ []models.GameData, err error) {
+	ctx, span := tracing.Start(ctx, "repository.GetRankings")
+	defer func() { tracing.End(span, err) }()
+
+	if !validGameDataSortColumns[sortBy] {
 		return nil, fmt.Errorf("invalid sort column: %s", sortBy)
 	}
 
@@ -146,7 +151,10 @@ func (r *Repository) ExistsSameGameData(ctx context.Context, userId string, tota
 	return existsInt == 1, nil
 }
 
-func (r *Repository) GetTotalMedals(ctx context.Context) (int, error) {
+func (r *Repository) GetTotalMedals(ctx context.Context) (_ int, err error) {
+	ctx, span := tracing.Start(ctx, "repository.GetTotalMedals")
+	defer func() { tracing.End(span, err) }()
+
 	const q = `
         SELECT IFNULL(SUM(gd.have_medal), 0)
         FROM game_data AS gd
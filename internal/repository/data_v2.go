@@ -5,188 +5,28 @@ import (
 	"runtime"
 
 	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/domain"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/pkg/tracing"
 	"github.com/pikachu0310/very-big-medal-pusher-data-server/openapi/models"
 )
 
-func (r *Repository) ExistsSameSave(ctx context.Context, userID string, playtime int64) (bool, error) {
+func (r *Repository) ExistsSameSave(ctx context.Context, userID string, playtime int64) (_ bool, err error) {
+	ctx, span := tracing.Start(ctx, "repository.ExistsSameSave")
+	defer func() { tracing.End(span, err) }()
+
 	const q = `SELECT EXISTS(SELECT 1 FROM v2_save_data WHERE user_id=? AND playtime=?)`
 	var exists int
-	err := r.db.QueryRowContext(ctx, q, userID, playtime).Scan(&exists)
+	err = r.db.QueryRowContext(ctx, q, userID, playtime).Scan(&exists)
 	return exists == 1, err
 }
 
-// InsertSave persists a SaveData and its child tables
-func (r *Repository) InsertSave(ctx context.Context, sd *domain.SaveData) error {
-	tx, err := r.db.BeginTxx(ctx, nil)
-	if err != nil {
-		return err
-	}
-	defer func() {
-		_ = tx.Rollback()
-	}()
-
-	res, err := tx.ExecContext(ctx, `
-INSERT INTO v2_save_data (
-    user_id, legacy, version,
-    credit, credit_all, medal_in, medal_get,
-    ball_get, ball_chain, slot_start, slot_startfev,
-    slot_hit, slot_getfev, sqr_get, sqr_step,
-    jack_get, jack_startmax, jack_totalmax,
-    ult_get, ult_combomax, ult_totalmax,
-    rmshbi_get, bstp_step, bstp_rwd, buy_total, skill_point, blackbox, blackbox_total, sp_use,
-    hide_record, cpm_max, jack_totalmax_v2, ult_totalmax_v2,
-    palball_get, pallot_lot_t0, pallot_lot_t1, pallot_lot_t2, pallot_lot_t3, pallot_lot_t4,
-    jacksp_get_all, jacksp_get_t0, jacksp_get_t1, jacksp_get_t2, jacksp_get_t3, jacksp_get_t4,
-    jacksp_startmax, jacksp_totalmax, ferball_get, ferlot_lot,
-    jackfr_get_all, jackfr_get_t0, jackfr_get_t1, jackfr_get_t2, jackfr_get_t3, jackfr_get_t4,
-    jackfr_startmax, jackfr_totalmax, ferlot_hit, ferlot_lose, ferlot_chance, ferlot_act, ferlot_lines, bbox_shop, ferlot_maxln, bbox_used_ferlot, get_medaltower,
-    task_cnt, totem_altars, totem_altars_credit, buy_shbi,
-    firstboot, lastsave, playtime
-	) VALUES (
-	    ?, ?, ?,
-	    ?, ?, ?, ?,
-	    ?, ?, ?, ?,
-	    ?, ?, ?, ?,
-	    ?, ?, ?,
-	    ?, ?, ?,
-	    ?, ?, ?, ?, ?, ?, ?, ?,
-	    ?, ?, ?, ?,
-	    ?, ?, ?, ?, ?, ?,
-	    ?, ?, ?, ?, ?, ?,
-	    ?, ?, ?, ?,
-	    ?, ?, ?, ?, ?, ?,
-	    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?,
-	    ?, ?, ?, ?,
-	    ?, ?, ?
-	)`,
-		sd.UserId, sd.Legacy, sd.Version,
-		sd.Credit, sd.CreditAll, sd.MedalIn, sd.MedalGet,
-		sd.BallGet, sd.BallChain, sd.SlotStart, sd.SlotStartFev,
-		sd.SlotHit, sd.SlotGetFev, sd.SqrGet, sd.SqrStep,
-		sd.JackGet, sd.JackStartMax, sd.JackTotalMax,
-		sd.UltGet, sd.UltComboMax, sd.UltTotalMax,
-		sd.RmShbiGet, sd.BstpStep, sd.BstpRwd, sd.BuyTotal, sd.SkillPoint, sd.BlackBox, sd.BlackBoxTotal, sd.SpUse,
-		sd.HideRecord, sd.CpMMax, sd.JackTotalMaxV2, sd.UltimateTotalMaxV2,
-		sd.PalettaBallGet, sd.PalettaLotteryAttemptTier0, sd.PalettaLotteryAttemptTier1, sd.PalettaLotteryAttemptTier2, sd.PalettaLotteryAttemptTier3, sd.PalettaLotteryAttemptTier4,
-		sd.JackpotSuperGetTotal, sd.JackpotSuperGetTier0, sd.JackpotSuperGetTier1, sd.JackpotSuperGetTier2, sd.JackpotSuperGetTier3, sd.JackpotSuperGetTier4,
-		sd.JackpotSuperStartMax, sd.JackpotSuperTotalMax, sd.FerrettaBallGet, sd.FerrettaLotteryAttempt,
-		sd.JackpotFerrettaGetTotal, sd.JackpotFerrettaGetTier0, sd.JackpotFerrettaGetTier1, sd.JackpotFerrettaGetTier2, sd.JackpotFerrettaGetTier3, sd.JackpotFerrettaGetTier4,
-		sd.JackpotFerrettaStartMax, sd.JackpotFerrettaTotalMax, sd.FerrettaLotteryHit, sd.FerrettaLotteryLose, sd.FerrettaLotteryChance, sd.FerrettaLotteryActives, sd.FerrettaLotteryLines, sd.BlackBoxShopUsed, sd.FerrettaLotteryMaxLines, sd.BlackBoxUsedFerrettaItem, sd.GetMedalTower,
-		sd.TaskCompleteCount, sd.TotemAltarUnlockCount, sd.TotemAltarUnlockUsedCredits, sd.BuyShbi,
-		sd.FirstBoot, sd.LastSave, sd.Playtime,
-	)
-	if err != nil {
-		return err
-	}
-	saveID, err := res.LastInsertId()
-	if err != nil {
-		return err
-	}
-
-	// medal_get
-	for id, cnt := range sd.DCMedalGet {
-		if _, err := tx.ExecContext(ctx, `INSERT INTO v2_save_data_medal_get(save_id, medal_id, count) VALUES(?,?,?)`, saveID, id, cnt); err != nil {
-			return err
-		}
-	}
-	// ball_get
-	for id, cnt := range sd.DCBallGet {
-		if _, err := tx.ExecContext(ctx, `INSERT INTO v2_save_data_ball_get(save_id, ball_id, count) VALUES(?,?,?)`, saveID, id, cnt); err != nil {
-			return err
-		}
-	}
-	// ball_chain
-	for id, cnt := range sd.DCBallChain {
-		if _, err := tx.ExecContext(ctx, `INSERT INTO v2_save_data_ball_chain(save_id, ball_id, chain_count) VALUES(?,?,?)`, saveID, id, cnt); err != nil {
-			return err
-		}
-	}
-	// achievements
-	for _, aid := range sd.LAchieve {
-		if _, err := tx.ExecContext(ctx, `INSERT INTO v2_save_data_achievements(save_id, achievement_id) VALUES(?,?)`, saveID, aid); err != nil {
-			return err
-		}
-	}
-
-	// palball_get
-	for id, cnt := range sd.DCPalettaBallGet {
-		if _, err := tx.ExecContext(ctx, `INSERT INTO v2_save_data_palball_get(save_id, ball_id, count) VALUES(?,?,?)`, saveID, id, cnt); err != nil {
-			return err
-		}
-	}
-
-	// palball_jp
-	for id, cnt := range sd.DCPalettaBallJackpot {
-		if _, err := tx.ExecContext(ctx, `INSERT INTO v2_save_data_palball_jp(save_id, ball_id, count) VALUES(?,?,?)`, saveID, id, cnt); err != nil {
-			return err
-		}
-	}
-
-	// bbox_shop
-	for id, cnt := range sd.DCBlackBoxShopUsed {
-		if _, err := tx.ExecContext(ctx, `INSERT INTO v2_save_data_bbox_shop(save_id, item_id, count) VALUES(?,?,?)`, saveID, id, cnt); err != nil {
-			return err
-		}
-	}
-
-	// ferlot_item
-	for id, cnt := range sd.DCFerrettaLotteryItem {
-		if _, err := tx.ExecContext(ctx, `INSERT INTO v2_save_data_ferlot_item(save_id, item_id, count) VALUES(?,?,?)`, saveID, id, cnt); err != nil {
-			return err
-		}
-	}
-
-	// ferlot_useitem
-	for id, cnt := range sd.DCFerrettaLotteryItemUsed {
-		if _, err := tx.ExecContext(ctx, `INSERT INTO v2_save_data_ferlot_useitem(save_id, item_id, count) VALUES(?,?,?)`, saveID, id, cnt); err != nil {
-			return err
-		}
-	}
-
-	// perks
-	for i, level := range sd.LPerkLevels {
-		if _, err := tx.ExecContext(ctx, `INSERT INTO v2_save_data_perks(save_id, perk_id, level) VALUES(?,?,?)`, saveID, i, level); err != nil {
-			return err
-		}
-	}
-
-	// perks_credit
-	for i, credits := range sd.LPerkUsedCredits {
-		if _, err := tx.ExecContext(ctx, `INSERT INTO v2_save_data_perks_credit(save_id, perk_id, credits) VALUES(?,?,?)`, saveID, i, credits); err != nil {
-			return err
-		}
-	}
-
-	// totems
-	for i, level := range sd.LTotemLevels {
-		if _, err := tx.ExecContext(ctx, `INSERT INTO v2_save_data_totems(save_id, totem_id, level) VALUES(?,?,?)`, saveID, i, level); err != nil {
-			return err
-		}
-	}
-
-	// totems_credit
-	for i, credits := range sd.LTotemUsedCredits {
-		if _, err := tx.ExecContext(ctx, `INSERT INTO v2_save_data_totems_credit(save_id, totem_id, credits) VALUES(?,?,?)`, saveID, i, credits); err != nil {
-			return err
-		}
-	}
-
-	// totems_placement
-	for i, totemID := range sd.LTotemPlacements {
-		if _, err := tx.ExecContext(ctx, `INSERT INTO v2_save_data_totems_placement(save_id, placement_idx, totem_id) VALUES(?,?,?)`, saveID, i, totemID); err != nil {
-			return err
-		}
-	}
-
-	return tx.Commit()
-}
-
 // GetLatestSave retrieves the latest SaveData for a user
-func (r *Repository) GetLatestSave(ctx context.Context, userID string) (*domain.SaveData, error) {
+func (r *Repository) GetLatestSave(ctx context.Context, userID string) (_ *domain.SaveData, err error) {
+	ctx, span := tracing.Start(ctx, "repository.GetLatestSave")
+	defer func() { tracing.End(span, err) }()
 
 	var sd domain.SaveData
 	// 1) main row
-	err := r.db.GetContext(ctx, &sd, `
+	err = r.db.GetContext(ctx, &sd, `
 SELECT * 
 FROM v2_save_data 
 WHERE user_id = ? 
@@ -197,7 +37,38 @@ LIMIT 1
 		return nil, err
 	}
 
-	// 2) medal_get map
+	// 2) achievements - v3_user_latest_save_data_achievements から取得
+	rows, err := r.db.QueryxContext(ctx, `
+SELECT achievement_id 
+FROM v3_user_latest_save_data_achievements 
+WHERE user_id = ?
+`, userID)
+	if err != nil {
+		return nil, err
+	}
+	// 事前に容量を確保してメモリ効率を改善（最大1000個まで）
+	sd.LAchieve = make([]string, 0, 1000)
+	for rows.Next() {
+		var aid string
+		if err := rows.Scan(&aid); err != nil {
+			return nil, err
+		}
+		sd.LAchieve = append(sd.LAchieve, aid)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+
+	// 3) 各種詳細カウンタ
+	if err := r.loadSaveChildren(ctx, &sd); err != nil {
+		return nil, err
+	}
+	return &sd, nil
+}
+
+// loadSaveChildren fills the per-save child tables (everything except achievements) of sd.
+func (r *Repository) loadSaveChildren(ctx context.Context, sd *domain.SaveData) error {
+	// 1) medal_get map
 	sd.DCMedalGet = make(map[string]int)
 	rows, err := r.db.QueryxContext(ctx, `
 SELECT medal_id, count 
@@ -205,21 +76,21 @@ FROM v2_save_data_medal_get
 WHERE save_id = ?
 `, sd.ID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	for rows.Next() {
 		var id string
 		var cnt int
 		if err := rows.Scan(&id, &cnt); err != nil {
-			return nil, err
+			return err
 		}
 		sd.DCMedalGet[id] = cnt
 	}
 	if err := rows.Close(); err != nil {
-		return nil, err
+		return err
 	}
 
-	// 3) ball_get
+	// 2) ball_get
 	sd.DCBallGet = make(map[string]int64)
 	rows, err = r.db.QueryxContext(ctx, `
 SELECT ball_id, count 
@@ -227,21 +98,21 @@ FROM v2_save_data_ball_get
 WHERE save_id = ?
 `, sd.ID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	for rows.Next() {
 		var id string
 		var cnt int64
 		if err := rows.Scan(&id, &cnt); err != nil {
-			return nil, err
+			return err
 		}
 		sd.DCBallGet[id] = cnt
 	}
 	if err := rows.Close(); err != nil {
-		return nil, err
+		return err
 	}
 
-	// 4) ball_chain
+	// 3) ball_chain
 	sd.DCBallChain = make(map[string]int)
 	rows, err = r.db.QueryxContext(ctx, `
 SELECT ball_id, chain_count 
@@ -249,43 +120,21 @@ FROM v2_save_data_ball_chain
 WHERE save_id = ?
 `, sd.ID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	for rows.Next() {
 		var id string
 		var cnt int
 		if err := rows.Scan(&id, &cnt); err != nil {
-			return nil, err
+			return err
 		}
 		sd.DCBallChain[id] = cnt
 	}
 	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-
-	// 5) achievements - v3_user_latest_save_data_achievements から取得
-	rows, err = r.db.QueryxContext(ctx, `
-SELECT achievement_id 
-FROM v3_user_latest_save_data_achievements 
-WHERE user_id = ?
-`, userID)
-	if err != nil {
-		return nil, err
-	}
-	// 事前に容量を確保してメモリ効率を改善（最大1000個まで）
-	sd.LAchieve = make([]string, 0, 1000)
-	for rows.Next() {
-		var aid string
-		if err := rows.Scan(&aid); err != nil {
-			return nil, err
-		}
-		sd.LAchieve = append(sd.LAchieve, aid)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
+		return err
 	}
 
-	// 6) palball_get
+	// 4) palball_get
 	sd.DCPalettaBallGet = make(map[string]int)
 	rows, err = r.db.QueryxContext(ctx, `
 SELECT ball_id, count 
@@ -293,21 +142,21 @@ FROM v2_save_data_palball_get
 WHERE save_id = ?
 `, sd.ID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	for rows.Next() {
 		var id string
 		var cnt int
 		if err := rows.Scan(&id, &cnt); err != nil {
-			return nil, err
+			return err
 		}
 		sd.DCPalettaBallGet[id] = cnt
 	}
 	if err := rows.Close(); err != nil {
-		return nil, err
+		return err
 	}
 
-	// 7) palball_jp
+	// 5) palball_jp
 	sd.DCPalettaBallJackpot = make(map[string]int)
 	rows, err = r.db.QueryxContext(ctx, `
 SELECT ball_id, count 
@@ -315,21 +164,21 @@ FROM v2_save_data_palball_jp
 WHERE save_id = ?
 `, sd.ID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	for rows.Next() {
 		var id string
 		var cnt int
 		if err := rows.Scan(&id, &cnt); err != nil {
-			return nil, err
+			return err
 		}
 		sd.DCPalettaBallJackpot[id] = cnt
 	}
 	if err := rows.Close(); err != nil {
-		return nil, err
+		return err
 	}
 
-	// 8) bbox_shop
+	// 6) bbox_shop
 	sd.DCBlackBoxShopUsed = make(map[string]int)
 	rows, err = r.db.QueryxContext(ctx, `
 SELECT item_id, count
@@ -337,21 +186,21 @@ FROM v2_save_data_bbox_shop
 WHERE save_id = ?
 `, sd.ID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	for rows.Next() {
 		var id string
 		var cnt int
 		if err := rows.Scan(&id, &cnt); err != nil {
-			return nil, err
+			return err
 		}
 		sd.DCBlackBoxShopUsed[id] = cnt
 	}
 	if err := rows.Close(); err != nil {
-		return nil, err
+		return err
 	}
 
-	// 9) ferlot_item
+	// 7) ferlot_item
 	sd.DCFerrettaLotteryItem = make(map[string]int)
 	rows, err = r.db.QueryxContext(ctx, `
 SELECT item_id, count
@@ -359,21 +208,21 @@ FROM v2_save_data_ferlot_item
 WHERE save_id = ?
 `, sd.ID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	for rows.Next() {
 		var id string
 		var cnt int
 		if err := rows.Scan(&id, &cnt); err != nil {
-			return nil, err
+			return err
 		}
 		sd.DCFerrettaLotteryItem[id] = cnt
 	}
 	if err := rows.Close(); err != nil {
-		return nil, err
+		return err
 	}
 
-	// 10) ferlot_useitem
+	// 8) ferlot_useitem
 	sd.DCFerrettaLotteryItemUsed = make(map[string]int)
 	rows, err = r.db.QueryxContext(ctx, `
 SELECT item_id, count
@@ -381,21 +230,21 @@ FROM v2_save_data_ferlot_useitem
 WHERE save_id = ?
 `, sd.ID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	for rows.Next() {
 		var id string
 		var cnt int
 		if err := rows.Scan(&id, &cnt); err != nil {
-			return nil, err
+			return err
 		}
 		sd.DCFerrettaLotteryItemUsed[id] = cnt
 	}
 	if err := rows.Close(); err != nil {
-		return nil, err
+		return err
 	}
 
-	// 11) perks
+	// 9) perks
 	rows, err = r.db.QueryxContext(ctx, `
 SELECT perk_id, level 
 FROM v2_save_data_perks 
@@ -403,7 +252,7 @@ WHERE save_id = ?
 ORDER BY perk_id
 `, sd.ID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	// 事前に容量を確保してメモリ効率を改善（最大100個まで）
 	sd.LPerkLevels = make([]int, 0, 100)
@@ -411,7 +260,7 @@ ORDER BY perk_id
 		var perkID int
 		var level int
 		if err := rows.Scan(&perkID, &level); err != nil {
-			return nil, err
+			return err
 		}
 		// perk_idの順序に合わせて配列を拡張（制限付き）
 		for len(sd.LPerkLevels) <= perkID && len(sd.LPerkLevels) < 100 {
@@ -422,10 +271,10 @@ ORDER BY perk_id
 		}
 	}
 	if err := rows.Close(); err != nil {
-		return nil, err
+		return err
 	}
 
-	// 12) perks_credit
+	// 10) perks_credit
 	rows, err = r.db.QueryxContext(ctx, `
 SELECT perk_id, credits 
 FROM v2_save_data_perks_credit 
@@ -433,7 +282,7 @@ WHERE save_id = ?
 ORDER BY perk_id
 `, sd.ID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	// 事前に容量を確保してメモリ効率を改善（最大100個まで）
 	sd.LPerkUsedCredits = make([]int64, 0, 100)
@@ -441,7 +290,7 @@ ORDER BY perk_id
 		var perkID int
 		var credits int64
 		if err := rows.Scan(&perkID, &credits); err != nil {
-			return nil, err
+			return err
 		}
 		// perk_idの順序に合わせて配列を拡張（制限付き）
 		for len(sd.LPerkUsedCredits) <= perkID && len(sd.LPerkUsedCredits) < 100 {
@@ -452,10 +301,10 @@ ORDER BY perk_id
 		}
 	}
 	if err := rows.Close(); err != nil {
-		return nil, err
+		return err
 	}
 
-	// 13) totems
+	// 11) totems
 	rows, err = r.db.QueryxContext(ctx, `
 SELECT totem_id, level 
 FROM v2_save_data_totems 
@@ -463,14 +312,14 @@ WHERE save_id = ?
 ORDER BY totem_id
 `, sd.ID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	sd.LTotemLevels = make([]int, 0, 100)
 	for rows.Next() {
 		var totemID int
 		var level int
 		if err := rows.Scan(&totemID, &level); err != nil {
-			return nil, err
+			return err
 		}
 		for len(sd.LTotemLevels) <= totemID && len(sd.LTotemLevels) < 100 {
 			sd.LTotemLevels = append(sd.LTotemLevels, 0)
@@ -480,10 +329,10 @@ ORDER BY totem_id
 		}
 	}
 	if err := rows.Close(); err != nil {
-		return nil, err
+		return err
 	}
 
-	// 14) totems_credit
+	// 12) totems_credit
 	rows, err = r.db.QueryxContext(ctx, `
 SELECT totem_id, credits 
 FROM v2_save_data_totems_credit
@@ -491,14 +340,14 @@ WHERE save_id = ?
 ORDER BY totem_id
 `, sd.ID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	sd.LTotemUsedCredits = make([]int64, 0, 100)
 	for rows.Next() {
 		var totemID int
 		var credits int64
 		if err := rows.Scan(&totemID, &credits); err != nil {
-			return nil, err
+			return err
 		}
 		for len(sd.LTotemUsedCredits) <= totemID && len(sd.LTotemUsedCredits) < 100 {
 			sd.LTotemUsedCredits = append(sd.LTotemUsedCredits, 0)
@@ -508,10 +357,10 @@ ORDER BY totem_id
 		}
 	}
 	if err := rows.Close(); err != nil {
-		return nil, err
+		return err
 	}
 
-	// 15) totems_placement
+	// 13) totems_placement
 	rows, err = r.db.QueryxContext(ctx, `
 SELECT placement_idx, totem_id 
 FROM v2_save_data_totems_placement
@@ -519,14 +368,14 @@ WHERE save_id = ?
 ORDER BY placement_idx
 `, sd.ID)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	sd.LTotemPlacements = make([]int, 0, 100)
 	for rows.Next() {
 		var placementIdx int
 		var totemID int
 		if err := rows.Scan(&placementIdx, &totemID); err != nil {
-			return nil, err
+			return err
 		}
 		for len(sd.LTotemPlacements) <= placementIdx && len(sd.LTotemPlacements) < 100 {
 			sd.LTotemPlacements = append(sd.LTotemPlacements, 0)
@@ -536,9 +385,9 @@ ORDER BY placement_idx
 		}
 	}
 	if err := rows.Close(); err != nil {
-		return nil, err
+		return err
 	}
-	return &sd, nil
+	return nil
 }
 
 // GetStatistics returns combined rankings and total medals.
@@ -885,7 +734,7 @@ SELECT
 FROM (
   SELECT
     user_id,
-    CAST(cpm_max AS SIGNED) AS value,
+    `+r.dialect().roundToInteger("cpm_max")+` AS value,
     updated_at AS created_at,
     ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY cpm_max DESC, updated_at ASC) AS rn
   FROM v2_save_data
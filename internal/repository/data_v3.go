@@ -2,15 +2,22 @@ package repository
 
 import (
 	"context"
-	"runtime"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/domain"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/pkg/tracing"
 	"github.com/pikachu0310/very-big-medal-pusher-data-server/openapi/models"
 )
 
 // InsertSaveV4 persists a SaveData and its child tables, and updates v3_user_latest_save_data
-func (r *Repository) InsertSaveV4(ctx context.Context, sd *domain.SaveData) error {
+func (r *Repository) InsertSaveV4(ctx context.Context, sd *domain.SaveData) (err error) {
+	ctx, span := tracing.Start(ctx, "repository.InsertSaveV4")
+	defer func() { tracing.End(span, err) }()
+
 	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return err
@@ -19,6 +26,27 @@ func (r *Repository) InsertSaveV4(ctx context.Context, sd *domain.SaveData) erro
 		_ = tx.Rollback()
 	}()
 
+	saveID, unlocked, err := r.insertSaveV4Tx(ctx, tx, sd)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	sd.ID = saveID
+	sd.UnlockedAchievements = unlocked
+	return nil
+}
+
+// insertSaveV4Tx is InsertSaveV4 within the caller's transaction.
+// It returns the new save ID and the achievements this save unlocked.
+func (r *Repository) insertSaveV4Tx(ctx context.Context, tx *sqlx.Tx, sd *domain.SaveData) (int64, []string, error) {
+	// 集計値の差分更新用に、保存前の寄与を取得（最新行をロックし、upsertLatestSave で書き換える前に読む）
+	before, err := loadSummaryContribution(ctx, tx, sd.UserId)
+	if err != nil {
+		return 0, nil, err
+	}
+
 	// v2_save_data に挿入
 	res, err := tx.ExecContext(ctx, `
 INSERT INTO v2_save_data (
@@ -36,7 +64,7 @@ INSERT INTO v2_save_data (
     jackfr_get_all, jackfr_get_t0, jackfr_get_t1, jackfr_get_t2, jackfr_get_t3, jackfr_get_t4,
     jackfr_startmax, jackfr_totalmax, ferlot_hit, ferlot_lose, ferlot_chance, ferlot_act, ferlot_lines, bbox_shop, ferlot_maxln, bbox_used_ferlot, get_medaltower,
     task_cnt, totem_altars, totem_altars_credit, buy_shbi,
-    firstboot, lastsave, playtime
+    firstboot, lastsave, playtime, extra_fields
 	) VALUES (
 	    ?, ?, ?,
 	    ?, ?, ?, ?,
@@ -52,7 +80,7 @@ INSERT INTO v2_save_data (
 	    ?, ?, ?, ?, ?, ?,
 	    ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?,
 	    ?, ?, ?, ?,
-	    ?, ?, ?
+	    ?, ?, ?, ?
 	)`,
 		sd.UserId, sd.Legacy, sd.Version,
 		sd.Credit, sd.CreditAll, sd.MedalIn, sd.MedalGet,
@@ -68,397 +96,215 @@ INSERT INTO v2_save_data (
 		sd.JackpotFerrettaGetTotal, sd.JackpotFerrettaGetTier0, sd.JackpotFerrettaGetTier1, sd.JackpotFerrettaGetTier2, sd.JackpotFerrettaGetTier3, sd.JackpotFerrettaGetTier4,
 		sd.JackpotFerrettaStartMax, sd.JackpotFerrettaTotalMax, sd.FerrettaLotteryHit, sd.FerrettaLotteryLose, sd.FerrettaLotteryChance, sd.FerrettaLotteryActives, sd.FerrettaLotteryLines, sd.BlackBoxShopUsed, sd.FerrettaLotteryMaxLines, sd.BlackBoxUsedFerrettaItem, sd.GetMedalTower,
 		sd.TaskCompleteCount, sd.TotemAltarUnlockCount, sd.TotemAltarUnlockUsedCredits, sd.BuyShbi,
-		sd.FirstBoot, sd.LastSave, sd.Playtime,
+		sd.FirstBoot, sd.LastSave, sd.Playtime, sd.ExtraFields,
 	)
 	if err != nil {
-		return err
+		return 0, nil, err
 	}
 	saveID, err := res.LastInsertId()
 	if err != nil {
-		return err
+		return 0, nil, err
 	}
 
 	// 関連テーブルに挿入
 	// medal_get
 	for id, cnt := range sd.DCMedalGet {
 		if _, err := tx.ExecContext(ctx, `INSERT INTO v2_save_data_medal_get(save_id, medal_id, count) VALUES(?,?,?)`, saveID, id, cnt); err != nil {
-			return err
+			return 0, nil, err
 		}
 	}
 	// ball_get
 	for id, cnt := range sd.DCBallGet {
 		if _, err := tx.ExecContext(ctx, `INSERT INTO v2_save_data_ball_get(save_id, ball_id, count) VALUES(?,?,?)`, saveID, id, cnt); err != nil {
-			return err
+			return 0, nil, err
 		}
 	}
 	// ball_chain
 	for id, cnt := range sd.DCBallChain {
 		if _, err := tx.ExecContext(ctx, `INSERT INTO v2_save_data_ball_chain(save_id, ball_id, chain_count) VALUES(?,?,?)`, saveID, id, cnt); err != nil {
-			return err
+			return 0, nil, err
 		}
 	}
 	// achievements - 最適化版：新しいアチーブメントのみを追加
-	if err := r.insertNewAchievements(ctx, tx, sd.UserId, saveID, sd.LAchieve); err != nil {
-		return err
+	unlocked, err := r.insertNewAchievements(ctx, tx, sd.UserId, saveID, sd.LAchieve)
+	if err != nil {
+		return 0, nil, err
 	}
 
 	// palball_get
 	for id, cnt := range sd.DCPalettaBallGet {
 		if _, err := tx.ExecContext(ctx, `INSERT INTO v2_save_data_palball_get(save_id, ball_id, count) VALUES(?,?,?)`, saveID, id, cnt); err != nil {
-			return err
+			return 0, nil, err
 		}
 	}
 
 	// palball_jp
 	for id, cnt := range sd.DCPalettaBallJackpot {
 		if _, err := tx.ExecContext(ctx, `INSERT INTO v2_save_data_palball_jp(save_id, ball_id, count) VALUES(?,?,?)`, saveID, id, cnt); err != nil {
-			return err
+			return 0, nil, err
 		}
 	}
 
 	// bbox_shop
 	for id, cnt := range sd.DCBlackBoxShopUsed {
 		if _, err := tx.ExecContext(ctx, `INSERT INTO v2_save_data_bbox_shop(save_id, item_id, count) VALUES(?,?,?)`, saveID, id, cnt); err != nil {
-			return err
+			return 0, nil, err
 		}
 	}
 
 	// ferlot_item
 	for id, cnt := range sd.DCFerrettaLotteryItem {
 		if _, err := tx.ExecContext(ctx, `INSERT INTO v2_save_data_ferlot_item(save_id, item_id, count) VALUES(?,?,?)`, saveID, id, cnt); err != nil {
-			return err
+			return 0, nil, err
 		}
 	}
 
 	// ferlot_useitem
 	for id, cnt := range sd.DCFerrettaLotteryItemUsed {
 		if _, err := tx.ExecContext(ctx, `INSERT INTO v2_save_data_ferlot_useitem(save_id, item_id, count) VALUES(?,?,?)`, saveID, id, cnt); err != nil {
-			return err
+			return 0, nil, err
 		}
 	}
 
 	// perks
 	for i, level := range sd.LPerkLevels {
 		if _, err := tx.ExecContext(ctx, `INSERT INTO v2_save_data_perks(save_id, perk_id, level) VALUES(?,?,?)`, saveID, i, level); err != nil {
-			return err
+			return 0, nil, err
 		}
 	}
 
 	// perks_credit
 	for i, credits := range sd.LPerkUsedCredits {
 		if _, err := tx.ExecContext(ctx, `INSERT INTO v2_save_data_perks_credit(save_id, perk_id, credits) VALUES(?,?,?)`, saveID, i, credits); err != nil {
-			return err
+			return 0, nil, err
 		}
 	}
 
 	// totems
 	for i, level := range sd.LTotemLevels {
 		if _, err := tx.ExecContext(ctx, `INSERT INTO v2_save_data_totems(save_id, totem_id, level) VALUES(?,?,?)`, saveID, i, level); err != nil {
-			return err
+			return 0, nil, err
 		}
 	}
 
 	// totems_credit
 	for i, credits := range sd.LTotemUsedCredits {
 		if _, err := tx.ExecContext(ctx, `INSERT INTO v2_save_data_totems_credit(save_id, totem_id, credits) VALUES(?,?,?)`, saveID, i, credits); err != nil {
-			return err
+			return 0, nil, err
 		}
 	}
 
 	// totems_placement
 	for i, totemID := range sd.LTotemPlacements {
 		if _, err := tx.ExecContext(ctx, `INSERT INTO v2_save_data_totems_placement(save_id, placement_idx, totem_id) VALUES(?,?,?)`, saveID, i, totemID); err != nil {
-			return err
+			return 0, nil, err
 		}
 	}
 
 	// v3_user_latest_save_data を更新
-	// 実績数を計算
-	achievementsCount := len(sd.LAchieve)
-
-	// 最大レインボーチェイン数を計算
-	maxChainRainbow := 0
-	if chainCount, exists := sd.DCBallChain["3"]; exists {
-		maxChainRainbow = chainCount
-	}
-
-	// golden_palball_getを計算（ball_id = 100の数）
-	goldenPalballGet := 0
-	if palballCount, exists := sd.DCPalettaBallGet["100"]; exists {
-		goldenPalballGet = palballCount
-	}
-
-	// v3_user_latest_save_data に挿入/更新
-	_, err = tx.ExecContext(ctx, `
-INSERT INTO v3_user_latest_save_data (
-    user_id, version, credit_all, playtime, save_id, achievements_count,
-    jacksp_startmax, jackfr_startmax, jackfr_totalmax, ferlot_lines, golden_palball_get,
-    cpm_max, max_chain_rainbow, jack_totalmax_v2, ult_combomax, ult_totalmax_v2, blackbox_total, sp_use, hide_record
-) VALUES (?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)
-ON DUPLICATE KEY UPDATE
-    version = VALUES(version),
-    credit_all = VALUES(credit_all),
-    playtime = VALUES(playtime),
-    save_id = VALUES(save_id),
-    achievements_count = VALUES(achievements_count),
-    jacksp_startmax = VALUES(jacksp_startmax),
-    jackfr_startmax = VALUES(jackfr_startmax),
-    jackfr_totalmax = VALUES(jackfr_totalmax),
-    ferlot_lines = VALUES(ferlot_lines),
-    golden_palball_get = VALUES(golden_palball_get),
-    cpm_max = VALUES(cpm_max),
-    max_chain_rainbow = VALUES(max_chain_rainbow),
-    jack_totalmax_v2 = VALUES(jack_totalmax_v2),
-    ult_combomax = VALUES(ult_combomax),
-    ult_totalmax_v2 = VALUES(ult_totalmax_v2),
-    blackbox_total = VALUES(blackbox_total),
-    sp_use = VALUES(sp_use),
-    hide_record = VALUES(hide_record),
-    updated_at = CURRENT_TIMESTAMP`,
-		sd.UserId, sd.Version, sd.CreditAll, sd.Playtime, saveID, achievementsCount,
-		sd.JackpotSuperStartMax, sd.JackpotFerrettaStartMax, sd.JackpotFerrettaTotalMax, sd.FerrettaLotteryLines, goldenPalballGet,
-		sd.CpMMax, maxChainRainbow, sd.JackTotalMaxV2, sd.UltComboMax, sd.UltimateTotalMaxV2, sd.BlackBoxTotal, sd.SpUse, sd.HideRecord,
-	)
-	if err != nil {
-		return err
-	}
-
-	return tx.Commit()
-}
-
-// GetStatisticsV4 returns the latest statistics for V4 using v3_user_latest_save_data (ランキング上限 1000).
-func (r *Repository) GetStatisticsV4(ctx context.Context) (*models.StatisticsV4, error) {
-	stats := &models.StatisticsV4{}
-
-	// ------ 共通ヘルパ (匿名関数) -----------------------------------------
-	addRanking := func(ptr **[]models.RankingEntry, query string, args ...interface{}) error {
-		rows, err := r.db.QueryxContext(ctx, query, args...)
-		if err != nil {
-			return err
-		}
-		defer func() {
-			_ = rows.Close()
-		}()
-
-		// 事前に容量を確保してメモリ効率を改善（最大1000個）
-		list := make([]models.RankingEntry, 0, 1000)
-		for rows.Next() {
-			var e models.RankingEntry
-			if err := rows.Scan(&e.UserId, &e.Value, &e.CreatedAt); err != nil {
-				return err
-			}
-			list = append(list, e)
-		}
-		*ptr = &list
-		return nil
-	}
-
-	// 1) achievements_count
-	if err := addRanking(&stats.AchievementsCount, `
-SELECT
-  user_id,
-  achievements_count AS value,
-  updated_at AS created_at
-FROM v3_user_latest_save_data
-WHERE hide_record = 0
-ORDER BY achievements_count DESC, created_at ASC
-LIMIT 1000
-`); err != nil {
-		return nil, err
+	if err := upsertLatestSave(ctx, tx, sd, saveID); err != nil {
+		return 0, nil, err
 	}
 
-	// 2) jacksp_startmax
-	if err := addRanking(&stats.JackspStartmax, `
-SELECT
-  user_id,
-  jacksp_startmax AS value,
-  updated_at AS created_at
-FROM v3_user_latest_save_data
-WHERE hide_record = 0
-ORDER BY jacksp_startmax DESC, created_at ASC
-LIMIT 1000
-`); err != nil {
-		return nil, err
-	}
-
-	// 3) golden_palball_get
-	if err := addRanking(&stats.GoldenPalballGet, `
-SELECT
-  user_id,
-  golden_palball_get AS value,
-  updated_at AS created_at
-FROM v3_user_latest_save_data
-WHERE hide_record = 0
-ORDER BY golden_palball_get DESC, created_at ASC
-LIMIT 1000
-`); err != nil {
-		return nil, err
-	}
-
-	// 4) jackfr_startmax
-	if err := addRanking(&stats.JackfrStartmax, `
-SELECT
-  user_id,
-  jackfr_startmax AS value,
-  updated_at AS created_at
-FROM v3_user_latest_save_data
-WHERE hide_record = 0
-ORDER BY jackfr_startmax DESC, created_at ASC
-LIMIT 1000
-`); err != nil {
-		return nil, err
-	}
-
-	// 5) jackfr_totalmax
-	if err := addRanking(&stats.JackfrTotalmax, `
-SELECT
-  user_id,
-  jackfr_totalmax AS value,
-  updated_at AS created_at
-FROM v3_user_latest_save_data
-WHERE hide_record = 0
-ORDER BY jackfr_totalmax DESC, created_at ASC
-LIMIT 1000
-`); err != nil {
-		return nil, err
+	// v4_summary_* を差分更新
+	if err := applySummaryDelta(ctx, tx, before, sd, unlocked); err != nil {
+		return 0, nil, err
 	}
+	return saveID, unlocked, nil
+}
 
-	// 6) ferlot_lines
-	if err := addRanking(&stats.FerlotLines, `
-SELECT
-  user_id,
-  ferlot_lines AS value,
-  updated_at AS created_at
-FROM v3_user_latest_save_data
-WHERE hide_record = 0
-ORDER BY ferlot_lines DESC, created_at ASC
-LIMIT 1000
-`); err != nil {
-		return nil, err
-	}
+// latestSaveRow is a row of v3_user_latest_save_data without its timestamps.
+type latestSaveRow struct {
+	UserID     string
+	SaveID     int64
+	Projection domain.RankingProjection
+}
 
-	// 7) cpm_max
-	if err := addRanking(&stats.CpmMax, `
-SELECT
-  user_id,
-  CAST(cpm_max AS SIGNED) AS value,
-  updated_at AS created_at
-FROM v3_user_latest_save_data
-WHERE hide_record = 0
-ORDER BY cpm_max DESC, created_at ASC
-LIMIT 1000
-`); err != nil {
-		return nil, err
-	}
+// Values returns the row's values in the order of latestSaveColumns.
+func (row latestSaveRow) Values() []any {
+	return append([]any{row.UserID, row.SaveID}, row.Projection.Values()...)
+}
 
-	// 8) max_chain_rainbow
-	if err := addRanking(&stats.MaxChainRainbow, `
-SELECT
-  user_id,
-  max_chain_rainbow AS value,
-  updated_at AS created_at
-FROM v3_user_latest_save_data
-WHERE hide_record = 0
-ORDER BY max_chain_rainbow DESC, created_at ASC
-LIMIT 1000
-`); err != nil {
-		return nil, err
-	}
+var latestSaveColumns = "user_id, save_id, " + strings.Join(domain.RankingColumns(), ", ")
+
+// upsertLatestSaveQuery writes a latestSaveRow and sets updated_at to the current time, which ranks the user
+// behind the users who reached the same value earlier (the ranking tie-breaker, see domain.RankedMetric).
+// created_at of an existing row is kept.
+func upsertLatestSaveQuery(d dialect) string {
+	cols := domain.RankingColumns()
+	updates := make([]string, 0, len(cols)+2)
+	updates = append(updates, "save_id = "+d.inserted("save_id"))
+	for _, c := range cols {
+		updates = append(updates, c+" = "+d.inserted(c))
+	}
+	updates = append(updates, "updated_at = CURRENT_TIMESTAMP")
+	return `
+INSERT INTO v3_user_latest_save_data (` + latestSaveColumns + `)
+VALUES (?` + strings.Repeat(", ?", len(cols)+1) + `)
+` + d.upsert("user_id") + `
+    ` + strings.Join(updates, ",\n    ")
+}
 
-	// 9) jack_totalmax_v2
-	if err := addRanking(&stats.JackTotalmaxV2, `
-SELECT
-  user_id,
-  jack_totalmax_v2 AS value,
-  updated_at AS created_at
-FROM v3_user_latest_save_data
-WHERE hide_record = 0
-ORDER BY jack_totalmax_v2 DESC, created_at ASC
-LIMIT 1000
-`); err != nil {
-		return nil, err
-	}
+// newLatestSaveRow returns the v3_user_latest_save_data row of sd, stored as saveID.
+func newLatestSaveRow(sd *domain.SaveData, saveID int64) latestSaveRow {
+	return latestSaveRow{UserID: sd.UserId, SaveID: saveID, Projection: domain.ProjectRanking(sd)}
+}
 
-	// 10) ult_combomax
-	if err := addRanking(&stats.UltCombomax, `
-SELECT
-  user_id,
-  ult_combomax AS value,
-  updated_at AS created_at
-FROM v3_user_latest_save_data
-WHERE hide_record = 0
-ORDER BY ult_combomax DESC, created_at ASC
-LIMIT 1000
-`); err != nil {
-		return nil, err
-	}
+// upsertLatestSave writes sd, stored as saveID, as the user's row of v3_user_latest_save_data.
+// sd.LAchieve must hold every achievement the user has unlocked; see domain.ProjectRanking.
+func upsertLatestSave(ctx context.Context, tx *sqlx.Tx, sd *domain.SaveData, saveID int64) error {
+	_, err := tx.ExecContext(ctx, upsertLatestSaveQuery(dialectOf(tx)), newLatestSaveRow(sd, saveID).Values()...)
+	return err
+}
 
-	// 11) ult_totalmax_v2
-	if err := addRanking(&stats.UltTotalmaxV2, `
-SELECT
-  user_id,
-  ult_totalmax_v2 AS value,
-  updated_at AS created_at
-FROM v3_user_latest_save_data
-WHERE hide_record = 0
-ORDER BY ult_totalmax_v2 DESC, created_at ASC
-LIMIT 1000
-`); err != nil {
-		return nil, err
-	}
+// GetStatisticsV4 returns the latest statistics for V4 using v3_user_latest_save_data (ランキング上限 1000).
+func (r *Repository) GetStatisticsV4(ctx context.Context) (_ *models.StatisticsV4, err error) {
+	ctx, span := tracing.Start(ctx, "repository.GetStatisticsV4")
+	defer func() { tracing.End(span, err) }()
 
-	// 12) blackbox_total
-	if err := addRanking(&stats.BlackboxTotal, `
-SELECT
-  user_id,
-  blackbox_total AS value,
-  updated_at AS created_at
-FROM v3_user_latest_save_data
-WHERE hide_record = 0
-ORDER BY blackbox_total DESC, created_at ASC
-LIMIT 1000
-`); err != nil {
-		return nil, err
-	}
+	started := time.Now()
+	stats := &models.StatisticsV4{}
 
-	// 13) sp_use
-	if err := addRanking(&stats.SpUse, `
-SELECT
-  user_id,
-  sp_use AS value,
-  updated_at AS created_at
-FROM v3_user_latest_save_data
-WHERE hide_record = 0
-ORDER BY sp_use DESC, created_at ASC
-LIMIT 1000
-`); err != nil {
-		return nil, err
+	// 各ランキング（domain.RankedMetrics）。ランキングごとに span を切り、どのクエリが遅いかをトレースで判別できるようにする
+	for _, m := range domain.RankedMetrics {
+		ptr := domain.RankingEntries(stats, m.Name)
+		if ptr == nil {
+			return nil, fmt.Errorf("ranked metric %s has no field in StatisticsV4", m.Name)
+		}
+		list, err := r.queryRanking(ctx, "repository.GetStatisticsV4.ranking", m, statisticsRankingLimit)
+		if err != nil {
+			return nil, err
+		}
+		*ptr = &list
 	}
 
-	// 10) total_medals（全ユーザーの合計）
-	var totalMedals int
+	// total_medals（全ユーザーの合計、v4_summary_totals で差分管理。モデレーション中のユーザーの分は差し引く）
 	{
-		q := `
-SELECT
-  COALESCE(SUM(credit_all), 0) AS total_medals
-FROM v3_user_latest_save_data
-WHERE hide_record = 0
-`
-		if err := r.db.GetContext(ctx, &totalMedals, q); err != nil {
+		totalCtx, totalSpan := tracing.Start(ctx, "repository.GetStatisticsV4.total_medals")
+		total, err := r.getSummaryTotal(totalCtx, summaryTotalMedals)
+		var moderated []int64
+		if err == nil {
+			moderated, err = r.moderatedCredits(totalCtx)
+		}
+		tracing.End(totalSpan, err)
+		if err != nil {
 			return nil, err
 		}
+		for _, credit := range moderated {
+			total -= credit
+		}
+		totalMedals := int(total)
 		stats.TotalMedals = &totalMedals
 	}
 
-	// 最終段階でGCを促してメモリ使用量を安定化
-	runtime.GC()
+	slog.InfoContext(ctx, "statistics v4 computed", "duration_ms", time.Since(started).Milliseconds())
 	return stats, nil
 }
 
 // insertNewAchievements は新しいアチーブメントのみを挿入する最適化版
-func (r *Repository) insertNewAchievements(ctx context.Context, tx *sqlx.Tx, userID string, saveID int64, newAchievements []string) error {
+func (r *Repository) insertNewAchievements(ctx context.Context, tx *sqlx.Tx, userID string, saveID int64, newAchievements []string) ([]string, error) {
 	if len(newAchievements) == 0 {
-		return nil
+		return nil, nil
 	}
 
 	// 既存のアチーブメントを取得
@@ -469,7 +315,7 @@ func (r *Repository) insertNewAchievements(ctx context.Context, tx *sqlx.Tx, use
 		WHERE user_id = ?
 	`, userID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer func() {
 		_ = rows.Close()
@@ -478,17 +324,18 @@ func (r *Repository) insertNewAchievements(ctx context.Context, tx *sqlx.Tx, use
 	for rows.Next() {
 		var achievementID string
 		if err := rows.Scan(&achievementID); err != nil {
-			return err
+			return nil, err
 		}
 		existingAchievements[achievementID] = true
 	}
 
 	// 新しいアチーブメントのみを処理
+	var unlocked []string
 	for _, achievementID := range newAchievements {
 		if !existingAchievements[achievementID] {
 			// v2_save_data_achievements に新しいアチーブメントを追加（ログ用）
 			if _, err := tx.ExecContext(ctx, `INSERT INTO v2_save_data_achievements(save_id, achievement_id) VALUES(?,?)`, saveID, achievementID); err != nil {
-				return err
+				return nil, err
 			}
 
 			// v3_user_latest_save_data_achievements に追加
@@ -496,32 +343,36 @@ func (r *Repository) insertNewAchievements(ctx context.Context, tx *sqlx.Tx, use
 				INSERT INTO v3_user_latest_save_data_achievements (user_id, achievement_id) 
 				VALUES (?,?)
 			`, userID, achievementID); err != nil {
-				return err
+				return nil, err
 			}
+			unlocked = append(unlocked, achievementID)
+			existingAchievements[achievementID] = true
 		}
 	}
 
-	return nil
+	return unlocked, nil
 }
 
 // GetAchievementRates returns achievement acquisition rates
-func (r *Repository) GetAchievementRates(ctx context.Context) (*models.AchievementRates, error) {
-	// 最適化1: 全ユーザー数を先に取得（サブクエリを排除）
-	var totalUsers int
-	err := r.db.GetContext(ctx, &totalUsers, `
-SELECT COUNT(DISTINCT user_id) FROM v3_user_latest_save_data_achievements
-`)
+func (r *Repository) GetAchievementRates(ctx context.Context) (_ *models.AchievementRates, err error) {
+	ctx, span := tracing.Start(ctx, "repository.GetAchievementRates")
+	defer func() { tracing.End(span, err) }()
+
+	// 集計値は InsertSaveV4 が v4_summary_* に差分で反映している（モデレーション中のユーザーの分はここで差し引く）
+	total, err := r.getSummaryTotal(ctx, summaryAchievementUsers)
 	if err != nil {
 		return nil, err
 	}
+	moderatedUsers, moderatedCounts, err := r.moderatedAchievements(ctx)
+	if err != nil {
+		return nil, err
+	}
+	totalUsers := int(total - moderatedUsers)
 
-	// 最適化2: アチーブメント別ユーザー数のみを取得（重複なしなのでCOUNT(DISTINCT)は不要）
 	rows, err := r.db.QueryxContext(ctx, `
-SELECT 
-    achievement_id,
-    COUNT(user_id) as user_count
-FROM v3_user_latest_save_data_achievements
-GROUP BY achievement_id
+SELECT achievement_id, user_count
+FROM v4_summary_achievement_users
+WHERE user_count > 0
 ORDER BY user_count DESC
 `)
 	if err != nil {
@@ -531,7 +382,7 @@ ORDER BY user_count DESC
 		_ = rows.Close()
 	}()
 
-	// 最適化3: 事前に容量を確保（アチーブメント数は限定的）
+	// 事前に容量を確保（アチーブメント数は限定的）
 	achievementRates := make(map[string]struct {
 		Count *int     `json:"count,omitempty"`
 		Rate  *float32 `json:"rate,omitempty"`
@@ -543,8 +394,12 @@ ORDER BY user_count DESC
 		if err := rows.Scan(&achievementID, &userCount); err != nil {
 			return nil, err
 		}
+		userCount -= int(moderatedCounts[achievementID])
+		if userCount <= 0 {
+			continue
+		}
 
-		// 最適化4: 浮動小数点計算を最適化
+		// 浮動小数点計算を最適化
 		rate := float32(0.0)
 		if totalUsers > 0 {
 			rate = float32(userCount) / float32(totalUsers)
@@ -6,11 +6,15 @@ import (
 	"time"
 
 	openapi_types "github.com/oapi-codegen/runtime/types"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/pkg/tracing"
 	"github.com/pikachu0310/very-big-medal-pusher-data-server/openapi/models"
 )
 
 // GetSaveHistory returns recent save snapshots for a user ordered by updated_at desc.
-func (r *Repository) GetSaveHistory(ctx context.Context, userID string, limit int, before *time.Time) ([]models.SaveHistoryEntry, bool, error) {
+func (r *Repository) GetSaveHistory(ctx context.Context, userID string, limit int, before *time.Time) (_ []models.SaveHistoryEntry, _ bool, err error) {
+	ctx, span := tracing.Start(ctx, "repository.GetSaveHistory")
+	defer func() { tracing.End(span, err) }()
+
 	query := `
 SELECT
   id AS save_id,
@@ -32,7 +36,7 @@ WHERE user_id = ?
 	args := []any{userID}
 	if before != nil {
 		query += " AND updated_at < ?"
-		args = append(args, *before)
+		args = append(args, r.dialect().timeArg(*before))
 	}
 	query += " ORDER BY updated_at DESC LIMIT ?"
 	args = append(args, limit+1)
@@ -95,7 +99,10 @@ WHERE user_id = ?
 }
 
 // GetAchievementUnlockHistory returns unlock moments for a user.
-func (r *Repository) GetAchievementUnlockHistory(ctx context.Context, userID string, limit int) ([]models.AchievementUnlockEntry, int, error) {
+func (r *Repository) GetAchievementUnlockHistory(ctx context.Context, userID string, limit int) (_ []models.AchievementUnlockEntry, _ int, err error) {
+	ctx, span := tracing.Start(ctx, "repository.GetAchievementUnlockHistory")
+	defer func() { tracing.End(span, err) }()
+
 	const totalQuery = `
 SELECT COUNT(*) 
 FROM v2_save_data_achievements a
@@ -148,7 +155,12 @@ LIMIT ?
 }
 
 // GetMedalTimeseries aggregates total medals by day using the latest save per user per day.
-func (r *Repository) GetMedalTimeseries(ctx context.Context, days int) (*models.MedalTimeseriesResponse, error) {
+// Like the other statistics, it leaves out the users under an active moderation.
+func (r *Repository) GetMedalTimeseries(ctx context.Context, days int) (_ *models.MedalTimeseriesResponse, err error) {
+	ctx, span := tracing.Start(ctx, "repository.GetMedalTimeseries")
+	defer func() { tracing.End(span, err) }()
+
+	d := r.dialect()
 	query := `
 WITH latest_per_user_day AS (
   SELECT 
@@ -156,7 +168,7 @@ WITH latest_per_user_day AS (
     DATE(updated_at) AS day,
     MAX(updated_at) AS latest_updated_at
   FROM v2_save_data
-  WHERE updated_at >= DATE_SUB(CURRENT_DATE, INTERVAL ? DAY)
+  WHERE updated_at >= ` + d.daysAgo() + d.notModerated("v2_save_data.user_id") + `
   GROUP BY user_id, DATE(updated_at)
 )
 SELECT
@@ -174,10 +186,10 @@ ORDER BY day ASC
 `
 
 	var rows []struct {
-		Day         time.Time `db:"day"`
-		TotalMedals int64     `db:"total_medals"`
-		ActiveUsers int64     `db:"active_users"`
-		AvgPlaytime float64   `db:"avg_playtime"`
+		Day         dbTime  `db:"day"`
+		TotalMedals int64   `db:"total_medals"`
+		ActiveUsers int64   `db:"active_users"`
+		AvgPlaytime float64 `db:"avg_playtime"`
 	}
 
 	if err := r.db.SelectContext(ctx, &rows, query, days); err != nil {
@@ -189,7 +201,7 @@ ORDER BY day ASC
 		total := int(row.TotalMedals)
 		users := int(row.ActiveUsers)
 		avg := int(math.Round(row.AvgPlaytime))
-		date := openapi_types.Date{Time: row.Day}
+		date := openapi_types.Date{Time: row.Day.Time}
 
 		buckets = append(buckets, models.MedalTimeseriesBucket{
 			Date:        &date,
@@ -204,23 +216,27 @@ ORDER BY day ASC
 	}, nil
 }
 
-// GetSaveActivity aggregates hourly save counts for a time window.
-func (r *Repository) GetSaveActivity(ctx context.Context, hours int) (*models.SaveActivityResponse, error) {
+// GetSaveActivity aggregates hourly save counts for a time window, leaving out the users under an active moderation.
+func (r *Repository) GetSaveActivity(ctx context.Context, hours int) (_ *models.SaveActivityResponse, err error) {
+	ctx, span := tracing.Start(ctx, "repository.GetSaveActivity")
+	defer func() { tracing.End(span, err) }()
+
+	d := r.dialect()
 	query := `
 SELECT
-  TIMESTAMP(DATE_FORMAT(updated_at, '%Y-%m-%d %H:00:00')) AS hour_start,
+  ` + d.hourStart("updated_at") + ` AS hour_start,
   COUNT(*) AS saves,
   COUNT(DISTINCT user_id) AS unique_users
 FROM v2_save_data
-WHERE updated_at >= DATE_SUB(UTC_TIMESTAMP(), INTERVAL ? HOUR)
+WHERE updated_at >= ` + d.hoursAgo() + d.notModerated("v2_save_data.user_id") + `
 GROUP BY hour_start
 ORDER BY hour_start ASC
 `
 
 	var rows []struct {
-		HourStart   time.Time `db:"hour_start"`
-		Saves       int       `db:"saves"`
-		UniqueUsers int       `db:"unique_users"`
+		HourStart   dbTime `db:"hour_start"`
+		Saves       int    `db:"saves"`
+		UniqueUsers int    `db:"unique_users"`
 	}
 
 	if err := r.db.SelectContext(ctx, &rows, query, hours); err != nil {
@@ -232,7 +248,7 @@ ORDER BY hour_start ASC
 		saves := row.Saves
 		users := row.UniqueUsers
 		buckets = append(buckets, models.SaveActivityBucket{
-			HourStart:   timePtr(row.HourStart),
+			HourStart:   timePtr(row.HourStart.Time),
 			Saves:       intPtr(saves),
 			UniqueUsers: intPtr(users),
 		})
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// dialect is the SQL flavour of the database behind a Repository. Queries are written for MariaDB
+// and ask the dialect only for the parts SQLite spells differently.
+type dialect int
+
+const (
+	dialectMySQL dialect = iota
+	dialectSQLite
+)
+
+// dialectOf returns the dialect of a *sqlx.DB or *sqlx.Tx from the name of its driver.
+func dialectOf(db interface{ DriverName() string }) dialect {
+	switch db.DriverName() {
+	case "sqlite", "sqlite3":
+		return dialectSQLite
+	default:
+		return dialectMySQL
+	}
+}
+
+func (r *Repository) dialect() dialect {
+	return dialectOf(r.db)
+}
+
+// forUpdate locks the selected rows until the transaction ends. SQLite transactions are opened with
+// BEGIN IMMEDIATE (see config.SQLiteDSN), which already keeps other writers out, so it needs no clause.
+func (d dialect) forUpdate() string {
+	if d == dialectSQLite {
+		return ""
+	}
+	return "\nFOR UPDATE"
+}
+
+// lockInShareMode share-locks the selected rows until the transaction ends; see forUpdate for SQLite.
+func (d dialect) lockInShareMode() string {
+	if d == dialectSQLite {
+		return ""
+	}
+	return " LOCK IN SHARE MODE"
+}
+
+// upsert starts the clause that updates the existing row when an INSERT conflicts on keys.
+// The assignments follow it, referring to the inserted values with inserted.
+func (d dialect) upsert(keys ...string) string {
+	if d == dialectSQLite {
+		return "ON CONFLICT(" + strings.Join(keys, ", ") + ") DO UPDATE SET"
+	}
+	return "ON DUPLICATE KEY UPDATE"
+}
+
+// inserted refers to the value the conflicting INSERT tried to write to column.
+func (d dialect) inserted(column string) string {
+	if d == dialectSQLite {
+		return "excluded." + column
+	}
+	return "VALUES(" + column + ")"
+}
+
+// insertIgnore starts an INSERT that skips rows conflicting with a unique key.
+func (d dialect) insertIgnore() string {
+	if d == dialectSQLite {
+		return "INSERT OR IGNORE"
+	}
+	return "INSERT IGNORE"
+}
+
+// deleteAliased starts a DELETE from table whose rows are referred to as alias in the WHERE clause.
+func (d dialect) deleteAliased(table, alias string) string {
+	if d == dialectSQLite {
+		return "DELETE FROM " + table + " AS " + alias
+	}
+	return "DELETE " + alias + " FROM " + table + " " + alias
+}
+
+// roundToInteger rounds expr to the nearest integer, halves away from zero as CAST(... AS SIGNED) does.
+func (d dialect) roundToInteger(expr string) string {
+	if d == dialectSQLite {
+		return "CAST(ROUND(" + expr + ") AS INTEGER)"
+	}
+	return "CAST(" + expr + " AS SIGNED)"
+}
+
+// charLength is the number of characters of expr.
+func (d dialect) charLength(expr string) string {
+	if d == dialectSQLite {
+		return "LENGTH(" + expr + ")"
+	}
+	return "CHAR_LENGTH(" + expr + ")"
+}
+
+// utcNow is the current UTC time, comparable with the stored timestamps.
+func (d dialect) utcNow() string {
+	if d == dialectSQLite {
+		return "CURRENT_TIMESTAMP"
+	}
+	return "UTC_TIMESTAMP()"
+}
+
+// daysAgo is the start of the day the number of days given by the next argument before today.
+func (d dialect) daysAgo() string {
+	if d == dialectSQLite {
+		return "DATE('now', '-' || ? || ' days')"
+	}
+	return "DATE_SUB(CURRENT_DATE, INTERVAL ? DAY)"
+}
+
+// hoursAgo is the UTC time the number of hours given by the next argument before now.
+func (d dialect) hoursAgo() string {
+	if d == dialectSQLite {
+		return "DATETIME('now', '-' || ? || ' hours')"
+	}
+	return "DATE_SUB(UTC_TIMESTAMP(), INTERVAL ? HOUR)"
+}
+
+// hourStart truncates the timestamp expr to its hour. Scan the result into a dbTime.
+func (d dialect) hourStart(expr string) string {
+	if d == dialectSQLite {
+		return "STRFTIME('%Y-%m-%d %H:00:00', " + expr + ")"
+	}
+	return "TIMESTAMP(DATE_FORMAT(" + expr + ", '%Y-%m-%d %H:00:00'))"
+}
+
+// sqliteTimeLayout is how SQLite stores timestamps (CURRENT_TIMESTAMP, in UTC). Fractional seconds are
+// kept so that comparisons with a stored second are the same as on MariaDB.
+const sqliteTimeLayout = "2006-01-02 15:04:05.999999999"
+
+// timeArg converts t for comparison with a stored timestamp. SQLite compares timestamps as text,
+// so t is written in UTC in the same layout; the MySQL driver converts t itself.
+func (d dialect) timeArg(t time.Time) any {
+	if d == dialectSQLite {
+		return t.UTC().Format(sqliteTimeLayout)
+	}
+	return t
+}
+
+// dbTime scans a timestamp computed in SQL. SQLite returns it as text, since only columns declared as
+// timestamps are converted to time.Time by its driver.
+type dbTime struct {
+	time.Time
+}
+
+func (t *dbTime) Scan(src any) error {
+	switch v := src.(type) {
+	case time.Time:
+		t.Time = v
+		return nil
+	case []byte:
+		return t.parse(string(v))
+	case string:
+		return t.parse(v)
+	default:
+		return fmt.Errorf("cannot scan %T into a time", src)
+	}
+}
+
+func (t *dbTime) parse(s string) error {
+	for _, layout := range []string{sqliteTimeLayout, time.DateOnly} {
+		if parsed, err := time.Parse(layout, s); err == nil {
+			t.Time = parsed
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot parse %q as a time", s)
+}
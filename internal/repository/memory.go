@@ -0,0 +1,523 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	openapi_types "github.com/oapi-codegen/runtime/types"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/domain"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/openapi/models"
+)
+
+// Memory implements the same read and write paths as Repository (everything handler.Repository needs)
+// on in-process maps, for tests and local development without MariaDB. Nothing is persisted.
+//
+// It keeps the rows the SQL version keeps — every save, the achievements each save recorded first and
+// the latest-save rows with their achievements — and derives the summaries from them on read.
+// There is no moderation and no legacy game_data, so nothing is excluded from the statistics and
+// the v1 rankings are always empty.
+type Memory struct {
+	// now returns the current time; timestamps are kept in UTC with the second precision of the SQL columns.
+	now func() time.Time
+
+	mu     sync.RWMutex
+	nextID int64
+	// saves holds every save in insertion (id) order, as v2_save_data and its child tables.
+	saves []*domain.SaveData
+	// unlockedIn holds, per save id, the achievements it recorded first (v2_save_data_achievements).
+	unlockedIn map[int64][]string
+	// latest and achievements are v3_user_latest_save_data and v3_user_latest_save_data_achievements.
+	latest       map[string]*memoryLatestSave
+	achievements map[string]map[string]bool
+}
+
+type memoryLatestSave struct {
+	saveID     int64
+	projection domain.RankingProjection
+	createdAt  time.Time
+	updatedAt  time.Time
+}
+
+// NewMemory returns an empty in-memory repository.
+func NewMemory() *Memory {
+	return &Memory{
+		now:          time.Now,
+		unlockedIn:   make(map[int64][]string),
+		latest:       make(map[string]*memoryLatestSave),
+		achievements: make(map[string]map[string]bool),
+	}
+}
+
+func (r *Memory) timestamp() time.Time {
+	return r.now().UTC().Truncate(time.Second)
+}
+
+// GetRankings returns the v1 rankings, which are always empty (there is no game_data); sortBy is still validated.
+func (r *Memory) GetRankings(ctx context.Context, sortBy string, limit int) ([]models.GameData, error) {
+	if !validGameDataSortColumns[sortBy] {
+		return nil, fmt.Errorf("get rankings: invalid sort column: %s", sortBy)
+	}
+	return []models.GameData{}, nil
+}
+
+// GetTotalMedals returns the v1 medal total, which is always 0 (there is no game_data).
+func (r *Memory) GetTotalMedals(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+func (r *Memory) ExistsSameSave(ctx context.Context, userID string, playtime int64) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, sd := range r.saves {
+		if sd.UserId == userID && sd.Playtime == playtime {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// InsertSaveV4 stores sd as a new save and updates the user's latest-save rows, like Repository.InsertSaveV4.
+func (r *Memory) InsertSaveV4(ctx context.Context, sd *domain.SaveData) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.timestamp()
+	r.nextID++
+	saveID := r.nextID
+
+	have := r.achievements[sd.UserId]
+	if have == nil {
+		have = make(map[string]bool)
+		r.achievements[sd.UserId] = have
+	}
+	var unlocked []string
+	for _, id := range sd.LAchieve {
+		if !have[id] {
+			have[id] = true
+			unlocked = append(unlocked, id)
+		}
+	}
+	if len(unlocked) > 0 {
+		r.unlockedIn[saveID] = unlocked
+	}
+
+	stored := cloneSave(sd)
+	stored.ID = saveID
+	stored.CreatedAt, stored.UpdatedAt = now, now
+	stored.LAchieve, stored.UnlockedAchievements = nil, nil
+	r.saves = append(r.saves, stored)
+
+	row := r.latest[sd.UserId]
+	if row == nil {
+		row = &memoryLatestSave{createdAt: now}
+		r.latest[sd.UserId] = row
+	}
+	row.saveID = saveID
+	row.projection = domain.ProjectRanking(sd)
+	row.updatedAt = now
+
+	sd.ID = saveID
+	sd.UnlockedAchievements = unlocked
+	return nil
+}
+
+// GetLatestSave returns the user's most recently stored save with every achievement they unlocked,
+// or sql.ErrNoRows if they have none.
+func (r *Memory) GetLatestSave(ctx context.Context, userID string) (*domain.SaveData, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var newest *domain.SaveData
+	for _, sd := range r.saves {
+		if sd.UserId == userID && (newest == nil || !sd.UpdatedAt.Before(newest.UpdatedAt)) {
+			newest = sd
+		}
+	}
+	if newest == nil {
+		return nil, sql.ErrNoRows
+	}
+	sd := cloneSave(newest)
+	sd.LAchieve = slices.Sorted(maps.Keys(r.achievements[userID]))
+	return sd, nil
+}
+
+// GetSaveHistory returns up to limit saves of the user, newest first, stored before before if set.
+func (r *Memory) GetSaveHistory(ctx context.Context, userID string, limit int, before *time.Time) ([]models.SaveHistoryEntry, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var matched []*domain.SaveData
+	for _, sd := range r.saves {
+		if sd.UserId != userID || (before != nil && !sd.UpdatedAt.Before(*before)) {
+			continue
+		}
+		matched = append(matched, sd)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if !matched[i].UpdatedAt.Equal(matched[j].UpdatedAt) {
+			return matched[i].UpdatedAt.After(matched[j].UpdatedAt)
+		}
+		return matched[i].ID > matched[j].ID
+	})
+
+	hasMore := len(matched) > limit
+	if hasMore {
+		matched = matched[:limit]
+	}
+	entries := make([]models.SaveHistoryEntry, 0, len(matched))
+	for _, sd := range matched {
+		entries = append(entries, models.SaveHistoryEntry{
+			SaveId:         intPtr(int(sd.ID)),
+			Version:        intPtr(sd.Version),
+			Playtime:       intPtr(int(sd.Playtime)),
+			CreditAll:      intPtr(int(sd.CreditAll)),
+			MedalGet:       intPtr(int(sd.MedalGet)),
+			BallGet:        intPtr(int(sd.BallGet)),
+			JackTotalmaxV2: intPtr(int(sd.JackTotalMaxV2)),
+			UltTotalmaxV2:  intPtr(int(sd.UltimateTotalMaxV2)),
+			CpmMax:         float64Ptr(sd.CpMMax),
+			BlackboxTotal:  intPtr(int(sd.BlackBoxTotal)),
+			SpUse:          intPtr(int(sd.SpUse)),
+			CreatedAt:      timePtr(sd.CreatedAt),
+			UpdatedAt:      timePtr(sd.UpdatedAt),
+		})
+	}
+	return entries, hasMore, nil
+}
+
+// GetAchievementUnlockHistory returns up to limit first unlocks of the user, oldest first, and their total number.
+func (r *Memory) GetAchievementUnlockHistory(ctx context.Context, userID string, limit int) ([]models.AchievementUnlockEntry, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var entries []models.AchievementUnlockEntry
+	for _, sd := range r.saves {
+		if sd.UserId != userID {
+			continue
+		}
+		for _, id := range r.unlockedIn[sd.ID] {
+			entries = append(entries, models.AchievementUnlockEntry{
+				AchievementId: stringPtr(id),
+				UnlockedAt:    timePtr(sd.UpdatedAt),
+				Playtime:      intPtr(int(sd.Playtime)),
+				SaveId:        intPtr(int(sd.ID)),
+			})
+		}
+	}
+	total := len(entries)
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].UnlockedAt.Before(*entries[j].UnlockedAt) })
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	if entries == nil {
+		entries = []models.AchievementUnlockEntry{}
+	}
+	return entries, total, nil
+}
+
+// GetStatisticsV4 returns every ranking of domain.RankedMetrics and the medal total of the visible latest saves.
+func (r *Memory) GetStatisticsV4(ctx context.Context) (*models.StatisticsV4, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stats := &models.StatisticsV4{}
+	for _, m := range domain.RankedMetrics {
+		ptr := domain.RankingEntries(stats, m.Name)
+		if ptr == nil {
+			return nil, fmt.Errorf("ranked metric %s has no field in StatisticsV4", m.Name)
+		}
+		list, err := r.ranking(m, statisticsRankingLimit)
+		if err != nil {
+			return nil, err
+		}
+		*ptr = &list
+	}
+
+	var total int64
+	for _, row := range r.latest {
+		if !row.projection.Hidden() {
+			total += row.projection.CreditAll()
+		}
+	}
+	totalMedals := int(total)
+	stats.TotalMedals = &totalMedals
+	return stats, nil
+}
+
+// GetRankingV4 returns the top limit users (at most 1000) of the ranked metric named metric.
+func (r *Memory) GetRankingV4(ctx context.Context, metric string, limit int) ([]models.RankingEntry, error) {
+	m, ok := domain.LookupRankedMetric(metric)
+	if !ok {
+		return nil, fmt.Errorf("unknown ranked metric %q", metric)
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.ranking(m, min(limit, statisticsRankingLimit))
+}
+
+// ranking orders the visible latest saves like rankingQuery: by the metric, then its tie-breaker.
+// Users still tied are ordered by user_id so the result is deterministic.
+func (r *Memory) ranking(m domain.RankedMetric, limit int) ([]models.RankingEntry, error) {
+	tie, err := memoryTieBreaker(m.TieBreaker)
+	if err != nil {
+		return nil, fmt.Errorf("ranked metric %s: %w", m.Name, err)
+	}
+
+	type candidate struct {
+		userID string
+		value  float64
+		row    *memoryLatestSave
+	}
+	candidates := make([]candidate, 0, len(r.latest))
+	for userID, row := range r.latest {
+		if row.projection.Hidden() {
+			continue
+		}
+		candidates = append(candidates, candidate{userID: userID, value: m.Value(row.projection), row: row})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		switch {
+		case m.Better(a.value, b.value):
+			return true
+		case m.Better(b.value, a.value):
+			return false
+		}
+		if c := tie(a.row, b.row); c != 0 {
+			return c < 0
+		}
+		return a.userID < b.userID
+	})
+
+	list := make([]models.RankingEntry, 0, min(limit, len(candidates)))
+	for _, c := range candidates[:min(limit, len(candidates))] {
+		// rounded like CAST(... AS SIGNED)
+		value := int64(math.Round(c.value))
+		list = append(list, models.RankingEntry{UserId: stringPtr(c.userID), Value: &value, CreatedAt: timePtr(c.row.updatedAt)})
+	}
+	return list, nil
+}
+
+// memoryTieBreaker interprets a RankedMetric.TieBreaker ("<column> [ASC|DESC]") for Memory.
+// Only the timestamps of the latest-save row are supported.
+func memoryTieBreaker(term string) (func(a, b *memoryLatestSave) int, error) {
+	col, dir, _ := strings.Cut(strings.TrimSpace(term), " ")
+	var key func(*memoryLatestSave) time.Time
+	switch col {
+	case "updated_at":
+		key = func(row *memoryLatestSave) time.Time { return row.updatedAt }
+	case "created_at":
+		key = func(row *memoryLatestSave) time.Time { return row.createdAt }
+	default:
+		return nil, fmt.Errorf("unsupported tie-breaker %q", term)
+	}
+	sign := 1
+	switch strings.ToUpper(strings.TrimSpace(dir)) {
+	case "", "ASC":
+	case "DESC":
+		sign = -1
+	default:
+		return nil, fmt.Errorf("unsupported tie-breaker %q", term)
+	}
+	return func(a, b *memoryLatestSave) int { return sign * key(a).Compare(key(b)) }, nil
+}
+
+// GetAchievementRates returns, per achievement, how many users unlocked it among the users with any achievement.
+func (r *Memory) GetAchievementRates(ctx context.Context) (*models.AchievementRates, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	counts := make(map[string]int)
+	totalUsers := 0
+	for _, have := range r.achievements {
+		if len(have) == 0 {
+			continue
+		}
+		totalUsers++
+		for id := range have {
+			counts[id]++
+		}
+	}
+
+	achievementRates := make(map[string]struct {
+		Count *int     `json:"count,omitempty"`
+		Rate  *float32 `json:"rate,omitempty"`
+	}, len(counts))
+	for id, count := range counts {
+		rate := float32(count) / float32(totalUsers)
+		achievementRates[id] = struct {
+			Count *int     `json:"count,omitempty"`
+			Rate  *float32 `json:"rate,omitempty"`
+		}{Count: intPtr(count), Rate: &rate}
+	}
+	return &models.AchievementRates{
+		TotalUsers:       &totalUsers,
+		AchievementRates: &achievementRates,
+	}, nil
+}
+
+// GetCreditAllDistribution returns the number of visible users per credit_all digit count.
+func (r *Memory) GetCreditAllDistribution(ctx context.Context) (*models.CreditAllDistributionResponse, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	countsByDigits := make(map[int]int64)
+	var totalUsers int64
+	maxDigits := 3
+	for _, row := range r.latest {
+		if row.projection.Hidden() {
+			continue
+		}
+		digits := creditDigits(row.projection.CreditAll())
+		countsByDigits[digits]++
+		totalUsers++
+		maxDigits = max(maxDigits, digits)
+	}
+
+	distribution := make([]models.CreditAllDistributionBucket, 0, maxDigits-2)
+	for digits := 3; digits <= maxDigits; digits++ {
+		rangeMin, rangeMax := creditRangeForDigits(digits)
+		distribution = append(distribution, models.CreditAllDistributionBucket{
+			RangeMin: rangeMin,
+			RangeMax: rangeMax,
+			Users:    countsByDigits[digits],
+		})
+	}
+	return &models.CreditAllDistributionResponse{
+		Users:        totalUsers,
+		Distribution: distribution,
+	}, nil
+}
+
+// GetMedalTimeseries aggregates, per UTC day of the last days days, the latest save of each user that day.
+// Like the SQL version, saves sharing a user's latest timestamp of the day all count.
+func (r *Memory) GetMedalTimeseries(ctx context.Context, days int) (*models.MedalTimeseriesResponse, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	today := r.timestamp().Truncate(24 * time.Hour)
+	from := today.AddDate(0, 0, -days)
+
+	type userDay struct {
+		userID string
+		day    time.Time
+	}
+	latest := make(map[userDay]time.Time)
+	for _, sd := range r.saves {
+		if sd.UpdatedAt.Before(from) {
+			continue
+		}
+		k := userDay{sd.UserId, sd.UpdatedAt.Truncate(24 * time.Hour)}
+		if sd.UpdatedAt.After(latest[k]) {
+			latest[k] = sd.UpdatedAt
+		}
+	}
+
+	type bucket struct {
+		total, users, playtime int64
+	}
+	byDay := make(map[time.Time]*bucket)
+	for _, sd := range r.saves {
+		k := userDay{sd.UserId, sd.UpdatedAt.Truncate(24 * time.Hour)}
+		if t, ok := latest[k]; !ok || !sd.UpdatedAt.Equal(t) {
+			continue
+		}
+		b := byDay[k.day]
+		if b == nil {
+			b = &bucket{}
+			byDay[k.day] = b
+		}
+		b.total += sd.CreditAll
+		b.users++
+		b.playtime += sd.Playtime
+	}
+
+	buckets := make([]models.MedalTimeseriesBucket, 0, len(byDay))
+	for _, day := range slices.SortedFunc(maps.Keys(byDay), time.Time.Compare) {
+		b := byDay[day]
+		date := openapi_types.Date{Time: day}
+		buckets = append(buckets, models.MedalTimeseriesBucket{
+			Date:        &date,
+			TotalMedals: intPtr(int(b.total)),
+			ActiveUsers: intPtr(int(b.users)),
+			AvgPlaytime: intPtr(int(math.Round(float64(b.playtime) / float64(b.users)))),
+		})
+	}
+	return &models.MedalTimeseriesResponse{Buckets: &buckets}, nil
+}
+
+// GetSaveActivity counts the saves and saving users per UTC hour over the last hours hours.
+func (r *Memory) GetSaveActivity(ctx context.Context, hours int) (*models.SaveActivityResponse, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	from := r.timestamp().Add(-time.Duration(hours) * time.Hour)
+	saves := make(map[time.Time]int)
+	users := make(map[time.Time]map[string]bool)
+	for _, sd := range r.saves {
+		if sd.UpdatedAt.Before(from) {
+			continue
+		}
+		hour := sd.UpdatedAt.Truncate(time.Hour)
+		saves[hour]++
+		if users[hour] == nil {
+			users[hour] = make(map[string]bool)
+		}
+		users[hour][sd.UserId] = true
+	}
+
+	buckets := make([]models.SaveActivityBucket, 0, len(saves))
+	for _, hour := range slices.SortedFunc(maps.Keys(saves), time.Time.Compare) {
+		buckets = append(buckets, models.SaveActivityBucket{
+			HourStart:   timePtr(hour),
+			Saves:       intPtr(saves[hour]),
+			UniqueUsers: intPtr(len(users[hour])),
+		})
+	}
+	return &models.SaveActivityResponse{Buckets: &buckets}, nil
+}
+
+// cloneSave copies sd deeply, as the child tables would store and load it: absent maps and lists come back empty,
+// and the per-index lists keep at most 100 entries.
+func cloneSave(sd *domain.SaveData) *domain.SaveData {
+	c := *sd
+	c.DCMedalGet = cloneMap(sd.DCMedalGet)
+	c.DCBallGet = cloneMap(sd.DCBallGet)
+	c.DCBallChain = cloneMap(sd.DCBallChain)
+	c.DCPalettaBallGet = cloneMap(sd.DCPalettaBallGet)
+	c.DCPalettaBallJackpot = cloneMap(sd.DCPalettaBallJackpot)
+	c.DCBlackBoxShopUsed = cloneMap(sd.DCBlackBoxShopUsed)
+	c.DCFerrettaLotteryItem = cloneMap(sd.DCFerrettaLotteryItem)
+	c.DCFerrettaLotteryItemUsed = cloneMap(sd.DCFerrettaLotteryItemUsed)
+	c.LAchieve = slices.Clone(sd.LAchieve)
+	c.LPerkLevels = cloneList(sd.LPerkLevels)
+	c.LPerkUsedCredits = cloneList(sd.LPerkUsedCredits)
+	c.LTotemLevels = cloneList(sd.LTotemLevels)
+	c.LTotemUsedCredits = cloneList(sd.LTotemUsedCredits)
+	c.LTotemPlacements = cloneList(sd.LTotemPlacements)
+	c.UnlockedAchievements = slices.Clone(sd.UnlockedAchievements)
+	c.ExtraFields = maps.Clone(sd.ExtraFields)
+	return &c
+}
+
+func cloneMap[V any](m map[string]V) map[string]V {
+	c := make(map[string]V, len(m))
+	maps.Copy(c, m)
+	return c
+}
+
+func cloneList[V any](list []V) []V {
+	return append(make([]V, 0, len(list)), list[:min(len(list), 100)]...)
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/handler"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/repository/repotest"
+)
+
+var _ handler.Repository = (*Memory)(nil)
+
+func TestMemoryContract(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) handler.Repository { return NewMemory() })
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/domain"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/pkg/tracing"
+)
+
+// activeModeration is the condition for a user_moderation row m that is still in effect.
+// expires_at is stored in UTC (see UpsertModeration), so it is compared with the current UTC time.
+func (d dialect) activeModeration() string {
+	return `(m.expires_at IS NULL OR m.expires_at > ` + d.utcNow() + `)`
+}
+
+// notModerated narrows a query to the rows whose userIDColumn is a user without an active moderation.
+func (d dialect) notModerated(userIDColumn string) string {
+	return `
+  AND NOT EXISTS (
+    SELECT 1 FROM user_moderation m
+    WHERE m.user_id = ` + userIDColumn + ` AND ` + d.activeModeration() + `
+  )`
+}
+
+const moderationColumns = `user_id, banned, hidden_from_rankings, reason, expires_at, moderator, created_at, updated_at`
+
+// UpsertModeration creates or replaces the moderation of m.UserID and returns the stored row.
+func (r *Repository) UpsertModeration(ctx context.Context, m domain.Moderation) (_ *domain.Moderation, err error) {
+	ctx, span := tracing.Start(ctx, "repository.UpsertModeration")
+	defer func() { tracing.End(span, err) }()
+
+	d := r.dialect()
+	var expiresAt any
+	if m.ExpiresAt != nil {
+		expiresAt = d.timeArg(m.ExpiresAt.UTC())
+	}
+	if _, err := r.db.ExecContext(ctx, `
+INSERT INTO user_moderation (user_id, banned, hidden_from_rankings, reason, expires_at, moderator)
+VALUES (?, ?, ?, ?, ?, ?)
+`+d.upsert("user_id")+`
+  banned = `+d.inserted("banned")+`,
+  hidden_from_rankings = `+d.inserted("hidden_from_rankings")+`,
+  reason = `+d.inserted("reason")+`,
+  expires_at = `+d.inserted("expires_at")+`,
+  moderator = `+d.inserted("moderator"),
+		m.UserID, m.Banned, m.HiddenFromRankings, m.Reason, expiresAt, m.Moderator); err != nil {
+		return nil, err
+	}
+	return r.GetModeration(ctx, m.UserID)
+}
+
+// GetModeration returns the moderation of the user, expired or not, or sql.ErrNoRows.
+func (r *Repository) GetModeration(ctx context.Context, userID string) (_ *domain.Moderation, err error) {
+	ctx, span := tracing.Start(ctx, "repository.GetModeration")
+	defer func() { tracing.End(span, err) }()
+
+	var m domain.Moderation
+	if err := r.db.GetContext(ctx, &m, `SELECT `+moderationColumns+` FROM user_moderation WHERE user_id = ?`, userID); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// DeleteModeration lifts the moderation of the user. It returns sql.ErrNoRows if there was none.
+func (r *Repository) DeleteModeration(ctx context.Context, userID string) (err error) {
+	ctx, span := tracing.Start(ctx, "repository.DeleteModeration")
+	defer func() { tracing.End(span, err) }()
+
+	res, err := r.db.ExecContext(ctx, `DELETE FROM user_moderation WHERE user_id = ?`, userID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ListModerations returns moderations, most recently changed first. Expired ones are included only on request.
+func (r *Repository) ListModerations(ctx context.Context, includeExpired bool, limit int) (_ []domain.Moderation, err error) {
+	ctx, span := tracing.Start(ctx, "repository.ListModerations")
+	defer func() { tracing.End(span, err) }()
+
+	query := `SELECT ` + moderationColumns + ` FROM user_moderation m`
+	if !includeExpired {
+		query += ` WHERE ` + r.dialect().activeModeration()
+	}
+	query += ` ORDER BY updated_at DESC, user_id LIMIT ?`
+
+	moderations := []domain.Moderation{}
+	if err := r.db.SelectContext(ctx, &moderations, query, limit); err != nil {
+		return nil, err
+	}
+	return moderations, nil
+}
+
+// IsUserBanned reports whether the user has an active ban.
+func (r *Repository) IsUserBanned(ctx context.Context, userID string) (_ bool, err error) {
+	ctx, span := tracing.Start(ctx, "repository.IsUserBanned")
+	defer func() { tracing.End(span, err) }()
+
+	var banned bool
+	err = r.db.GetContext(ctx, &banned, `
+SELECT EXISTS(
+  SELECT 1 FROM user_moderation m
+  WHERE m.user_id = ? AND m.banned AND `+r.dialect().activeModeration()+`
+)`, userID)
+	return banned, err
+}
+
+// The v4_summary_* tables do not know about moderation (an expiry would otherwise need a write to take effect),
+// so readers subtract what the currently moderated users contribute.
+
+// moderatedCredits returns credit_all of every moderated user who would otherwise count as visible.
+func (r *Repository) moderatedCredits(ctx context.Context) ([]int64, error) {
+	credits := []int64{}
+	err := r.db.SelectContext(ctx, &credits, `
+SELECT COALESCE(l.credit_all, 0)
+FROM v3_user_latest_save_data l
+JOIN user_moderation m ON m.user_id = l.user_id
+WHERE l.hide_record = 0 AND `+r.dialect().activeModeration())
+	return credits, err
+}
+
+// moderatedAchievements returns how many moderated users have achievements, and per achievement how many hold it.
+func (r *Repository) moderatedAchievements(ctx context.Context) (int64, map[string]int64, error) {
+	active := r.dialect().activeModeration()
+	var users int64
+	if err := r.db.GetContext(ctx, &users, `
+SELECT COUNT(DISTINCT a.user_id)
+FROM v3_user_latest_save_data_achievements a
+JOIN user_moderation m ON m.user_id = a.user_id
+WHERE `+active); err != nil {
+		return 0, nil, err
+	}
+	if users == 0 {
+		return 0, nil, nil
+	}
+
+	var rows []summaryRow
+	if err := r.db.SelectContext(ctx, &rows, `
+SELECT a.achievement_id AS k, COUNT(*) AS v
+FROM v3_user_latest_save_data_achievements a
+JOIN user_moderation m ON m.user_id = a.user_id
+WHERE `+active+`
+GROUP BY a.achievement_id`); err != nil {
+		return 0, nil, err
+	}
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Key] = row.Value
+	}
+	return users, counts, nil
+}
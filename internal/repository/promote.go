@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/domain"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/pkg/tracing"
+)
+
+// PromoteOptions controls a promote-save-field run.
+type PromoteOptions struct {
+	// Apply writes the columns; otherwise the run only counts the saves it would change.
+	Apply bool
+	// BatchSize is the number of saves read and updated per transaction.
+	BatchSize int
+}
+
+var columnName = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// unpromotableColumns are the columns of v2_save_data that are not save values.
+var unpromotableColumns = []string{"id", "user_id", "created_at", "updated_at", "extra_fields"}
+
+// PromoteSaveField moves the extra field key of every stored save into column of v2_save_data and removes it from
+// the extra fields, one batch of saves at a time. updated_at is kept, so the latest save of each user stays the same.
+// The column must exist already; v3_user_latest_* and the v4 summaries are untouched.
+func (r *Repository) PromoteSaveField(ctx context.Context, key, column string, opts PromoteOptions) (_ domain.SaveFieldPromotion, err error) {
+	ctx, span := tracing.Start(ctx, "repository.PromoteSaveField")
+	defer func() { tracing.End(span, err) }()
+
+	report := domain.SaveFieldPromotion{DryRun: !opts.Apply, Key: key, Column: column}
+	if key == "" {
+		return report, fmt.Errorf("empty key")
+	}
+	if !columnName.MatchString(column) || slices.Contains(unpromotableColumns, column) {
+		return report, fmt.Errorf("invalid column %q", column)
+	}
+	check, err := r.db.QueryContext(ctx, `SELECT `+column+` FROM v2_save_data WHERE 1 = 0`)
+	if err != nil {
+		return report, fmt.Errorf("column %s of v2_save_data: %w", column, err)
+	}
+	if err := check.Close(); err != nil {
+		return report, err
+	}
+	batchSize := max(opts.BatchSize, 1)
+
+	type saveRow struct {
+		ID     int64                  `db:"id"`
+		Fields domain.SaveExtraFields `db:"extra_fields"`
+	}
+	// LIKE only narrows the scan (_ in a key matches any character); the decoded fields decide
+	pattern := `%"` + key + `"%`
+	var afterID int64
+	for {
+		var rows []saveRow
+		if err := r.db.SelectContext(ctx, &rows, `
+SELECT id, extra_fields
+FROM v2_save_data
+WHERE id > ? AND extra_fields LIKE ?
+ORDER BY id
+LIMIT ?`, afterID, pattern, batchSize); err != nil {
+			return report, err
+		}
+
+		var updates []savePromotion
+		for _, row := range rows {
+			raw, ok := row.Fields[key]
+			if !ok {
+				continue
+			}
+			value, set, err := domain.PromotedValue(raw)
+			if err != nil {
+				return report, fmt.Errorf("save %d: %w", row.ID, err)
+			}
+			if set {
+				report.Promoted++
+			} else {
+				report.Dropped++
+			}
+			rest := maps.Clone(row.Fields)
+			delete(rest, key)
+			updates = append(updates, savePromotion{id: row.ID, value: value, set: set, rest: rest})
+		}
+		if opts.Apply && len(updates) > 0 {
+			if err := r.applyPromotions(ctx, column, updates); err != nil {
+				return report, err
+			}
+		}
+
+		if len(rows) < batchSize {
+			return report, nil
+		}
+		afterID = rows[len(rows)-1].ID
+	}
+}
+
+// savePromotion is the update of one save: value goes into the column unless set is false (a null value),
+// and rest are the extra fields left.
+type savePromotion struct {
+	id    int64
+	value any
+	set   bool
+	rest  domain.SaveExtraFields
+}
+
+func (r *Repository) applyPromotions(ctx context.Context, column string, updates []savePromotion) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	for _, u := range updates {
+		// updated_at = updated_at stops ON UPDATE CURRENT_TIMESTAMP (and the SQLite trigger ignores extra_fields updates)
+		if u.set {
+			_, err = tx.ExecContext(ctx, `UPDATE v2_save_data SET `+column+` = ?, extra_fields = ?, updated_at = updated_at WHERE id = ?`, u.value, u.rest, u.id)
+		} else {
+			_, err = tx.ExecContext(ctx, `UPDATE v2_save_data SET extra_fields = ?, updated_at = updated_at WHERE id = ?`, u.rest, u.id)
+		}
+		if err != nil {
+			return fmt.Errorf("save %d: %w", u.id, err)
+		}
+	}
+	return tx.Commit()
+}
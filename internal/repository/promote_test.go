@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/domain"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/repository/repotest"
+)
+
+func TestSQLitePromoteSaveField(t *testing.T) {
+	ctx := context.Background()
+	repo := newSQLite(t)
+
+	// get_medaltower stands in for a key the server learnt after these saves were stored
+	extras := []domain.SaveExtraFields{
+		{"zz_tower": json.RawMessage(`"12"`), "zz_other": json.RawMessage(`1`)},
+		{"zz_tower": json.RawMessage(`null`)},
+		nil,
+		{"zz_tower": json.RawMessage(`30`)},
+	}
+	var ids []int64
+	for i, extra := range extras {
+		sd := repotest.NewSave("alice", int64(100+i), 5, nil)
+		sd.ExtraFields = extra
+		if err := repo.InsertSaveV4(ctx, sd); err != nil {
+			t.Fatalf("insert: %v", err)
+		}
+		ids = append(ids, sd.ID)
+	}
+	// the oldest save is the latest one by updated_at; promoting must not change that
+	if _, err := repo.db.ExecContext(ctx, `UPDATE v2_save_data SET updated_at = '2999-01-01 00:00:00' WHERE id = ?`, ids[0]); err != nil {
+		t.Fatalf("age saves: %v", err)
+	}
+
+	for _, column := range []string{"no_such_column", "user_id", "get_medaltower; DROP TABLE v2_save_data"} {
+		if _, err := repo.PromoteSaveField(ctx, "zz_tower", column, PromoteOptions{Apply: true}); err == nil {
+			t.Fatalf("column %q must be rejected", column)
+		}
+	}
+
+	report, err := repo.PromoteSaveField(ctx, "zz_tower", "get_medaltower", PromoteOptions{BatchSize: 1})
+	if err != nil || !report.DryRun || report.Promoted != 2 || report.Dropped != 1 {
+		t.Fatalf("dry run: %+v, %v", report, err)
+	}
+	latest, err := repo.GetLatestSave(ctx, "alice")
+	if err != nil || latest.GetMedalTower != 0 || len(latest.ExtraFields) != 2 {
+		t.Fatalf("the dry run changed the save: %+v, %v", latest, err)
+	}
+
+	report, err = repo.PromoteSaveField(ctx, "zz_tower", "get_medaltower", PromoteOptions{Apply: true, BatchSize: 1})
+	if err != nil || report.DryRun || report.Promoted != 2 || report.Dropped != 1 {
+		t.Fatalf("apply: %+v, %v", report, err)
+	}
+	latest, err = repo.GetLatestSave(ctx, "alice")
+	if err != nil || latest.ID != ids[0] || latest.UpdatedAt.Year() != 2999 {
+		t.Fatalf("the latest save changed: %+v, %v", latest, err)
+	}
+	if latest.GetMedalTower != 12 || len(latest.ExtraFields) != 1 || string(latest.ExtraFields["zz_other"]) != `1` {
+		t.Fatalf("promoted save: tower %d, extra %s", latest.GetMedalTower, latest.ExtraFields)
+	}
+	for i, want := range []int{12, 0, 0, 30} {
+		sd, err := repo.GetSave(ctx, "alice", ids[i])
+		if err != nil || sd.GetMedalTower != want || sd.ExtraFields["zz_tower"] != nil {
+			t.Fatalf("save %d: tower %d, extra %s, %v", i, sd.GetMedalTower, sd.ExtraFields, err)
+		}
+	}
+
+	if report, err := repo.PromoteSaveField(ctx, "zz_tower", "get_medaltower", PromoteOptions{Apply: true}); err != nil || report.Promoted+report.Dropped != 0 {
+		t.Fatalf("second run: %+v, %v", report, err)
+	}
+}
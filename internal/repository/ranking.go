@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/domain"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/pkg/tracing"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/openapi/models"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// statisticsRankingLimit is the length of every ranking in GetStatisticsV4, and the most GetRankingV4 returns.
+const statisticsRankingLimit = 1000
+
+// rankingQuery selects the top users of m with (user_id, value, created_at), created_at being when the user
+// stored the save the value comes from. Hidden records and moderated users are left out.
+func rankingQuery(d dialect, m domain.RankedMetric) string {
+	direction := "ASC"
+	if m.Descending {
+		direction = "DESC"
+	}
+	return `
+SELECT
+  user_id,
+  ` + d.roundToInteger(m.Column) + ` AS value,
+  updated_at AS created_at
+FROM v3_user_latest_save_data
+WHERE hide_record = 0` + d.notModerated("v3_user_latest_save_data.user_id") + `
+ORDER BY ` + m.Column + ` ` + direction + `, ` + m.TieBreaker + `
+LIMIT ?`
+}
+
+func (r *Repository) queryRanking(ctx context.Context, spanName string, m domain.RankedMetric, limit int) (_ []models.RankingEntry, err error) {
+	ctx, span := tracing.Start(ctx, spanName, attribute.String("ranking", m.Name))
+	defer func() { tracing.End(span, err) }()
+
+	rows, err := r.db.QueryxContext(ctx, rankingQuery(r.dialect(), m), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	// 事前に容量を確保してメモリ効率を改善
+	list := make([]models.RankingEntry, 0, limit)
+	for rows.Next() {
+		var e models.RankingEntry
+		if err := rows.Scan(&e.UserId, &e.Value, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+	}
+	return list, rows.Err()
+}
+
+// GetRankingV4 returns the top limit users (at most 1000) of the ranked metric named metric,
+// ordered as in GetStatisticsV4. An unknown metric is an error; callers check with domain.LookupRankedMetric.
+func (r *Repository) GetRankingV4(ctx context.Context, metric string, limit int) ([]models.RankingEntry, error) {
+	m, ok := domain.LookupRankedMetric(metric)
+	if !ok {
+		return nil, fmt.Errorf("unknown ranked metric %q", metric)
+	}
+	return r.queryRanking(ctx, "repository.GetRankingV4", m, min(limit, statisticsRankingLimit))
+}
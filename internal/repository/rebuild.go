@@ -0,0 +1,334 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/domain"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/pkg/tracing"
+)
+
+// rebuildAttempts bounds how often a rebuild restarts because the user stored a save meanwhile.
+const rebuildAttempts = 3
+
+// RebuildLatestSave recomputes the user's rows of v3_user_latest_save_data and v3_user_latest_save_data_achievements
+// from their save history, as InsertSaveV4 and RollbackSave leave them, and moves the v4 summaries by the difference.
+// A user without saves loses their latest-save rows; sql.ErrNoRows means there was nothing at all.
+func (r *Repository) RebuildLatestSave(ctx context.Context, userID string) (err error) {
+	ctx, span := tracing.Start(ctx, "repository.RebuildLatestSave")
+	defer func() { tracing.End(span, err) }()
+
+	for range rebuildAttempts {
+		var newest sql.NullInt64
+		if err := r.db.GetContext(ctx, &newest, `SELECT MAX(id) FROM v2_save_data WHERE user_id = ?`, userID); err != nil {
+			return err
+		}
+		var sd *domain.SaveData
+		if newest.Valid {
+			if sd, err = r.GetSave(ctx, userID, newest.Int64); err != nil {
+				return err
+			}
+		}
+
+		done, err := r.rebuildLatestSaveTx(ctx, userID, newest.Int64, sd)
+		if err != nil || done {
+			return err
+		}
+	}
+	return fmt.Errorf("user %s kept saving during the rebuild", userID)
+}
+
+// rebuildLatestSaveTx writes sd (nil if the user has no saves) unless a newer save than saveID arrived since it was read.
+func (r *Repository) rebuildLatestSaveTx(ctx context.Context, userID string, saveID int64, sd *domain.SaveData) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	// 最新行をロックしてから確かめるので、以降に保存されたセーブとは食い違わない
+	before, err := loadSummaryContribution(ctx, tx, userID)
+	if err != nil {
+		return false, err
+	}
+	var newest sql.NullInt64
+	if err := tx.GetContext(ctx, &newest, `SELECT MAX(id) FROM v2_save_data WHERE user_id = ?`, userID); err != nil {
+		return false, err
+	}
+	if newest.Int64 != saveID {
+		return false, nil
+	}
+
+	var current []string
+	if err := tx.SelectContext(ctx, &current, `
+SELECT achievement_id
+FROM v3_user_latest_save_data_achievements
+WHERE user_id = ?`+dialectOf(tx).forUpdate(), userID); err != nil {
+		return false, err
+	}
+
+	if sd == nil {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM v3_user_latest_save_data_achievements WHERE user_id = ?`, userID); err != nil {
+			return false, err
+		}
+		res, err := tx.ExecContext(ctx, `DELETE FROM v3_user_latest_save_data WHERE user_id = ?`, userID)
+		if err != nil {
+			return false, err
+		}
+		if n, err := res.RowsAffected(); err != nil {
+			return false, err
+		} else if n == 0 && len(current) == 0 {
+			return false, sql.ErrNoRows
+		}
+		if err := applySummaryChange(ctx, tx, before, summaryContribution{}, nil, current); err != nil {
+			return false, err
+		}
+		return true, tx.Commit()
+	}
+
+	unlocked, revoked := diffAchievements(current, sd.LAchieve)
+	if err := replaceLatestAchievements(ctx, tx, userID, unlocked, revoked); err != nil {
+		return false, err
+	}
+	if err := upsertLatestSave(ctx, tx, sd, saveID); err != nil {
+		return false, err
+	}
+	after := summaryContribution{
+		visible:         sd.HideRecord == 0,
+		creditAll:       sd.CreditAll,
+		hasAchievements: len(sd.LAchieve) > 0,
+	}
+	if err := applySummaryChange(ctx, tx, before, after, unlocked, revoked); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// diffAchievements returns the achievements in want but not in have, and those in have but not in want.
+func diffAchievements(have, want []string) (added, removed []string) {
+	inHave := make(map[string]bool, len(have))
+	for _, id := range have {
+		inHave[id] = true
+	}
+	inWant := make(map[string]bool, len(want))
+	for _, id := range want {
+		inWant[id] = true
+		if !inHave[id] {
+			added = append(added, id)
+		}
+	}
+	for _, id := range have {
+		if !inWant[id] {
+			removed = append(removed, id)
+		}
+	}
+	return added, removed
+}
+
+func replaceLatestAchievements(ctx context.Context, tx *sqlx.Tx, userID string, added, removed []string) error {
+	for _, id := range removed {
+		if _, err := tx.ExecContext(ctx, `
+DELETE FROM v3_user_latest_save_data_achievements
+WHERE user_id = ? AND achievement_id = ?`, userID, id); err != nil {
+			return err
+		}
+	}
+	for _, id := range added {
+		if _, err := tx.ExecContext(ctx, `
+INSERT INTO v3_user_latest_save_data_achievements (user_id, achievement_id)
+VALUES (?, ?)`, userID, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListUserIDs returns up to limit users with at least one save or a latest-save row, in user_id order after afterUserID.
+func (r *Repository) ListUserIDs(ctx context.Context, afterUserID string, limit int) (_ []string, err error) {
+	ctx, span := tracing.Start(ctx, "repository.ListUserIDs")
+	defer func() { tracing.End(span, err) }()
+
+	users := []string{}
+	if err := r.db.SelectContext(ctx, &users, `
+SELECT user_id FROM (SELECT DISTINCT user_id FROM v2_save_data WHERE user_id > ? ORDER BY user_id LIMIT ?) s
+UNION
+SELECT user_id FROM (SELECT user_id FROM v3_user_latest_save_data WHERE user_id > ? ORDER BY user_id LIMIT ?) l
+ORDER BY user_id
+LIMIT ?`, afterUserID, limit, afterUserID, limit, limit); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// VerifyLatestSave compares the user's rows of v3_user_latest_save_data and v3_user_latest_save_data_achievements
+// with what RebuildLatestSave would write, and returns every difference. It writes nothing.
+func (r *Repository) VerifyLatestSave(ctx context.Context, userID string) (_ []domain.LatestSaveMismatch, err error) {
+	ctx, span := tracing.Start(ctx, "repository.VerifyLatestSave")
+	defer func() { tracing.End(span, err) }()
+
+	// 同じスナップショットで読むので、途中で保存されても最新セーブと v3 の行は食い違わない
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var newest sql.NullInt64
+	if err := tx.GetContext(ctx, &newest, `SELECT MAX(id) FROM v2_save_data WHERE user_id = ?`, userID); err != nil {
+		return nil, err
+	}
+	stored, err := scanLatestSaveRows(ctx, tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	var storedAchievements []string
+	if err := tx.SelectContext(ctx, &storedAchievements, `
+SELECT achievement_id
+FROM v3_user_latest_save_data_achievements
+WHERE user_id = ?`, userID); err != nil {
+		return nil, err
+	}
+
+	var have, want *latestSaveRow
+	if len(stored) > 0 {
+		have = &stored[0]
+	}
+	var wantAchievements []string
+	if newest.Valid {
+		// 保存済みのセーブは書き換わらないので、スナップショットの外で読んでよい
+		sd, err := r.GetSave(ctx, userID, newest.Int64)
+		if err != nil {
+			return nil, err
+		}
+		row := newLatestSaveRow(sd, newest.Int64)
+		want, wantAchievements = &row, sd.LAchieve
+	}
+	return diffLatestSave(userID, have, storedAchievements, want, wantAchievements), nil
+}
+
+// scanLatestSaveRows reads the user's row of v3_user_latest_save_data, if there is one.
+func scanLatestSaveRows(ctx context.Context, tx *sqlx.Tx, userID string) ([]latestSaveRow, error) {
+	rows, err := tx.QueryContext(ctx, `SELECT `+latestSaveColumns+` FROM v3_user_latest_save_data WHERE user_id = ?`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stored []latestSaveRow
+	for rows.Next() {
+		var row latestSaveRow
+		projection, dest := domain.ScanRankingProjection()
+		if err := rows.Scan(append([]any{&row.UserID, &row.SaveID}, dest...)...); err != nil {
+			return nil, err
+		}
+		row.Projection = projection
+		stored = append(stored, row)
+	}
+	return stored, rows.Err()
+}
+
+// diffLatestSave lists the differences between the stored latest-save rows and the expected ones; nil means absent.
+func diffLatestSave(userID string, have *latestSaveRow, haveAchievements []string, want *latestSaveRow, wantAchievements []string) []domain.LatestSaveMismatch {
+	var found []domain.LatestSaveMismatch
+	switch {
+	case have == nil && want == nil:
+	case have == nil:
+		found = append(found, domain.LatestSaveMismatch{UserID: userID, Field: "row", Stored: "missing", Expected: fmt.Sprintf("save %d", want.SaveID)})
+	case want == nil:
+		found = append(found, domain.LatestSaveMismatch{UserID: userID, Field: "row", Stored: fmt.Sprintf("save %d", have.SaveID), Expected: "missing"})
+	default:
+		if have.SaveID != want.SaveID {
+			found = append(found, domain.LatestSaveMismatch{UserID: userID, Field: "save_id", Stored: fmt.Sprint(have.SaveID), Expected: fmt.Sprint(want.SaveID)})
+		}
+		hv, wv := have.Projection.Values(), want.Projection.Values()
+		for i, col := range domain.RankingColumns() {
+			stored, expected := fmt.Sprint(hv[i]), fmt.Sprint(wv[i])
+			if stored != expected {
+				found = append(found, domain.LatestSaveMismatch{UserID: userID, Field: col, Stored: stored, Expected: expected})
+			}
+		}
+	}
+
+	missing, extra := diffAchievements(haveAchievements, wantAchievements)
+	sort.Strings(missing)
+	sort.Strings(extra)
+	for _, id := range missing {
+		found = append(found, domain.LatestSaveMismatch{UserID: userID, Field: "achievement:" + id, Stored: "absent", Expected: "present"})
+	}
+	for _, id := range extra {
+		found = append(found, domain.LatestSaveMismatch{UserID: userID, Field: "achievement:" + id, Stored: "present", Expected: "absent"})
+	}
+	return found
+}
+
+// RebuildOptions controls a run over the latest-save rows of every user.
+type RebuildOptions struct {
+	// VerifyOnly reports the mismatches without rewriting anything.
+	VerifyOnly bool
+	// AfterUserID resumes an earlier run: only users after it in user_id order are handled.
+	AfterUserID string
+	// BatchSize is the number of users listed per batch.
+	BatchSize int
+	// OnMismatch, if set, is called with every mismatch found.
+	OnMismatch func(domain.LatestSaveMismatch)
+	// OnBatch, if set, is called after each batch with the report so far; an error stops the run.
+	OnBatch func(domain.RebuildReport) error
+}
+
+// RebuildLatestSaves verifies the latest-save rows of every user, one batch at a time, and rebuilds those
+// of users with a mismatch unless opts.VerifyOnly is set. On error the report's LastUserID is the last user
+// fully handled, so the run can be resumed after it.
+func (r *Repository) RebuildLatestSaves(ctx context.Context, opts RebuildOptions) (_ domain.RebuildReport, err error) {
+	ctx, span := tracing.Start(ctx, "repository.RebuildLatestSaves")
+	defer func() { tracing.End(span, err) }()
+
+	report := domain.RebuildReport{VerifyOnly: opts.VerifyOnly, LastUserID: opts.AfterUserID}
+	batchSize := max(opts.BatchSize, 1)
+
+	for {
+		users, err := r.ListUserIDs(ctx, report.LastUserID, batchSize)
+		if err != nil {
+			return report, err
+		}
+		for _, userID := range users {
+			mismatches, err := r.VerifyLatestSave(ctx, userID)
+			// 一覧の後に削除されたユーザーは飛ばす
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return report, fmt.Errorf("verify %s: %w", userID, err)
+			}
+			report.Users++
+			if len(mismatches) > 0 {
+				report.Mismatched++
+				if opts.OnMismatch != nil {
+					for _, m := range mismatches {
+						opts.OnMismatch(m)
+					}
+				}
+				if !opts.VerifyOnly {
+					if err := r.RebuildLatestSave(ctx, userID); err != nil && !errors.Is(err, sql.ErrNoRows) {
+						return report, fmt.Errorf("rebuild %s: %w", userID, err)
+					}
+					report.Rebuilt++
+				}
+			}
+			report.LastUserID = userID
+		}
+
+		if opts.OnBatch != nil && len(users) > 0 {
+			if err := opts.OnBatch(report); err != nil {
+				return report, err
+			}
+		}
+		if len(users) < batchSize {
+			return report, nil
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/domain"
+)
+
+func TestNewLatestSaveRow(t *testing.T) {
+	sd := &domain.SaveData{
+		UserId:           "u",
+		CreditAll:        1234,
+		HideRecord:       1,
+		LAchieve:         []string{"a", "b", "c"},
+		DCBallChain:      map[string]int{"1": 9, "3": 4},
+		DCPalettaBallGet: map[string]int{"100": 7, "5": 2},
+	}
+	row := newLatestSaveRow(sd, 42)
+	p := row.Projection
+	if row.SaveID != 42 || row.UserID != "u" || p.CreditAll() != 1234 || !p.Hidden() {
+		t.Fatalf("copied fields: %+v", row)
+	}
+	if p.Column("achievements_count") != int64(3) || p.Column("max_chain_rainbow") != int64(4) || p.Column("golden_palball_get") != int64(7) {
+		t.Fatalf("derived fields: %+v", row)
+	}
+	if values := row.Values(); len(values) != len(strings.Split(latestSaveColumns, ",")) || values[0] != "u" || values[1] != int64(42) {
+		t.Fatalf("values: %v", values)
+	}
+
+	p = newLatestSaveRow(&domain.SaveData{UserId: "u"}, 1).Projection
+	if p.Column("max_chain_rainbow") != int64(0) || p.Column("golden_palball_get") != int64(0) {
+		t.Fatalf("derived fields without counters: %+v", p)
+	}
+}
+
+func TestDiffLatestSave(t *testing.T) {
+	want := newLatestSaveRow(&domain.SaveData{UserId: "u", CreditAll: 10, LAchieve: []string{"a", "b"}}, 7)
+	have := newLatestSaveRow(&domain.SaveData{UserId: "u", CreditAll: 3, LAchieve: []string{"a", "b"}}, 7)
+
+	got := diffLatestSave("u", &have, []string{"c", "a"}, &want, []string{"a", "b"})
+	expected := []domain.LatestSaveMismatch{
+		{UserID: "u", Field: "credit_all", Stored: "3", Expected: "10"},
+		{UserID: "u", Field: "achievement:b", Stored: "absent", Expected: "present"},
+		{UserID: "u", Field: "achievement:c", Stored: "present", Expected: "absent"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("diffLatestSave:\n got %v\nwant %v", got, expected)
+	}
+
+	if got := diffLatestSave("u", &want, []string{"b", "a"}, &want, []string{"a", "b"}); len(got) != 0 {
+		t.Fatalf("identical rows should not differ: %v", got)
+	}
+	if got := diffLatestSave("u", nil, nil, &want, nil); len(got) != 1 || got[0].Field != "row" || got[0].Stored != "missing" {
+		t.Fatalf("missing row: %v", got)
+	}
+	if got := diffLatestSave("u", &have, []string{"a"}, nil, nil); len(got) != 2 || got[0].Expected != "missing" {
+		t.Fatalf("stale row: %v", got)
+	}
+	if got := diffLatestSave("u", nil, nil, nil, nil); got != nil {
+		t.Fatalf("no rows: %v", got)
+	}
+}
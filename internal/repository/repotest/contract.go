@@ -0,0 +1,560 @@
+// Package repotest is the contract every implementation of handler.Repository must satisfy.
+// The MariaDB repository runs it in the integration tests and the in-memory one in its unit tests,
+// so both keep the same semantics.
+package repotest
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/domain"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/handler"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/openapi/models"
+)
+
+// Run runs the contract against repositories made by newRepo, which must return an empty repository on each call.
+//
+// The SQL timestamps only have second precision, so saves stored within one test usually share updated_at;
+// the contract therefore never depends on how such saves are ordered among themselves.
+func Run(t *testing.T, newRepo func(t *testing.T) handler.Repository) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, repo handler.Repository)
+	}{
+		{"InsertAndLatestSave", testInsertAndLatestSave},
+		{"LatestSaveNotFound", testLatestSaveNotFound},
+		{"ExistsSameSave", testExistsSameSave},
+		{"AchievementsAccumulate", testAchievementsAccumulate},
+		{"SaveHistory", testSaveHistory},
+		{"AchievementUnlockHistory", testAchievementUnlockHistory},
+		{"Rankings", testRankings},
+		{"RankingTieBreaker", testRankingTieBreaker},
+		{"StatisticsFollowLatestSave", testStatisticsFollowLatestSave},
+		{"AchievementRates", testAchievementRates},
+		{"CreditAllDistribution", testCreditAllDistribution},
+		{"MedalTimeseries", testMedalTimeseries},
+		{"SaveActivity", testSaveActivity},
+		{"LegacyRankings", testLegacyRankings},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, newRepo(t))
+		})
+	}
+}
+
+// NewSave returns a save of userID with every column, child table and an extra field filled, and the given key values.
+func NewSave(userID string, playtime, creditAll int64, achievements []string) *domain.SaveData {
+	return &domain.SaveData{
+		UserId:                    userID,
+		Legacy:                    1,
+		Version:                   19,
+		Credit:                    10,
+		CreditAll:                 creditAll,
+		MedalIn:                   1,
+		MedalGet:                  2,
+		BallGet:                   3,
+		BallChain:                 4,
+		SlotStart:                 5,
+		SlotStartFev:              6,
+		SlotHit:                   7,
+		SlotGetFev:                8,
+		SqrGet:                    9,
+		SqrStep:                   10,
+		JackGet:                   11,
+		JackStartMax:              12,
+		JackTotalMax:              13,
+		UltGet:                    14,
+		UltComboMax:               15,
+		UltTotalMax:               16,
+		RmShbiGet:                 17,
+		BuyShbi:                   18,
+		FirstBoot:                 100,
+		LastSave:                  200,
+		Playtime:                  playtime,
+		BstpStep:                  1,
+		BstpRwd:                   2,
+		BuyTotal:                  3,
+		SkillPoint:                4,
+		BlackBox:                  5,
+		BlackBoxTotal:             6,
+		SpUse:                     7,
+		CpMMax:                    1.2,
+		JackTotalMaxV2:            33,
+		UltimateTotalMaxV2:        44,
+		PalettaBallGet:            2,
+		JackpotSuperStartMax:      3,
+		JackpotFerrettaStartMax:   4,
+		JackpotFerrettaTotalMax:   5,
+		FerrettaLotteryLines:      6,
+		TaskCompleteCount:         7,
+		DCMedalGet:                map[string]int{"1": 10, "2": 20},
+		DCBallGet:                 map[string]int64{"1": 5},
+		DCBallChain:               map[string]int{"1": 2, "3": 9},
+		DCPalettaBallGet:          map[string]int{"100": 3},
+		DCPalettaBallJackpot:      map[string]int{"1": 1},
+		DCBlackBoxShopUsed:        map[string]int{"a": 1},
+		DCFerrettaLotteryItem:     map[string]int{"b": 2},
+		DCFerrettaLotteryItemUsed: map[string]int{"c": 3},
+		LAchieve:                  achievements,
+		LPerkLevels:               []int{1, 2, 3},
+		LPerkUsedCredits:          []int64{10, 20, 30},
+		LTotemLevels:              []int{4, 5},
+		LTotemUsedCredits:         []int64{40, 50},
+		LTotemPlacements:          []int{1, 0},
+		ExtraFields:               domain.SaveExtraFields{"zz_new": json.RawMessage(`{"n":[1,2]}`)},
+	}
+}
+
+func insert(t *testing.T, repo handler.Repository, sd *domain.SaveData) *domain.SaveData {
+	t.Helper()
+	if err := repo.InsertSaveV4(context.Background(), sd); err != nil {
+		t.Fatalf("insert save of %s: %v", sd.UserId, err)
+	}
+	return sd
+}
+
+func testInsertAndLatestSave(t *testing.T, repo handler.Repository) {
+	ctx := context.Background()
+	want := insert(t, repo, NewSave("user-1", 100, 5000, []string{"ach-1", "ach-2"}))
+	if want.ID <= 0 {
+		t.Fatalf("save id: got %d", want.ID)
+	}
+	if !sameSet(want.UnlockedAchievements, []string{"ach-1", "ach-2"}) {
+		t.Fatalf("unlocked: got %v", want.UnlockedAchievements)
+	}
+
+	got, err := repo.GetLatestSave(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("latest save: %v", err)
+	}
+	if got.ID != want.ID || got.UserId != "user-1" || got.CreditAll != 5000 || got.Playtime != 100 || got.Version != 19 {
+		t.Fatalf("main row: got %+v", got)
+	}
+	if got.JackpotFerrettaTotalMax != 5 || got.CpMMax != 1.2 || got.TaskCompleteCount != 7 {
+		t.Fatalf("columns: got %+v", got)
+	}
+	if got.UpdatedAt.IsZero() {
+		t.Fatal("updated_at is not set")
+	}
+	if !sameSet(got.LAchieve, []string{"ach-1", "ach-2"}) {
+		t.Fatalf("achievements: got %v", got.LAchieve)
+	}
+	checks := []struct {
+		name      string
+		got, want any
+	}{
+		{"medal_get", got.DCMedalGet, want.DCMedalGet},
+		{"ball_get", got.DCBallGet, want.DCBallGet},
+		{"ball_chain", got.DCBallChain, want.DCBallChain},
+		{"palball_get", got.DCPalettaBallGet, want.DCPalettaBallGet},
+		{"palball_jp", got.DCPalettaBallJackpot, want.DCPalettaBallJackpot},
+		{"bbox_shop", got.DCBlackBoxShopUsed, want.DCBlackBoxShopUsed},
+		{"ferlot_item", got.DCFerrettaLotteryItem, want.DCFerrettaLotteryItem},
+		{"ferlot_useitem", got.DCFerrettaLotteryItemUsed, want.DCFerrettaLotteryItemUsed},
+		{"perks", got.LPerkLevels, want.LPerkLevels},
+		{"perks_credit", got.LPerkUsedCredits, want.LPerkUsedCredits},
+		{"totems", got.LTotemLevels, want.LTotemLevels},
+		{"totems_credit", got.LTotemUsedCredits, want.LTotemUsedCredits},
+		{"totems_placement", got.LTotemPlacements, want.LTotemPlacements},
+		{"extra_fields", got.ExtraFields, want.ExtraFields},
+	}
+	for _, c := range checks {
+		if fmt.Sprint(c.got) != fmt.Sprint(c.want) {
+			t.Errorf("%s: got %v, want %v", c.name, c.got, c.want)
+		}
+	}
+}
+
+func testLatestSaveNotFound(t *testing.T, repo handler.Repository) {
+	if _, err := repo.GetLatestSave(context.Background(), "nobody"); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("want sql.ErrNoRows, got %v", err)
+	}
+}
+
+func testExistsSameSave(t *testing.T, repo handler.Repository) {
+	ctx := context.Background()
+	insert(t, repo, NewSave("user-1", 100, 10, nil))
+
+	for _, c := range []struct {
+		userID   string
+		playtime int64
+		want     bool
+	}{
+		{"user-1", 100, true},
+		{"user-1", 101, false},
+		{"user-2", 100, false},
+	} {
+		got, err := repo.ExistsSameSave(ctx, c.userID, c.playtime)
+		if err != nil {
+			t.Fatalf("exists %s/%d: %v", c.userID, c.playtime, err)
+		}
+		if got != c.want {
+			t.Errorf("exists %s/%d: got %v, want %v", c.userID, c.playtime, got, c.want)
+		}
+	}
+}
+
+func testAchievementsAccumulate(t *testing.T, repo handler.Repository) {
+	insert(t, repo, NewSave("user-1", 100, 10, []string{"ach-1", "ach-2"}))
+	// achievements missing from a later save stay unlocked
+	second := insert(t, repo, NewSave("user-1", 200, 20, []string{"ach-1", "ach-3", "ach-3"}))
+	if !sameSet(second.UnlockedAchievements, []string{"ach-3"}) {
+		t.Fatalf("unlocked by the second save: got %v", second.UnlockedAchievements)
+	}
+
+	got, err := repo.GetLatestSave(context.Background(), "user-1")
+	if err != nil {
+		t.Fatalf("latest save: %v", err)
+	}
+	if !sameSet(got.LAchieve, []string{"ach-1", "ach-2", "ach-3"}) {
+		t.Fatalf("achievements: got %v", got.LAchieve)
+	}
+}
+
+func testSaveHistory(t *testing.T, repo handler.Repository) {
+	ctx := context.Background()
+	var ids []int
+	for i := range 3 {
+		ids = append(ids, int(insert(t, repo, NewSave("user-1", int64(100+i), int64(10*i), nil)).ID))
+	}
+	insert(t, repo, NewSave("user-2", 100, 10, nil))
+
+	entries, hasMore, err := repo.GetSaveHistory(ctx, "user-1", 5, nil)
+	if err != nil {
+		t.Fatalf("history: %v", err)
+	}
+	if hasMore || len(entries) != 3 {
+		t.Fatalf("history: got %d entries, hasMore %v", len(entries), hasMore)
+	}
+	var got []int
+	for _, e := range entries {
+		got = append(got, *e.SaveId)
+		if e.UpdatedAt == nil || e.CreditAll == nil || e.CpmMax == nil || *e.CpmMax != 1.2 {
+			t.Fatalf("entry: got %+v", e)
+		}
+	}
+	if !sameSet(got, ids) {
+		t.Fatalf("save ids: got %v, want %v", got, ids)
+	}
+	for i := 1; i < len(entries); i++ {
+		if entries[i].UpdatedAt.After(*entries[i-1].UpdatedAt) {
+			t.Fatalf("history is not newest first: %v then %v", entries[i-1].UpdatedAt, entries[i].UpdatedAt)
+		}
+	}
+
+	entries, hasMore, err = repo.GetSaveHistory(ctx, "user-1", 2, nil)
+	if err != nil {
+		t.Fatalf("history with limit: %v", err)
+	}
+	if !hasMore || len(entries) != 2 {
+		t.Fatalf("history with limit: got %d entries, hasMore %v", len(entries), hasMore)
+	}
+
+	past := time.Now().Add(-time.Hour)
+	entries, _, err = repo.GetSaveHistory(ctx, "user-1", 5, &past)
+	if err != nil {
+		t.Fatalf("history before: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Fatalf("history before an hour ago: got %d entries", len(entries))
+	}
+	future := time.Now().Add(time.Hour)
+	entries, _, err = repo.GetSaveHistory(ctx, "user-1", 5, &future)
+	if err != nil {
+		t.Fatalf("history before: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("history before an hour later: got %d entries", len(entries))
+	}
+}
+
+func testAchievementUnlockHistory(t *testing.T, repo handler.Repository) {
+	ctx := context.Background()
+	first := insert(t, repo, NewSave("user-1", 100, 10, []string{"ach-1"}))
+	second := insert(t, repo, NewSave("user-1", 200, 20, []string{"ach-1", "ach-2", "ach-3"}))
+	insert(t, repo, NewSave("user-2", 100, 10, []string{"ach-1"}))
+
+	entries, total, err := repo.GetAchievementUnlockHistory(ctx, "user-1", 10)
+	if err != nil {
+		t.Fatalf("unlock history: %v", err)
+	}
+	if total != 3 || len(entries) != 3 {
+		t.Fatalf("unlock history: got %d entries, total %d", len(entries), total)
+	}
+	wantSave := map[string]int64{"ach-1": first.ID, "ach-2": second.ID, "ach-3": second.ID}
+	for _, e := range entries {
+		if e.AchievementId == nil || e.SaveId == nil || e.UnlockedAt == nil || e.Playtime == nil {
+			t.Fatalf("entry: got %+v", e)
+		}
+		if int64(*e.SaveId) != wantSave[*e.AchievementId] {
+			t.Errorf("%s: unlocked by save %d, want %d", *e.AchievementId, *e.SaveId, wantSave[*e.AchievementId])
+		}
+	}
+
+	entries, total, err = repo.GetAchievementUnlockHistory(ctx, "user-1", 2)
+	if err != nil {
+		t.Fatalf("unlock history with limit: %v", err)
+	}
+	if total != 3 || len(entries) != 2 {
+		t.Fatalf("unlock history with limit: got %d entries, total %d", len(entries), total)
+	}
+
+	entries, total, err = repo.GetAchievementUnlockHistory(ctx, "nobody", 10)
+	if err != nil {
+		t.Fatalf("unlock history of nobody: %v", err)
+	}
+	if total != 0 || len(entries) != 0 {
+		t.Fatalf("unlock history of nobody: got %d entries, total %d", len(entries), total)
+	}
+}
+
+func testRankings(t *testing.T, repo handler.Repository) {
+	ctx := context.Background()
+	for i, cpm := range []float64{10.2, 30.6, 20.5} {
+		sd := NewSave(fmt.Sprintf("user-%d", i+1), 100, 1000, nil)
+		sd.CpMMax = cpm
+		sd.SpUse = int64(i + 1)
+		insert(t, repo, sd)
+	}
+	hidden := NewSave("user-hidden", 100, 1000, nil)
+	hidden.CpMMax = 99
+	hidden.HideRecord = 1
+	insert(t, repo, hidden)
+
+	ranking, err := repo.GetRankingV4(ctx, "cpm_max", 10)
+	if err != nil {
+		t.Fatalf("cpm_max ranking: %v", err)
+	}
+	if got := rankingUsers(ranking); !slices.Equal(got, []string{"user-2", "user-3", "user-1"}) {
+		t.Fatalf("cpm_max ranking: got %v", got)
+	}
+	// values are rounded to integers
+	if got := rankingValues(ranking); !slices.Equal(got, []int64{31, 21, 10}) {
+		t.Fatalf("cpm_max values: got %v", got)
+	}
+	if ranking[0].CreatedAt == nil {
+		t.Fatal("created_at is not set")
+	}
+
+	ranking, err = repo.GetRankingV4(ctx, "sp_use", 2)
+	if err != nil {
+		t.Fatalf("sp_use ranking: %v", err)
+	}
+	if got := rankingUsers(ranking); !slices.Equal(got, []string{"user-3", "user-2"}) {
+		t.Fatalf("sp_use ranking with limit: got %v", got)
+	}
+
+	if _, err := repo.GetRankingV4(ctx, "credit_all", 10); err == nil {
+		t.Fatal("unknown metric should fail")
+	}
+
+	stats, err := repo.GetStatisticsV4(ctx)
+	if err != nil {
+		t.Fatalf("statistics: %v", err)
+	}
+	for _, m := range domain.RankedMetrics {
+		entries := *domain.RankingEntries(stats, m.Name)
+		if entries == nil || len(*entries) != 3 {
+			t.Errorf("%s: want the 3 visible users, got %v", m.Name, entries)
+		}
+	}
+	if got := rankingUsers(*stats.CpmMax); !slices.Equal(got, []string{"user-2", "user-3", "user-1"}) {
+		t.Fatalf("statistics cpm_max: got %v", got)
+	}
+	if stats.TotalMedals == nil || *stats.TotalMedals != 3000 {
+		t.Fatalf("total medals: got %v", stats.TotalMedals)
+	}
+}
+
+func testRankingTieBreaker(t *testing.T, repo handler.Repository) {
+	ctx := context.Background()
+	insert(t, repo, NewSave("user-early", 100, 1000, []string{"ach-1"}))
+	// on equal values the user who got there first ranks higher
+	time.Sleep(1100 * time.Millisecond)
+	insert(t, repo, NewSave("user-late", 100, 1000, []string{"ach-1"}))
+
+	ranking, err := repo.GetRankingV4(ctx, "achievements_count", 10)
+	if err != nil {
+		t.Fatalf("ranking: %v", err)
+	}
+	if got := rankingUsers(ranking); !slices.Equal(got, []string{"user-early", "user-late"}) {
+		t.Fatalf("tie-break: got %v", got)
+	}
+}
+
+func testStatisticsFollowLatestSave(t *testing.T, repo handler.Repository) {
+	ctx := context.Background()
+	insert(t, repo, NewSave("user-1", 100, 1000, nil))
+	insert(t, repo, NewSave("user-2", 100, 500, nil))
+	// a new save replaces the latest row, and hiding the record drops the user from the statistics
+	insert(t, repo, NewSave("user-1", 200, 4000, nil))
+	hidden := NewSave("user-2", 200, 800, nil)
+	hidden.HideRecord = 1
+	insert(t, repo, hidden)
+
+	stats, err := repo.GetStatisticsV4(ctx)
+	if err != nil {
+		t.Fatalf("statistics: %v", err)
+	}
+	if stats.TotalMedals == nil || *stats.TotalMedals != 4000 {
+		t.Fatalf("total medals: got %v", stats.TotalMedals)
+	}
+	if got := rankingUsers(*stats.SpUse); !slices.Equal(got, []string{"user-1"}) {
+		t.Fatalf("sp_use ranking: got %v", got)
+	}
+}
+
+func testAchievementRates(t *testing.T, repo handler.Repository) {
+	ctx := context.Background()
+	insert(t, repo, NewSave("user-1", 100, 10, []string{"ach-1", "ach-2"}))
+	insert(t, repo, NewSave("user-2", 100, 10, []string{"ach-1"}))
+	// hidden users count towards the achievement rates, users without achievements do not
+	hidden := NewSave("user-3", 100, 10, []string{"ach-1"})
+	hidden.HideRecord = 1
+	insert(t, repo, hidden)
+	insert(t, repo, NewSave("user-4", 100, 10, nil))
+
+	rates, err := repo.GetAchievementRates(ctx)
+	if err != nil {
+		t.Fatalf("achievement rates: %v", err)
+	}
+	if rates.TotalUsers == nil || *rates.TotalUsers != 3 {
+		t.Fatalf("total users: got %v", rates.TotalUsers)
+	}
+	for id, want := range map[string]int{"ach-1": 3, "ach-2": 1} {
+		rate, ok := (*rates.AchievementRates)[id]
+		if !ok || rate.Count == nil || *rate.Count != want {
+			t.Errorf("%s count: got %+v, want %d", id, rate, want)
+			continue
+		}
+		if wantRate := float32(want) / 3; rate.Rate == nil || *rate.Rate != wantRate {
+			t.Errorf("%s rate: got %v, want %v", id, rate.Rate, wantRate)
+		}
+	}
+}
+
+func testCreditAllDistribution(t *testing.T, repo handler.Repository) {
+	ctx := context.Background()
+	empty, err := repo.GetCreditAllDistribution(ctx)
+	if err != nil {
+		t.Fatalf("empty distribution: %v", err)
+	}
+	if empty.Users != 0 || len(empty.Distribution) != 1 || empty.Distribution[0].RangeMax != 999 {
+		t.Fatalf("empty distribution: got %+v", empty)
+	}
+
+	for i, credit := range []int64{5, 999, 12345, 67890} {
+		insert(t, repo, NewSave(fmt.Sprintf("user-%d", i), 100, credit, nil))
+	}
+	hidden := NewSave("user-hidden", 100, 1_000_000, nil)
+	hidden.HideRecord = 1
+	insert(t, repo, hidden)
+
+	dist, err := repo.GetCreditAllDistribution(ctx)
+	if err != nil {
+		t.Fatalf("distribution: %v", err)
+	}
+	if dist.Users != 4 {
+		t.Fatalf("users: got %d", dist.Users)
+	}
+	want := []models.CreditAllDistributionBucket{
+		{RangeMin: 0, RangeMax: 999, Users: 2},
+		{RangeMin: 1000, RangeMax: 9999, Users: 0},
+		{RangeMin: 10000, RangeMax: 99999, Users: 2},
+	}
+	if !slices.Equal(dist.Distribution, want) {
+		t.Fatalf("distribution: got %+v, want %+v", dist.Distribution, want)
+	}
+}
+
+func testMedalTimeseries(t *testing.T, repo handler.Repository) {
+	ctx := context.Background()
+	insert(t, repo, NewSave("user-1", 100, 1000, nil))
+	insert(t, repo, NewSave("user-2", 300, 3000, nil))
+
+	resp, err := repo.GetMedalTimeseries(ctx, 1)
+	if err != nil {
+		t.Fatalf("medal timeseries: %v", err)
+	}
+	// the day boundary depends on the time zone, so check the buckets summed up
+	var total, users int
+	for _, b := range *resp.Buckets {
+		if b.Date == nil || b.TotalMedals == nil || b.ActiveUsers == nil || b.AvgPlaytime == nil {
+			t.Fatalf("bucket: got %+v", b)
+		}
+		total += *b.TotalMedals
+		users += *b.ActiveUsers
+	}
+	if total != 4000 || users != 2 {
+		t.Fatalf("medal timeseries: total %d, users %d", total, users)
+	}
+	if len(*resp.Buckets) == 1 && *(*resp.Buckets)[0].AvgPlaytime != 200 {
+		t.Fatalf("average playtime: got %d", *(*resp.Buckets)[0].AvgPlaytime)
+	}
+}
+
+func testSaveActivity(t *testing.T, repo handler.Repository) {
+	ctx := context.Background()
+	insert(t, repo, NewSave("user-1", 100, 10, nil))
+	insert(t, repo, NewSave("user-1", 200, 10, nil))
+	insert(t, repo, NewSave("user-2", 100, 10, nil))
+
+	resp, err := repo.GetSaveActivity(ctx, 24)
+	if err != nil {
+		t.Fatalf("save activity: %v", err)
+	}
+	var saves int
+	for _, b := range *resp.Buckets {
+		if b.HourStart == nil || b.Saves == nil || b.UniqueUsers == nil {
+			t.Fatalf("bucket: got %+v", b)
+		}
+		if *b.UniqueUsers > *b.Saves {
+			t.Fatalf("bucket: %d users in %d saves", *b.UniqueUsers, *b.Saves)
+		}
+		saves += *b.Saves
+	}
+	if saves != 3 {
+		t.Fatalf("saves: got %d", saves)
+	}
+}
+
+func testLegacyRankings(t *testing.T, repo handler.Repository) {
+	if _, err := repo.GetRankings(context.Background(), "not_a_column", 10); err == nil {
+		t.Fatal("unknown sort column should fail")
+	}
+}
+
+func rankingUsers(entries []models.RankingEntry) []string {
+	users := make([]string, 0, len(entries))
+	for _, e := range entries {
+		users = append(users, *e.UserId)
+	}
+	return users
+}
+
+func rankingValues(entries []models.RankingEntry) []int64 {
+	values := make([]int64, 0, len(entries))
+	for _, e := range entries {
+		values = append(values, *e.Value)
+	}
+	return values
+}
+
+func sameSet[T comparable](got, want []T) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for _, v := range want {
+		if !slices.Contains(got, v) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/domain"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/pkg/tracing"
+)
+
+// retentionDeleteChunk caps the ids deleted by one statement, so each batch holds its locks briefly.
+const retentionDeleteChunk = 500
+
+// protectedSave is true for saves the retention policy never removes (s is the v2_save_data row);
+// see domain.RetentionCandidate.Protected.
+const protectedSave = `(
+  EXISTS (SELECT 1 FROM v3_user_latest_save_data l WHERE l.user_id = s.user_id AND l.save_id = s.id)
+  OR EXISTS (SELECT 1 FROM v2_save_data_achievements a WHERE a.save_id = s.id)
+  OR EXISTS (SELECT 1 FROM v2_save_data_achievement_revocations rv WHERE rv.save_id = s.id)
+  OR EXISTS (SELECT 1 FROM anomaly_flags f WHERE f.save_id = s.id)
+)`
+
+// PruneOptions controls a retention run.
+type PruneOptions struct {
+	// Apply deletes the saves; otherwise the run only reports what it would delete.
+	Apply bool
+	// BatchSize is the number of users handled per batch.
+	BatchSize int
+	// OnUser, if set, is called with the saves removed (or to be removed) for each user.
+	OnUser func(userID string, ids []int64)
+}
+
+// PruneSaves thins the stored saves of every user according to policy, one batch of users at a time.
+// Child rows go with their save through ON DELETE CASCADE; the latest-save tables and v4 summaries are untouched.
+func (r *Repository) PruneSaves(ctx context.Context, policy domain.RetentionPolicy, now time.Time, opts PruneOptions) (_ domain.RetentionReport, err error) {
+	ctx, span := tracing.Start(ctx, "repository.PruneSaves")
+	defer func() { tracing.End(span, err) }()
+
+	report := domain.RetentionReport{DryRun: !opts.Apply}
+	if err := policy.Validate(); err != nil {
+		return report, err
+	}
+	batchSize := max(opts.BatchSize, 1)
+	cutoff := r.dialect().timeArg(policy.Cutoff(now))
+
+	afterUserID := ""
+	for {
+		var users []string
+		if err := r.db.SelectContext(ctx, &users, `
+SELECT DISTINCT user_id
+FROM v2_save_data
+WHERE created_at < ? AND user_id > ?
+ORDER BY user_id
+LIMIT ?`, cutoff, afterUserID, batchSize); err != nil {
+			return report, err
+		}
+
+		for _, userID := range users {
+			var saves []domain.RetentionCandidate
+			if err := r.db.SelectContext(ctx, &saves, `
+SELECT s.id, s.created_at, `+protectedSave+` AS protected
+FROM v2_save_data s
+WHERE s.user_id = ? AND s.created_at < ?
+ORDER BY s.id`, userID, cutoff); err != nil {
+				return report, err
+			}
+			report.Users++
+			report.Examined += len(saves)
+			for _, s := range saves {
+				if s.Protected {
+					report.Protected++
+				}
+			}
+
+			ids := policy.Prunable(saves, now)
+			if len(ids) == 0 {
+				continue
+			}
+			if !opts.Apply {
+				report.Removed += int64(len(ids))
+			} else {
+				deleted, err := r.deleteSaves(ctx, userID, ids)
+				report.Removed += deleted
+				if err != nil {
+					return report, err
+				}
+			}
+			if opts.OnUser != nil {
+				opts.OnUser(userID, ids)
+			}
+		}
+
+		if len(users) < batchSize {
+			return report, nil
+		}
+		afterUserID = users[len(users)-1]
+	}
+}
+
+// deleteSaves removes the given saves of the user. The protection is checked again in the statement,
+// so a save that became the latest or got flagged since it was listed survives.
+func (r *Repository) deleteSaves(ctx context.Context, userID string, ids []int64) (int64, error) {
+	var deleted int64
+	for start := 0; start < len(ids); start += retentionDeleteChunk {
+		chunk := ids[start:min(start+retentionDeleteChunk, len(ids))]
+		query, args, err := sqlx.In(`
+`+r.dialect().deleteAliased("v2_save_data", "s")+`
+WHERE s.user_id = ? AND s.id IN (?) AND NOT `+protectedSave, userID, chunk)
+		if err != nil {
+			return deleted, err
+		}
+		res, err := r.db.ExecContext(ctx, query, args...)
+		if err != nil {
+			return deleted, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return deleted, err
+		}
+		deleted += n
+	}
+	return deleted, nil
+}
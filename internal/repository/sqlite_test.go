@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/domain"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/handler"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/migration"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/pkg/config"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/repository/repotest"
+	_ "modernc.org/sqlite"
+)
+
+// newSQLite returns a Repository on a fresh, migrated SQLite file opened as DB_DRIVER=sqlite does.
+func newSQLite(t *testing.T) *Repository {
+	t.Helper()
+	t.Setenv("SQLITE_PATH", filepath.Join(t.TempDir(), "app.db"))
+	db, err := sqlx.Connect("sqlite", config.SQLiteDSN())
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	if _, err := migration.Up(context.Background(), db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return New(db)
+}
+
+func TestSQLiteContract(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) handler.Repository { return newSQLite(t) })
+}
+
+func TestSQLiteSummaryHasNoDrift(t *testing.T) {
+	ctx := context.Background()
+	repo := newSQLite(t)
+	saves := []*domain.SaveData{
+		repotest.NewSave("alice", 100, 5, []string{"a1"}),
+		repotest.NewSave("bob", 100, 123456, []string{"a1", "a2"}),
+		repotest.NewSave("alice", 200, 98765, []string{"a3"}),
+	}
+	for _, sd := range saves {
+		if err := repo.InsertSaveV4(ctx, sd); err != nil {
+			t.Fatalf("insert %s: %v", sd.UserId, err)
+		}
+	}
+
+	drifts, err := repo.ReconcileSummary(ctx, false)
+	if err != nil {
+		t.Fatalf("reconcile: %v", err)
+	}
+	if len(drifts) != 0 {
+		t.Fatalf("summaries drifted: %+v", drifts)
+	}
+}
+
+func TestSQLiteModerationHidesFromStatistics(t *testing.T) {
+	ctx := context.Background()
+	repo := newSQLite(t)
+	for _, sd := range []*domain.SaveData{
+		repotest.NewSave("alice", 100, 5, nil),
+		repotest.NewSave("bob", 100, 7, nil),
+	} {
+		if err := repo.InsertSaveV4(ctx, sd); err != nil {
+			t.Fatalf("insert %s: %v", sd.UserId, err)
+		}
+	}
+
+	// An expiry in another time zone must still compare correctly with the stored UTC timestamps.
+	expires := time.Now().In(time.FixedZone("JST", 9*60*60)).Add(time.Hour)
+	if _, err := repo.UpsertModeration(ctx, domain.Moderation{UserID: "bob", HiddenFromRankings: true, ExpiresAt: &expires}); err != nil {
+		t.Fatalf("moderate: %v", err)
+	}
+	if _, err := repo.UpsertModeration(ctx, domain.Moderation{UserID: "alice", HiddenFromRankings: true, ExpiresAt: &time.Time{}}); err != nil {
+		t.Fatalf("moderate: %v", err)
+	}
+
+	entries, err := repo.GetRankingV4(ctx, "sp_use", 10)
+	if err != nil {
+		t.Fatalf("ranking: %v", err)
+	}
+	if len(entries) != 1 || *entries[0].UserId != "alice" {
+		t.Fatalf("only alice's moderation has expired: %+v", entries)
+	}
+
+	// the statistics read from v2_save_data leave bob out too
+	timeseries, err := repo.GetMedalTimeseries(ctx, 1)
+	if err != nil {
+		t.Fatalf("medal timeseries: %v", err)
+	}
+	var medals, users int
+	for _, bucket := range *timeseries.Buckets {
+		medals += *bucket.TotalMedals
+		users += *bucket.ActiveUsers
+	}
+	if medals != 5 || users != 1 {
+		t.Fatalf("medal timeseries: %d medals of %d users", medals, users)
+	}
+	activity, err := repo.GetSaveActivity(ctx, 24)
+	if err != nil {
+		t.Fatalf("save activity: %v", err)
+	}
+	var saves int
+	for _, bucket := range *activity.Buckets {
+		saves += *bucket.Saves
+	}
+	if saves != 1 {
+		t.Fatalf("save activity: %d saves", saves)
+	}
+
+	m, err := repo.GetModeration(ctx, "bob")
+	if err != nil {
+		t.Fatalf("get moderation: %v", err)
+	}
+	if m.ExpiresAt == nil || !m.ExpiresAt.Equal(expires) {
+		t.Fatalf("expires_at: got %v want %v", m.ExpiresAt, expires)
+	}
+}
+
+func TestSQLiteDeleteUserData(t *testing.T) {
+	ctx := context.Background()
+	repo := newSQLite(t)
+	for _, sd := range []*domain.SaveData{
+		repotest.NewSave("alice", 100, 5, []string{"ach-1"}),
+		repotest.NewSave("bob", 100, 7, nil),
+	} {
+		if err := repo.InsertSaveV4(ctx, sd); err != nil {
+			t.Fatalf("insert %s: %v", sd.UserId, err)
+		}
+		if _, err := repo.InsertAnomalyFlags(ctx, []domain.AnomalyFlag{
+			{UserID: sd.UserId, Kind: domain.AnomalyCreditRate, SaveID: sd.ID, Score: 2, Evidence: []byte(`{}`), Status: domain.AnomalyStatusOpen},
+		}); err != nil {
+			t.Fatalf("flag %s: %v", sd.UserId, err)
+		}
+		if err := repo.InsertSignatureBypass(ctx, &domain.SignatureBypassEntry{Route: "/v4/data", UserID: sd.UserId, RemoteIP: "192.0.2.1"}); err != nil {
+			t.Fatalf("bypass %s: %v", sd.UserId, err)
+		}
+	}
+	if _, err := repo.UpsertModeration(ctx, domain.Moderation{UserID: "alice", Banned: true}); err != nil {
+		t.Fatalf("moderate: %v", err)
+	}
+
+	if deleted, err := repo.DeleteUserData(ctx, "alice"); err != nil || deleted != 1 {
+		t.Fatalf("delete: %d, %v", deleted, err)
+	}
+	for _, table := range []string{"anomaly_flags", "signature_bypass_log"} {
+		var users []string
+		if err := repo.db.SelectContext(ctx, &users, `SELECT user_id FROM `+table); err != nil {
+			t.Fatalf("%s: %v", table, err)
+		}
+		if len(users) != 1 || users[0] != "bob" {
+			t.Fatalf("%s: only bob's rows should remain, got %v", table, users)
+		}
+	}
+	if m, err := repo.GetModeration(ctx, "alice"); err != nil || !m.Banned {
+		t.Fatalf("the ban outlives the deletion: %+v, %v", m, err)
+	}
+	if drifts, err := repo.ReconcileSummary(ctx, false); err != nil || len(drifts) != 0 {
+		t.Fatalf("drift after delete: %+v, %v", drifts, err)
+	}
+	if _, err := repo.DeleteUserData(ctx, "alice"); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("deleting again: got %v", err)
+	}
+}
+
+func TestSQLiteAnomalyScanLease(t *testing.T) {
+	ctx := context.Background()
+	repo := newSQLite(t)
+	old := repotest.NewSave("alice", 100, 5, nil)
+	if err := repo.InsertSaveV4(ctx, old); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+	if _, err := repo.db.ExecContext(ctx, `UPDATE v2_save_data SET created_at = '2000-01-01 00:00:00' WHERE id = ?`, old.ID); err != nil {
+		t.Fatalf("age save: %v", err)
+	}
+	if err := repo.InsertSaveV4(ctx, repotest.NewSave("alice", 200, 5, nil)); err != nil {
+		t.Fatalf("insert: %v", err)
+	}
+
+	// the first scan starts with the saves stored since the given time
+	afterID, ok, err := repo.ClaimAnomalyScan(ctx, "replica-a", time.Minute, time.Now().Add(-time.Hour))
+	if err != nil || !ok || afterID != old.ID {
+		t.Fatalf("first claim: %d %v %v", afterID, ok, err)
+	}
+	if _, ok, err := repo.ClaimAnomalyScan(ctx, "replica-b", time.Minute, time.Now()); err != nil || ok {
+		t.Fatalf("claim while another replica holds the lease: %v %v", ok, err)
+	}
+	if err := repo.AdvanceAnomalyScan(ctx, "replica-b", 100, time.Minute); err != nil {
+		t.Fatalf("advance without the lease: %v", err)
+	}
+	if err := repo.AdvanceAnomalyScan(ctx, "replica-a", old.ID+1, time.Minute); err != nil {
+		t.Fatalf("advance: %v", err)
+	}
+	if afterID, ok, err := repo.ClaimAnomalyScan(ctx, "replica-a", time.Minute, time.Now()); err != nil || !ok || afterID != old.ID+1 {
+		t.Fatalf("renewed claim: %d %v %v", afterID, ok, err)
+	}
+
+	// once the lease has expired another replica takes over where the scan stopped
+	if _, err := repo.db.ExecContext(ctx, `UPDATE anomaly_scan_state SET lease_until = '2000-01-01 00:00:00'`); err != nil {
+		t.Fatalf("expire lease: %v", err)
+	}
+	if afterID, ok, err := repo.ClaimAnomalyScan(ctx, "replica-b", time.Minute, time.Now()); err != nil || !ok || afterID != old.ID+1 {
+		t.Fatalf("claim after the lease expired: %d %v %v", afterID, ok, err)
+	}
+}
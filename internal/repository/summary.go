@@ -0,0 +1,289 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/domain"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/pkg/tracing"
+)
+
+// v4_summary_totals のキー
+const (
+	summaryTotalMedals      = "total_medals"
+	summaryVisibleUsers     = "visible_users"
+	summaryAchievementUsers = "achievement_users"
+)
+
+// summaryContribution is what a user's latest save currently contributes to the summary tables.
+type summaryContribution struct {
+	visible         bool
+	creditAll       int64
+	hasAchievements bool
+}
+
+// loadSummaryContribution reads the user's current contribution before the save is written,
+// and locks the user's latest-save row so concurrent saves of the same user apply their deltas in turn.
+func loadSummaryContribution(ctx context.Context, tx *sqlx.Tx, userID string) (summaryContribution, error) {
+	var c summaryContribution
+	var row struct {
+		CreditAll  sql.NullInt64 `db:"credit_all"`
+		HideRecord int           `db:"hide_record"`
+	}
+	err := tx.GetContext(ctx, &row, `
+SELECT credit_all, hide_record
+FROM v3_user_latest_save_data
+WHERE user_id = ?`+dialectOf(tx).forUpdate(), userID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return c, err
+	default:
+		c.visible = row.HideRecord == 0
+		c.creditAll = row.CreditAll.Int64
+	}
+
+	if err := tx.GetContext(ctx, &c.hasAchievements, `
+SELECT EXISTS(SELECT 1 FROM v3_user_latest_save_data_achievements WHERE user_id = ?)`, userID); err != nil {
+		return c, err
+	}
+	return c, nil
+}
+
+// applySummaryDelta replaces the previous contribution with the one of sd.
+func applySummaryDelta(ctx context.Context, tx *sqlx.Tx, before summaryContribution, sd *domain.SaveData, unlocked []string) error {
+	after := summaryContribution{
+		visible:         sd.HideRecord == 0,
+		creditAll:       sd.CreditAll,
+		hasAchievements: before.hasAchievements || len(unlocked) > 0,
+	}
+	return applySummaryChange(ctx, tx, before, after, unlocked, nil)
+}
+
+// applySummaryChange replaces the contribution before with after, and adds the user to (unlocked)
+// or removes them from (revoked) the per-achievement user counts.
+func applySummaryChange(ctx context.Context, tx *sqlx.Tx, before, after summaryContribution, unlocked, revoked []string) error {
+	d := dialectOf(tx)
+	var medals, users, achievementUsers int64
+	digits := make(map[int]int64, 2)
+	if before.visible {
+		medals -= before.creditAll
+		users--
+		digits[creditDigits(before.creditAll)]--
+	}
+	if after.visible {
+		medals += after.creditAll
+		users++
+		digits[creditDigits(after.creditAll)]++
+	}
+	switch {
+	case !before.hasAchievements && after.hasAchievements:
+		achievementUsers++
+	case before.hasAchievements && !after.hasAchievements:
+		achievementUsers--
+	}
+
+	for name, delta := range map[string]int64{
+		summaryTotalMedals:      medals,
+		summaryVisibleUsers:     users,
+		summaryAchievementUsers: achievementUsers,
+	} {
+		if delta == 0 {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `
+INSERT INTO v4_summary_totals (name, value) VALUES (?, ?)
+`+d.upsert("name")+` value = value + `+d.inserted("value"), name, delta); err != nil {
+			return err
+		}
+	}
+	for n, delta := range digits {
+		if delta == 0 {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `
+INSERT INTO v4_summary_credit_digits (digits, user_count) VALUES (?, ?)
+`+d.upsert("digits")+` user_count = user_count + `+d.inserted("user_count"), n, delta); err != nil {
+			return err
+		}
+	}
+
+	achievements := make(map[string]int64, len(unlocked)+len(revoked))
+	for _, id := range unlocked {
+		achievements[id]++
+	}
+	for _, id := range revoked {
+		achievements[id]--
+	}
+	// 同時に保存されたセーブ同士でロック順が食い違わないよう、キー順に更新する
+	ids := make([]string, 0, len(achievements))
+	for id := range achievements {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if achievements[id] == 0 {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, `
+INSERT INTO v4_summary_achievement_users (achievement_id, user_count) VALUES (?, ?)
+`+d.upsert("achievement_id")+` user_count = user_count + `+d.inserted("user_count"), id, achievements[id]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Repository) getSummaryTotal(ctx context.Context, name string) (int64, error) {
+	var value int64
+	err := r.db.GetContext(ctx, &value, `SELECT value FROM v4_summary_totals WHERE name = ?`, name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return value, err
+}
+
+type summaryRow struct {
+	Key   string `db:"k"`
+	Value int64  `db:"v"`
+}
+
+type summarySource struct {
+	table     string
+	keyColumn string
+	valColumn string
+	recompute string
+	stored    string
+}
+
+// summarySources lists each summary table with the query that recomputes it from scratch and the one that reads it.
+// The recompute queries must stay in sync with applySummaryChange and migration 33.
+// Moderation is deliberately not reflected in the summaries; readers subtract it (see moderation.go).
+func summarySources(d dialect) []summarySource {
+	return []summarySource{
+		{
+			table:     "v4_summary_totals",
+			keyColumn: "name",
+			valColumn: "value",
+			recompute: `
+SELECT 'total_medals' AS k, COALESCE(SUM(credit_all), 0) AS v FROM v3_user_latest_save_data WHERE hide_record = 0
+UNION ALL
+SELECT 'visible_users', COUNT(*) FROM v3_user_latest_save_data WHERE hide_record = 0
+UNION ALL
+SELECT 'achievement_users', COUNT(DISTINCT user_id) FROM v3_user_latest_save_data_achievements`,
+			stored: `SELECT name AS k, value AS v FROM v4_summary_totals`,
+		},
+		{
+			table:     "v4_summary_achievement_users",
+			keyColumn: "achievement_id",
+			valColumn: "user_count",
+			recompute: `
+SELECT achievement_id AS k, COUNT(*) AS v
+FROM v3_user_latest_save_data_achievements
+GROUP BY achievement_id`,
+			stored: `SELECT achievement_id AS k, user_count AS v FROM v4_summary_achievement_users`,
+		},
+		{
+			table:     "v4_summary_credit_digits",
+			keyColumn: "digits",
+			valColumn: "user_count",
+			recompute: `
+SELECT
+  CAST(CASE
+    WHEN credit_all IS NULL OR credit_all < 1000 THEN 3
+    ELSE ` + d.charLength("CAST(credit_all AS CHAR)") + `
+  END AS CHAR) AS k,
+  COUNT(*) AS v
+FROM v3_user_latest_save_data
+WHERE hide_record = 0
+GROUP BY k`,
+			stored: `SELECT CAST(digits AS CHAR) AS k, user_count AS v FROM v4_summary_credit_digits`,
+		},
+	}
+}
+
+// ReconcileSummary recomputes the summary tables from v3_user_latest_save_data and reports every value that drifted.
+// With apply, the drifted values are overwritten inside the same transaction; the source rows are share-locked
+// meanwhile so concurrent saves cannot slip between the recomputation and the fix.
+func (r *Repository) ReconcileSummary(ctx context.Context, apply bool) (_ []domain.SummaryDrift, err error) {
+	ctx, span := tracing.Start(ctx, "repository.ReconcileSummary")
+	defer func() { tracing.End(span, err) }()
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	d := dialectOf(tx)
+	if apply {
+		// 再計算と書き戻しの間に保存が割り込まないよう、集計元の行を共有ロックしておく
+		for _, table := range []string{"v3_user_latest_save_data", "v3_user_latest_save_data_achievements"} {
+			var n int64
+			if err := tx.GetContext(ctx, &n, "SELECT COUNT(*) FROM "+table+d.lockInShareMode()); err != nil {
+				return nil, fmt.Errorf("lock %s: %w", table, err)
+			}
+		}
+	}
+
+	var drifts []domain.SummaryDrift
+	for _, src := range summarySources(d) {
+		var actual, stored []summaryRow
+		if err := tx.SelectContext(ctx, &actual, src.recompute); err != nil {
+			return nil, fmt.Errorf("recompute %s: %w", src.table, err)
+		}
+		if err := tx.SelectContext(ctx, &stored, src.stored); err != nil {
+			return nil, fmt.Errorf("read %s: %w", src.table, err)
+		}
+
+		found := diffSummary(src.table, stored, actual)
+		if apply {
+			for _, drift := range found {
+				if _, err := tx.ExecContext(ctx, fmt.Sprintf(`
+INSERT INTO %s (%s, %s) VALUES (?, ?)
+%s %s = %s`, src.table, src.keyColumn, src.valColumn, d.upsert(src.keyColumn), src.valColumn, d.inserted(src.valColumn)),
+					drift.Key, drift.Actual); err != nil {
+					return nil, fmt.Errorf("fix %s: %w", src.table, err)
+				}
+			}
+		}
+		drifts = append(drifts, found...)
+	}
+
+	if apply {
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+	}
+	return drifts, nil
+}
+
+// diffSummary compares stored and recomputed rows; a key missing on either side counts as 0.
+func diffSummary(table string, stored, actual []summaryRow) []domain.SummaryDrift {
+	values := make(map[string][2]int64, len(actual))
+	for _, row := range stored {
+		v := values[row.Key]
+		v[0] = row.Value
+		values[row.Key] = v
+	}
+	for _, row := range actual {
+		v := values[row.Key]
+		v[1] = row.Value
+		values[row.Key] = v
+	}
+
+	var drifts []domain.SummaryDrift
+	for key, v := range values {
+		if v[0] != v[1] {
+			drifts = append(drifts, domain.SummaryDrift{Table: table, Key: key, Stored: v[0], Actual: v[1]})
+		}
+	}
+	sort.Slice(drifts, func(i, j int) bool { return drifts[i].Key < drifts[j].Key })
+	return drifts
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/domain"
+)
+
+func TestDiffSummary(t *testing.T) {
+	stored := []summaryRow{{"a", 1}, {"b", 2}, {"stale", 3}}
+	actual := []summaryRow{{"a", 1}, {"b", 5}, {"new", 4}}
+
+	got := diffSummary("t", stored, actual)
+	want := []domain.SummaryDrift{
+		{Table: "t", Key: "b", Stored: 2, Actual: 5},
+		{Table: "t", Key: "new", Stored: 0, Actual: 4},
+		{Table: "t", Key: "stale", Stored: 3, Actual: 0},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("diffSummary:\n got %v\nwant %v", got, want)
+	}
+
+	if got := diffSummary("t", actual, actual); len(got) != 0 {
+		t.Fatalf("expected no drift, got %v", got)
+	}
+}
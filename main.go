@@ -1,21 +1,31 @@
 package main
 
 import (
+	"context"
 	_ "embed"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/getkin/kin-openapi/openapi3"
-	"github.com/jmoiron/sqlx"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
-	echolog "github.com/labstack/gommon/log"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/domain"
 	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/handler"
 	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/migration"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/pkg/cache"
 	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/pkg/config"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/pkg/logging"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/pkg/tracing"
 	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/repository"
 	"github.com/pikachu0310/very-big-medal-pusher-data-server/openapi"
+	"github.com/redis/go-redis/v9"
 )
 
 //go:embed openapi/openapi.yaml
@@ -50,14 +60,71 @@ const swaggerHTML = `<!doctype html>
 </html>`
 
 func main() {
+	os.Exit(run())
+}
+
+// run はサブコマンドを実行して終了コードを返す。
+// os.Exit は defer を飛ばすので、トレースの送信を済ませてから main で終了する。
+func run() int {
+	slog.SetDefault(logging.New(os.Stdout, logging.ParseLevel(config.LogLevel())))
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Exporter:    config.TracingExporter(),
+		ServiceName: config.TracingServiceName(),
+		SampleRatio: config.TracingSampleRatio(),
+	})
+	if err != nil {
+		slog.Error("failed to setup tracing", "error", err)
+		return 1
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = shutdownTracing(ctx)
+	}()
+
+	name, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	cmd, ok := findCommand(name)
+	if !ok {
+		_, _ = fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+		printUsage(os.Stderr)
+		return 2
+	}
+	if err := cmd.run(context.Background(), args, os.Stdout); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		slog.Error(name+" failed", "error", err)
+		return 1
+	}
+	return 0
+}
+
+// serve は API サーバーを起動する（サブコマンド省略時もこれ）
+func serve(ctx context.Context, args []string, _ io.Writer) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	migrateOnStart := fs.Bool("migrate", config.MigrateOnStart(), "apply pending migrations before serving")
+	inMemory := fs.Bool("memory", false, "serve from an in-memory repository instead of the database (nothing is persisted)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
 	e := echo.New()
-	e.Logger.SetOutput(os.Stdout)
-	e.Logger.SetLevel(echolog.INFO)
-	e.Logger.SetHeader("${time_rfc3339} ${level} ")
+	e.HideBanner = true
+	e.HTTPErrorHandler = handler.HTTPErrorHandler
+	// c.RealIP()（リクエストログ・監査ログの送信元）。TRUSTED_PROXY_CIDRS が空なら echo 既定のヘッダーを使う
+	ipExtractor, err := handler.NewIPExtractor(config.TrustedProxyCIDRs())
+	if err != nil {
+		return fmt.Errorf("invalid TRUSTED_PROXY_CIDRS: %w", err)
+	}
+	e.IPExtractor = ipExtractor
 
 	swagger, err := openapi.GetSwagger()
 	if err != nil {
-		e.Logger.Fatal("Error loading swagger spec\n: %s", err)
+		return fmt.Errorf("load swagger spec: %w", err)
 	}
 
 	baseURL := "/api"
@@ -65,29 +132,91 @@ func main() {
 
 	// middlewares
 	e.Use(middleware.Recover())
-	e.Use(handler.RequestLogMiddleware(baseURL))
-	//e.Use(oapimiddleware.OapiRequestValidator(swagger))
+	e.Use(handler.RequestIDMiddleware())
+	e.Use(handler.TracingMiddleware())
+	e.Use(handler.RequestLogMiddleware(baseURL, slog.Default()))
+	e.Use(handler.CompressMiddleware())
+	e.Use(handler.RequestValidatorMiddleware(swagger, baseURL))
+
+	// setup repository
+	var repo handler.Repository
+	if *inMemory {
+		// ローカル開発用。管理 API・不審セーブの解析など DB にしかない機能は無効になる
+		slog.Warn("serving from an in-memory repository; nothing is persisted")
+		repo = repository.NewMemory()
+	} else {
+		db, err := connectDB()
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = db.Close()
+		}()
 
-	// connect to database
-	db, err := sqlx.Connect("mysql", config.MySQL().FormatDSN())
+		// migrate tables（無効ならデプロイ前に migrate up を済ませておく）
+		if *migrateOnStart {
+			if err := migration.MigrateTables(db); err != nil {
+				return fmt.Errorf("migrate tables: %w", err)
+			}
+		} else if err := checkMigrated(ctx, db); err != nil {
+			return err
+		}
+		repo = repository.New(db)
+	}
+
+	// setup cache backend
+	cacheBackend, err := newCacheBackend(ctx)
 	if err != nil {
-		e.Logger.Fatal(err)
+		return fmt.Errorf("setup cache backend: %w", err)
+	}
+	slog.Info("cache backend ready", "backend", cacheBackend.Name())
+
+	adminTokens, err := handler.ParseAdminTokens(config.AdminTokens())
+	if err != nil {
+		return fmt.Errorf("invalid ADMIN_TOKENS: %w", err)
 	}
-	defer func() {
-		_ = db.Close()
-	}()
 
-	// migrate tables
-	if err := migration.MigrateTables(db.DB); err != nil {
-		e.Logger.Fatal(err)
+	bypassPolicy, err := handler.ParseSignatureBypassPolicy(baseURL,
+		config.SignatureBypassRoutes(), config.SignatureBypassCIDRs(), config.TrustedProxyCIDRs())
+	if err != nil {
+		return fmt.Errorf("invalid signature bypass restriction: %w", err)
+	}
+	if config.GetSignatureBypassToken() != "" {
+		slog.Warn("signature bypass token enabled",
+			"routes", config.SignatureBypassRoutes(), "cidrs", config.SignatureBypassCIDRs())
+		// リバースプロキシの後ろでは接続元がすべてプロキシになり、送信元の制限が効かない
+		if config.SignatureBypassCIDRs() != "" && config.TrustedProxyCIDRs() == "" {
+			slog.Warn("signature bypass CIDRs are matched against the connecting address; set TRUSTED_PROXY_CIDRS behind a reverse proxy",
+				"cidrs", config.SignatureBypassCIDRs())
+		}
 	}
 
-	// setup repository
-	repo := repository.New(db)
+	saveSchemaMode, err := domain.ParseSaveSchemaMode(config.SaveSchemaMode())
+	if err != nil {
+		return fmt.Errorf("invalid SAVE_SCHEMA_MODE: %w", err)
+	}
 
 	// setup routes
-	h := handler.New(repo)
+	h := handler.New(repo,
+		handler.WithCacheBackend(cacheBackend),
+		handler.WithAdminTokens(adminTokens),
+		handler.WithSignatureBypassPolicy(bypassPolicy),
+		handler.WithRejectBannedSaves(config.RejectBannedSaves()),
+		handler.WithSaveSchemaMode(saveSchemaMode),
+	)
 	openapi.RegisterHandlersWithBaseURL(e, h, baseURL)
+	if h.RegisterAdminRoutes(e.Group(baseURL + "/admin")) {
+		slog.Info("admin API enabled", "tokens", len(adminTokens))
+	}
+
+	// 重い集計のキャッシュを温め、済むまでは readiness を 503 にする
+	h.StartWarmer(ctx)
+	e.GET(baseURL+"/ready", h.Ready)
+
+	// 新しいセーブの推移を定期的に解析し、不審なものを anomaly_flags に記録する
+	if interval := config.AnomalyScanInterval(); h.StartAnomalyAnalyzer(ctx, interval) {
+		slog.Info("anomaly analyzer started", "interval", interval)
+	}
 
 	// expose OpenAPI and Swagger UI
 	e.GET(baseURL+"/openapi.yaml", func(c echo.Context) error {
@@ -101,5 +230,32 @@ func main() {
 		return c.HTML(http.StatusOK, html)
 	})
 
-	e.Logger.Fatal(e.Start(config.AppAddr()))
+	slog.Info("starting server", "addr", config.AppAddr())
+	if err := e.Start(config.AppAddr()); err != nil {
+		return fmt.Errorf("server stopped: %w", err)
+	}
+	return nil
+}
+
+// newCacheBackend は CACHE_BACKEND に応じたキャッシュの置き場所を返す。
+func newCacheBackend(ctx context.Context) (cache.Backend, error) {
+	switch backend := config.CacheBackend(); backend {
+	case "", "memory":
+		return cache.Backend{}, nil
+	case "redis":
+		client := redis.NewClient(&redis.Options{
+			Addr:     config.RedisAddr(),
+			Password: config.RedisPassword(),
+			DB:       config.RedisDB(),
+		})
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+		if err := client.Ping(ctx).Err(); err != nil {
+			_ = client.Close()
+			return cache.Backend{}, fmt.Errorf("ping redis %s: %w", config.RedisAddr(), err)
+		}
+		return cache.Backend{Redis: client, Prefix: config.CacheKeyPrefix()}, nil
+	default:
+		return cache.Backend{}, fmt.Errorf("unknown CACHE_BACKEND %q", backend)
+	}
 }
@@ -4,11 +4,31 @@
 package models
 
 import (
+	"encoding/json"
+	"fmt"
 	"time"
 
 	openapi_types "github.com/oapi-codegen/runtime/types"
 )
 
+// Defines values for ErrorCode.
+const (
+	DUPLICATESAVE    ErrorCode = "DUPLICATE_SAVE"
+	FORBIDDEN        ErrorCode = "FORBIDDEN"
+	INTERNALERROR    ErrorCode = "INTERNAL_ERROR"
+	INVALIDPARAMETER ErrorCode = "INVALID_PARAMETER"
+	INVALIDSAVEDATA  ErrorCode = "INVALID_SAVE_DATA"
+	INVALIDSIGNATURE ErrorCode = "INVALID_SIGNATURE"
+	INVALIDUSERID    ErrorCode = "INVALID_USER_ID"
+	METHODNOTALLOWED ErrorCode = "METHOD_NOT_ALLOWED"
+	MISSINGPARAMETER ErrorCode = "MISSING_PARAMETER"
+	NOTFOUND         ErrorCode = "NOT_FOUND"
+	PAYLOADTOOLARGE  ErrorCode = "PAYLOAD_TOO_LARGE"
+	REQUESTREJECTED  ErrorCode = "REQUEST_REJECTED"
+	UNAUTHORIZED     ErrorCode = "UNAUTHORIZED"
+	USERBANNED       ErrorCode = "USER_BANNED"
+)
+
 // Defines values for GetRankingsParamsSort.
 const (
 	Fever           GetRankingsParamsSort = "fever"
@@ -69,6 +89,18 @@ type CreditAllDistributionResponse struct {
 	Users int64 `json:"users"`
 }
 
+// Error エラー応答。`code` はクライアントが分岐に使う安定した識別子で、`message` は人間向けの説明です。 内部エラーの詳細（SQL エラーなど）は含めません。
+type Error struct {
+	Code ErrorCode `json:"code"`
+
+	// Details code ごとの補足情報（例 MISSING_PARAMETER では不足しているパラメータ名）
+	Details *map[string]interface{} `json:"details,omitempty"`
+	Message string                  `json:"message"`
+}
+
+// ErrorCode defines model for Error.Code.
+type ErrorCode string
+
 // GameData defines model for GameData.
 type GameData struct {
 	RMedal           *int       `db:"R_medal" json:"R_medal,omitempty"`
@@ -120,6 +152,15 @@ type RankingEntry struct {
 	Value     *int64     `json:"value,omitempty"`
 }
 
+// RankingV4 defines model for RankingV4.
+type RankingV4 struct {
+	// Entries 値の大きい順（同値は先にその値に達したユーザーが上位）
+	Entries []RankingEntry `json:"entries"`
+
+	// Metric 指標名
+	Metric string `json:"metric"`
+}
+
 // SaveActivityBucket defines model for SaveActivityBucket.
 type SaveActivityBucket struct {
 	HourStart   *time.Time `json:"hour_start,omitempty"`
@@ -132,94 +173,95 @@ type SaveActivityResponse struct {
 	Buckets *[]SaveActivityBucket `json:"buckets,omitempty"`
 }
 
-// SaveDataV2 defines model for SaveDataV2.
+// SaveDataV2 サーバーが知らないトップレベルのキーは保存時のまま返します（additionalProperties）
 type SaveDataV2 struct {
-	BallChain         *int              `db:"ball_chain" json:"ball_chain,omitempty"`
-	BallGet           *int64            `db:"ball_get" json:"ball_get,omitempty"`
-	Bbox              *float64          `db:"blackbox" json:"bbox,omitempty"`
-	BboxAll           *float64          `db:"blackbox_total" json:"bbox_all,omitempty"`
-	BboxShop          *float64          `db:"bbox_shop" json:"bbox_shop,omitempty"`
-	BboxUsedFerlot    *float64          `db:"bbox_used_ferlot" json:"bbox_used_ferlot,omitempty"`
-	BstpRwd           *int64            `db:"bstp_rwd" json:"bstp_rwd,omitempty"`
-	BstpStep          *int64            `db:"bstp_step" json:"bstp_step,omitempty"`
-	BuyShbi           *int              `db:"buy_shbi" json:"buy_shbi,omitempty"`
-	BuyTotal          *int              `db:"buy_total" json:"buy_total,omitempty"`
-	CpmMax            *float64          `db:"cpm_max" json:"cpm_max,omitempty"`
-	Credit            *string           `db:"credit" json:"credit,omitempty"`
-	CreditAll         *string           `db:"credit_all" json:"credit_all,omitempty"`
-	DcBallChain       *map[string]int   `json:"dc_ball_chain,omitempty" table:"save_data_v2_ball_chain"`
-	DcBallGet         *map[string]int64 `json:"dc_ball_get,omitempty" table:"save_data_v2_ball_get"`
-	DcBboxShop        *map[string]int   `json:"dc_bbox_shop,omitempty" table:"save_data_v2_bbox_shop"`
-	DcFerlotItem      *map[string]int   `json:"dc_ferlot_item,omitempty" table:"save_data_v2_ferlot_item"`
-	DcFerlotUseitem   *map[string]int   `json:"dc_ferlot_useitem,omitempty" table:"save_data_v2_ferlot_useitem"`
-	DcMedalGet        *map[string]int   `json:"dc_medal_get,omitempty" table:"save_data_v2_medal_get"`
-	DcPalballGet      *map[string]int   `json:"dc_palball_get,omitempty" table:"save_data_v2_palball_get"`
-	DcPalballJp       *map[string]int   `json:"dc_palball_jp,omitempty" table:"save_data_v2_palball_jp"`
-	FerballGet        *float64          `db:"ferball_get" json:"ferball_get,omitempty"`
-	FerlotAct         *float64          `db:"ferlot_act" json:"ferlot_act,omitempty"`
-	FerlotChance      *float64          `db:"ferlot_chance" json:"ferlot_chance,omitempty"`
-	FerlotHit         *float64          `db:"ferlot_hit" json:"ferlot_hit,omitempty"`
-	FerlotLines       *float64          `db:"ferlot_lines" json:"ferlot_lines,omitempty"`
-	FerlotLose        *float64          `db:"ferlot_lose" json:"ferlot_lose,omitempty"`
-	FerlotLot         *float64          `db:"ferlot_lot" json:"ferlot_lot,omitempty"`
-	FerlotMaxln       *float64          `db:"ferlot_maxln" json:"ferlot_maxln,omitempty"`
-	Firstboot         *string           `json:"firstboot,omitempty"`
-	GetMedaltower     *float64          `db:"get_medaltower" json:"get_medaltower,omitempty"`
-	HideRecord        *int              `db:"hide_record" json:"hide_record,omitempty"`
-	JackGet           *int64            `db:"jack_get" json:"jack_get,omitempty"`
-	JackStartmax      *int64            `db:"jack_startmax" json:"jack_startmax,omitempty"`
-	JackTotalmax      *int              `db:"jack_totalmax" json:"jack_totalmax,omitempty"`
-	JackTotalmaxV2    *int64            `db:"jack_totalmax_v2" json:"jack_totalmax_v2,omitempty"`
-	JackfrGetAll      *float64          `db:"jackfr_get_all" json:"jackfr_get_all,omitempty"`
-	JackfrGetT0       *float64          `db:"jackfr_get_t0" json:"jackfr_get_t0,omitempty"`
-	JackfrGetT1       *float64          `db:"jackfr_get_t1" json:"jackfr_get_t1,omitempty"`
-	JackfrGetT2       *float64          `db:"jackfr_get_t2" json:"jackfr_get_t2,omitempty"`
-	JackfrGetT3       *float64          `db:"jackfr_get_t3" json:"jackfr_get_t3,omitempty"`
-	JackfrGetT4       *float64          `db:"jackfr_get_t4" json:"jackfr_get_t4,omitempty"`
-	JackfrStartmax    *int64            `db:"jackfr_startmax" json:"jackfr_startmax,omitempty"`
-	JackfrTotalmax    *int64            `db:"jackfr_totalmax" json:"jackfr_totalmax,omitempty"`
-	JackspGetAll      *float64          `db:"jacksp_get_all" json:"jacksp_get_all,omitempty"`
-	JackspGetT0       *float64          `db:"jacksp_get_t0" json:"jacksp_get_t0,omitempty"`
-	JackspGetT1       *float64          `db:"jacksp_get_t1" json:"jacksp_get_t1,omitempty"`
-	JackspGetT2       *float64          `db:"jacksp_get_t2" json:"jacksp_get_t2,omitempty"`
-	JackspGetT3       *float64          `db:"jacksp_get_t3" json:"jacksp_get_t3,omitempty"`
-	JackspGetT4       *float64          `db:"jacksp_get_t4" json:"jacksp_get_t4,omitempty"`
-	JackspStartmax    *float64          `db:"jacksp_startmax" json:"jacksp_startmax,omitempty"`
-	JackspTotalmax    *float64          `db:"jacksp_totalmax" json:"jacksp_totalmax,omitempty"`
-	LAchieve          *[]string         `json:"l_achieve,omitempty" table:"save_data_v2_achievements"`
-	LPerks            *[]int            `json:"l_perks,omitempty" table:"save_data_v2_perks"`
-	LPerksCredit      *[]int64          `json:"l_perks_credit,omitempty" table:"save_data_v2_perks_credit"`
-	LTotems           *[]int            `json:"l_totems,omitempty" table:"save_data_v2_totems"`
-	LTotemsCredit     *[]int64          `json:"l_totems_credit,omitempty" table:"save_data_v2_totems_credit"`
-	LTotemsSet        *[]int            `json:"l_totems_set,omitempty" table:"save_data_v2_totems_placement"`
-	Lastsave          *string           `json:"lastsave,omitempty"`
-	Legacy            *int              `db:"legacy" json:"legacy,omitempty"`
-	MedalGet          *int64            `db:"medal_get" json:"medal_get,omitempty"`
-	MedalIn           *int              `db:"medal_in" json:"medal_in,omitempty"`
-	PalballGet        *float64          `db:"palball_get" json:"palball_get,omitempty"`
-	PallotLotT0       *float64          `db:"pallot_lot_t0" json:"pallot_lot_t0,omitempty"`
-	PallotLotT1       *float64          `db:"pallot_lot_t1" json:"pallot_lot_t1,omitempty"`
-	PallotLotT2       *float64          `db:"pallot_lot_t2" json:"pallot_lot_t2,omitempty"`
-	PallotLotT3       *float64          `db:"pallot_lot_t3" json:"pallot_lot_t3,omitempty"`
-	PallotLotT4       *float64          `db:"pallot_lot_t4" json:"pallot_lot_t4,omitempty"`
-	Playtime          *int64            `json:"playtime,omitempty"`
-	RmshbiGet         *int              `db:"rmshbi_get" json:"rmshbi_get,omitempty"`
-	SlotGetfev        *int64            `db:"slot_getfev" json:"slot_getfev,omitempty"`
-	SlotHit           *int64            `db:"slot_hit" json:"slot_hit,omitempty"`
-	SlotStart         *int64            `db:"slot_start" json:"slot_start,omitempty"`
-	SlotStartfev      *int64            `db:"slot_startfev" json:"slot_startfev,omitempty"`
-	Sp                *float64          `db:"skill_point" json:"sp,omitempty"`
-	SpUse             *int64            `db:"sp_use" json:"sp_use,omitempty"`
-	SqrGet            *int64            `db:"sqr_get" json:"sqr_get,omitempty"`
-	SqrStep           *int64            `db:"sqr_step" json:"sqr_step,omitempty"`
-	TaskCnt           *float64          `db:"task_cnt" json:"task_cnt,omitempty"`
-	TotemAltars       *int              `db:"totem_altars" json:"totem_altars,omitempty"`
-	TotemAltarsCredit *int64            `db:"totem_altars_credit" json:"totem_altars_credit,omitempty"`
-	UltCombomax       *int              `db:"ult_combomax" json:"ult_combomax,omitempty"`
-	UltGet            *int              `db:"ult_get" json:"ult_get,omitempty"`
-	UltTotalmax       *int              `db:"ult_totalmax" json:"ult_totalmax,omitempty"`
-	UltTotalmaxV2     *int64            `db:"ult_totalmax_v2" json:"ult_totalmax_v2,omitempty"`
-	Version           *int              `db:"version" json:"version,omitempty"`
+	BallChain            *int                   `db:"ball_chain" json:"ball_chain,omitempty"`
+	BallGet              *int64                 `db:"ball_get" json:"ball_get,omitempty"`
+	Bbox                 *float64               `db:"blackbox" json:"bbox,omitempty"`
+	BboxAll              *float64               `db:"blackbox_total" json:"bbox_all,omitempty"`
+	BboxShop             *float64               `db:"bbox_shop" json:"bbox_shop,omitempty"`
+	BboxUsedFerlot       *float64               `db:"bbox_used_ferlot" json:"bbox_used_ferlot,omitempty"`
+	BstpRwd              *int64                 `db:"bstp_rwd" json:"bstp_rwd,omitempty"`
+	BstpStep             *int64                 `db:"bstp_step" json:"bstp_step,omitempty"`
+	BuyShbi              *int                   `db:"buy_shbi" json:"buy_shbi,omitempty"`
+	BuyTotal             *int                   `db:"buy_total" json:"buy_total,omitempty"`
+	CpmMax               *float64               `db:"cpm_max" json:"cpm_max,omitempty"`
+	Credit               *string                `db:"credit" json:"credit,omitempty"`
+	CreditAll            *string                `db:"credit_all" json:"credit_all,omitempty"`
+	DcBallChain          *map[string]int        `json:"dc_ball_chain,omitempty" table:"save_data_v2_ball_chain"`
+	DcBallGet            *map[string]int64      `json:"dc_ball_get,omitempty" table:"save_data_v2_ball_get"`
+	DcBboxShop           *map[string]int        `json:"dc_bbox_shop,omitempty" table:"save_data_v2_bbox_shop"`
+	DcFerlotItem         *map[string]int        `json:"dc_ferlot_item,omitempty" table:"save_data_v2_ferlot_item"`
+	DcFerlotUseitem      *map[string]int        `json:"dc_ferlot_useitem,omitempty" table:"save_data_v2_ferlot_useitem"`
+	DcMedalGet           *map[string]int        `json:"dc_medal_get,omitempty" table:"save_data_v2_medal_get"`
+	DcPalballGet         *map[string]int        `json:"dc_palball_get,omitempty" table:"save_data_v2_palball_get"`
+	DcPalballJp          *map[string]int        `json:"dc_palball_jp,omitempty" table:"save_data_v2_palball_jp"`
+	FerballGet           *float64               `db:"ferball_get" json:"ferball_get,omitempty"`
+	FerlotAct            *float64               `db:"ferlot_act" json:"ferlot_act,omitempty"`
+	FerlotChance         *float64               `db:"ferlot_chance" json:"ferlot_chance,omitempty"`
+	FerlotHit            *float64               `db:"ferlot_hit" json:"ferlot_hit,omitempty"`
+	FerlotLines          *float64               `db:"ferlot_lines" json:"ferlot_lines,omitempty"`
+	FerlotLose           *float64               `db:"ferlot_lose" json:"ferlot_lose,omitempty"`
+	FerlotLot            *float64               `db:"ferlot_lot" json:"ferlot_lot,omitempty"`
+	FerlotMaxln          *float64               `db:"ferlot_maxln" json:"ferlot_maxln,omitempty"`
+	Firstboot            *string                `json:"firstboot,omitempty"`
+	GetMedaltower        *float64               `db:"get_medaltower" json:"get_medaltower,omitempty"`
+	HideRecord           *int                   `db:"hide_record" json:"hide_record,omitempty"`
+	JackGet              *int64                 `db:"jack_get" json:"jack_get,omitempty"`
+	JackStartmax         *int64                 `db:"jack_startmax" json:"jack_startmax,omitempty"`
+	JackTotalmax         *int                   `db:"jack_totalmax" json:"jack_totalmax,omitempty"`
+	JackTotalmaxV2       *int64                 `db:"jack_totalmax_v2" json:"jack_totalmax_v2,omitempty"`
+	JackfrGetAll         *float64               `db:"jackfr_get_all" json:"jackfr_get_all,omitempty"`
+	JackfrGetT0          *float64               `db:"jackfr_get_t0" json:"jackfr_get_t0,omitempty"`
+	JackfrGetT1          *float64               `db:"jackfr_get_t1" json:"jackfr_get_t1,omitempty"`
+	JackfrGetT2          *float64               `db:"jackfr_get_t2" json:"jackfr_get_t2,omitempty"`
+	JackfrGetT3          *float64               `db:"jackfr_get_t3" json:"jackfr_get_t3,omitempty"`
+	JackfrGetT4          *float64               `db:"jackfr_get_t4" json:"jackfr_get_t4,omitempty"`
+	JackfrStartmax       *int64                 `db:"jackfr_startmax" json:"jackfr_startmax,omitempty"`
+	JackfrTotalmax       *int64                 `db:"jackfr_totalmax" json:"jackfr_totalmax,omitempty"`
+	JackspGetAll         *float64               `db:"jacksp_get_all" json:"jacksp_get_all,omitempty"`
+	JackspGetT0          *float64               `db:"jacksp_get_t0" json:"jacksp_get_t0,omitempty"`
+	JackspGetT1          *float64               `db:"jacksp_get_t1" json:"jacksp_get_t1,omitempty"`
+	JackspGetT2          *float64               `db:"jacksp_get_t2" json:"jacksp_get_t2,omitempty"`
+	JackspGetT3          *float64               `db:"jacksp_get_t3" json:"jacksp_get_t3,omitempty"`
+	JackspGetT4          *float64               `db:"jacksp_get_t4" json:"jacksp_get_t4,omitempty"`
+	JackspStartmax       *float64               `db:"jacksp_startmax" json:"jacksp_startmax,omitempty"`
+	JackspTotalmax       *float64               `db:"jacksp_totalmax" json:"jacksp_totalmax,omitempty"`
+	LAchieve             *[]string              `json:"l_achieve,omitempty" table:"save_data_v2_achievements"`
+	LPerks               *[]int                 `json:"l_perks,omitempty" table:"save_data_v2_perks"`
+	LPerksCredit         *[]int64               `json:"l_perks_credit,omitempty" table:"save_data_v2_perks_credit"`
+	LTotems              *[]int                 `json:"l_totems,omitempty" table:"save_data_v2_totems"`
+	LTotemsCredit        *[]int64               `json:"l_totems_credit,omitempty" table:"save_data_v2_totems_credit"`
+	LTotemsSet           *[]int                 `json:"l_totems_set,omitempty" table:"save_data_v2_totems_placement"`
+	Lastsave             *string                `json:"lastsave,omitempty"`
+	Legacy               *int                   `db:"legacy" json:"legacy,omitempty"`
+	MedalGet             *int64                 `db:"medal_get" json:"medal_get,omitempty"`
+	MedalIn              *int                   `db:"medal_in" json:"medal_in,omitempty"`
+	PalballGet           *float64               `db:"palball_get" json:"palball_get,omitempty"`
+	PallotLotT0          *float64               `db:"pallot_lot_t0" json:"pallot_lot_t0,omitempty"`
+	PallotLotT1          *float64               `db:"pallot_lot_t1" json:"pallot_lot_t1,omitempty"`
+	PallotLotT2          *float64               `db:"pallot_lot_t2" json:"pallot_lot_t2,omitempty"`
+	PallotLotT3          *float64               `db:"pallot_lot_t3" json:"pallot_lot_t3,omitempty"`
+	PallotLotT4          *float64               `db:"pallot_lot_t4" json:"pallot_lot_t4,omitempty"`
+	Playtime             *int64                 `json:"playtime,omitempty"`
+	RmshbiGet            *int                   `db:"rmshbi_get" json:"rmshbi_get,omitempty"`
+	SlotGetfev           *int64                 `db:"slot_getfev" json:"slot_getfev,omitempty"`
+	SlotHit              *int64                 `db:"slot_hit" json:"slot_hit,omitempty"`
+	SlotStart            *int64                 `db:"slot_start" json:"slot_start,omitempty"`
+	SlotStartfev         *int64                 `db:"slot_startfev" json:"slot_startfev,omitempty"`
+	Sp                   *float64               `db:"skill_point" json:"sp,omitempty"`
+	SpUse                *int64                 `db:"sp_use" json:"sp_use,omitempty"`
+	SqrGet               *int64                 `db:"sqr_get" json:"sqr_get,omitempty"`
+	SqrStep              *int64                 `db:"sqr_step" json:"sqr_step,omitempty"`
+	TaskCnt              *float64               `db:"task_cnt" json:"task_cnt,omitempty"`
+	TotemAltars          *int                   `db:"totem_altars" json:"totem_altars,omitempty"`
+	TotemAltarsCredit    *int64                 `db:"totem_altars_credit" json:"totem_altars_credit,omitempty"`
+	UltCombomax          *int                   `db:"ult_combomax" json:"ult_combomax,omitempty"`
+	UltGet               *int                   `db:"ult_get" json:"ult_get,omitempty"`
+	UltTotalmax          *int                   `db:"ult_totalmax" json:"ult_totalmax,omitempty"`
+	UltTotalmaxV2        *int64                 `db:"ult_totalmax_v2" json:"ult_totalmax_v2,omitempty"`
+	Version              *int                   `db:"version" json:"version,omitempty"`
+	AdditionalProperties map[string]interface{} `json:"-"`
 }
 
 // SaveHistoryEntry defines model for SaveHistoryEntry.
@@ -307,6 +349,24 @@ type StatisticsV4 struct {
 	UltTotalmaxV2 *[]RankingEntry `json:"ult_totalmax_v2,omitempty"`
 }
 
+// BadRequest エラー応答。`code` はクライアントが分岐に使う安定した識別子で、`message` は人間向けの説明です。 内部エラーの詳細（SQL エラーなど）は含めません。
+type BadRequest = Error
+
+// Conflict エラー応答。`code` はクライアントが分岐に使う安定した識別子で、`message` は人間向けの説明です。 内部エラーの詳細（SQL エラーなど）は含めません。
+type Conflict = Error
+
+// Forbidden エラー応答。`code` はクライアントが分岐に使う安定した識別子で、`message` は人間向けの説明です。 内部エラーの詳細（SQL エラーなど）は含めません。
+type Forbidden = Error
+
+// InternalError エラー応答。`code` はクライアントが分岐に使う安定した識別子で、`message` は人間向けの説明です。 内部エラーの詳細（SQL エラーなど）は含めません。
+type InternalError = Error
+
+// NotFound エラー応答。`code` はクライアントが分岐に使う安定した識別子で、`message` は人間向けの説明です。 内部エラーの詳細（SQL エラーなど）は含めません。
+type NotFound = Error
+
+// Unauthorized エラー応答。`code` はクライアントが分岐に使う安定した識別子で、`message` は人間向けの説明です。 内部エラーの詳細（SQL エラーなど）は含めません。
+type Unauthorized = Error
+
 // GetDataParams defines parameters for GetData.
 type GetDataParams struct {
 	Version       int    `form:"version" json:"version"`
@@ -390,6 +450,12 @@ type GetV4DataVerifyParams struct {
 	Sig    string `form:"sig" json:"sig"`
 }
 
+// GetV4RankingsMetricParams defines parameters for GetV4RankingsMetric.
+type GetV4RankingsMetricParams struct {
+	// Limit 取得する件数（1〜1000）
+	Limit *int `form:"limit,omitempty" json:"limit,omitempty"`
+}
+
 // GetV4StatisticsMedalsTimeseriesParams defines parameters for GetV4StatisticsMedalsTimeseries.
 type GetV4StatisticsMedalsTimeseriesParams struct {
 	// Days 取得する日数（1〜180）
@@ -434,3 +500,1346 @@ type GetV4UsersUserIdSavesParams struct {
 	// Before この日時より前のセーブを取得（ISO8601）
 	Before *time.Time `form:"before,omitempty" json:"before,omitempty"`
 }
+
+// Getter for additional properties for SaveDataV2. Returns the specified
+// element and whether it was found
+func (a SaveDataV2) Get(fieldName string) (value interface{}, found bool) {
+	if a.AdditionalProperties != nil {
+		value, found = a.AdditionalProperties[fieldName]
+	}
+	return
+}
+
+// Setter for additional properties for SaveDataV2
+func (a *SaveDataV2) Set(fieldName string, value interface{}) {
+	if a.AdditionalProperties == nil {
+		a.AdditionalProperties = make(map[string]interface{})
+	}
+	a.AdditionalProperties[fieldName] = value
+}
+
+// Override default JSON handling for SaveDataV2 to handle AdditionalProperties
+func (a *SaveDataV2) UnmarshalJSON(b []byte) error {
+	object := make(map[string]json.RawMessage)
+	err := json.Unmarshal(b, &object)
+	if err != nil {
+		return err
+	}
+
+	if raw, found := object["ball_chain"]; found {
+		err = json.Unmarshal(raw, &a.BallChain)
+		if err != nil {
+			return fmt.Errorf("error reading 'ball_chain': %w", err)
+		}
+		delete(object, "ball_chain")
+	}
+
+	if raw, found := object["ball_get"]; found {
+		err = json.Unmarshal(raw, &a.BallGet)
+		if err != nil {
+			return fmt.Errorf("error reading 'ball_get': %w", err)
+		}
+		delete(object, "ball_get")
+	}
+
+	if raw, found := object["bbox"]; found {
+		err = json.Unmarshal(raw, &a.Bbox)
+		if err != nil {
+			return fmt.Errorf("error reading 'bbox': %w", err)
+		}
+		delete(object, "bbox")
+	}
+
+	if raw, found := object["bbox_all"]; found {
+		err = json.Unmarshal(raw, &a.BboxAll)
+		if err != nil {
+			return fmt.Errorf("error reading 'bbox_all': %w", err)
+		}
+		delete(object, "bbox_all")
+	}
+
+	if raw, found := object["bbox_shop"]; found {
+		err = json.Unmarshal(raw, &a.BboxShop)
+		if err != nil {
+			return fmt.Errorf("error reading 'bbox_shop': %w", err)
+		}
+		delete(object, "bbox_shop")
+	}
+
+	if raw, found := object["bbox_used_ferlot"]; found {
+		err = json.Unmarshal(raw, &a.BboxUsedFerlot)
+		if err != nil {
+			return fmt.Errorf("error reading 'bbox_used_ferlot': %w", err)
+		}
+		delete(object, "bbox_used_ferlot")
+	}
+
+	if raw, found := object["bstp_rwd"]; found {
+		err = json.Unmarshal(raw, &a.BstpRwd)
+		if err != nil {
+			return fmt.Errorf("error reading 'bstp_rwd': %w", err)
+		}
+		delete(object, "bstp_rwd")
+	}
+
+	if raw, found := object["bstp_step"]; found {
+		err = json.Unmarshal(raw, &a.BstpStep)
+		if err != nil {
+			return fmt.Errorf("error reading 'bstp_step': %w", err)
+		}
+		delete(object, "bstp_step")
+	}
+
+	if raw, found := object["buy_shbi"]; found {
+		err = json.Unmarshal(raw, &a.BuyShbi)
+		if err != nil {
+			return fmt.Errorf("error reading 'buy_shbi': %w", err)
+		}
+		delete(object, "buy_shbi")
+	}
+
+	if raw, found := object["buy_total"]; found {
+		err = json.Unmarshal(raw, &a.BuyTotal)
+		if err != nil {
+			return fmt.Errorf("error reading 'buy_total': %w", err)
+		}
+		delete(object, "buy_total")
+	}
+
+	if raw, found := object["cpm_max"]; found {
+		err = json.Unmarshal(raw, &a.CpmMax)
+		if err != nil {
+			return fmt.Errorf("error reading 'cpm_max': %w", err)
+		}
+		delete(object, "cpm_max")
+	}
+
+	if raw, found := object["credit"]; found {
+		err = json.Unmarshal(raw, &a.Credit)
+		if err != nil {
+			return fmt.Errorf("error reading 'credit': %w", err)
+		}
+		delete(object, "credit")
+	}
+
+	if raw, found := object["credit_all"]; found {
+		err = json.Unmarshal(raw, &a.CreditAll)
+		if err != nil {
+			return fmt.Errorf("error reading 'credit_all': %w", err)
+		}
+		delete(object, "credit_all")
+	}
+
+	if raw, found := object["dc_ball_chain"]; found {
+		err = json.Unmarshal(raw, &a.DcBallChain)
+		if err != nil {
+			return fmt.Errorf("error reading 'dc_ball_chain': %w", err)
+		}
+		delete(object, "dc_ball_chain")
+	}
+
+	if raw, found := object["dc_ball_get"]; found {
+		err = json.Unmarshal(raw, &a.DcBallGet)
+		if err != nil {
+			return fmt.Errorf("error reading 'dc_ball_get': %w", err)
+		}
+		delete(object, "dc_ball_get")
+	}
+
+	if raw, found := object["dc_bbox_shop"]; found {
+		err = json.Unmarshal(raw, &a.DcBboxShop)
+		if err != nil {
+			return fmt.Errorf("error reading 'dc_bbox_shop': %w", err)
+		}
+		delete(object, "dc_bbox_shop")
+	}
+
+	if raw, found := object["dc_ferlot_item"]; found {
+		err = json.Unmarshal(raw, &a.DcFerlotItem)
+		if err != nil {
+			return fmt.Errorf("error reading 'dc_ferlot_item': %w", err)
+		}
+		delete(object, "dc_ferlot_item")
+	}
+
+	if raw, found := object["dc_ferlot_useitem"]; found {
+		err = json.Unmarshal(raw, &a.DcFerlotUseitem)
+		if err != nil {
+			return fmt.Errorf("error reading 'dc_ferlot_useitem': %w", err)
+		}
+		delete(object, "dc_ferlot_useitem")
+	}
+
+	if raw, found := object["dc_medal_get"]; found {
+		err = json.Unmarshal(raw, &a.DcMedalGet)
+		if err != nil {
+			return fmt.Errorf("error reading 'dc_medal_get': %w", err)
+		}
+		delete(object, "dc_medal_get")
+	}
+
+	if raw, found := object["dc_palball_get"]; found {
+		err = json.Unmarshal(raw, &a.DcPalballGet)
+		if err != nil {
+			return fmt.Errorf("error reading 'dc_palball_get': %w", err)
+		}
+		delete(object, "dc_palball_get")
+	}
+
+	if raw, found := object["dc_palball_jp"]; found {
+		err = json.Unmarshal(raw, &a.DcPalballJp)
+		if err != nil {
+			return fmt.Errorf("error reading 'dc_palball_jp': %w", err)
+		}
+		delete(object, "dc_palball_jp")
+	}
+
+	if raw, found := object["ferball_get"]; found {
+		err = json.Unmarshal(raw, &a.FerballGet)
+		if err != nil {
+			return fmt.Errorf("error reading 'ferball_get': %w", err)
+		}
+		delete(object, "ferball_get")
+	}
+
+	if raw, found := object["ferlot_act"]; found {
+		err = json.Unmarshal(raw, &a.FerlotAct)
+		if err != nil {
+			return fmt.Errorf("error reading 'ferlot_act': %w", err)
+		}
+		delete(object, "ferlot_act")
+	}
+
+	if raw, found := object["ferlot_chance"]; found {
+		err = json.Unmarshal(raw, &a.FerlotChance)
+		if err != nil {
+			return fmt.Errorf("error reading 'ferlot_chance': %w", err)
+		}
+		delete(object, "ferlot_chance")
+	}
+
+	if raw, found := object["ferlot_hit"]; found {
+		err = json.Unmarshal(raw, &a.FerlotHit)
+		if err != nil {
+			return fmt.Errorf("error reading 'ferlot_hit': %w", err)
+		}
+		delete(object, "ferlot_hit")
+	}
+
+	if raw, found := object["ferlot_lines"]; found {
+		err = json.Unmarshal(raw, &a.FerlotLines)
+		if err != nil {
+			return fmt.Errorf("error reading 'ferlot_lines': %w", err)
+		}
+		delete(object, "ferlot_lines")
+	}
+
+	if raw, found := object["ferlot_lose"]; found {
+		err = json.Unmarshal(raw, &a.FerlotLose)
+		if err != nil {
+			return fmt.Errorf("error reading 'ferlot_lose': %w", err)
+		}
+		delete(object, "ferlot_lose")
+	}
+
+	if raw, found := object["ferlot_lot"]; found {
+		err = json.Unmarshal(raw, &a.FerlotLot)
+		if err != nil {
+			return fmt.Errorf("error reading 'ferlot_lot': %w", err)
+		}
+		delete(object, "ferlot_lot")
+	}
+
+	if raw, found := object["ferlot_maxln"]; found {
+		err = json.Unmarshal(raw, &a.FerlotMaxln)
+		if err != nil {
+			return fmt.Errorf("error reading 'ferlot_maxln': %w", err)
+		}
+		delete(object, "ferlot_maxln")
+	}
+
+	if raw, found := object["firstboot"]; found {
+		err = json.Unmarshal(raw, &a.Firstboot)
+		if err != nil {
+			return fmt.Errorf("error reading 'firstboot': %w", err)
+		}
+		delete(object, "firstboot")
+	}
+
+	if raw, found := object["get_medaltower"]; found {
+		err = json.Unmarshal(raw, &a.GetMedaltower)
+		if err != nil {
+			return fmt.Errorf("error reading 'get_medaltower': %w", err)
+		}
+		delete(object, "get_medaltower")
+	}
+
+	if raw, found := object["hide_record"]; found {
+		err = json.Unmarshal(raw, &a.HideRecord)
+		if err != nil {
+			return fmt.Errorf("error reading 'hide_record': %w", err)
+		}
+		delete(object, "hide_record")
+	}
+
+	if raw, found := object["jack_get"]; found {
+		err = json.Unmarshal(raw, &a.JackGet)
+		if err != nil {
+			return fmt.Errorf("error reading 'jack_get': %w", err)
+		}
+		delete(object, "jack_get")
+	}
+
+	if raw, found := object["jack_startmax"]; found {
+		err = json.Unmarshal(raw, &a.JackStartmax)
+		if err != nil {
+			return fmt.Errorf("error reading 'jack_startmax': %w", err)
+		}
+		delete(object, "jack_startmax")
+	}
+
+	if raw, found := object["jack_totalmax"]; found {
+		err = json.Unmarshal(raw, &a.JackTotalmax)
+		if err != nil {
+			return fmt.Errorf("error reading 'jack_totalmax': %w", err)
+		}
+		delete(object, "jack_totalmax")
+	}
+
+	if raw, found := object["jack_totalmax_v2"]; found {
+		err = json.Unmarshal(raw, &a.JackTotalmaxV2)
+		if err != nil {
+			return fmt.Errorf("error reading 'jack_totalmax_v2': %w", err)
+		}
+		delete(object, "jack_totalmax_v2")
+	}
+
+	if raw, found := object["jackfr_get_all"]; found {
+		err = json.Unmarshal(raw, &a.JackfrGetAll)
+		if err != nil {
+			return fmt.Errorf("error reading 'jackfr_get_all': %w", err)
+		}
+		delete(object, "jackfr_get_all")
+	}
+
+	if raw, found := object["jackfr_get_t0"]; found {
+		err = json.Unmarshal(raw, &a.JackfrGetT0)
+		if err != nil {
+			return fmt.Errorf("error reading 'jackfr_get_t0': %w", err)
+		}
+		delete(object, "jackfr_get_t0")
+	}
+
+	if raw, found := object["jackfr_get_t1"]; found {
+		err = json.Unmarshal(raw, &a.JackfrGetT1)
+		if err != nil {
+			return fmt.Errorf("error reading 'jackfr_get_t1': %w", err)
+		}
+		delete(object, "jackfr_get_t1")
+	}
+
+	if raw, found := object["jackfr_get_t2"]; found {
+		err = json.Unmarshal(raw, &a.JackfrGetT2)
+		if err != nil {
+			return fmt.Errorf("error reading 'jackfr_get_t2': %w", err)
+		}
+		delete(object, "jackfr_get_t2")
+	}
+
+	if raw, found := object["jackfr_get_t3"]; found {
+		err = json.Unmarshal(raw, &a.JackfrGetT3)
+		if err != nil {
+			return fmt.Errorf("error reading 'jackfr_get_t3': %w", err)
+		}
+		delete(object, "jackfr_get_t3")
+	}
+
+	if raw, found := object["jackfr_get_t4"]; found {
+		err = json.Unmarshal(raw, &a.JackfrGetT4)
+		if err != nil {
+			return fmt.Errorf("error reading 'jackfr_get_t4': %w", err)
+		}
+		delete(object, "jackfr_get_t4")
+	}
+
+	if raw, found := object["jackfr_startmax"]; found {
+		err = json.Unmarshal(raw, &a.JackfrStartmax)
+		if err != nil {
+			return fmt.Errorf("error reading 'jackfr_startmax': %w", err)
+		}
+		delete(object, "jackfr_startmax")
+	}
+
+	if raw, found := object["jackfr_totalmax"]; found {
+		err = json.Unmarshal(raw, &a.JackfrTotalmax)
+		if err != nil {
+			return fmt.Errorf("error reading 'jackfr_totalmax': %w", err)
+		}
+		delete(object, "jackfr_totalmax")
+	}
+
+	if raw, found := object["jacksp_get_all"]; found {
+		err = json.Unmarshal(raw, &a.JackspGetAll)
+		if err != nil {
+			return fmt.Errorf("error reading 'jacksp_get_all': %w", err)
+		}
+		delete(object, "jacksp_get_all")
+	}
+
+	if raw, found := object["jacksp_get_t0"]; found {
+		err = json.Unmarshal(raw, &a.JackspGetT0)
+		if err != nil {
+			return fmt.Errorf("error reading 'jacksp_get_t0': %w", err)
+		}
+		delete(object, "jacksp_get_t0")
+	}
+
+	if raw, found := object["jacksp_get_t1"]; found {
+		err = json.Unmarshal(raw, &a.JackspGetT1)
+		if err != nil {
+			return fmt.Errorf("error reading 'jacksp_get_t1': %w", err)
+		}
+		delete(object, "jacksp_get_t1")
+	}
+
+	if raw, found := object["jacksp_get_t2"]; found {
+		err = json.Unmarshal(raw, &a.JackspGetT2)
+		if err != nil {
+			return fmt.Errorf("error reading 'jacksp_get_t2': %w", err)
+		}
+		delete(object, "jacksp_get_t2")
+	}
+
+	if raw, found := object["jacksp_get_t3"]; found {
+		err = json.Unmarshal(raw, &a.JackspGetT3)
+		if err != nil {
+			return fmt.Errorf("error reading 'jacksp_get_t3': %w", err)
+		}
+		delete(object, "jacksp_get_t3")
+	}
+
+	if raw, found := object["jacksp_get_t4"]; found {
+		err = json.Unmarshal(raw, &a.JackspGetT4)
+		if err != nil {
+			return fmt.Errorf("error reading 'jacksp_get_t4': %w", err)
+		}
+		delete(object, "jacksp_get_t4")
+	}
+
+	if raw, found := object["jacksp_startmax"]; found {
+		err = json.Unmarshal(raw, &a.JackspStartmax)
+		if err != nil {
+			return fmt.Errorf("error reading 'jacksp_startmax': %w", err)
+		}
+		delete(object, "jacksp_startmax")
+	}
+
+	if raw, found := object["jacksp_totalmax"]; found {
+		err = json.Unmarshal(raw, &a.JackspTotalmax)
+		if err != nil {
+			return fmt.Errorf("error reading 'jacksp_totalmax': %w", err)
+		}
+		delete(object, "jacksp_totalmax")
+	}
+
+	if raw, found := object["l_achieve"]; found {
+		err = json.Unmarshal(raw, &a.LAchieve)
+		if err != nil {
+			return fmt.Errorf("error reading 'l_achieve': %w", err)
+		}
+		delete(object, "l_achieve")
+	}
+
+	if raw, found := object["l_perks"]; found {
+		err = json.Unmarshal(raw, &a.LPerks)
+		if err != nil {
+			return fmt.Errorf("error reading 'l_perks': %w", err)
+		}
+		delete(object, "l_perks")
+	}
+
+	if raw, found := object["l_perks_credit"]; found {
+		err = json.Unmarshal(raw, &a.LPerksCredit)
+		if err != nil {
+			return fmt.Errorf("error reading 'l_perks_credit': %w", err)
+		}
+		delete(object, "l_perks_credit")
+	}
+
+	if raw, found := object["l_totems"]; found {
+		err = json.Unmarshal(raw, &a.LTotems)
+		if err != nil {
+			return fmt.Errorf("error reading 'l_totems': %w", err)
+		}
+		delete(object, "l_totems")
+	}
+
+	if raw, found := object["l_totems_credit"]; found {
+		err = json.Unmarshal(raw, &a.LTotemsCredit)
+		if err != nil {
+			return fmt.Errorf("error reading 'l_totems_credit': %w", err)
+		}
+		delete(object, "l_totems_credit")
+	}
+
+	if raw, found := object["l_totems_set"]; found {
+		err = json.Unmarshal(raw, &a.LTotemsSet)
+		if err != nil {
+			return fmt.Errorf("error reading 'l_totems_set': %w", err)
+		}
+		delete(object, "l_totems_set")
+	}
+
+	if raw, found := object["lastsave"]; found {
+		err = json.Unmarshal(raw, &a.Lastsave)
+		if err != nil {
+			return fmt.Errorf("error reading 'lastsave': %w", err)
+		}
+		delete(object, "lastsave")
+	}
+
+	if raw, found := object["legacy"]; found {
+		err = json.Unmarshal(raw, &a.Legacy)
+		if err != nil {
+			return fmt.Errorf("error reading 'legacy': %w", err)
+		}
+		delete(object, "legacy")
+	}
+
+	if raw, found := object["medal_get"]; found {
+		err = json.Unmarshal(raw, &a.MedalGet)
+		if err != nil {
+			return fmt.Errorf("error reading 'medal_get': %w", err)
+		}
+		delete(object, "medal_get")
+	}
+
+	if raw, found := object["medal_in"]; found {
+		err = json.Unmarshal(raw, &a.MedalIn)
+		if err != nil {
+			return fmt.Errorf("error reading 'medal_in': %w", err)
+		}
+		delete(object, "medal_in")
+	}
+
+	if raw, found := object["palball_get"]; found {
+		err = json.Unmarshal(raw, &a.PalballGet)
+		if err != nil {
+			return fmt.Errorf("error reading 'palball_get': %w", err)
+		}
+		delete(object, "palball_get")
+	}
+
+	if raw, found := object["pallot_lot_t0"]; found {
+		err = json.Unmarshal(raw, &a.PallotLotT0)
+		if err != nil {
+			return fmt.Errorf("error reading 'pallot_lot_t0': %w", err)
+		}
+		delete(object, "pallot_lot_t0")
+	}
+
+	if raw, found := object["pallot_lot_t1"]; found {
+		err = json.Unmarshal(raw, &a.PallotLotT1)
+		if err != nil {
+			return fmt.Errorf("error reading 'pallot_lot_t1': %w", err)
+		}
+		delete(object, "pallot_lot_t1")
+	}
+
+	if raw, found := object["pallot_lot_t2"]; found {
+		err = json.Unmarshal(raw, &a.PallotLotT2)
+		if err != nil {
+			return fmt.Errorf("error reading 'pallot_lot_t2': %w", err)
+		}
+		delete(object, "pallot_lot_t2")
+	}
+
+	if raw, found := object["pallot_lot_t3"]; found {
+		err = json.Unmarshal(raw, &a.PallotLotT3)
+		if err != nil {
+			return fmt.Errorf("error reading 'pallot_lot_t3': %w", err)
+		}
+		delete(object, "pallot_lot_t3")
+	}
+
+	if raw, found := object["pallot_lot_t4"]; found {
+		err = json.Unmarshal(raw, &a.PallotLotT4)
+		if err != nil {
+			return fmt.Errorf("error reading 'pallot_lot_t4': %w", err)
+		}
+		delete(object, "pallot_lot_t4")
+	}
+
+	if raw, found := object["playtime"]; found {
+		err = json.Unmarshal(raw, &a.Playtime)
+		if err != nil {
+			return fmt.Errorf("error reading 'playtime': %w", err)
+		}
+		delete(object, "playtime")
+	}
+
+	if raw, found := object["rmshbi_get"]; found {
+		err = json.Unmarshal(raw, &a.RmshbiGet)
+		if err != nil {
+			return fmt.Errorf("error reading 'rmshbi_get': %w", err)
+		}
+		delete(object, "rmshbi_get")
+	}
+
+	if raw, found := object["slot_getfev"]; found {
+		err = json.Unmarshal(raw, &a.SlotGetfev)
+		if err != nil {
+			return fmt.Errorf("error reading 'slot_getfev': %w", err)
+		}
+		delete(object, "slot_getfev")
+	}
+
+	if raw, found := object["slot_hit"]; found {
+		err = json.Unmarshal(raw, &a.SlotHit)
+		if err != nil {
+			return fmt.Errorf("error reading 'slot_hit': %w", err)
+		}
+		delete(object, "slot_hit")
+	}
+
+	if raw, found := object["slot_start"]; found {
+		err = json.Unmarshal(raw, &a.SlotStart)
+		if err != nil {
+			return fmt.Errorf("error reading 'slot_start': %w", err)
+		}
+		delete(object, "slot_start")
+	}
+
+	if raw, found := object["slot_startfev"]; found {
+		err = json.Unmarshal(raw, &a.SlotStartfev)
+		if err != nil {
+			return fmt.Errorf("error reading 'slot_startfev': %w", err)
+		}
+		delete(object, "slot_startfev")
+	}
+
+	if raw, found := object["sp"]; found {
+		err = json.Unmarshal(raw, &a.Sp)
+		if err != nil {
+			return fmt.Errorf("error reading 'sp': %w", err)
+		}
+		delete(object, "sp")
+	}
+
+	if raw, found := object["sp_use"]; found {
+		err = json.Unmarshal(raw, &a.SpUse)
+		if err != nil {
+			return fmt.Errorf("error reading 'sp_use': %w", err)
+		}
+		delete(object, "sp_use")
+	}
+
+	if raw, found := object["sqr_get"]; found {
+		err = json.Unmarshal(raw, &a.SqrGet)
+		if err != nil {
+			return fmt.Errorf("error reading 'sqr_get': %w", err)
+		}
+		delete(object, "sqr_get")
+	}
+
+	if raw, found := object["sqr_step"]; found {
+		err = json.Unmarshal(raw, &a.SqrStep)
+		if err != nil {
+			return fmt.Errorf("error reading 'sqr_step': %w", err)
+		}
+		delete(object, "sqr_step")
+	}
+
+	if raw, found := object["task_cnt"]; found {
+		err = json.Unmarshal(raw, &a.TaskCnt)
+		if err != nil {
+			return fmt.Errorf("error reading 'task_cnt': %w", err)
+		}
+		delete(object, "task_cnt")
+	}
+
+	if raw, found := object["totem_altars"]; found {
+		err = json.Unmarshal(raw, &a.TotemAltars)
+		if err != nil {
+			return fmt.Errorf("error reading 'totem_altars': %w", err)
+		}
+		delete(object, "totem_altars")
+	}
+
+	if raw, found := object["totem_altars_credit"]; found {
+		err = json.Unmarshal(raw, &a.TotemAltarsCredit)
+		if err != nil {
+			return fmt.Errorf("error reading 'totem_altars_credit': %w", err)
+		}
+		delete(object, "totem_altars_credit")
+	}
+
+	if raw, found := object["ult_combomax"]; found {
+		err = json.Unmarshal(raw, &a.UltCombomax)
+		if err != nil {
+			return fmt.Errorf("error reading 'ult_combomax': %w", err)
+		}
+		delete(object, "ult_combomax")
+	}
+
+	if raw, found := object["ult_get"]; found {
+		err = json.Unmarshal(raw, &a.UltGet)
+		if err != nil {
+			return fmt.Errorf("error reading 'ult_get': %w", err)
+		}
+		delete(object, "ult_get")
+	}
+
+	if raw, found := object["ult_totalmax"]; found {
+		err = json.Unmarshal(raw, &a.UltTotalmax)
+		if err != nil {
+			return fmt.Errorf("error reading 'ult_totalmax': %w", err)
+		}
+		delete(object, "ult_totalmax")
+	}
+
+	if raw, found := object["ult_totalmax_v2"]; found {
+		err = json.Unmarshal(raw, &a.UltTotalmaxV2)
+		if err != nil {
+			return fmt.Errorf("error reading 'ult_totalmax_v2': %w", err)
+		}
+		delete(object, "ult_totalmax_v2")
+	}
+
+	if raw, found := object["version"]; found {
+		err = json.Unmarshal(raw, &a.Version)
+		if err != nil {
+			return fmt.Errorf("error reading 'version': %w", err)
+		}
+		delete(object, "version")
+	}
+
+	if len(object) != 0 {
+		a.AdditionalProperties = make(map[string]interface{})
+		for fieldName, fieldBuf := range object {
+			var fieldVal interface{}
+			err := json.Unmarshal(fieldBuf, &fieldVal)
+			if err != nil {
+				return fmt.Errorf("error unmarshaling field %s: %w", fieldName, err)
+			}
+			a.AdditionalProperties[fieldName] = fieldVal
+		}
+	}
+	return nil
+}
+
+// Override default JSON handling for SaveDataV2 to handle AdditionalProperties
+func (a SaveDataV2) MarshalJSON() ([]byte, error) {
+	var err error
+	object := make(map[string]json.RawMessage)
+
+	if a.BallChain != nil {
+		object["ball_chain"], err = json.Marshal(a.BallChain)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'ball_chain': %w", err)
+		}
+	}
+
+	if a.BallGet != nil {
+		object["ball_get"], err = json.Marshal(a.BallGet)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'ball_get': %w", err)
+		}
+	}
+
+	if a.Bbox != nil {
+		object["bbox"], err = json.Marshal(a.Bbox)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'bbox': %w", err)
+		}
+	}
+
+	if a.BboxAll != nil {
+		object["bbox_all"], err = json.Marshal(a.BboxAll)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'bbox_all': %w", err)
+		}
+	}
+
+	if a.BboxShop != nil {
+		object["bbox_shop"], err = json.Marshal(a.BboxShop)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'bbox_shop': %w", err)
+		}
+	}
+
+	if a.BboxUsedFerlot != nil {
+		object["bbox_used_ferlot"], err = json.Marshal(a.BboxUsedFerlot)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'bbox_used_ferlot': %w", err)
+		}
+	}
+
+	if a.BstpRwd != nil {
+		object["bstp_rwd"], err = json.Marshal(a.BstpRwd)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'bstp_rwd': %w", err)
+		}
+	}
+
+	if a.BstpStep != nil {
+		object["bstp_step"], err = json.Marshal(a.BstpStep)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'bstp_step': %w", err)
+		}
+	}
+
+	if a.BuyShbi != nil {
+		object["buy_shbi"], err = json.Marshal(a.BuyShbi)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'buy_shbi': %w", err)
+		}
+	}
+
+	if a.BuyTotal != nil {
+		object["buy_total"], err = json.Marshal(a.BuyTotal)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'buy_total': %w", err)
+		}
+	}
+
+	if a.CpmMax != nil {
+		object["cpm_max"], err = json.Marshal(a.CpmMax)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'cpm_max': %w", err)
+		}
+	}
+
+	if a.Credit != nil {
+		object["credit"], err = json.Marshal(a.Credit)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'credit': %w", err)
+		}
+	}
+
+	if a.CreditAll != nil {
+		object["credit_all"], err = json.Marshal(a.CreditAll)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'credit_all': %w", err)
+		}
+	}
+
+	if a.DcBallChain != nil {
+		object["dc_ball_chain"], err = json.Marshal(a.DcBallChain)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'dc_ball_chain': %w", err)
+		}
+	}
+
+	if a.DcBallGet != nil {
+		object["dc_ball_get"], err = json.Marshal(a.DcBallGet)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'dc_ball_get': %w", err)
+		}
+	}
+
+	if a.DcBboxShop != nil {
+		object["dc_bbox_shop"], err = json.Marshal(a.DcBboxShop)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'dc_bbox_shop': %w", err)
+		}
+	}
+
+	if a.DcFerlotItem != nil {
+		object["dc_ferlot_item"], err = json.Marshal(a.DcFerlotItem)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'dc_ferlot_item': %w", err)
+		}
+	}
+
+	if a.DcFerlotUseitem != nil {
+		object["dc_ferlot_useitem"], err = json.Marshal(a.DcFerlotUseitem)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'dc_ferlot_useitem': %w", err)
+		}
+	}
+
+	if a.DcMedalGet != nil {
+		object["dc_medal_get"], err = json.Marshal(a.DcMedalGet)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'dc_medal_get': %w", err)
+		}
+	}
+
+	if a.DcPalballGet != nil {
+		object["dc_palball_get"], err = json.Marshal(a.DcPalballGet)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'dc_palball_get': %w", err)
+		}
+	}
+
+	if a.DcPalballJp != nil {
+		object["dc_palball_jp"], err = json.Marshal(a.DcPalballJp)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'dc_palball_jp': %w", err)
+		}
+	}
+
+	if a.FerballGet != nil {
+		object["ferball_get"], err = json.Marshal(a.FerballGet)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'ferball_get': %w", err)
+		}
+	}
+
+	if a.FerlotAct != nil {
+		object["ferlot_act"], err = json.Marshal(a.FerlotAct)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'ferlot_act': %w", err)
+		}
+	}
+
+	if a.FerlotChance != nil {
+		object["ferlot_chance"], err = json.Marshal(a.FerlotChance)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'ferlot_chance': %w", err)
+		}
+	}
+
+	if a.FerlotHit != nil {
+		object["ferlot_hit"], err = json.Marshal(a.FerlotHit)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'ferlot_hit': %w", err)
+		}
+	}
+
+	if a.FerlotLines != nil {
+		object["ferlot_lines"], err = json.Marshal(a.FerlotLines)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'ferlot_lines': %w", err)
+		}
+	}
+
+	if a.FerlotLose != nil {
+		object["ferlot_lose"], err = json.Marshal(a.FerlotLose)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'ferlot_lose': %w", err)
+		}
+	}
+
+	if a.FerlotLot != nil {
+		object["ferlot_lot"], err = json.Marshal(a.FerlotLot)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'ferlot_lot': %w", err)
+		}
+	}
+
+	if a.FerlotMaxln != nil {
+		object["ferlot_maxln"], err = json.Marshal(a.FerlotMaxln)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'ferlot_maxln': %w", err)
+		}
+	}
+
+	if a.Firstboot != nil {
+		object["firstboot"], err = json.Marshal(a.Firstboot)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'firstboot': %w", err)
+		}
+	}
+
+	if a.GetMedaltower != nil {
+		object["get_medaltower"], err = json.Marshal(a.GetMedaltower)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'get_medaltower': %w", err)
+		}
+	}
+
+	if a.HideRecord != nil {
+		object["hide_record"], err = json.Marshal(a.HideRecord)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'hide_record': %w", err)
+		}
+	}
+
+	if a.JackGet != nil {
+		object["jack_get"], err = json.Marshal(a.JackGet)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'jack_get': %w", err)
+		}
+	}
+
+	if a.JackStartmax != nil {
+		object["jack_startmax"], err = json.Marshal(a.JackStartmax)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'jack_startmax': %w", err)
+		}
+	}
+
+	if a.JackTotalmax != nil {
+		object["jack_totalmax"], err = json.Marshal(a.JackTotalmax)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'jack_totalmax': %w", err)
+		}
+	}
+
+	if a.JackTotalmaxV2 != nil {
+		object["jack_totalmax_v2"], err = json.Marshal(a.JackTotalmaxV2)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'jack_totalmax_v2': %w", err)
+		}
+	}
+
+	if a.JackfrGetAll != nil {
+		object["jackfr_get_all"], err = json.Marshal(a.JackfrGetAll)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'jackfr_get_all': %w", err)
+		}
+	}
+
+	if a.JackfrGetT0 != nil {
+		object["jackfr_get_t0"], err = json.Marshal(a.JackfrGetT0)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'jackfr_get_t0': %w", err)
+		}
+	}
+
+	if a.JackfrGetT1 != nil {
+		object["jackfr_get_t1"], err = json.Marshal(a.JackfrGetT1)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'jackfr_get_t1': %w", err)
+		}
+	}
+
+	if a.JackfrGetT2 != nil {
+		object["jackfr_get_t2"], err = json.Marshal(a.JackfrGetT2)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'jackfr_get_t2': %w", err)
+		}
+	}
+
+	if a.JackfrGetT3 != nil {
+		object["jackfr_get_t3"], err = json.Marshal(a.JackfrGetT3)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'jackfr_get_t3': %w", err)
+		}
+	}
+
+	if a.JackfrGetT4 != nil {
+		object["jackfr_get_t4"], err = json.Marshal(a.JackfrGetT4)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'jackfr_get_t4': %w", err)
+		}
+	}
+
+	if a.JackfrStartmax != nil {
+		object["jackfr_startmax"], err = json.Marshal(a.JackfrStartmax)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'jackfr_startmax': %w", err)
+		}
+	}
+
+	if a.JackfrTotalmax != nil {
+		object["jackfr_totalmax"], err = json.Marshal(a.JackfrTotalmax)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'jackfr_totalmax': %w", err)
+		}
+	}
+
+	if a.JackspGetAll != nil {
+		object["jacksp_get_all"], err = json.Marshal(a.JackspGetAll)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'jacksp_get_all': %w", err)
+		}
+	}
+
+	if a.JackspGetT0 != nil {
+		object["jacksp_get_t0"], err = json.Marshal(a.JackspGetT0)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'jacksp_get_t0': %w", err)
+		}
+	}
+
+	if a.JackspGetT1 != nil {
+		object["jacksp_get_t1"], err = json.Marshal(a.JackspGetT1)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'jacksp_get_t1': %w", err)
+		}
+	}
+
+	if a.JackspGetT2 != nil {
+		object["jacksp_get_t2"], err = json.Marshal(a.JackspGetT2)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'jacksp_get_t2': %w", err)
+		}
+	}
+
+	if a.JackspGetT3 != nil {
+		object["jacksp_get_t3"], err = json.Marshal(a.JackspGetT3)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'jacksp_get_t3': %w", err)
+		}
+	}
+
+	if a.JackspGetT4 != nil {
+		object["jacksp_get_t4"], err = json.Marshal(a.JackspGetT4)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'jacksp_get_t4': %w", err)
+		}
+	}
+
+	if a.JackspStartmax != nil {
+		object["jacksp_startmax"], err = json.Marshal(a.JackspStartmax)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'jacksp_startmax': %w", err)
+		}
+	}
+
+	if a.JackspTotalmax != nil {
+		object["jacksp_totalmax"], err = json.Marshal(a.JackspTotalmax)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'jacksp_totalmax': %w", err)
+		}
+	}
+
+	if a.LAchieve != nil {
+		object["l_achieve"], err = json.Marshal(a.LAchieve)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'l_achieve': %w", err)
+		}
+	}
+
+	if a.LPerks != nil {
+		object["l_perks"], err = json.Marshal(a.LPerks)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'l_perks': %w", err)
+		}
+	}
+
+	if a.LPerksCredit != nil {
+		object["l_perks_credit"], err = json.Marshal(a.LPerksCredit)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'l_perks_credit': %w", err)
+		}
+	}
+
+	if a.LTotems != nil {
+		object["l_totems"], err = json.Marshal(a.LTotems)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'l_totems': %w", err)
+		}
+	}
+
+	if a.LTotemsCredit != nil {
+		object["l_totems_credit"], err = json.Marshal(a.LTotemsCredit)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'l_totems_credit': %w", err)
+		}
+	}
+
+	if a.LTotemsSet != nil {
+		object["l_totems_set"], err = json.Marshal(a.LTotemsSet)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'l_totems_set': %w", err)
+		}
+	}
+
+	if a.Lastsave != nil {
+		object["lastsave"], err = json.Marshal(a.Lastsave)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'lastsave': %w", err)
+		}
+	}
+
+	if a.Legacy != nil {
+		object["legacy"], err = json.Marshal(a.Legacy)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'legacy': %w", err)
+		}
+	}
+
+	if a.MedalGet != nil {
+		object["medal_get"], err = json.Marshal(a.MedalGet)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'medal_get': %w", err)
+		}
+	}
+
+	if a.MedalIn != nil {
+		object["medal_in"], err = json.Marshal(a.MedalIn)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'medal_in': %w", err)
+		}
+	}
+
+	if a.PalballGet != nil {
+		object["palball_get"], err = json.Marshal(a.PalballGet)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'palball_get': %w", err)
+		}
+	}
+
+	if a.PallotLotT0 != nil {
+		object["pallot_lot_t0"], err = json.Marshal(a.PallotLotT0)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'pallot_lot_t0': %w", err)
+		}
+	}
+
+	if a.PallotLotT1 != nil {
+		object["pallot_lot_t1"], err = json.Marshal(a.PallotLotT1)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'pallot_lot_t1': %w", err)
+		}
+	}
+
+	if a.PallotLotT2 != nil {
+		object["pallot_lot_t2"], err = json.Marshal(a.PallotLotT2)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'pallot_lot_t2': %w", err)
+		}
+	}
+
+	if a.PallotLotT3 != nil {
+		object["pallot_lot_t3"], err = json.Marshal(a.PallotLotT3)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'pallot_lot_t3': %w", err)
+		}
+	}
+
+	if a.PallotLotT4 != nil {
+		object["pallot_lot_t4"], err = json.Marshal(a.PallotLotT4)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'pallot_lot_t4': %w", err)
+		}
+	}
+
+	if a.Playtime != nil {
+		object["playtime"], err = json.Marshal(a.Playtime)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'playtime': %w", err)
+		}
+	}
+
+	if a.RmshbiGet != nil {
+		object["rmshbi_get"], err = json.Marshal(a.RmshbiGet)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'rmshbi_get': %w", err)
+		}
+	}
+
+	if a.SlotGetfev != nil {
+		object["slot_getfev"], err = json.Marshal(a.SlotGetfev)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'slot_getfev': %w", err)
+		}
+	}
+
+	if a.SlotHit != nil {
+		object["slot_hit"], err = json.Marshal(a.SlotHit)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'slot_hit': %w", err)
+		}
+	}
+
+	if a.SlotStart != nil {
+		object["slot_start"], err = json.Marshal(a.SlotStart)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'slot_start': %w", err)
+		}
+	}
+
+	if a.SlotStartfev != nil {
+		object["slot_startfev"], err = json.Marshal(a.SlotStartfev)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'slot_startfev': %w", err)
+		}
+	}
+
+	if a.Sp != nil {
+		object["sp"], err = json.Marshal(a.Sp)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'sp': %w", err)
+		}
+	}
+
+	if a.SpUse != nil {
+		object["sp_use"], err = json.Marshal(a.SpUse)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'sp_use': %w", err)
+		}
+	}
+
+	if a.SqrGet != nil {
+		object["sqr_get"], err = json.Marshal(a.SqrGet)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'sqr_get': %w", err)
+		}
+	}
+
+	if a.SqrStep != nil {
+		object["sqr_step"], err = json.Marshal(a.SqrStep)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'sqr_step': %w", err)
+		}
+	}
+
+	if a.TaskCnt != nil {
+		object["task_cnt"], err = json.Marshal(a.TaskCnt)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'task_cnt': %w", err)
+		}
+	}
+
+	if a.TotemAltars != nil {
+		object["totem_altars"], err = json.Marshal(a.TotemAltars)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'totem_altars': %w", err)
+		}
+	}
+
+	if a.TotemAltarsCredit != nil {
+		object["totem_altars_credit"], err = json.Marshal(a.TotemAltarsCredit)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'totem_altars_credit': %w", err)
+		}
+	}
+
+	if a.UltCombomax != nil {
+		object["ult_combomax"], err = json.Marshal(a.UltCombomax)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'ult_combomax': %w", err)
+		}
+	}
+
+	if a.UltGet != nil {
+		object["ult_get"], err = json.Marshal(a.UltGet)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'ult_get': %w", err)
+		}
+	}
+
+	if a.UltTotalmax != nil {
+		object["ult_totalmax"], err = json.Marshal(a.UltTotalmax)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'ult_totalmax': %w", err)
+		}
+	}
+
+	if a.UltTotalmaxV2 != nil {
+		object["ult_totalmax_v2"], err = json.Marshal(a.UltTotalmaxV2)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'ult_totalmax_v2': %w", err)
+		}
+	}
+
+	if a.Version != nil {
+		object["version"], err = json.Marshal(a.Version)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling 'version': %w", err)
+		}
+	}
+
+	for fieldName, field := range a.AdditionalProperties {
+		object[fieldName], err = json.Marshal(field)
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling '%s': %w", fieldName, err)
+		}
+	}
+	return json.Marshal(object)
+}
@@ -69,6 +69,9 @@ type ServerInterface interface {
 	// セーブデータ署名を検証 (v4)
 	// (GET /v4/data/verify)
 	GetV4DataVerify(ctx echo.Context, params GetV4DataVerifyParams) error
+	// 指標ごとのランキングを取得 (v4)
+	// (GET /v4/rankings/{metric})
+	GetV4RankingsMetric(ctx echo.Context, metric string, params GetV4RankingsMetricParams) error
 	// グローバル統計を取得 (v4・上位1000件・最適化版)
 	// (GET /v4/statistics)
 	GetV4Statistics(ctx echo.Context) error
@@ -563,6 +566,31 @@ func (w *ServerInterfaceWrapper) GetV4DataVerify(ctx echo.Context) error {
 	return err
 }
 
+// GetV4RankingsMetric converts echo context to params.
+func (w *ServerInterfaceWrapper) GetV4RankingsMetric(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "metric" -------------
+	var metric string
+
+	err = runtime.BindStyledParameterWithOptions("simple", "metric", ctx.Param("metric"), &metric, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationPath, Explode: false, Required: true})
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter metric: %s", err))
+	}
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetV4RankingsMetricParams
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", ctx.QueryParams(), &params.Limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshaled arguments
+	err = w.Handler.GetV4RankingsMetric(ctx, metric, params)
+	return err
+}
+
 // GetV4Statistics converts echo context to params.
 func (w *ServerInterfaceWrapper) GetV4Statistics(ctx echo.Context) error {
 	var err error
@@ -773,6 +801,7 @@ func RegisterHandlersWithBaseURL(router EchoRouter, si ServerInterface, baseURL
 	router.GET(baseURL+"/v4/achievements/rates", wrapper.GetV4AchievementsRates)
 	router.GET(baseURL+"/v4/data", wrapper.GetV4Data)
 	router.GET(baseURL+"/v4/data/verify", wrapper.GetV4DataVerify)
+	router.GET(baseURL+"/v4/rankings/:metric", wrapper.GetV4RankingsMetric)
 	router.GET(baseURL+"/v4/statistics", wrapper.GetV4Statistics)
 	router.GET(baseURL+"/v4/statistics/medals/timeseries", wrapper.GetV4StatisticsMedalsTimeseries)
 	router.GET(baseURL+"/v4/statistics/saves/activity", wrapper.GetV4StatisticsSavesActivity)
@@ -786,95 +815,116 @@ func RegisterHandlersWithBaseURL(router EchoRouter, si ServerInterface, baseURL
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/+xdb3PTxtb/Kho/zwtgAvE/enky87zgXjotd25vGWh50zJGsTeximy5kuyS6TATyRQM",
-	"JE2gkEChBS7QuOSSQOmfQEL4LnctO3nFV3hmd/VnJa1sRZaT0Ic3TBKk3zl79pyz55w9u/o6kZdKFakM",
-	"yqqSGPk6oeSLoMTjHw/niwKogRIoq8d5FeC/VWSpAmRVIL/xzhM52XqELxQEVZDKvHjM9bD71bxULavo",
-	"hwJQ8rJQQW8kRhJQ+w5qS8bS3c7KOtSvGTNzxvo81OahdhfWf4L1Naj/Dutr7RtPE0MJdaICEiMJoayC",
-	"cSAnzg0lEBN+UILS+fbim7VG8kByf+pA8s3apcRQYkySS7yaGEmMiRKvOojlamkUAZ6z/yKNfgHyKiLh",
-	"gcacHj0CtetQayLWLVqwfhGz+zrBAFEllRdzVQXIip/dzh8zvcfKYo2asE/LopQ/835ZlSe6T5tQQH8x",
-	"oRRVFsrjCKoi8hOqUALUf1JiVvgacL9J/WcVkwaFHI/n15ZxgVfBfow55KUXajAfCooqyRPHgVKRygrw",
-	"D0tQQcn9w3/LYCwxkvivYUfHh00FHw4QlsMKL8v8hD1ZrLGy2P6bDAqCelgUjwhodKNVNKl/rebPANXP",
-	"scyXx8FH/FmGDiyfN27/ArWl9p1J4+GCMfmQ22PMLkJ9ci+tt0JZfS8bYAkIWij3gH46s3XoILXFuMaF",
-	"b6C25NffnriIZ/BlVZBBITHymcP/kCMli/SpsHIP1pUC9VRolek2twy9CZDT5u0LG82Gsby+8ey+X1Tc",
-	"nqJQADkZ5CW5wP0vl9wbQXiE8pB7lCypfcCXwBFe5f0COp4rgQJT7YcSZ/dLfEXYn5cKYByU94Ozqszv",
-	"V/lxMtjRxIj9NmIsLwNe3Yo7CEOAAkU0xkANyGx3NA7UnFIU5FEQcSwUACJVRL6vH9lQAAgvwAEL5b6I",
-	"2K8jEl/w+TMViazvEbDs1xFWiT+byxd5oZxDJhMR0QPixpWwvfeNbMK4sWVeKI9KX/UNbuFY6KaIcl8R",
-	"hxsRm0axkEmkYP5PH9huHDd6VVSFkhk59QVvA2F8pH+5VFRQ820HKd0XUppCyvSFlKGQsn0hZSmkg30h",
-	"HcRIUlXty2k47yM04vNyo9WJTDIZEdGNgVFFSc0VhaiqbL+OsVReVnPoT1HRHACMVx2XZOlMNaeooBLV",
-	"W3pAztnRPgqpcwExdRhgL8o5M8RghvBhAK2XEVANyIoZCoWJcj9CWvKJUAIKkAWgBMW3fF4VasDJc/yr",
-	"M18bz3XPNQqmY3IFDv4UwpIy1l8l4jiC48VRPMLw2QVbQL4gkcXTcb58RiiPB+RuW46mumkJmnherLrl",
-	"2yXG9PF6gq+Bw2iSBXUiSAmKUlXOYUsLzzFKMJWg9FL4stpFp3qxGd8cMwYfaoLReyjoPplm8MCLIokx",
-	"InoJCgARx7+OAzXMDIeGR3gYfFQ6655TqToqAl8dJRSwyOfPIDwLOMeLYtzgJExxSChFqRIbDRvQhq8q",
-	"oJAbA7K5QMVGhcbFxBS1kpO/KsQ2yRaeDY7WsljRMSCGr07klOKoEFXdrdctrKBiTVgwR0PylVKuxMem",
-	"4BacmRIXXCHQ1jLfghn/kB8tQ4kKhd9HcIV8zu1+giq5fqfs9nE9aKs8kuEIqSIWeJXP1dI5j9+yeDFd",
-	"VxAnIapVMbFm+TzEGO06tlVGLhdTyJs+wM7Et5MXmrSbm6oCdpAhi7rJE0mPemjRINhxCJucVHgxjEYP",
-	"gheatIebLyo7xcwXFbNyJzNjlD48LQ1pkkCawefjpGAhUgTyRb6cBzHTMEEpMmbyHCMNK582fxWFsse7",
-	"9k+CYNJEJCVuUWFIFwk1dgouQZX4s2I5ZhIEExMRZEUdlSSVmbdRuwRR69AUglUljjNVsPFscJwEekOq",
-	"finYoDYZHMKZZKKi2hg+1FwtHSv/NK5FbExGgoszA/KgegipyQHQUZM+MqlBkEn5yKQHQcY3O2pmEGQy",
-	"PjLZQZDJ0mQGYZc0LEWKts0YSfnMVakMwoIoVA+heC3IAfWSSQ2CTMpHJj0IMmkfmcwgyGR8ZLKDIJOl",
-	"yTAtqH9CPgtSKmwL6p+Uy4LEnNki5KqF+ivudKkzSi5ANSIpJuEKkM8oLLL+JCQ6XUKEIphzKjI23fC1",
-	"hT4ZyVElHRFNhLeNaQCETSo0yZ2SgZu6iyMFqNsjiFxF5PNYDwkDvKKip5haL4JxPj8RMbg0X3b2fWMM",
-	"uN0lB/Jb9H4E63WE5aldxOF2vDWJCi+aGVaMa6kb1EsmNQgyKR+Z9CDIpH1kMoMgk/GRyQ6CDFlL6X3g",
-	"MB2OJaU4Klg6GUHFKQC7M2EcqGOgFpdF0pDe5ofY8O1uCPSLf5+1X3iC6CYQt4hsUEwmti055YwgirmK",
-	"JJhuXankqgqIjXOChoG/lON05BacBR3n7puNh7emeeVMLl+OzanbeGbXCyjleFHlZSV6y4sD4cWk4pU4",
-	"BMOCxi02oprLS6VRKXpFyQVhYUb3W9bbFlKf9S4XhBczxmqXF7Z721EYROvtc+e2ujNBTIGKAzE3J/ga",
-	"ME8ZBHTf0FGQf0XytBkwn9nCrnKU3umgXWGKA1Y10/+UKzr1/3cf50McL8xo7vGrHuOhSmHLQtlifxul",
-	"CXGdN/EpF+PEQBmcVXOjYEySQT9HZ04I42VercrgJJCFMdcIPEc2Xv1izE63H97ZaK51fptt/3gnMeQZ",
-	"ZI0XyUSy3oTaVPvJA3w867xx71djtgG1RU6VqxSjo5IkAr7sO6NAgE8FsA8KVpeUn/ZfeQW8l+WgtgD1",
-	"Jqw/h/pzWF+D9UtQuwH1Kajdhfoq/sucfQgLak3Csm+Aha403ATaKw2ovYbakp8A9/cTH//zzVqj3bzV",
-	"fjnHkffJKTN/d5sw7qf44UeH/7b/xIeH0wff24N42stBbRHqDahfIZy/WWv84+PDRzio/4FJLsP6v2G9",
-	"DusNqF9rvXrdud5k0vPIHY+X8MAUvsqrgqIKeYXVnsbq0g+l/a6mRobmM1v0Y0P2NdHHghyl4ZQSb6br",
-	"wTwlZ5+QjIVZus8qFkBqIYsFb1wSC6DsbZOIBdq3+RgfKh17xY9qLoGxAXsq1TFb7lvjEpwIZFv8gD+H",
-	"iIWsN/SPHTQ+7evhCbPb6wl9Ufqu9IfeZpy3wskOynF5NqnjxB2IBx+so33L/KHnxoRvmvSRZ3wC+j6s",
-	"T8L6ojHb2Gg22GfOB+1BB+ns8DHf8pjkF8bJ438r8mrnetOWAazPo6AexfgPcIqxjP9dROnN5BSK+vVX",
-	"KPPRHkDtKtQuep/Xf8HP34OT01i08yhL0B86iZB+bXNSa72+D7VbUL9y+NhROKl/Xv7P5HWuluKg1jTW",
-	"p9pzL1ovr7VnbkNtiaulOahfa8/MttZv40xvHb2J3kETJah4S+0jXlGEGuDwsSfuWFUpApn7gC8BDqVw",
-	"3OFjRxNUDp5IH0geSCLJSRVQ5itCYiSROZA8kEHJGa8W8QQMkzrGfl4U93tP65t+yy3LWgYfCsqJvAoU",
-	"NWdXdzhy1UF77ikrLbwC9Uvcaadmcho/fl9r33hq3+lh3Ziw4Dmrj2SJD/P75IKWMh7xdbSQGEl8AFTm",
-	"1QEJlJmR3BwPOU3OWealsgrIgsdXKqKQx0jDXyhk8EQLI91VYFcCsEq6xeeIwJGA0bhgrNTRRB0krHku",
-	"a9F/w/KchfU148I3m/Umzpd/hvU18zxlqcTLE13B7UtekDbh6txniVo2cQq9P2zl5vZ8V2SQ51WUyKpy",
-	"FXiHsG/ff76/92ZlhiO3yJjJe/0SrP+ATeA5ypW15c0ffmx/2zQeNbEdoTmDug61C61Xr6E+gysIj6E2",
-	"g/7VL+N5xdfPTOr79n1e/ryMEnWSkbdWb0INmVnnt2cbzUa7/o1x7xnUr33w/idQW6DNjK0UR0gqXuFl",
-	"vgRUfJ7ts68TAhrKl1UgTySGEmW+BKhKJ53KEwk42uBPP9lY1mnAEFhOCYENRV1lEAdn9qUFcYA5h5nj",
-	"QLN32uIAo26UiIU35xRzLHCuM9txIFpn+ePDSseIlYkRKxsj1sFYsI7HaATeE+hxYJKbW7aKFFg6tUul",
-	"m/cuGC9njNsvjaXvYX3VdHob62u4gLvQuX633Zgl9VKmEQjjcThIz0UnkZTBW1/pD8TKHSJ5BvfVBlEg",
-	"7Ltkoo6CviMlKoa7HNwfin3lSVeYU+xIz63G7ScPjJUVqC22Xv9gPLlp7WaY8QeKwrKs11or03gT5jHR",
-	"fU6SOVi/isOw++YNdOjVVDLotr2gOClMVBRDbOikLL4chdtjx2p76fgwZcaHFWR8QfkAzUGnuWbUpzv/",
-	"ernxeBplW+wRm9FgLeMOEpfwjpLGjYMykHkR78ug8TehrkHtJ6idt4N/VpR3jBzH7T3/foZbK0+8wqrf",
-	"RMmg/gLWNagv4NRvmdvTfvZt57pLRiazpqBkkrEquyyYZonruMVqqMBYkWS3BRfAGF8VVW9wCsrVEhKL",
-	"64/W2kPFnXTU6HPeDFfsd6ynhsIuDqJQEgKYP5gcCu1EQqeLoWob9o1w/rqGL2XE5vwc6k/wv0+dGy8H",
-	"6W885uDmwEomezkOb4lqVyWY/gqZWb5wqhaLULsMtatQv8KddlQa1y5IEQ3q1zZeX7frEmxT+wRJ4SMi",
-	"hD51y1293/rOKEu5zIpY54+ZzYszngHt7iWtS43THk04RcXX3Ax/bYav53ZjRYSlWZ8ittE/RwtdihwV",
-	"Xi32V5c4NcAKmuMJWcrpnl1fCEOitSxDiVxvTm38dAVqD3EZkgj2NtS/207/SXNjljuZAVk4da2ld2fN",
-	"7u8nPv4nZ0zNmX/214H1a9wH73/iDZypKt481H6y4vJexd6TabbSu0f+6fF/sPuLcCsR52cyIFU1G3r6",
-	"zlVjrAtuNXseZHrUOX/fuPwCKQYjKUqmglrbNh5PbzTXjIfP2jfmyaP/w9jLmp1qrUxS6/LS5sXpjYcX",
-	"2w/vGBdf7va0i2EEVtpVS8P6KtvU046pK/YW/u6LoWbPd5pL3vBQa4ZZmenkjo45uti708wwyD0dV28e",
-	"Y1Ui2xDbE4bHoH9PYf2J9fCiybuz0IRTwbc0PjqZ/jNESNRFhqwYyb+ABQdFtgfdgYiof1VmZmss/7oV",
-	"5c4M001Qw/b3NXaVn61lONyJ/DPC1P9lw5r9Avq1zm966+UFy4ea754ermUZozvNIRE1fu5cb5KACxO+",
-	"h1Z67Xyw981Qn3JQyJdKBqj0vq+iMFSffBWE8SmQ3Z21erim1DUTqK4ZR113ZeTfh4Ki8URVyXcJwLsE",
-	"4K1OAEJZ/C5OAPqwe2dUUa1/29OBTIh0gNvjSYdaK5c3b81yqWQyufctzxVCaetbkCv0pbes8UXV4O1K",
-	"T7r1cnD2ib0BLjE7mAhtYel5lzOZRu5qvOxp89leKZR7LT/vfM1vif5mYJevEHLDnKek1L7x9M3aJc92",
-	"VUBrwMnsW5W6DC6/yO5ltf+aMXjgjJFDtjiMZpwFRrMVFElHrfdzpC++Pblgt4XASc1Y/679wyOoLXE9",
-	"Q3rdmJmH2lWswFd7lRWzYbIISghbySWQWrs6/RdYzGOGl4Pb5KKkHiHZdw5zU8Z19Ajm2xR35/rd9txF",
-	"48m80ZjHh6V1Y3ndeH0nmN93Kc4AUpyB5iFdPcNwDV9wEOgg6DReaxqzU1C7iY/QNGH9MfY/yBiNmTm0",
-	"uE1q9HUI2L4RJxv3p+gOL2tili23gJbaHiZMrmF4Z8h/akOOJ5AMuLmDld25lHWx3Zg1Lt+l04JBe4vY",
-	"zd68U0S/RgbVzfgDCg8uV3pnclP7Ge/5m/rnyYcRIfxxXW7fPpQDc63V3/ft47D8FjzBGxd8uKx+wRrG",
-	"on0PBwkfNhdvbk7epThwtYoGOYxtrx1k46gdDDrBz8L6amvlcuvVNCLZWv0d1lftGe5cavRWlGHSjjas",
-	"2h9lC1ad+UfG9M3Wq2kUDzoH/O56t4q1pfa3zc7Cqj/Y56zXlo3Z855UJ9Wef2T32njzHpcGhdYU0sPn",
-	"fG6u11JjJTS3oH6lPf8IpyyNFJy8kzqU7LZGTCjsdtVMEnfCCqVqKTGSOoR+E8rmbwNoZN3Ch/e6eVDG",
-	"dN7SO89X0SLkztS7dd0znGifxtBamevcmPK3JtjK1jttotQef0pvmDe/Uxeo853bv268vkpksDn3HdUM",
-	"ZqomPhBLMt0r1v1D3tOw4bolKMU9gZizvqHXS2vpD2cTLk2t/Us6yVl/CFLeolSVA7Q39d4hSn3/kt5J",
-	"9WV+qbBbTal9+Uan+RpPjjl1RuMR0ZSd0N0wfIXSYG8x01XIKZJ71IId+NxTcikZrqBq1gJ93yxyIUV9",
-	"ZVy+5+0G1JaMZ4/aT35l+HP3gd+F9p1/t16+JOdJ/DlCkN5TRVW67mPeCvcnqLEOsRea1urvZIkh8Vaa",
-	"rODBttr1YAS91CCkHTRWahI/LYtS/oz3ykCG4W4sPNi89ZDoWSQD3c4InWE8NP/eAI02krB23bXA1566",
-	"iI9vhi0Ve6z2tCKMo8x/gUMazxHx+M2VcyemUFvmXJVFkwvf9smbtYadcxsvnrfnLnrqe2/WLoXzBu+2",
-	"WEJlxtSlkIEJsX0nA/vqxp3bitn+/ZJ+TDJUZS1gw9GqtJkVirkXxtoMMiOzVHEPH1yKsGZ2K6W9s5X/",
-	"p1Uks2pwqXO9uYX6kVdz7e+Nh1+GqJz9BaxfxjexzuN7mBbsW1ntOHTz3gVGUSlUUDmpncbxEDYs7jS5",
-	"HRivarD+PWZoxepuX8A44Q0KZ187aUtv1hpBfsR0I62VSeJAYr2qIUSQmooeo6Zd1ZCeEeoQe3++Pf+o",
-	"fUvHFwBfNi5NuzXOdPJv1hpHT3x86L1kKphR8zppmtNQ90oPOssNESvbA347wuUA/xAlVkbAQK5ZFlmV",
-	"xcRIoqiqFWVkeLhSVYoHVJmvHBjnS0AZ5itCAmmR/6n9KlDU7o+ODA+LUp4Xi5KijhxKHkqSZ07ZHPmD",
-	"nVnL6yzA+vPNH35srZMtsSVTAvULUH8A64/NH1h9JvRdSOSeAmIG3Tuw2vMLh48d9ZxBtG7ISvUPke4f",
-	"IpPw23NnZn3j/hS5A896Lps4d+rc/wUAAP//tHSN83yTAAA=",
+	"H4sIAAAAAAAC/+xdX1MT2bb/Kl1978OMN0gIcc5cqu4DI4zDKUUPoufee8YKTbKBHpPuTHeHkTtFVbqj",
+	"EBUGdBR0ZEY94hDlADo6HkTE73J2OoEnvsKtvfvf7n+hSTqIU75Y0un+rdW711p7rbXXXvt7OslnsjwH",
+	"OEmkO76nBSBmeU4E+I8vmFQf+DYHRAn9leQ5CXD4v0w2m2aTjMTyXOs3Is+ha2JyBGQY9L9/F8AQ3UH/",
+	"W6sF3ar9KrZ2CwIv0OPj4xE6BcSkwGYRCN1BVy8/VK+9hvJTWLgBC09g4SEsbELlHT0eoY/z3FCaTR4A",
+	"F+rsVHk9DwuTGnEor+5MTm8vTlYWF9TJDcTLl7wwyKZSgDsAZopPqrdKqrxQWfl7eX0Fyquw8Ctm7BUs",
+	"bCJmejgJCByT1iCazhBUfkfkC7OwsKlOXNkplKBSwh8Lc9PLS1/yOS51AIxYH2hq+9frUF6E8nWoXIPy",
+	"FpTvQeVHxM45jslJI7zA/h84AJaqb39TZ6e3n05vlzbVxeeV2/M0ukl/DsF2JkdYMAoygJP6GEnTsKzA",
+	"Z4EgsdpfjHVHQjBuYVIpFtFg0mdsN9sfTfI57dUcAyX/COVVdfV+dX0LKjfVmTl1ax7K81C+T8pS5fYz",
+	"OkJLY1lAd9AsJ4FhIKAhREy4QTWU6g+Tu5vF6NFoS9vR6O7mVTpCD/FChpHoDnoozTOShcjlMoMIcNy8",
+	"wg9+A5IS7RZ5zGlPF5RvQbmEWDdomZ+c9gCReIlJJ3IiEEQ3u9V/zuz9rl6sER/sHJfmkxe7OUkYq/3Z",
+	"WCxqOpQoCSw3jKCyaWZMYjOA+JEYZpEZBfYniR9zmDRIJRj8fc0xTjESaMGYESe9QC/zFStKvDDWpxt8",
+	"92uxEsjY/1NLMXwGy2KFEQRmzPxYXu/qxfZxAaRYqTOd7mLR2w3m0Ef9Ipe8CCQ3xwLDDYNTzCUPGVi7",
+	"rN77DcqrlYW8urik5hepT9TZZajkPyXlluWkz+I+moCgWW4P6Gcz+4f2E1uMq05ccdh9TX73xEU8g29z",
+	"rICM398s/iPWKBmkLwQdd39ZSRF3BRaZWt/WQ258xmnn3sR2qaiubW0/f+geKuqTETYFEgJI8kKK+i8q",
+	"+mkdg6dRjtjf0mvUzHnYOW/qs6T6bqG6cgvmlYEknwIDFJTXoLKGflMWofJ3WHgBC0UoT6nFCfW3WSgv",
+	"l9++g/KEunpVXf1JM9vbK3fU4mN1ZRbKSzAvD2SAKDLDGlZ5Y2Nn7kd19gaUb0B5dfvpPyp3fkD3yXdh",
+	"XqEcUza648mL6stnu5vFs385SRE/PIXyk93Nq1Bew5Ism/MqzCtfIxlyTj4pLBOAy2XQePX0nu882dOV",
+	"ONPZ13mqu7+7j47Qp3rOnu3pPWG7Ztx37mx3X6Kni7hytudEb2f/ub5u8lrn+e5EV2d/Jx2hu86dOdlz",
+	"vLO/G1+lI3Tv6f7El6fP9SKQU939X53uSqBLnSdPnv5rN7p4pvN/Tp7u7Er0nz6dONnZdwI9dK6381z/",
+	"V6f7ev4X3/Ll6b4verq6unvRT4ilLzp7e/Evfd1/Odd9tj/R1/3n7uP93Rqn/d19vZ0nE919faf7CGmw",
+	"jH4KSAybrjGJS0IOOGdANJaUOf1tP1rYfvWiUriiPni+u1ksb12nXANJ4S+8Vl6f3n71AgvJr1C+DJXr",
+	"Dk9anZ3WpmmX2Ooy5DFzORQBf2jrfi8VOMFkQBcjMW4b0ZfIgJSn5Y/Ql1p4Jsu2IPxhwLWAS5LAtEjM",
+	"sKbvg3SH+TRiKSkARtrPjBiEAAGKaAyBUSB4z8jDQEqII6wwCOp8FwIAkRpB038jY0MAIDwfH4TlGiLC",
+	"cgSJb5jkxSwviXVimY8jrAxzKZEcYVgugWaNOhEdIHZcHk95DSPrMHZsgWG5Qf67hsENHANdH6LEdyzX",
+	"ADaJYiBrzrL+SwPYdhw7ei4tsRk9eGgI3gTC+Ej+Em31gupPW0ixhpBiBFJ7Q0jtBFK8IaQ4gXSsIaRj",
+	"GInPSQ0ZDet5hKbZvMRgbqw9Gq0T0Y6BUdO8lBhh6xVl83GMJTGClECX6kWzADBebpgX+Iu5hCiBbL3W",
+	"0gEybga8KKpM+ISVQYCdKOO6l+0ZxQYBNB5GQKNAEPVoIEigdwpJST+bASIQWCD6hXhMUmJHgRXqu2dn",
+	"ZnQ4UTvcTumGyeY40B4OnDY+WH7FOt/DP2QaxG8YPMD2HiBXnOTFUx/DXWS5YZ/0xb69qVpSgj48k87Z",
+	"x7dGmOXH6/m4m1HASYL+X0fWKL+IUkWLS1CehvLlnQcTu5tFdXYKX19TrxShvAzln9E96MryjnxLC6bI",
+	"YBHKU+X1a+W3upMc6JvYBtYjZM0ASWCTboYrU5OV0l11dpqO7OFx6wgR8+W9fO6zzCjoRIrBSmN+ijPC",
+	"54QEtk7BvzLKS4l+WSn221wNPRzfg83w9MLj5QMpBXoOBSrnY/sL0MgkOJSnqvcfQ+UqDpkvo+C9UICF",
+	"eVj4ByzchYVllJBQVvCda+V3P6srdyp3FXQRBdRb2+80OdyC8t3dzaIXD5owOkaISac1r7FOu08AoKHB",
+	"fw4DKYjOBoZHeBh8kL9klzg+N5gGruRwIOA0k7yI8AzgBJNOhw2uOZ4WCXGEz4ZGwwQ04XMiSCWGgKC7",
+	"HKFRIXExMVHKJoTvUqF9ZAPPBEfeSajoGBDD58YS4sggW6+4G48bWH4Z6KBgloQks5lEhglNwA04PcmR",
+	"sjm1+8tlpHSPVvuvoSj1QuHnEVwqmbCbH7/lKfeUYbfAe9CWGDSGHdrSSIqRmMRoLOGwWwYvuuny4yRA",
+	"Cj4k1gybhxgjTceBjpHNxKSSug0wcysHyQtJ2s5NTgTvkSGDus6TFvDuIUXNYMcirHOSZdJBJLoZvJCk",
+	"Hdx8k31fzHyT1XOxgqeP0oClJSF1EkgymGSYFAxEgkByhOGSIGQaOihBRk+HhEjDyJDof6ZZDoghk9Aw",
+	"SSK8GPZQYUgbCSl0CraByjCX0lzIJDRMTIQVRGmQ5yXPSBytM2AbI/HfASEsJhyoeAXDWmOtdwmDQDAW",
+	"GMKMSUw8ExzHwk7frVEKJqhJBvuKOpl6UU0MF2piNBYq/ySuQWxIQAMXZqjlQHUQkqJNoCNFXWTamkGm",
+	"zUUm1gwyrq8jtTeDTLuLTLwZZOIkmWboJQlLkCJ1M0RSLnUVs83QIALVQShcDbJAnWTamkGmzUUm1gwy",
+	"MReZ9maQaXeRiTeDTJwk46lBjRNyaZCY9dagxknZNCid0AssbSlh92INmfGtJ+ggyjhFnXAWCBdFL7Lu",
+	"aKd+uhoRgmDCSv2YdIMnMRpkJEHkjtLoQziLQJtAWKdCknxfY2CnbuNIBNLBDARavkxiOdQYYEQJ3eUp",
+	"9WkwzCTH6nQu9YetkoEQHW57bkP7q/5SFuNxhOVIkoRhdpzJjyyT1kO5EOdSO6iTTFszyLS5yMSaQSbm",
+	"ItPeDDLtLjLxZpDR5lKyhCBIfXgGrTAYMlmHiBMAZlHLMJCGwGhYGklCOutmQsM3C2nQH+7l5kbhNUQ7",
+	"gbCHyATFZEJb+xMvsul0IsuzulkXsygPHRrnGhoG/lYI05AbcAZ0mMt8Jh4ClxjxYiLJhWbUTTy9YApk",
+	"EkxaYgSx/mopC8KJSfgrYQyMFzQimUtLiSSfGeTrzyjZIAzM+u2W8bSB1GC+ywbhxAwx2+WErV2xFgTR",
+	"eHp8fL9LIKlBhx9Ij+v1KfoeLZ/CLdILcs9IjnoGz3v2sXxdT9m93/IzwYFXNtN9l807df/cwO46ywq7",
+	"f/MQPY+bsql9D8o+SyMJSQhrt55LuDyK1zhwSUoMgiFeAI1sPDzLDnOMlBPAeSCwQ7Y38NpBW1lc2C5t",
+	"Vn+frfyy4Kp8GmXSbMrvSShPVVYe4YKqy+qDl+osKvujUAmXxeggz6cBw7nK7DTgCz7sg5RRLOam/QUj",
+	"gs/ieA8O2j/1AiovcHnYVSjfhsoUKjJU3uArc8Su5ZLGsusFUzVp2AlU1otQfocrzJwEqD+fPd27u1ms",
+	"lO5WNuYo7Xnb5h9LGEV22E3xq1Odx1vOftUZO/bZJ4inTylUQqkUoXJd43x3s4i2U1FQ+ScmuYZL3gqo",
+	"Ak65WX77rnqr5EnPMe74fTUePAdfYiRWlNikqFXp2QfLa4NHOGWbXrs7QkN27b8IBbmeWmVieNtrbmsW",
+	"E+b+8lCYJQu6QgEkJrJQ8Ib5dApwznqMUKBdi4/hoZK+V/io+hQYGrAjUx2y5n4wJsHyQA7EDrhjiFDI",
+	"Ol3/0EHDk749LGH8YC2hy0s/lPbQWfXzQRjZZhkuxyJ1mLhNseDNNbQfmD107By6UrLvAVrF2+XzsLCs",
+	"zha3S0U68j4saDON3TjeCj7EuwfjfN/xEUaq3iqZY4C3shSwj/8Ihxhr+N9lFN7kp5DXr7zF3Qce4Y4T",
+	"k877ld/w/Q9gfhoPLd4YoyxagZBycycvl989RB0qlOudZ3pwg4l/5W9Ro20UlEvq1lRl7nV542Zl5h6U",
+	"V6nRGAWVm5WZ2fLWPXPrjNGUQmIlvKR2ihFFdhRQeMccdSYnjgCBQn0JKBTCUZ1nemgiBqdjR6NHo2jk",
+	"+CzgmCxLd9DtR6NH2+kInWWkEfwBWrU8RguTTrc4e53odss+lqPteG9UIs1IQJQSZnaH0hrFVOaeeYWF",
+	"16FylRqwciYD+PaHcuX2M7MlhNFvZsnR6QSNJW6F4hoXNJXhXlc9KbqDPgEkz8YrdMTe/C0WjYbWNat2",
+	"NxmPblrWEFgjgJqirBfQhzoWjfpRNF+h1d4bTduMm8kwwlhNeLNJFh2htfzc3+jROH0BPd9qROfmF88K",
+	"IImyP947xY4c+ddPD3bXZyitC5cevheuwsLPWAm0Zi9rOz//UvmhpD4umZ1aoKJAeQL1flFmcA7hKZRn",
+	"0L96hzO8dTGvHDnyNfc1h0J1LSYvv7mDN0CuVn9/vl0qal1DoHLzRHc/lJdIRfMWiy4tGM8yApMBEt7Y",
+	"97fvaRa9yrc5IIzREZpjMoDIdZLBvDYCljy4A1BvLGMraQAsK4ngDUX0wQiDM5YLEczaCR8GmrnWFgYY",
+	"0Y4kFN6sLfChwNk2/IeBaDSCCA8rFiJWe4hY8RCxjoWC1ReiEjjbF4SBqbX92S+Sb/LUTJbuPJhQN2bU",
+	"exuokVfhjW70trc2cQp3qXrrfqU4q+8891ICdjgMA+noklOXMDgzLI2BGNFDXZbB3hejHgizEVG9b0E2",
+	"2KkXw54QbgzF7JdTE+aCt6/naFKw8khdX0dN6PDGdWM9Q/c/6PEIHfd6rLw+jZdhnmqyT/EC5dHSN94W",
+	"9etW6ucnBfGKCO9wH61rbb6hFbS4ohTqE9NX+5T0D9t0/zCLlM8vIiA5qJY21cJ09e8b20+nq7c0Vtxv",
+	"rHuDo+12J3EVrynJ1DDggMCk8coMev8SbtSHe78Z7r+Xl3dG2/m79/d3M1xeX3EOVuEOCgeV17AgQ2UJ",
+	"B39r1CeV5z9Ub9nGSGdWHyhBi1nFQ+ZMew1Xn8FqIMdY5AW7BqfAEJNLS07n1OiUaLtozD2E30l6jS7j",
+	"7WGK3Yb1QiTo5JBmM6wP88eikcBGJHDAGCi7YbYTdGc2PLpCP8Hroyv432em/jbV3jjUwc6BEUzuZTic",
+	"SapDFWC6c2R6AsPKWyxD+RrKACnXqQFLpHH2QkujQeUm2ezEW9X60Sic0gahQdmy5+/3vzbqJVx6Tqz6",
+	"z5mdyRnHCx3uKa1GltN8m2CCivv9tH6vu6/jhzEj4iVZ5xDb6J+eVI0kB8r2NZaXuNDEHJplCb2E0/51",
+	"XS6M5q3FPYTI9qR/M/2Dsp8kN3rC09MhCyauo7HDmbND5TmUOjWnX3ZngpWb1InufqfjTGTxUJdfwy/f",
+	"K917PuYt9PY3P9d30rvCCBcTUW4mfUJVvaSn4Vg1xLzgfqPnZoZHtc45iUfb/Irb7AdLoFv/033r3qeX",
+	"HOawy0MJjLBrNAYLb7xVPWapumgu4h8+H2r2crW06nQP5VKQmZkM7kifo4a+W+UMzVzVsVXneR2Jgpch",
+	"DsYND0H+nsHCinHzss67NdEEE8EP1D86H/sjeEhER0fPw4tcE5i/UxTgeKFDLMqe0ZqXfd2PcLe3kmVQ",
+	"reb5RIfKzo62U14HaqzpFQPKzervSnljwrCh+rMDraNxj7cbQLUG2iFcxrEKM1B+gGZ6+bK/9W0njsIR",
+	"tZOemij0rlOlvA4Sw6cqeRyldLijVgfXhLi2+4pruyWuh9Lzb0BA0fvUK5IfA4CPAcAHHQAE0vhDHAA0",
+	"oPfWW9Wr/QceDrQHCAeoTxzhUHn92s7dWaotGo1++oHHCoGk9QOIFRqSW6/3q1eCDyo8qVXLQZl79po4",
+	"xbzHQGgfU8/HmElXclvh5Z46H98rhLLP5Zet01BXyTNXa5ziSrVSjpRS5fYzdJKffbnKpzTgfPyDCl3a",
+	"veRQXbxaufcSi8n87maxZ6ill+dAyylGSo5QrVTPUMspPsUOsSDVcpblkuisvanqzJa6gE+bnbiirr6G",
+	"cqm8nt+efInqscIoN64RycQ/9So01r19X9nQNvRih91j3zGSCz+fvd6VBUqrwa/kl8wCFJiX1a0fKz8/",
+	"RoX5ewYPijozD+UbWFVu7JXAjAeJV4hB2E/UghTItqtgyYt5zPCaf0FePUFOQPatjeOEGvd0Yb714Ubl",
+	"gnOT6sq8WpzHG7MVdW1Lfbfgz+8HHkxF6BHApPSzZv67BU1wLX9lBM4oIvKb4eQpSq9Vp/Ac81o/jaXw",
+	"C0q+zxbRpKId3YKv724Wc9xFjv+OS1wEY1QrlWFFkeWG9b848B0Q0P+RRc0r1Nc08XuH2aQiQmB0DPH8",
+	"17RGvAjlCTxzLePZ7Rd1akMtTuKJDB3lur3yq3rjGjJHlx/iYoopKD8zavqt81eptmgsTqE5Dc2SuLLi",
+	"1RUoo635anECymvVBRk9kpcrC8vqsy3UBOFr+j/w1ctQvl+5/QwxpNz0UkX/rzhuxbO1DeEXTKoPfJsD",
+	"okS4FbUfsZ3Ujh9q3/sh6yB+Kxqu/cRxnhtKs0kpFJteO1ytadZbR3EnDF/rTmZ7kJROQfkO3mtVgoWn",
+	"ePJAllSdmUOik5fJvhnYOCNOth9OkYWAhlatGTadPMzXx/5q/To+WuE/tBUOJ97wafHilQSwCetypTir",
+	"XrtPRo/0AdqZJhgB7f3QHkX8irVMgVEE2/q9dsjbeG2DYEsDobq6y+5qQwptmlzEAfUNmJe18+yoAVzZ",
+	"OUCV37xCPWHwCfg4zaJdueqIDKgBjR98pDjlRducLAfcO+MHYOHNgL79fIAijhJHUxzlYNk8tV07EA+H",
+	"e+Tpavp1dfWn6tYTRC/FZxiWO4qKckHqFGZTHNDgK8830Cso18wX8TNuRknvKeNsvZoGzrVov0qe3ueR",
+	"dzCP7GvALBkhHtqsV37zCgdxxTaYX0Cfzd++1CjhbYtGcXkwm0GVxwgmQmdYTv+zCeW9AfYsn497mQhD",
+	"FpzlEgcZ8tVlgeJ7P9LLS1/yOS4c62MMlFEn51t87G+DfDLmts+xkN+Rn+BiNX1GdCRykbHTbMqRI4ZV",
+	"OXKEwmq45LQt/vuiCxOGKV02W0hp0ejO8p2d/H2CA9seBz8tP/CkdzyMpPcHk9PYMwceh4U32gSEXq78",
+	"5hUsvDFlqXq1uLdItmoV262Seeitv5DOP1an75TfTqNEhrUL/r6zmkperfxQqi69cefDKOOxNTyz2rKB",
+	"bZX5x6aaOVODNlkNLJNambt1nO9esxA5IVTmHxMTwufRWv7xmOg9HbTbZoPP3+dk4HdicoAifHm1clep",
+	"vniDHPD3kRmsY5poWPHK63PV21PuSkFTsPdj9VvxEb+tjH5+rq9+Ve+93H53QxvvnbkfidpsXQ1whwot",
+	"8XzdaAjobE8RrHiRUBKU5hGNs3330hBNfzWPUuNS15A/xaKUccFPUdDpyD6a0vbZ54Sq/Cn2PlXF8wTl",
+	"Wks8lWu3q6V3+OPon04tPtYk5Y+uJ0HGIJC2ONcxbWs4I1oTVf+Jae6Z1pEUL57KhovzUF/fQkrxVr32",
+	"wLkRQF5Vnz+urLz0mKfsvT6WKgv/KG9saFtJ3XkfPx0j1lPJJR+9JewfYHnVJ6IyYynNY41pnkldAdUx",
+	"W0AVe78BFfERz3FpPnnR2S/Yw0hsLz3aubuoydkHlnXxUCXybZxuKKkyQbW85vpbZWoS93EIumbs0OEB",
+	"kR1GuZElCsk/pa2vu5WXsqceUTbGtvCnc+Gqo9jdLJpZVfX1i8rcpGP5TVu+CGAbPtZaBMp9Ev2hfVOe",
+	"ZnMm7y7OPjUZB7XectCpizrKLRpR5EArLj71SsYKjJ65nnutbs4g5dNT2DjVWs+8W2uJ5aOGfVxd6KCN",
+	"/MrV6q3SPtYVnHKMQ7z9TWVEduM1LFzDjd3ncVvHJbPJu+nZ7jyY8Ej0BXJT87KxNgHlEjWgHTaAZ0ZY",
+	"+AkztG5k3ZeMZfCA6oVjx/epWbubRT+rohuV8npeMyeh9n0K4Pa21e/1xhyrCHv4vBHvYj+Uwbqr4PME",
+	"rqlXp+0Sp5t8FHqePf35Z9E2f0Y1gbFxGuiYimbH6AG8b/OFP0QH3Mda1ON9I2AgjBr6mRPSdAc9IklZ",
+	"saO1NZsTR45KApM9OsxkUJ4qy9LjEa+7WiQgSrVv7WhtTfNJJj3Ci1LH59HPo9o9F0yO3Ct/s4YNWoKF",
+	"Fzs//1Le0sooVvURKExA5REqytD+41XCSrZZ1FogjUf2Lu6uzC91nulxtDfQcUbbGoeINQ7RTru1uzqz",
+	"tf1wSmuwa9wXp8cvjP//AA3d4DFnpAAA",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/repository"
+)
+
+// promoteSaveField は extra_fields に残したセーブキーを v2_save_data の列へ移す。既定は dry-run で、移す件数を報告するだけ。
+// 列は先にマイグレーションで追加しておく。-apply を付けると過去のセーブの列を書き換え、extra_fields からキーを消す。
+func promoteSaveField(ctx context.Context, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("promote-save-field", flag.ContinueOnError)
+	key := fs.String("key", "", "save JSON key kept in extra_fields (required)")
+	column := fs.String("column", "", "column of v2_save_data to move it into (default: the key)")
+	batch := fs.Int("batch", 500, "number of saves updated per transaction")
+	apply := fs.Bool("apply", false, "update the saves instead of only reporting them")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *key == "" {
+		return errors.New("-key is required")
+	}
+	if *column == "" {
+		*column = *key
+	}
+
+	db, err := openDB(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+
+	report, err := repository.New(db).PromoteSaveField(ctx, *key, *column, repository.PromoteOptions{Apply: *apply, BatchSize: *batch})
+	if err != nil && report.Promoted+report.Dropped == 0 {
+		return err
+	}
+	_, _ = fmt.Fprintln(out, report)
+	if err != nil {
+		return err
+	}
+	if report.DryRun && report.Promoted+report.Dropped > 0 {
+		_, _ = fmt.Fprintln(out, "run with -apply to update them")
+	}
+	if !report.DryRun && report.Promoted > 0 {
+		// 最新セーブから計算するランキング・統計は書き換えないので、必要なら作り直す
+		_, _ = fmt.Fprintln(out, "if the column feeds rankings or statistics, run rebuild-latest and recompute-stats -apply")
+	}
+	return nil
+}
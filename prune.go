@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/domain"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/pkg/config"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/repository"
+)
+
+// pruneSaves は保存ポリシーに従って古いセーブを間引く。既定は dry-run で、消える件数を報告するだけ。
+// -apply を付けると実際に削除する。最新セーブ・実績を解除したセーブ・不審なセーブとして記録されたものは消さない。
+func pruneSaves(ctx context.Context, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("prune-saves", flag.ContinueOnError)
+	keepAllDays := fs.Int("keep-all-days", config.RetentionKeepAllDays(), "keep every save younger than this many days")
+	dailyDays := fs.Int("daily-days", config.RetentionDailyDays(), "keep one save per day up to this age in days, one per week beyond")
+	batch := fs.Int("batch", 200, "number of users handled per batch")
+	apply := fs.Bool("apply", false, "delete the saves instead of only reporting them")
+	verbose := fs.Bool("v", false, "list the saves removed for each user")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	policy := domain.RetentionPolicy{KeepAllDays: *keepAllDays, DailyDays: *dailyDays}
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+
+	db, err := openDB(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+
+	opts := repository.PruneOptions{Apply: *apply, BatchSize: *batch}
+	if *verbose {
+		opts.OnUser = func(userID string, ids []int64) {
+			_, _ = fmt.Fprintf(out, "%s: %d saves %v\n", userID, len(ids), ids)
+		}
+	}
+	report, err := repository.New(db).PruneSaves(ctx, policy, time.Now(), opts)
+	_, _ = fmt.Fprintln(out, report)
+	if err != nil {
+		return err
+	}
+	if report.DryRun && report.Removed > 0 {
+		_, _ = fmt.Fprintln(out, "run with -apply to delete them")
+	}
+	return nil
+}
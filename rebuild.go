@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/domain"
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/repository"
+)
+
+// rebuildLatest は v3_user_latest_save_data と v3_user_latest_save_data_achievements を
+// セーブ履歴（v2_save_data と子テーブル）から InsertSaveV4 と同じ導出で作り直す。
+// -user を省略すると全ユーザーをバッチごとに確認し、ずれていたユーザーだけ書き直す（v4 の集計も差分だけ動かす）。
+// -verify では書き込まずにずれを報告する。-checkpoint のファイルにバッチごとの進み具合を残し、中断しても続きから再開できる。
+func rebuildLatest(ctx context.Context, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("rebuild-latest", flag.ContinueOnError)
+	user := fs.String("user", "", "handle only this user_id")
+	verify := fs.Bool("verify", false, "only report mismatches, do not rewrite anything")
+	batch := fs.Int("batch", 200, "number of users listed per batch")
+	after := fs.String("after", "", "resume after this user_id")
+	checkpoint := fs.String("checkpoint", "", "file that records the last user handled; a run resumes from it and removes it when done")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *batch < 1 {
+		return errors.New("-batch must be positive")
+	}
+
+	db, err := openDB(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+	repo := repository.New(db)
+
+	printMismatch := func(m domain.LatestSaveMismatch) {
+		_, _ = fmt.Fprintln(out, m)
+	}
+
+	if *user != "" {
+		mismatches, err := repo.VerifyLatestSave(ctx, *user)
+		if err != nil {
+			return err
+		}
+		for _, m := range mismatches {
+			printMismatch(m)
+		}
+		switch {
+		case len(mismatches) == 0:
+			_, _ = fmt.Fprintf(out, "%s is up to date\n", *user)
+		case *verify:
+			_, _ = fmt.Fprintf(out, "%d mismatches (run without -verify to rebuild)\n", len(mismatches))
+		default:
+			if err := repo.RebuildLatestSave(ctx, *user); err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+			_, _ = fmt.Fprintf(out, "rebuilt %s\n", *user)
+		}
+		return nil
+	}
+
+	opts := repository.RebuildOptions{
+		VerifyOnly:  *verify,
+		AfterUserID: *after,
+		BatchSize:   *batch,
+		OnMismatch:  printMismatch,
+		OnBatch: func(r domain.RebuildReport) error {
+			_, _ = fmt.Fprintf(out, "... %s (up to %s)\n", r, r.LastUserID)
+			if *checkpoint == "" {
+				return nil
+			}
+			return os.WriteFile(*checkpoint, []byte(r.LastUserID+"\n"), 0o644)
+		},
+	}
+	if *checkpoint != "" && opts.AfterUserID == "" {
+		b, err := os.ReadFile(*checkpoint)
+		switch {
+		case err == nil:
+			opts.AfterUserID = strings.TrimSpace(string(b))
+			_, _ = fmt.Fprintf(out, "resuming after %s\n", opts.AfterUserID)
+		case !errors.Is(err, os.ErrNotExist):
+			return fmt.Errorf("read checkpoint: %w", err)
+		}
+	}
+
+	report, err := repo.RebuildLatestSaves(ctx, opts)
+	_, _ = fmt.Fprintln(out, report)
+	if err != nil {
+		if report.LastUserID != "" {
+			_, _ = fmt.Fprintf(out, "stopped; resume with -after %s\n", report.LastUserID)
+		}
+		return err
+	}
+	if *checkpoint != "" {
+		if err := os.Remove(*checkpoint); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("remove checkpoint: %w", err)
+		}
+	}
+	if *verify && report.Mismatched > 0 {
+		_, _ = fmt.Fprintln(out, "run without -verify to rebuild them")
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/repository"
+)
+
+// recomputeStats は v4_summary_* を v3_user_latest_save_data から再計算し、ずれを報告する。
+// -apply を付けるとずれていた値を再計算結果で上書きする。
+func recomputeStats(ctx context.Context, args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("recompute-stats", flag.ContinueOnError)
+	apply := fs.Bool("apply", false, "overwrite drifted summary values with the recomputed ones")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	db, err := openDB(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = db.Close()
+	}()
+
+	drifts, err := repository.New(db).ReconcileSummary(ctx, *apply)
+	if err != nil {
+		return err
+	}
+	for _, d := range drifts {
+		_, _ = fmt.Fprintln(out, d)
+	}
+	switch {
+	case len(drifts) == 0:
+		_, _ = fmt.Fprintln(out, "no drift")
+	case *apply:
+		_, _ = fmt.Fprintf(out, "fixed %d drifted values\n", len(drifts))
+	default:
+		_, _ = fmt.Fprintf(out, "%d drifted values (run with -apply to fix)\n", len(drifts))
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+// savekeys はクライアントの ClientJsonKeyDefines.cs からセーブの JSON キー一覧（domain.SaveKeys）を生成する。
+//
+//	go run ./tools/savekeys -in ClientJsonKeyDefines.cs -out internal/domain/savekeys.gen.go
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// key は ClientJsonKeyDefines.cs の const 1 つ分
+type key struct {
+	Const    string
+	Key      string
+	Summary  string
+	JSONType string
+	GameType string
+	Since    int
+	Temp     bool
+	Obsolete string
+}
+
+var (
+	constPattern    = regexp.MustCompile(`^public const string (\w+) = "([^"]*)";$`)
+	sincePattern    = regexp.MustCompile(`追加した Save Version: (\d+)`)
+	typePattern     = regexp.MustCompile(`データ型 - JSON: \[(\w+)\], ゲーム内: \[(\w+)\]\s*(?:\((.+)\))?`)
+	obsoletePattern = regexp.MustCompile(`^\[System\.Obsolete\("(.*)"\)\]$`)
+	tagPattern      = regexp.MustCompile(`<[^>]+>`)
+)
+
+func main() {
+	in := flag.String("in", "ClientJsonKeyDefines.cs", "path of ClientJsonKeyDefines.cs")
+	out := flag.String("out", "internal/domain/savekeys.gen.go", "path of the generated Go file")
+	flag.Parse()
+
+	f, err := os.Open(*in)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	keys, err := parse(f)
+	if err != nil {
+		log.Fatalf("parse %s: %v", *in, err)
+	}
+	src, err := render(keys)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// parse は const の直前のドキュメントコメントと属性からキーの定義を読む
+func parse(r io.Reader) ([]key, error) {
+	var (
+		keys      []key
+		summary   []string
+		remarks   []string
+		obsolete  string
+		inSummary bool
+		inRemarks bool
+		temp      bool
+	)
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		text := strings.TrimSpace(strings.TrimPrefix(sc.Text(), "\ufeff"))
+		doc, isDoc := strings.CutPrefix(text, "///")
+		doc = strings.TrimSpace(doc)
+		switch {
+		case strings.HasPrefix(text, "#region"):
+			// 一時セーブのキーはゲームの途中状態で、サーバーには保存しない
+			temp = strings.Contains(text, "一時セーブ")
+		case isDoc && doc == "<summary>":
+			summary, remarks, obsolete = nil, nil, ""
+			inSummary = true
+		case isDoc && doc == "</summary>":
+			inSummary = false
+		case isDoc && doc == "<remarks>":
+			inRemarks = true
+		case isDoc && doc == "</remarks>":
+			inRemarks = false
+		case isDoc && inSummary:
+			summary = append(summary, doc)
+		case isDoc && inRemarks:
+			remarks = append(remarks, doc)
+		case obsoletePattern.MatchString(text):
+			obsolete = obsoletePattern.FindStringSubmatch(text)[1]
+		case constPattern.MatchString(text):
+			m := constPattern.FindStringSubmatch(text)
+			k, err := newKey(m[1], m[2], summary, remarks, obsolete, temp)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			keys = append(keys, k)
+			summary, remarks, obsolete = nil, nil, ""
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no key constants found")
+	}
+	return keys, nil
+}
+
+func newKey(name, jsonKey string, summary, remarks []string, obsolete string, temp bool) (key, error) {
+	k := key{Const: name, Key: jsonKey, Obsolete: obsolete, Temp: temp}
+
+	// [Temp] は一時セーブ以外のリージョンにも付いている（bbox など）ので、見るのはリージョンだけ
+	s := tagPattern.ReplaceAllString(strings.Join(summary, " "), "")
+	s = strings.TrimPrefix(strings.TrimSpace(s), "[Temp]")
+	s = strings.TrimPrefix(strings.TrimSpace(s), "[JSON キー]")
+	k.Summary = strings.TrimSpace(s)
+
+	for _, r := range remarks {
+		if m := sincePattern.FindStringSubmatch(r); m != nil {
+			v, err := strconv.Atoi(m[1])
+			if err != nil {
+				return key{}, fmt.Errorf("%s: save version: %w", name, err)
+			}
+			k.Since = v
+		}
+		if m := typePattern.FindStringSubmatch(r); m != nil {
+			k.JSONType, k.GameType = m[1], m[2]
+			if m[3] != "" {
+				k.GameType += " (" + m[3] + ")"
+			}
+		}
+	}
+	// 一時セーブ以外は型の記載が必須（ないとサーバー側の型を決められない）
+	if !k.Temp && k.JSONType == "" {
+		return key{}, fmt.Errorf("%s: no データ型 remark", name)
+	}
+	return k, nil
+}
+
+var fileTemplate = template.Must(template.New("").Parse(`// Code generated by go run ./tools/savekeys; DO NOT EDIT.
+// Source: ClientJsonKeyDefines.cs
+
+package domain
+
+// SaveKeys lists the JSON keys of ClientJsonKeyDefines.cs in file order.
+var SaveKeys = []SaveKey{
+{{- range .}}
+	{Const: {{printf "%q" .Const}}, Key: {{printf "%q" .Key}}, Summary: {{printf "%q" .Summary}}
+		{{- if .JSONType}}, JSONType: {{printf "%q" .JSONType}}, GameType: {{printf "%q" .GameType}}{{end}}
+		{{- if .Since}}, Since: {{.Since}}{{end}}
+		{{- if .Temp}}, Temp: true{{end}}
+		{{- if .Obsolete}}, Obsolete: {{printf "%q" .Obsolete}}{{end}}},
+{{- end}}
+}
+`))
+
+func render(keys []key) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := fileTemplate.Execute(&buf, keys); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
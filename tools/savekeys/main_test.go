@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	const src = `public static class JsonKeyDefines
+{
+    #region JSON キー定義 - セーブ値
+
+    /// <summary>
+    /// [JSON キー] セーブデータの作成日時<br/>
+    /// 文字列で保存
+    /// </summary>
+    /// <remarks>
+    /// 追加した Save Version: 5<br/>
+    /// データ型 - JSON: [String], ゲーム内: [Long]
+    /// </remarks>
+    public const string FirstBoot = "firstboot";
+
+    /// <summary>
+    /// [JSON キー] パークレベル
+    /// </summary>
+    /// <remarks>
+    /// データ型 - JSON: [List], ゲーム内: [List] (Int)
+    /// </remarks>
+    public const string ListPerkLevels = "l_perks";
+
+    /// <summary>
+    /// [Temp] [JSON キー] BlackBox（仮名）所持数
+    /// </summary>
+    /// <remarks>
+    /// 追加した Save Version: 14<br/>
+    /// データ型 - JSON: [Double], ゲーム内: [Int]
+    /// </remarks>
+    public const string BlackBox = "bbox";
+
+    #endregion
+
+    #region JSON キー定義 - 一時セーブ
+
+    /// <summary>
+    /// [Temp] [JSON キー] スキルポイント
+    /// </summary>
+    [System.Obsolete("Moved to JsonKeyDefines.SkillPoint")]
+    public const string TempSkillPoint = "sp";
+
+    #endregion
+}
+`
+	keys, err := parse(strings.NewReader(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []key{
+		{Const: "FirstBoot", Key: "firstboot", Summary: "セーブデータの作成日時 文字列で保存", JSONType: "String", GameType: "Long", Since: 5},
+		{Const: "ListPerkLevels", Key: "l_perks", Summary: "パークレベル", JSONType: "List", GameType: "List (Int)"},
+		{Const: "BlackBox", Key: "bbox", Summary: "BlackBox（仮名）所持数", JSONType: "Double", GameType: "Int", Since: 14},
+		{Const: "TempSkillPoint", Key: "sp", Summary: "スキルポイント", Temp: true, Obsolete: "Moved to JsonKeyDefines.SkillPoint"},
+	}
+	if !reflect.DeepEqual(keys, want) {
+		t.Fatalf("got  %+v\nwant %+v", keys, want)
+	}
+}
+
+func TestParseRequiresType(t *testing.T) {
+	const src = `
+    /// <summary>
+    /// [JSON キー] 型の書いていないキー
+    /// </summary>
+    public const string Untyped = "untyped";
+`
+	if _, err := parse(strings.NewReader(src)); err == nil {
+		t.Fatalf("a stored key without a type must be an error")
+	}
+}
+
+func TestGeneratedFileIsUpToDate(t *testing.T) {
+	f, err := os.Open("../../ClientJsonKeyDefines.cs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	keys, err := parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := render(keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile("../../internal/domain/savekeys.gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("internal/domain/savekeys.gen.go is stale; run go generate ./internal/domain")
+	}
+}
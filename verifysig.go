@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/pikachu0310/very-big-medal-pusher-data-server/internal/handler"
+)
+
+var errInvalidSignature = errors.New("signature does not match")
+
+// verifySig はクライアントの署名をサーバーと同じ手順で検証し、期待される署名も表示する。
+// 「署名が合わない」という問い合わせで、鍵・user_id のエンコード・data のどれがずれているかを切り分ける。
+//
+//	verify-sig save -user_id <id> -data <json> -sig <hex>   … /v4/data の署名
+//	verify-sig user -user_id <id> -sig <hex>                … /v4/users/{user_id}/... の署名
+func verifySig(_ context.Context, args []string, out io.Writer) error {
+	if len(args) == 0 || (args[0] != "save" && args[0] != "user") {
+		return errors.New("usage: verify-sig save|user -user_id <id> [-data <json>] -sig <hex>")
+	}
+	kind := args[0]
+
+	fs := flag.NewFlagSet("verify-sig "+kind, flag.ContinueOnError)
+	userID := fs.String("user_id", "", "user_id as sent in the query or path (URL-encoded form is accepted)")
+	sig := fs.String("sig", "", "signature sent by the client")
+	var data *string
+	if kind == "save" {
+		data = fs.String("data", "", "save data JSON exactly as sent by the client")
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *userID == "" || *sig == "" || (data != nil && *data == "") {
+		fs.Usage()
+		return errors.New("missing required flags")
+	}
+
+	var (
+		valid    bool
+		expected string
+		err      error
+	)
+	if kind == "save" {
+		if valid, err = handler.VerifySaveSignatureV4(*userID, *data, *sig); err != nil {
+			return err
+		}
+		if expected, err = handler.SaveSignatureV4(*userID, *data); err != nil {
+			return err
+		}
+	} else {
+		if valid, err = handler.VerifyUserSignatureV4(*userID, *sig); err != nil {
+			return err
+		}
+		expected = handler.UserSignature(*userID)
+	}
+
+	if valid {
+		_, _ = fmt.Fprintln(out, "valid")
+		return nil
+	}
+	_, _ = fmt.Fprintf(out, "invalid\nexpected: %s\n", expected)
+	return errInvalidSignature
+}